@@ -18,6 +18,7 @@ package v1alpha1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
@@ -32,6 +33,10 @@ const (
 
 	// DecreaseFirstRolloutStrategyType indicates that we decrease the source resources first
 	DecreaseFirstRolloutStrategyType RolloutStrategyType = "DecreaseFirst"
+
+	// BlueGreenRolloutStrategyType indicates that we scale the target resources to the full size alongside
+	// the source resources and then switch all the traffic to the target at once
+	BlueGreenRolloutStrategyType RolloutStrategyType = "BlueGreen"
 )
 
 // HookType can be pre, post or during rollout
@@ -142,6 +147,14 @@ type RolloutPlan struct {
 	// before complete the process
 	// +optional
 	CanaryMetric []CanaryMetric `json:"canaryMetric,omitempty"`
+
+	// TrafficRouting shifts the traffic from the source to the target along with the batches
+	// +optional
+	TrafficRouting *TrafficRouting `json:"trafficRouting,omitempty"`
+
+	// BlueGreen defines how the traffic is switched when the rollout strategy is BlueGreen
+	// +optional
+	BlueGreen *BlueGreenStrategy `json:"blueGreen,omitempty"`
 }
 
 // RolloutBatch is used to describe how the each batch rollout should be
@@ -177,6 +190,12 @@ type RolloutBatch struct {
 	// before moving to the next batch
 	// +optional
 	CanaryMetric []CanaryMetric `json:"canaryMetric,omitempty"`
+
+	// TrafficWeight is the percentage of the traffic routed to the target once the pods in the batch are ready
+	// it only works with the traffic routing of the rollout plan
+	// default is the percentage of the upgraded pods
+	// +optional
+	TrafficWeight *int32 `json:"trafficWeight,omitempty"`
 }
 
 // RolloutWebhook holds the reference to external checks used for canary analysis
@@ -233,6 +252,73 @@ type CanaryMetric struct {
 	TemplateRef *corev1.ObjectReference `json:"templateRef,omitempty"`
 }
 
+// TrafficRouting defines how the traffic is shifted between the services of the source and the target
+// exactly one of the istio, smi and nginx routing has to be set
+type TrafficRouting struct {
+	// StableService is the name of the service that selects the pods of the source
+	StableService string `json:"stableService"`
+
+	// CanaryService is the name of the service that selects the pods of the target
+	CanaryService string `json:"canaryService"`
+
+	// Istio shifts the traffic by updating the weights of the routes in an Istio VirtualService
+	// +optional
+	Istio *IstioTrafficRouting `json:"istio,omitempty"`
+
+	// SMI shifts the traffic by updating the weights of the backends in an SMI TrafficSplit
+	// +optional
+	SMI *SMITrafficRouting `json:"smi,omitempty"`
+
+	// Nginx shifts the traffic by a canary ingress with the NGINX-ingress canary annotations
+	// +optional
+	Nginx *NginxTrafficRouting `json:"nginx,omitempty"`
+}
+
+// IstioTrafficRouting holds the reference to the Istio VirtualService
+type IstioTrafficRouting struct {
+	// VirtualService is the name of the VirtualService in the same namespace
+	VirtualService string `json:"virtualService"`
+
+	// Routes are the names of the http routes to update, all the http routes are updated if empty
+	// +optional
+	Routes []string `json:"routes,omitempty"`
+}
+
+// SMITrafficRouting holds the reference to the SMI TrafficSplit
+type SMITrafficRouting struct {
+	// TrafficSplit is the name of the TrafficSplit in the same namespace, it is created if not exists
+	TrafficSplit string `json:"trafficSplit"`
+
+	// RootService is the service the clients use to communicate, default is the stable service
+	// +optional
+	RootService string `json:"rootService,omitempty"`
+}
+
+// NginxTrafficRouting holds the reference to the ingress served by NGINX-ingress
+type NginxTrafficRouting struct {
+	// Ingress is the name of the ingress routing to the stable service,
+	// a canary ingress routing to the canary service is created alongside it
+	Ingress string `json:"ingress"`
+}
+
+// BlueGreenStrategy defines how the blue-green rollout switches the traffic from the source to the target
+type BlueGreenStrategy struct {
+	// ActiveService is the name of the service that serves the production traffic,
+	// its selector is switched from the pods of the source to the pods of the target once the target is verified.
+	// The traffic is switched by the traffic routing of the rollout plan instead if it is set
+	// +optional
+	ActiveService string `json:"activeService,omitempty"`
+
+	// PreviewService is the name of the service that selects the pods of the target during the verification
+	// +optional
+	PreviewService string `json:"previewService,omitempty"`
+
+	// ScaleDownDelaySeconds is how long the source is kept in its full size after the traffic is switched
+	// so that the rollout can be rolled back instantly, default is 30
+	// +optional
+	ScaleDownDelaySeconds *int32 `json:"scaleDownDelaySeconds,omitempty"`
+}
+
 // MetricsExpectedRange defines the range used for metrics validation
 type MetricsExpectedRange struct {
 	// Minimum value
@@ -282,4 +368,12 @@ type RolloutStatus struct {
 
 	// UpgradedReadyReplicas is the number of Pods upgraded by the rollout controller that have a Ready Condition.
 	UpgradedReadyReplicas int32 `json:"upgradedReadyReplicas"`
+
+	// TrafficWeight is the percentage of the traffic routed to the target by the traffic routing
+	// +optional
+	TrafficWeight *int32 `json:"trafficWeight,omitempty"`
+
+	// TrafficShiftedTime is the last time the traffic routing was changed
+	// +optional
+	TrafficShiftedTime *metav1.Time `json:"trafficShiftedTime,omitempty"`
 }
@@ -217,6 +217,9 @@ func (r *RolloutStatus) ResetStatus() {
 	r.CurrentBatch = 0
 	r.UpgradedReplicas = 0
 	r.UpgradedReadyReplicas = 0
+	// the traffic of the next rollout starts from its source
+	r.TrafficWeight = nil
+	r.TrafficShiftedTime = nil
 }
 
 // SetRolloutCondition sets the supplied condition, replacing any existing condition
@@ -26,6 +26,26 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BlueGreenStrategy) DeepCopyInto(out *BlueGreenStrategy) {
+	*out = *in
+	if in.ScaleDownDelaySeconds != nil {
+		in, out := &in.ScaleDownDelaySeconds, &out.ScaleDownDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BlueGreenStrategy.
+func (in *BlueGreenStrategy) DeepCopy() *BlueGreenStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(BlueGreenStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CanaryMetric) DeepCopyInto(out *CanaryMetric) {
 	*out = *in
@@ -67,6 +87,26 @@ func (in *CompRolloutStatus) DeepCopy() *CompRolloutStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IstioTrafficRouting) DeepCopyInto(out *IstioTrafficRouting) {
+	*out = *in
+	if in.Routes != nil {
+		in, out := &in.Routes, &out.Routes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IstioTrafficRouting.
+func (in *IstioTrafficRouting) DeepCopy() *IstioTrafficRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(IstioTrafficRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetricsExpectedRange) DeepCopyInto(out *MetricsExpectedRange) {
 	*out = *in
@@ -92,6 +132,21 @@ func (in *MetricsExpectedRange) DeepCopy() *MetricsExpectedRange {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NginxTrafficRouting) DeepCopyInto(out *NginxTrafficRouting) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NginxTrafficRouting.
+func (in *NginxTrafficRouting) DeepCopy() *NginxTrafficRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(NginxTrafficRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Rollout) DeepCopyInto(out *Rollout) {
 	*out = *in
@@ -152,6 +207,11 @@ func (in *RolloutBatch) DeepCopyInto(out *RolloutBatch) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TrafficWeight != nil {
+		in, out := &in.TrafficWeight, &out.TrafficWeight
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutBatch.
@@ -235,6 +295,16 @@ func (in *RolloutPlan) DeepCopyInto(out *RolloutPlan) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TrafficRouting != nil {
+		in, out := &in.TrafficRouting, &out.TrafficRouting
+		*out = new(TrafficRouting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BlueGreen != nil {
+		in, out := &in.BlueGreen, &out.BlueGreen
+		*out = new(BlueGreenStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutPlan.
@@ -267,6 +337,15 @@ func (in *RolloutSpec) DeepCopy() *RolloutSpec {
 func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
 	*out = *in
 	in.ConditionedStatus.DeepCopyInto(&out.ConditionedStatus)
+	if in.TrafficWeight != nil {
+		in, out := &in.TrafficWeight, &out.TrafficWeight
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TrafficShiftedTime != nil {
+		in, out := &in.TrafficShiftedTime, &out.TrafficShiftedTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
@@ -331,3 +410,48 @@ func (in *RolloutWebhookPayload) DeepCopy() *RolloutWebhookPayload {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SMITrafficRouting) DeepCopyInto(out *SMITrafficRouting) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SMITrafficRouting.
+func (in *SMITrafficRouting) DeepCopy() *SMITrafficRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(SMITrafficRouting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficRouting) DeepCopyInto(out *TrafficRouting) {
+	*out = *in
+	if in.Istio != nil {
+		in, out := &in.Istio, &out.Istio
+		*out = new(IstioTrafficRouting)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SMI != nil {
+		in, out := &in.SMI, &out.SMI
+		*out = new(SMITrafficRouting)
+		**out = **in
+	}
+	if in.Nginx != nil {
+		in, out := &in.Nginx, &out.Nginx
+		*out = new(NginxTrafficRouting)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficRouting.
+func (in *TrafficRouting) DeepCopy() *TrafficRouting {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficRouting)
+	in.DeepCopyInto(out)
+	return out
+}
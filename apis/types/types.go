@@ -104,4 +104,5 @@ type Cluster struct {
 	Type     string
 	EndPoint string
 	Accepted bool
+	Labels   map[string]string
 }
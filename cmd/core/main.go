@@ -34,16 +34,21 @@ import (
 	"k8s.io/klog/v2"
 	"k8s.io/klog/v2/klogr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
+	velatypes "github.com/oam-dev/kubevela/apis/types"
 	standardcontroller "github.com/oam-dev/kubevela/pkg/controller"
+	"github.com/oam-dev/kubevela/pkg/controller/clusterhealth"
 	commonconfig "github.com/oam-dev/kubevela/pkg/controller/common"
 	oamcontroller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	oamv1alpha2 "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2"
+	"github.com/oam-dev/kubevela/pkg/controller/sharding"
 	"github.com/oam-dev/kubevela/pkg/controller/utils"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	_ "github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	"github.com/oam-dev/kubevela/pkg/monitor/tracing"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
@@ -84,6 +89,7 @@ func main() {
 	var renewDeadline time.Duration
 	var retryPeriod time.Duration
 	var enableClusterGateway bool
+	var tracingOpts tracing.Options
 
 	flag.BoolVar(&useWebhook, "use-webhook", false, "Enable Admission Webhook")
 	flag.StringVar(&certDir, "webhook-cert-dir", "/k8s-webhook-server/serving-certs", "Admission webhook cert/key dir.")
@@ -131,7 +137,19 @@ func main() {
 	flag.DurationVar(&retryPeriod, "leader-election-retry-period", 2*time.Second,
 		"The duration the LeaderElector clients should wait between tries of actions")
 	flag.BoolVar(&enableClusterGateway, "enable-cluster-gateway", false, "Enable cluster-gateway to use multicluster, disabled by default.")
-
+	flag.DurationVar(&controllerArgs.ClusterHealthProbeInterval, "cluster-health-probe-interval", time.Minute,
+		"The interval to probe the health of the managed clusters, only works when cluster-gateway is enabled.")
+	flag.DurationVar(&controllerArgs.ClusterCredentialExpiryWarning, "cluster-credential-expiry-warning", 7*24*time.Hour,
+		"How long ahead of the expiration of the managed cluster credentials to emit warnings, only works when cluster-gateway is enabled.")
+
+	flag.BoolVar(&controllerArgs.EnableSharding, "enable-sharding", false, "Enable sharding, each shard of the controller only reconciles the applications assigned to it.")
+	flag.StringVar(&controllerArgs.ShardID, "shard-id", sharding.MasterShardID, "The id of the shard the controller runs for if sharding is enabled. "+
+		"The master shard assigns the applications to the live shards and runs all the controllers, the other shards only run the application controller.")
+	flag.DurationVar(&controllerArgs.ShardLeaseDuration, "shard-lease-duration", 40*time.Second,
+		"How long a shard is considered alive after its last heartbeat, the applications of a dead shard are assigned to the other shards.")
+	flag.StringVar(&controllerArgs.ShardLeaseNamespace, "shard-lease-namespace", velatypes.DefaultKubeVelaNS, "The namespace of the leases recording the heartbeats of the shards.")
+	flag.StringVar(&tracingOpts.Endpoint, "tracing-endpoint", "", "The OTLP/HTTP endpoint the traces of the reconciles are exported to, like http://otel-collector:4318. Tracing is disabled if it's empty.")
+	flag.Float64Var(&tracingOpts.SamplingRatio, "tracing-sampling-ratio", 1, "The ratio of the reconciles to trace, within [0, 1].")
 	flag.Parse()
 	// setup logging
 	klog.InitFlags(nil)
@@ -183,6 +201,25 @@ func main() {
 	klog.InfoS("Disable capabilities", "name", disableCaps)
 	klog.InfoS("Vela-Core init", "definition namespace", oam.SystemDefinitonNamespace)
 
+	shutdownTracing, err := tracing.Setup(tracingOpts)
+	if err != nil {
+		klog.ErrorS(err, "Unable to setup tracing")
+		os.Exit(1)
+	}
+	stopTracing := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			klog.ErrorS(err, "Failed to flush the traces")
+		}
+	}
+	defer stopTracing()
+	// os.Exit skips the deferred functions, so the traces are flushed before exiting
+	exit := func(code int) {
+		stopTracing()
+		os.Exit(code)
+	}
+
 	restConfig := ctrl.GetConfigOrDie()
 	restConfig.UserAgent = kubevelaName + "/" + version.GitRevision
 	restConfig.QPS = float32(qps)
@@ -192,16 +229,25 @@ func main() {
 	if enableClusterGateway {
 		if _, err := multicluster.Initialize(restConfig, true); err != nil {
 			klog.ErrorS(err, "failed to enable multicluster")
-			os.Exit(1)
+			exit(1)
 		}
 	}
 	ctrl.SetLogger(klogr.New())
+	leaderElectionID := kubevelaName
+	var newCache cache.NewCacheFunc
+	if controllerArgs.EnableSharding {
+		klog.InfoS("Sharding is enabled", "shard", controllerArgs.ShardID)
+		if controllerArgs.ShardID != sharding.MasterShardID {
+			newCache = sharding.NewCache(controllerArgs.ShardID)
+			leaderElectionID = kubevelaName + "-" + controllerArgs.ShardID
+		}
+	}
 	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                     scheme,
 		MetricsBindAddress:         metricsAddr,
 		LeaderElection:             enableLeaderElection,
 		LeaderElectionNamespace:    leaderElectionNamespace,
-		LeaderElectionID:           kubevelaName,
+		LeaderElectionID:           leaderElectionID,
 		Port:                       webhookPort,
 		CertDir:                    certDir,
 		HealthProbeBindAddress:     healthAddr,
@@ -211,20 +257,21 @@ func main() {
 		RenewDeadline:              &renewDeadline,
 		RetryPeriod:                &retryPeriod,
 		ClientDisableCacheFor:      []client.Object{&appsv1.ControllerRevision{}},
+		NewCache:                   newCache,
 	})
 	if err != nil {
 		klog.ErrorS(err, "Unable to create a controller manager")
-		os.Exit(1)
+		exit(1)
 	}
 
 	if err := registerHealthChecks(mgr); err != nil {
 		klog.ErrorS(err, "Unable to register ready/health checks")
-		os.Exit(1)
+		exit(1)
 	}
 
 	if err := utils.CheckDisabledCapabilities(disableCaps); err != nil {
 		klog.ErrorS(err, "Unable to get enabled capabilities")
-		os.Exit(1)
+		exit(1)
 	}
 
 	switch strings.ToLower(applyOnceOnly) {
@@ -241,20 +288,20 @@ func main() {
 		klog.ErrorS(fmt.Errorf("invalid apply-once-only value: %s", applyOnceOnly),
 			"Unable to setup the vela core controller",
 			"apply-once-only", "on/off/force, by default it's off")
-		os.Exit(1)
+		exit(1)
 	}
 
 	dm, err := discoverymapper.New(mgr.GetConfig())
 	if err != nil {
 		klog.ErrorS(err, "Failed to create CRD discovery client")
-		os.Exit(1)
+		exit(1)
 	}
 	controllerArgs.DiscoveryMapper = dm
 	pd, err := packages.NewPackageDiscover(mgr.GetConfig())
 	if err != nil {
 		klog.Error(err, "Failed to create CRD discovery for CUE package client")
 		if !packages.IsCUEParseErr(err) {
-			os.Exit(1)
+			exit(1)
 		}
 	}
 	controllerArgs.PackageDiscover = pd
@@ -264,18 +311,32 @@ func main() {
 		oamwebhook.Register(mgr, controllerArgs)
 		if err := waitWebhookSecretVolume(certDir, waitSecretTimeout, waitSecretInterval); err != nil {
 			klog.ErrorS(err, "Unable to get webhook secret")
-			os.Exit(1)
+			exit(1)
 		}
 	}
 
 	if err = oamv1alpha2.Setup(mgr, controllerArgs); err != nil {
 		klog.ErrorS(err, "Unable to setup the oam controller")
-		os.Exit(1)
+		exit(1)
 	}
 
-	if err = standardcontroller.Setup(mgr, disableCaps, controllerArgs); err != nil {
-		klog.ErrorS(err, "Unable to setup the vela core controller")
-		os.Exit(1)
+	if sharding.IsMaster(controllerArgs) {
+		if err = standardcontroller.Setup(mgr, disableCaps, controllerArgs); err != nil {
+			klog.ErrorS(err, "Unable to setup the vela core controller")
+			exit(1)
+		}
+	}
+
+	if err = sharding.Setup(mgr, controllerArgs); err != nil {
+		klog.ErrorS(err, "Unable to setup sharding")
+		exit(1)
+	}
+
+	if enableClusterGateway && sharding.IsMaster(controllerArgs) {
+		if err = clusterhealth.Setup(mgr, controllerArgs); err != nil {
+			klog.ErrorS(err, "Unable to setup the cluster health controller")
+			exit(1)
+		}
 	}
 
 	if driver := os.Getenv(system.StorageDriverEnv); len(driver) == 0 {
@@ -283,7 +344,7 @@ func main() {
 		err := os.Setenv(system.StorageDriverEnv, storageDriver)
 		if err != nil {
 			klog.ErrorS(err, "Unable to setup the vela core controller")
-			os.Exit(1)
+			exit(1)
 		}
 	}
 	klog.InfoS("Use storage driver", "storageDriver", os.Getenv(system.StorageDriverEnv))
@@ -292,7 +353,7 @@ func main() {
 
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		klog.ErrorS(err, "Failed to run manager")
-		os.Exit(1)
+		exit(1)
 	}
 	if logFilePath != "" {
 		klog.Flush()
@@ -27,6 +27,17 @@ func init() {
 	RegistModel(&ApplicationComponent{}, &ApplicationPolicy{}, &Application{}, &ApplicationRevision{})
 }
 
+const (
+	// LabelSourceOfTruth describes where the application is created from
+	LabelSourceOfTruth = "ux.oam.dev/source-of-truth"
+	// FromCR means the application is synced from the application CR created by CLI or kubectl
+	FromCR = "from-k8s-resource"
+	// LabelSyncNamespace describes the namespace of the application CR synced from
+	LabelSyncNamespace = "ux.oam.dev/from-namespace"
+	// LabelSyncGeneration describes the generation of the application CR synced from
+	LabelSyncGeneration = "ux.oam.dev/synced-generation"
+)
+
 // Application application delivery model
 type Application struct {
 	Model
@@ -49,6 +60,11 @@ func (a *Application) PrimaryKey() string {
 	return a.Name
 }
 
+// IsSynced returns whether the application is synced from the application CR
+func (a *Application) IsSynced() bool {
+	return a.Labels[LabelSourceOfTruth] == FromCR
+}
+
 // Index return custom index
 func (a *Application) Index() map[string]string {
 	index := make(map[string]string)
@@ -52,6 +52,9 @@ type JSONStruct map[string]interface{}
 
 // NewJSONStruct new jsonstruct from runtime.RawExtension
 func NewJSONStruct(raw *runtime.RawExtension) (*JSONStruct, error) {
+	if raw == nil || raw.Raw == nil {
+		return nil, nil
+	}
 	var data JSONStruct
 	err := json.Unmarshal(raw.Raw, &data)
 	if err != nil {
@@ -16,10 +16,22 @@ limitations under the License.
 
 package model
 
+import "fmt"
+
 func init() {
 	RegistModel(&Project{})
+	RegistModel(&ProjectUser{})
 }
 
+const (
+	// ProjectRoleAdmin the role could manage the project and its members
+	ProjectRoleAdmin = "admin"
+	// ProjectRoleMember the role could manage the applications in the project
+	ProjectRoleMember = "member"
+	// ProjectRoleViewer the role could only view the applications in the project
+	ProjectRoleViewer = "viewer"
+)
+
 // Project project model
 type Project struct {
 	Model
@@ -28,6 +40,20 @@ type Project struct {
 	Description string `json:"description,omitempty"`
 	// Namespace Control cluster namespace
 	Namespace string `json:"namespace"`
+	// Quota limits the resources could be used by the project, no limit if it is empty
+	Quota *ProjectQuota `json:"quota,omitempty"`
+}
+
+// ProjectQuota the quota of the project
+type ProjectQuota struct {
+	// MaxApplications is the max number of the applications, zero means no limit
+	MaxApplications int64 `json:"maxApplications,omitempty"`
+	// DeliveryTargets is the allowed delivery targets, empty means all targets
+	DeliveryTargets []string `json:"deliveryTargets,omitempty"`
+	// Clusters is the allowed clusters of the delivery targets, empty means all clusters
+	Clusters []string `json:"clusters,omitempty"`
+	// DefinitionTypes is the allowed component and trait types, empty means all types
+	DefinitionTypes []string `json:"definitionTypes,omitempty"`
 }
 
 // TableName return custom table name
@@ -51,3 +77,36 @@ func (p *Project) Index() map[string]string {
 	}
 	return index
 }
+
+// ProjectUser the member of the project
+type ProjectUser struct {
+	Model
+	ProjectName string `json:"projectName"`
+	Username    string `json:"username"`
+	Role        string `json:"role"`
+}
+
+// TableName return custom table name
+func (p *ProjectUser) TableName() string {
+	return tableNamePrefix + "project_user"
+}
+
+// PrimaryKey return custom primary key
+func (p *ProjectUser) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", p.ProjectName, p.Username)
+}
+
+// Index return custom index
+func (p *ProjectUser) Index() map[string]string {
+	index := make(map[string]string)
+	if p.ProjectName != "" {
+		index["projectName"] = p.ProjectName
+	}
+	if p.Username != "" {
+		index["username"] = p.Username
+	}
+	if p.Role != "" {
+		index["role"] = p.Role
+	}
+	return index
+}
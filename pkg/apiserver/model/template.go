@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import "fmt"
+
+func init() {
+	RegistModel(&ApplicationTemplate{}, &ApplicationTemplateVersion{})
+}
+
+// ApplicationTemplate application template model
+type ApplicationTemplate struct {
+	Model
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Project is the project of the application the template published from
+	Project    string `json:"project"`
+	CreateUser string `json:"createUser"`
+}
+
+// TableName return custom table name
+func (a *ApplicationTemplate) TableName() string {
+	return tableNamePrefix + "application_template"
+}
+
+// PrimaryKey return custom primary key
+func (a *ApplicationTemplate) PrimaryKey() string {
+	return a.Name
+}
+
+// Index return custom index
+func (a *ApplicationTemplate) Index() map[string]string {
+	index := make(map[string]string)
+	if a.Name != "" {
+		index["name"] = a.Name
+	}
+	if a.Project != "" {
+		index["project"] = a.Project
+	}
+	return index
+}
+
+// ApplicationTemplateVersion records the snapshot of the application when publishing the template
+type ApplicationTemplateVersion struct {
+	Model
+	TemplateName string `json:"templateName"`
+	Version      string `json:"version"`
+	Description  string `json:"description"`
+	CreateUser   string `json:"createUser"`
+	// SourceApplication is the name of the application the version published from
+	SourceApplication string                         `json:"sourceApplication"`
+	Components        []ApplicationComponent         `json:"components,omitempty"`
+	Policies          []ApplicationPolicy            `json:"policies,omitempty"`
+	EnvBindings       []EnvBinding                   `json:"envBindings,omitempty"`
+	Workflows         []Workflow                     `json:"workflows,omitempty"`
+	Parameters        []ApplicationTemplateParameter `json:"parameters,omitempty"`
+}
+
+// TableName return custom table name
+func (a *ApplicationTemplateVersion) TableName() string {
+	return tableNamePrefix + "application_template_version"
+}
+
+// PrimaryKey return custom primary key
+func (a *ApplicationTemplateVersion) PrimaryKey() string {
+	return fmt.Sprintf("%s-%s", a.TemplateName, a.Version)
+}
+
+// Index return custom index
+func (a *ApplicationTemplateVersion) Index() map[string]string {
+	index := make(map[string]string)
+	if a.TemplateName != "" {
+		index["templateName"] = a.TemplateName
+	}
+	if a.Version != "" {
+		index["version"] = a.Version
+	}
+	return index
+}
+
+// ApplicationTemplateParameter is the property of the component that could be specified when instantiating the template
+type ApplicationTemplateParameter struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Component   string `json:"component"`
+	// Trait is the trait type of the component, the parameter refers to the component properties if it is empty
+	Trait string `json:"trait,omitempty"`
+	// Path is the field path of the properties separated by dot, e.g. image or resources.cpu
+	Path     string      `json:"path"`
+	Default  interface{} `json:"default,omitempty"`
+	Required bool        `json:"required,omitempty"`
+}
@@ -27,7 +27,9 @@ import (
 	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils"
+	"github.com/oam-dev/kubevela/pkg/apprevision"
 	"github.com/oam-dev/kubevela/pkg/cloudprovider"
+	"github.com/oam-dev/kubevela/pkg/velaql/providers/query"
 )
 
 var (
@@ -41,6 +43,10 @@ var (
 	CtxKeyApplicationEnvBinding = "envbinding-policy"
 	// CtxKeyApplicationComponent request context key of component
 	CtxKeyApplicationComponent = "component"
+	// CtxKeyProject request context key of project
+	CtxKeyProject = "project"
+	// CtxKeyApplicationTemplate request context key of application template
+	CtxKeyApplicationTemplate = "application-template"
 )
 
 // AddonPhase defines the phase of an addon
@@ -158,12 +164,23 @@ type CreateClusterRequest struct {
 	Alias            string            `json:"alias" validate:"checkalias" optional:"true"`
 	Description      string            `json:"description,omitempty"`
 	Icon             string            `json:"icon"`
-	KubeConfig       string            `json:"kubeConfig,omitempty" validate:"required_without=KubeConfigSecret"`
-	KubeConfigSecret string            `json:"kubeConfigSecret,omitempty" validate:"required_without=KubeConfig"`
+	KubeConfig       string            `json:"kubeConfig,omitempty" validate:"required_without_all=KubeConfigSecret Token"`
+	KubeConfigSecret string            `json:"kubeConfigSecret,omitempty" validate:"required_without_all=KubeConfig Token"`
+	Token            *ClusterToken     `json:"token,omitempty" optional:"true"`
 	Labels           map[string]string `json:"labels,omitempty"`
 	DashboardURL     string            `json:"dashboardURL,omitempty"`
 }
 
+// ClusterToken the endpoint, CA and bearer token to access the cluster, usually the token of a service account
+type ClusterToken struct {
+	Endpoint string `json:"endpoint" validate:"required"`
+	// CAData is the base64 encoded PEM CA certificate of the cluster, required unless Insecure is set
+	CAData string `json:"caData,omitempty" optional:"true"`
+	Token  string `json:"token" validate:"required"`
+	// Insecure skips the TLS verification of the cluster, only for the clusters without the CA certificate
+	Insecure bool `json:"insecure,omitempty" optional:"true"`
+}
+
 // ConnectCloudClusterRequest request parameters to create a cluster from cloud cluster
 type ConnectCloudClusterRequest struct {
 	AccessKeyID     string            `json:"accessKeyID"`
@@ -216,6 +233,8 @@ type CreateClusterNamespaceResponse struct {
 type DetailClusterResponse struct {
 	model.Cluster
 	ResourceInfo ClusterResourceInfo `json:"resourceInfo"`
+	// CredentialExpiration is the expiration time of the credential used to access the cluster, empty if never expires
+	CredentialExpiration *time.Time `json:"credentialExpiration,omitempty"`
 }
 
 // ListClusterResponse list cluster
@@ -224,6 +243,11 @@ type ListClusterResponse struct {
 	Total    int64         `json:"total"`
 }
 
+// ListCloudClusterProviderResponse list the registered cloud cluster providers
+type ListCloudClusterProviderResponse struct {
+	Providers []string `json:"providers"`
+}
+
 // ListCloudClusterResponse list cloud clusters
 type ListCloudClusterResponse struct {
 	Clusters []cloudprovider.CloudCluster `json:"clusters"`
@@ -301,6 +325,12 @@ type ApplicationStatusResponse struct {
 	Status  *common.AppStatus `json:"status"`
 }
 
+// ListServiceEndpointsResponse list the endpoints of the application in the env
+type ListServiceEndpointsResponse struct {
+	EnvName   string                  `json:"envName"`
+	Endpoints []query.ServiceEndpoint `json:"endpoints"`
+}
+
 // ApplicationStatisticsResponse application statistics response body
 type ApplicationStatisticsResponse struct {
 	EnvCount            int64 `json:"envCount"`
@@ -444,11 +474,15 @@ type CreateApplicationTemplateRequest struct {
 	TemplateName string `json:"templateName" validate:"checkname"`
 	Version      string `json:"version" validate:"required"`
 	Description  string `json:"description"`
+	// Parameters are the properties could be specified when instantiating the template
+	Parameters []model.ApplicationTemplateParameter `json:"parameters,omitempty" optional:"true"`
 }
 
 // ApplicationTemplateBase app template model
 type ApplicationTemplateBase struct {
 	TemplateName string                        `json:"templateName"`
+	Description  string                        `json:"description"`
+	Project      string                        `json:"project"`
 	Versions     []*ApplicationTemplateVersion `json:"versions,omitempty"`
 	CreateTime   time.Time                     `json:"createTime"`
 	UpdateTime   time.Time                     `json:"updateTime"`
@@ -456,11 +490,69 @@ type ApplicationTemplateBase struct {
 
 // ApplicationTemplateVersion template version model
 type ApplicationTemplateVersion struct {
-	Version     string    `json:"version"`
-	Description string    `json:"description"`
-	CreateUser  string    `json:"createUser"`
-	CreateTime  time.Time `json:"createTime"`
-	UpdateTime  time.Time `json:"updateTime"`
+	Version           string                               `json:"version"`
+	Description       string                               `json:"description"`
+	CreateUser        string                               `json:"createUser"`
+	SourceApplication string                               `json:"sourceApplication"`
+	Components        []NameAlias                          `json:"components,omitempty"`
+	Parameters        []model.ApplicationTemplateParameter `json:"parameters,omitempty"`
+	CreateTime        time.Time                            `json:"createTime"`
+	UpdateTime        time.Time                            `json:"updateTime"`
+}
+
+// ListApplicationTemplateResponse list app templates response body
+type ListApplicationTemplateResponse struct {
+	Templates []*ApplicationTemplateBase `json:"templates"`
+}
+
+// ApplicationManifest is the declarative metadata of the application, includes the entities can not be expressed by the application spec
+type ApplicationManifest struct {
+	Name            string                        `json:"name" validate:"checkname"`
+	Alias           string                        `json:"alias,omitempty" validate:"checkalias" optional:"true"`
+	Project         string                        `json:"project" validate:"checkname"`
+	Description     string                        `json:"description,omitempty" optional:"true"`
+	Icon            string                        `json:"icon,omitempty" optional:"true"`
+	Labels          map[string]string             `json:"labels,omitempty" optional:"true"`
+	EnvBindings     []*EnvBinding                 `json:"envBindings,omitempty" optional:"true"`
+	Workflows       []CreateWorkflowRequest       `json:"workflows,omitempty" optional:"true"`
+	DeliveryTargets []CreateDeliveryTargetRequest `json:"deliveryTargets,omitempty" optional:"true"`
+}
+
+// ExportApplicationResponse the declarative files of the application
+type ExportApplicationResponse struct {
+	// Files the key is the relative file path and the value is the yaml content
+	Files map[string]string `json:"files"`
+}
+
+// ImportApplicationRequest import the application from the declarative files
+type ImportApplicationRequest struct {
+	// Files the key is the relative file path and the value is the yaml content
+	Files map[string]string `json:"files" validate:"required"`
+	// Project overrides the project recorded in the metadata if specified
+	Project string `json:"project,omitempty" optional:"true"`
+}
+
+// CreateApplicationFromTemplateRequest instantiate the app template request body
+type CreateApplicationFromTemplateRequest struct {
+	Name        string            `json:"name" validate:"checkname"`
+	Alias       string            `json:"alias" validate:"checkalias" optional:"true"`
+	Description string            `json:"description" optional:"true"`
+	Icon        string            `json:"icon" optional:"true"`
+	Labels      map[string]string `json:"labels,omitempty" optional:"true"`
+	Project     string            `json:"project" validate:"checkname"`
+	// Version is the template version, the latest version is used if it is empty
+	Version string `json:"version,omitempty" optional:"true"`
+	// Parameters is the values of the template parameters, the key is the parameter name
+	Parameters map[string]interface{} `json:"parameters,omitempty" optional:"true"`
+}
+
+// CloneApplicationRequest clone app request body
+type CloneApplicationRequest struct {
+	Name        string `json:"name" validate:"checkname"`
+	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
+	Description string `json:"description" optional:"true"`
+	// Project is the project of the new application, the same with the source application if it is empty
+	Project string `json:"project,omitempty" optional:"true"`
 }
 
 // ListProjectResponse list project response body
@@ -470,20 +562,62 @@ type ListProjectResponse struct {
 
 // ProjectBase project base model
 type ProjectBase struct {
-	Name        string    `json:"name"`
-	Alias       string    `json:"alias"`
-	Description string    `json:"description"`
-	Namespace   string    `json:"namespace"`
-	CreateTime  time.Time `json:"createTime"`
-	UpdateTime  time.Time `json:"updateTime"`
+	Name        string        `json:"name"`
+	Alias       string        `json:"alias"`
+	Description string        `json:"description"`
+	Namespace   string        `json:"namespace"`
+	Quota       *ProjectQuota `json:"quota,omitempty"`
+	CreateTime  time.Time     `json:"createTime"`
+	UpdateTime  time.Time     `json:"updateTime"`
+}
+
+// ProjectQuota the quota of the project
+type ProjectQuota struct {
+	MaxApplications int64    `json:"maxApplications,omitempty" validate:"gte=0" optional:"true"`
+	DeliveryTargets []string `json:"deliveryTargets,omitempty" optional:"true"`
+	Clusters        []string `json:"clusters,omitempty" optional:"true"`
+	DefinitionTypes []string `json:"definitionTypes,omitempty" optional:"true"`
 }
 
 // CreateProjectRequest create project request body
 type CreateProjectRequest struct {
-	Name        string `json:"name" validate:"checkname"`
-	Alias       string `json:"alias" validate:"checkalias" optional:"true"`
-	Description string `json:"description" optional:"true"`
-	Namespace   string `json:"namespace" optional:"true"`
+	Name        string        `json:"name" validate:"checkname"`
+	Alias       string        `json:"alias" validate:"checkalias" optional:"true"`
+	Description string        `json:"description" optional:"true"`
+	Namespace   string        `json:"namespace" optional:"true"`
+	Quota       *ProjectQuota `json:"quota,omitempty" optional:"true"`
+}
+
+// UpdateProjectRequest update project request body
+type UpdateProjectRequest struct {
+	Alias       string        `json:"alias" validate:"checkalias" optional:"true"`
+	Description string        `json:"description" optional:"true"`
+	Quota       *ProjectQuota `json:"quota,omitempty" optional:"true"`
+}
+
+// ProjectUserBase project user base model
+type ProjectUserBase struct {
+	UserName   string    `json:"userName"`
+	Role       string    `json:"role"`
+	CreateTime time.Time `json:"createTime"`
+	UpdateTime time.Time `json:"updateTime"`
+}
+
+// ListProjectUsersResponse list project users response body
+type ListProjectUsersResponse struct {
+	Users []*ProjectUserBase `json:"users"`
+	Total int64              `json:"total"`
+}
+
+// AddProjectUserRequest add project user request body
+type AddProjectUserRequest struct {
+	UserName string `json:"userName" validate:"checkname"`
+	Role     string `json:"role" validate:"oneof=admin member viewer"`
+}
+
+// UpdateProjectUserRequest update project user request body
+type UpdateProjectUserRequest struct {
+	Role string `json:"role" validate:"oneof=admin member viewer"`
 }
 
 // ListDefinitionResponse list definition response model
@@ -780,3 +914,20 @@ type ListRevisionsResponse struct {
 type DetailRevisionResponse struct {
 	model.ApplicationRevision
 }
+
+// ApplicationRevisionDiffResponse the differences between two application revisions
+type ApplicationRevisionDiffResponse struct {
+	BaseVersion   string                  `json:"baseVersion"`
+	TargetVersion string                  `json:"targetVersion"`
+	Diffs         []apprevision.DiffEntry `json:"diffs"`
+}
+
+// ApplicationRollbackRequest rollback the application to a revision
+type ApplicationRollbackRequest struct {
+	// User note message, optional
+	Note string `json:"note"`
+	// TriggerType the event trigger source, Web or API
+	TriggerType string `json:"triggerType" validate:"oneof=web api"`
+	// Force set to True to ignore unfinished events.
+	Force bool `json:"force"`
+}
@@ -152,6 +152,11 @@ func (s *restServer) setupLeaderElection() (*leaderelection.LeaderElectionConfig
 
 func (s restServer) runLeader(ctx context.Context, duration time.Duration) {
 	w := usecase.NewWorkflowUsecase(s.dataStore)
+	projectUsecase := usecase.NewProjectUsecase(s.dataStore)
+	definitionUsecase := usecase.NewDefinitionUsecase()
+	envBindingUsecase := usecase.NewEnvBindingUsecase(s.dataStore, w, definitionUsecase)
+	deliveryTargetUsecase := usecase.NewDeliveryTargetUsecase(s.dataStore, projectUsecase)
+	a := usecase.NewApplicationUsecase(s.dataStore, w, envBindingUsecase, deliveryTargetUsecase, definitionUsecase, projectUsecase)
 
 	t := time.NewTicker(duration)
 	defer t.Stop()
@@ -162,6 +167,10 @@ func (s restServer) runLeader(ctx context.Context, duration time.Duration) {
 			if err := w.SyncWorkflowRecord(ctx); err != nil {
 				klog.ErrorS(err, "syncWorkflowRecordError")
 			}
+			// sync the applications created by CLI or kubectl, so that they could be managed by the apiserver
+			if err := a.SyncApplications(ctx); err != nil {
+				klog.ErrorS(err, "syncApplicationsError")
+			}
 		case <-ctx.Done():
 			return
 		}
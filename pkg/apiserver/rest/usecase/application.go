@@ -43,8 +43,10 @@ import (
 	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/apprevision"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
+	"github.com/oam-dev/kubevela/pkg/velaql/providers/query"
 )
 
 // PolicyType build-in policy type
@@ -63,8 +65,14 @@ type ApplicationUsecase interface {
 	ListApplications(ctx context.Context, listOptions apisv1.ListApplicatioOptions) ([]*apisv1.ApplicationBase, error)
 	GetApplication(ctx context.Context, appName string) (*model.Application, error)
 	GetApplicationStatus(ctx context.Context, app *model.Application, envName string) (*common.AppStatus, error)
+	ListServiceEndpoints(ctx context.Context, app *model.Application, envName string) (*apisv1.ListServiceEndpointsResponse, error)
 	DetailApplication(ctx context.Context, app *model.Application) (*apisv1.DetailApplicationResponse, error)
-	PublishApplicationTemplate(ctx context.Context, app *model.Application) (*apisv1.ApplicationTemplateBase, error)
+	PublishApplicationTemplate(ctx context.Context, app *model.Application, req apisv1.CreateApplicationTemplateRequest) (*apisv1.ApplicationTemplateBase, error)
+	CreateApplicationFromTemplate(ctx context.Context, template *model.ApplicationTemplate, req apisv1.CreateApplicationFromTemplateRequest) (*apisv1.ApplicationBase, error)
+	CloneApplication(ctx context.Context, app *model.Application, req apisv1.CloneApplicationRequest) (*apisv1.ApplicationBase, error)
+	ExportApplication(ctx context.Context, app *model.Application) (*apisv1.ExportApplicationResponse, error)
+	ImportApplication(ctx context.Context, req apisv1.ImportApplicationRequest) (*apisv1.ApplicationBase, error)
+	SyncApplications(ctx context.Context) error
 	CreateApplication(context.Context, apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error)
 	UpdateApplication(context.Context, *model.Application, apisv1.UpdateApplicationRequest) (*apisv1.ApplicationBase, error)
 	DeleteApplication(ctx context.Context, app *model.Application) error
@@ -85,6 +93,8 @@ type ApplicationUsecase interface {
 	UpdateApplicationTrait(ctx context.Context, app *model.Application, component *model.ApplicationComponent, traitType string, req apisv1.UpdateApplicationTraitRequest) (*apisv1.ApplicationTrait, error)
 	ListRevisions(ctx context.Context, appName, envName, status string, page, pageSize int) (*apisv1.ListRevisionsResponse, error)
 	DetailRevision(ctx context.Context, appName, revisionName string) (*apisv1.DetailRevisionResponse, error)
+	DiffRevisions(ctx context.Context, appName, baseVersion, targetVersion string) (*apisv1.ApplicationRevisionDiffResponse, error)
+	RollbackRevision(ctx context.Context, app *model.Application, revisionVersion string, req apisv1.ApplicationRollbackRequest) (*apisv1.ApplicationDeployResponse, error)
 	Statistics(ctx context.Context, app *model.Application) (*apisv1.ApplicationStatisticsResponse, error)
 	ListRecords(ctx context.Context, appName string) (*apisv1.ListWorkflowRecordsResponse, error)
 }
@@ -216,7 +226,7 @@ func (c *applicationUsecaseImpl) DetailApplication(ctx context.Context, app *mod
 // GetApplicationStatus get application status from controller cluster
 func (c *applicationUsecaseImpl) GetApplicationStatus(ctx context.Context, appmodel *model.Application, envName string) (*common.AppStatus, error) {
 	var app v1beta1.Application
-	err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: appmodel.Namespace, Name: convertAppName(appmodel.Name, envName)}, &app)
+	err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: appmodel.Namespace, Name: deployAppName(appmodel, envName)}, &app)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil, nil
@@ -229,6 +239,27 @@ func (c *applicationUsecaseImpl) GetApplicationStatus(ctx context.Context, appmo
 	return &app.Status, nil
 }
 
+// ListServiceEndpoints list the endpoints exposed by the application in the env
+func (c *applicationUsecaseImpl) ListServiceEndpoints(ctx context.Context, appmodel *model.Application, envName string) (*apisv1.ListServiceEndpointsResponse, error) {
+	resp := &apisv1.ListServiceEndpointsResponse{EnvName: envName, Endpoints: []query.ServiceEndpoint{}}
+	var app v1beta1.Application
+	err := c.kubeClient.Get(ctx, types.NamespacedName{Namespace: appmodel.Namespace, Name: deployAppName(appmodel, envName)}, &app)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return resp, nil
+		}
+		return nil, err
+	}
+	collector := query.NewAppCollector(c.kubeClient, query.Option{Name: app.Name, Namespace: app.Namespace})
+	endpoints, err := collector.CollectServiceEndpoints()
+	if err != nil {
+		log.Logger.Errorf("collect the endpoints of application %s failure %s", app.Name, err.Error())
+		return nil, err
+	}
+	resp.Endpoints = append(resp.Endpoints, endpoints...)
+	return resp, nil
+}
+
 // GetApplicationCR get application cr in cluster
 func (c *applicationUsecaseImpl) GetApplicationCR(ctx context.Context, appModel *model.Application) (*v1beta1.ApplicationList, error) {
 	var apps v1beta1.ApplicationList
@@ -251,12 +282,6 @@ func (c *applicationUsecaseImpl) GetApplicationCR(ctx context.Context, appModel
 	return &apps, nil
 }
 
-// PublishApplicationTemplate publish app template
-func (c *applicationUsecaseImpl) PublishApplicationTemplate(ctx context.Context, app *model.Application) (*apisv1.ApplicationTemplateBase, error) {
-	// TODO:
-	return nil, nil
-}
-
 // CreateApplication create application
 func (c *applicationUsecaseImpl) CreateApplication(ctx context.Context, req apisv1.CreateApplicationRequest) (*apisv1.ApplicationBase, error) {
 	application := model.Application{
@@ -283,6 +308,9 @@ func (c *applicationUsecaseImpl) CreateApplication(ctx context.Context, req apis
 	}
 	application.Namespace = project.Namespace
 	application.Project = project.Name
+	if err := checkApplicationQuota(ctx, c.ds, project); err != nil {
+		return nil, err
+	}
 
 	if req.YamlConfig != "" {
 		var oamApp v1beta1.Application
@@ -290,6 +318,16 @@ func (c *applicationUsecaseImpl) CreateApplication(ctx context.Context, req apis
 			log.Logger.Errorf("application yaml config is invalid,%s", err.Error())
 			return nil, bcode.ErrApplicationConfig
 		}
+		var definitionTypes []string
+		for _, component := range oamApp.Spec.Components {
+			definitionTypes = append(definitionTypes, component.Type)
+			for _, trait := range component.Traits {
+				definitionTypes = append(definitionTypes, trait.Type)
+			}
+		}
+		if err := checkDefinitionQuota(project, definitionTypes...); err != nil {
+			return nil, err
+		}
 
 		// split the configuration and store it in the database.
 		if err := c.saveApplicationComponent(ctx, &application, oamApp.Spec.Components); err != nil {
@@ -378,41 +416,48 @@ func (c *applicationUsecaseImpl) saveApplicationComponent(ctx context.Context, a
 	var componentModels []datastore.Entity
 	for _, component := range components {
 		// TODO: Check whether the component type is supported.
-		var traits []model.ApplicationTrait
-		for _, trait := range component.Traits {
-			properties, err := model.NewJSONStruct(trait.Properties)
-			if err != nil {
-				log.Logger.Errorf("parse trait properties failire %w", err)
-				return bcode.ErrInvalidProperties
-			}
-			traits = append(traits, model.ApplicationTrait{
-				Type:       trait.Type,
-				Properties: properties,
-			})
-		}
-		properties, err := model.NewJSONStruct(component.Properties)
+		componentModel, err := convertToComponentModel(app, component)
 		if err != nil {
-			log.Logger.Errorf("parse component properties failire %w", err)
-			return bcode.ErrInvalidProperties
-		}
-		componentModel := model.ApplicationComponent{
-			AppPrimaryKey:    app.PrimaryKey(),
-			Name:             component.Name,
-			Type:             component.Type,
-			ExternalRevision: component.ExternalRevision,
-			DependsOn:        component.DependsOn,
-			Inputs:           component.Inputs,
-			Outputs:          component.Outputs,
-			Scopes:           component.Scopes,
-			Traits:           traits,
-			Properties:       properties,
+			return err
 		}
-		componentModels = append(componentModels, &componentModel)
+		componentModels = append(componentModels, componentModel)
 	}
 	log.Logger.Infof("batch add %d components for app %s", len(componentModels), app.PrimaryKey())
 	return c.ds.BatchAdd(ctx, componentModels)
 }
 
+func convertToComponentModel(app *model.Application, component common.ApplicationComponent) (*model.ApplicationComponent, error) {
+	var traits []model.ApplicationTrait
+	for _, trait := range component.Traits {
+		properties, err := model.NewJSONStruct(trait.Properties)
+		if err != nil {
+			log.Logger.Errorf("parse trait properties failire %w", err)
+			return nil, bcode.ErrInvalidProperties
+		}
+		traits = append(traits, model.ApplicationTrait{
+			Type:       trait.Type,
+			Properties: properties,
+		})
+	}
+	properties, err := model.NewJSONStruct(component.Properties)
+	if err != nil {
+		log.Logger.Errorf("parse component properties failire %w", err)
+		return nil, bcode.ErrInvalidProperties
+	}
+	return &model.ApplicationComponent{
+		AppPrimaryKey:    app.PrimaryKey(),
+		Name:             component.Name,
+		Type:             component.Type,
+		ExternalRevision: component.ExternalRevision,
+		DependsOn:        component.DependsOn,
+		Inputs:           component.Inputs,
+		Outputs:          component.Outputs,
+		Scopes:           component.Scopes,
+		Traits:           traits,
+		Properties:       properties,
+	}, nil
+}
+
 // ListRecords list application record
 func (c *applicationUsecaseImpl) ListRecords(ctx context.Context, appName string) (*apisv1.ListWorkflowRecordsResponse, error) {
 	var record = model.WorkflowRecord{
@@ -546,16 +591,9 @@ func (c *applicationUsecaseImpl) converPolicyModelToBase(policy *model.Applicati
 func (c *applicationUsecaseImpl) saveApplicationPolicy(ctx context.Context, app *model.Application, policys []v1beta1.AppPolicy) error {
 	var policyModels []datastore.Entity
 	for _, policy := range policys {
-		properties, err := model.NewJSONStruct(policy.Properties)
+		appPolicy, err := convertToPolicyModel(app, policy)
 		if err != nil {
-			log.Logger.Errorf("parse trait properties failire %w", err)
-			return bcode.ErrInvalidProperties
-		}
-		appPolicy := &model.ApplicationPolicy{
-			AppPrimaryKey: app.PrimaryKey(),
-			Name:          policy.Name,
-			Type:          policy.Type,
-			Properties:    properties,
+			return err
 		}
 		if policy.Type != string(EnvBindingPolicy) {
 			policyModels = append(policyModels, appPolicy)
@@ -564,6 +602,20 @@ func (c *applicationUsecaseImpl) saveApplicationPolicy(ctx context.Context, app
 	return c.ds.BatchAdd(ctx, policyModels)
 }
 
+func convertToPolicyModel(app *model.Application, policy v1beta1.AppPolicy) (*model.ApplicationPolicy, error) {
+	properties, err := model.NewJSONStruct(policy.Properties)
+	if err != nil {
+		log.Logger.Errorf("parse trait properties failire %w", err)
+		return nil, bcode.ErrInvalidProperties
+	}
+	return &model.ApplicationPolicy{
+		AppPrimaryKey: app.PrimaryKey(),
+		Name:          policy.Name,
+		Type:          policy.Type,
+		Properties:    properties,
+	}, nil
+}
+
 func (c *applicationUsecaseImpl) queryApplicationPolicys(ctx context.Context, app *model.Application) (list []*model.ApplicationPolicy, err error) {
 	var policy = model.ApplicationPolicy{
 		AppPrimaryKey: app.PrimaryKey(),
@@ -615,34 +667,8 @@ func (c *applicationUsecaseImpl) Deploy(ctx context.Context, app *model.Applicat
 
 	// step2: check and create deploy event
 	if !req.Force {
-		var lastVersion = model.ApplicationRevision{
-			AppPrimaryKey: app.PrimaryKey(),
-			EnvName:       workflow.EnvName,
-		}
-		list, err := c.ds.List(ctx, &lastVersion, &datastore.ListOptions{
-			PageSize: 1, Page: 1, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
-		if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
-			log.Logger.Errorf("query app latest revision failure %s", err.Error())
-			return nil, bcode.ErrDeployConflict
-		}
-		if len(list) > 0 {
-			revision := list[0].(*model.ApplicationRevision)
-			var status string
-			if revision.Status == model.RevisionStatusRollback {
-				rollbackRevision := &model.ApplicationRevision{
-					AppPrimaryKey: revision.AppPrimaryKey,
-					Version:       revision.RollbackVersion,
-				}
-				if err := c.ds.Get(ctx, rollbackRevision); err == nil {
-					status = rollbackRevision.Status
-				}
-			} else {
-				status = revision.Status
-			}
-			if status != model.RevisionStatusComplete && status != model.RevisionStatusTerminated {
-				log.Logger.Warnf("last app revision can not complete %s/%s", list[0].(*model.ApplicationRevision).AppPrimaryKey, list[0].(*model.ApplicationRevision).Version)
-				return nil, bcode.ErrDeployConflict
-			}
+		if err := c.checkDeployConflict(ctx, app, workflow.EnvName); err != nil {
+			return nil, err
 		}
 	}
 
@@ -659,6 +685,45 @@ func (c *applicationUsecaseImpl) Deploy(ctx context.Context, app *model.Applicat
 		EnvName:      workflow.EnvName,
 	}
 
+	return c.applyRevision(ctx, app, oamApp, workflow, appRevision)
+}
+
+// checkDeployConflict checks whether the last revision deployed to the env is finished
+func (c *applicationUsecaseImpl) checkDeployConflict(ctx context.Context, app *model.Application, envName string) error {
+	var lastVersion = model.ApplicationRevision{
+		AppPrimaryKey: app.PrimaryKey(),
+		EnvName:       envName,
+	}
+	list, err := c.ds.List(ctx, &lastVersion, &datastore.ListOptions{
+		PageSize: 1, Page: 1, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+		log.Logger.Errorf("query app latest revision failure %s", err.Error())
+		return bcode.ErrDeployConflict
+	}
+	if len(list) > 0 {
+		revision := list[0].(*model.ApplicationRevision)
+		var status string
+		if revision.Status == model.RevisionStatusRollback {
+			rollbackRevision := &model.ApplicationRevision{
+				AppPrimaryKey: revision.AppPrimaryKey,
+				Version:       revision.RollbackVersion,
+			}
+			if err := c.ds.Get(ctx, rollbackRevision); err == nil {
+				status = rollbackRevision.Status
+			}
+		} else {
+			status = revision.Status
+		}
+		if status != model.RevisionStatusComplete && status != model.RevisionStatusTerminated {
+			log.Logger.Warnf("last app revision can not complete %s/%s", revision.AppPrimaryKey, revision.Version)
+			return bcode.ErrDeployConflict
+		}
+	}
+	return nil
+}
+
+// applyRevision records the app revision, applies the application to the controller cluster and creates the workflow record
+func (c *applicationUsecaseImpl) applyRevision(ctx context.Context, app *model.Application, oamApp *v1beta1.Application, workflow *model.Workflow, appRevision *model.ApplicationRevision) (*apisv1.ApplicationDeployResponse, error) {
 	if err := c.ds.Add(ctx, appRevision); err != nil {
 		return nil, err
 	}
@@ -672,8 +737,7 @@ func (c *applicationUsecaseImpl) Deploy(ctx context.Context, app *model.Applicat
 		}
 	}
 	// step4: apply to controller cluster
-	err = c.apply.Apply(ctx, oamApp)
-	if err != nil {
+	if err := c.apply.Apply(ctx, oamApp); err != nil {
 		appRevision.Status = model.RevisionStatusFail
 		appRevision.Reason = err.Error()
 		if err := c.ds.Put(ctx, appRevision); err != nil {
@@ -739,7 +803,7 @@ func (c *applicationUsecaseImpl) renderOAMApplication(ctx context.Context, appMo
 			APIVersion: "core.oam.dev/v1beta1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      convertAppName(appModel.Name, workflow.EnvName),
+			Name:      deployAppName(appModel, workflow.EnvName),
 			Namespace: appModel.Namespace,
 			Labels:    labels,
 			Annotations: map[string]string{
@@ -753,7 +817,7 @@ func (c *applicationUsecaseImpl) renderOAMApplication(ctx context.Context, appMo
 	}
 	originalApp := &v1beta1.Application{}
 	if err := c.kubeClient.Get(ctx, types.NamespacedName{
-		Name:      convertAppName(appModel.Name, workflow.EnvName),
+		Name:      deployAppName(appModel, workflow.EnvName),
 		Namespace: appModel.Namespace,
 	}, originalApp); err == nil {
 		app.ResourceVersion = originalApp.ResourceVersion
@@ -792,7 +856,7 @@ func (c *applicationUsecaseImpl) renderOAMApplication(ctx context.Context, appMo
 			traits = append(traits, aTrait)
 		}
 		bc := common.ApplicationComponent{
-			Name:             converComponentName(component.Name, workflow.EnvName),
+			Name:             deployComponentName(appModel, component.Name, workflow.EnvName),
 			Type:             component.Type,
 			ExternalRevision: component.ExternalRevision,
 			DependsOn:        component.DependsOn,
@@ -974,6 +1038,9 @@ func (c *applicationUsecaseImpl) UpdateComponent(ctx context.Context, app *model
 }
 
 func (c *applicationUsecaseImpl) AddComponent(ctx context.Context, app *model.Application, com apisv1.CreateComponentRequest) (*apisv1.ComponentBase, error) {
+	if err := c.checkDefinitionQuota(ctx, app, com.ComponentType); err != nil {
+		return nil, err
+	}
 	componentModel := model.ApplicationComponent{
 		AppPrimaryKey: app.PrimaryKey(),
 		Description:   com.Description,
@@ -1004,6 +1071,18 @@ func (c *applicationUsecaseImpl) AddComponent(ctx context.Context, app *model.Ap
 	return converComponentModelToBase(&componentModel), nil
 }
 
+// checkDefinitionQuota checks whether the component or trait types are allowed by the project of the application
+func (c *applicationUsecaseImpl) checkDefinitionQuota(ctx context.Context, app *model.Application, definitionTypes ...string) error {
+	if app.Project == "" {
+		return nil
+	}
+	project, err := _getProjectFromDataStore(ctx, c.ds, app.Project)
+	if err != nil {
+		return err
+	}
+	return checkDefinitionQuota(project, definitionTypes...)
+}
+
 func (c *applicationUsecaseImpl) initCreateDefaultTrait(component *model.ApplicationComponent) {
 	replicationTrait := model.ApplicationTrait{
 		Alias:       "Replication",
@@ -1129,6 +1208,9 @@ func (c *applicationUsecaseImpl) UpdatePolicy(ctx context.Context, app *model.Ap
 }
 
 func (c *applicationUsecaseImpl) CreateApplicationTrait(ctx context.Context, app *model.Application, component *model.ApplicationComponent, req apisv1.CreateApplicationTraitRequest) (*apisv1.ApplicationTrait, error) {
+	if err := c.checkDefinitionQuota(ctx, app, req.Type); err != nil {
+		return nil, err
+	}
 	var comp = model.ApplicationComponent{
 		AppPrimaryKey: app.PrimaryKey(),
 		Name:          component.Name,
@@ -1261,6 +1343,124 @@ func (c *applicationUsecaseImpl) DetailRevision(ctx context.Context, appName, re
 	}, nil
 }
 
+func (c *applicationUsecaseImpl) getRevision(ctx context.Context, appName, revisionVersion string) (*model.ApplicationRevision, error) {
+	var revision = &model.ApplicationRevision{
+		AppPrimaryKey: appName,
+		Version:       revisionVersion,
+	}
+	if err := c.ds.Get(ctx, revision); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationRevisionNotExist
+		}
+		return nil, err
+	}
+	return revision, nil
+}
+
+func revisionApplication(revision *model.ApplicationRevision) (*v1beta1.Application, error) {
+	app := &v1beta1.Application{}
+	if err := yaml.Unmarshal([]byte(revision.ApplyAppConfig), app); err != nil {
+		return nil, fmt.Errorf("cannot decode the application of the revision %s: %w", revision.Version, err)
+	}
+	return app, nil
+}
+
+// DiffRevisions compares the components, traits, policies and workflow steps between two application revisions.
+// If the target revision is not specified, compare with the latest revision deployed to the env of the base revision.
+func (c *applicationUsecaseImpl) DiffRevisions(ctx context.Context, appName, baseVersion, targetVersion string) (*apisv1.ApplicationRevisionDiffResponse, error) {
+	base, err := c.getRevision(ctx, appName, baseVersion)
+	if err != nil {
+		return nil, err
+	}
+	var target *model.ApplicationRevision
+	if targetVersion != "" {
+		if target, err = c.getRevision(ctx, appName, targetVersion); err != nil {
+			return nil, err
+		}
+	} else {
+		list, err := c.ds.List(ctx, &model.ApplicationRevision{AppPrimaryKey: appName, EnvName: base.EnvName}, &datastore.ListOptions{
+			PageSize: 1, Page: 1, SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+		if err != nil {
+			return nil, err
+		}
+		if len(list) == 0 {
+			return nil, bcode.ErrApplicationRevisionNotExist
+		}
+		target = list[0].(*model.ApplicationRevision)
+	}
+	baseApp, err := revisionApplication(base)
+	if err != nil {
+		return nil, err
+	}
+	targetApp, err := revisionApplication(target)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := apprevision.Diff(baseApp, targetApp)
+	if err != nil {
+		return nil, err
+	}
+	return &apisv1.ApplicationRevisionDiffResponse{
+		BaseVersion:   base.Version,
+		TargetVersion: target.Version,
+		Diffs:         diffs,
+	}, nil
+}
+
+// RollbackRevision deploys the application recorded in the revision again as a new revision, the components keep the
+// revisions pinned in the recorded application. The components and policies of the application in the datastore are
+// not changed.
+func (c *applicationUsecaseImpl) RollbackRevision(ctx context.Context, app *model.Application, revisionVersion string, req apisv1.ApplicationRollbackRequest) (*apisv1.ApplicationDeployResponse, error) {
+	revision, err := c.getRevision(ctx, app.PrimaryKey(), revisionVersion)
+	if err != nil {
+		return nil, err
+	}
+	oamApp, err := revisionApplication(revision)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := c.workflowUsecase.GetWorkflow(ctx, app, revision.WorkflowName)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Force {
+		if err := c.checkDeployConflict(ctx, app, workflow.EnvName); err != nil {
+			return nil, err
+		}
+	}
+
+	version := utils.GenerateVersion("")
+	if oamApp.Annotations == nil {
+		oamApp.Annotations = make(map[string]string)
+	}
+	oamApp.Annotations[oam.AnnotationDeployVersion] = version
+	oamApp.Annotations[oam.AnnotationPublishVersion] = utils.GenerateVersion(revision.WorkflowName)
+	oamApp.ResourceVersion = ""
+	originalApp := &v1beta1.Application{}
+	if err := c.kubeClient.Get(ctx, types.NamespacedName{Name: oamApp.Name, Namespace: oamApp.Namespace}, originalApp); err == nil {
+		oamApp.ResourceVersion = originalApp.ResourceVersion
+	}
+	configByte, _ := yaml.Marshal(oamApp)
+
+	note := req.Note
+	if note == "" {
+		note = fmt.Sprintf("Rollback to revision %s", revision.Version)
+	}
+	var appRevision = &model.ApplicationRevision{
+		AppPrimaryKey:  app.PrimaryKey(),
+		Version:        version,
+		ApplyAppConfig: string(configByte),
+		Status:         model.RevisionStatusInit,
+		// TODO: Get user information from ctx and assign a value.
+		DeployUser:   "",
+		Note:         note,
+		TriggerType:  req.TriggerType,
+		WorkflowName: revision.WorkflowName,
+		EnvName:      workflow.EnvName,
+	}
+	return c.applyRevision(ctx, app, oamApp, workflow, appRevision)
+}
+
 func (c *applicationUsecaseImpl) Statistics(ctx context.Context, app *model.Application) (*apisv1.ApplicationStatisticsResponse, error) {
 	var targetMap = make(map[string]int)
 	envbinding, err := c.envBindingUsecase.GetEnvBindings(ctx, app)
@@ -1326,7 +1526,7 @@ func (c *applicationUsecaseImpl) createTargetClusterEnv(ctx context.Context, app
 					properties["providerRef"].(map[string]interface{})["namespace"] = providerNamespace
 				}
 				componentPatchs = append(componentPatchs, v1alpha1.EnvComponentPatch{
-					Name:       converComponentName(component.Name, envBind.Name),
+					Name:       deployComponentName(app, component.Name, envBind.Name),
 					Properties: properties.RawExtension(),
 					Type:       component.Type,
 				})
@@ -1352,6 +1552,23 @@ func converComponentName(componentModelName, envName string) string {
 	return fmt.Sprintf("%s-%s", componentModelName, envName)
 }
 
+// deployAppName returns the name of the application CR deployed to the env,
+// the application synced from CR keeps the original name so that the CR won't be duplicated.
+func deployAppName(app *model.Application, envName string) string {
+	if app.IsSynced() {
+		return app.Name
+	}
+	return convertAppName(app.Name, envName)
+}
+
+// deployComponentName returns the name of the component in the application CR deployed to the env
+func deployComponentName(app *model.Application, componentModelName, envName string) string {
+	if app.IsSynced() {
+		return componentModelName
+	}
+	return converComponentName(componentModelName, envName)
+}
+
 func genPolicyName(envName string) string {
 	return fmt.Sprintf("%s-%s", EnvBindingPolicyDefaultName, envName)
 }
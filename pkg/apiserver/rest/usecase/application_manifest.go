@@ -0,0 +1,424 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// ApplicationManifestFile is the file of the application metadata, env bindings, workflows and delivery targets
+	ApplicationManifestFile = "metadata.yaml"
+	// ApplicationSpecFile is the file of the application components and policies
+	ApplicationSpecFile = "application.yaml"
+	// ApplicationEnvFileFormat is the file of the application rendered for the env, it is only exported for reference
+	ApplicationEnvFileFormat = "envs/%s.yaml"
+)
+
+// ExportApplication export the application as the declarative files
+func (c *applicationUsecaseImpl) ExportApplication(ctx context.Context, app *model.Application) (*apisv1.ExportApplicationResponse, error) {
+	listOptions := &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}}}
+	manifest := apisv1.ApplicationManifest{
+		Name:        app.Name,
+		Alias:       app.Alias,
+		Project:     app.Project,
+		Description: app.Description,
+		Icon:        app.Icon,
+		Labels:      app.Labels,
+	}
+
+	envBindings, err := c.ds.List(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	targetNames := map[string]bool{}
+	for _, entity := range envBindings {
+		envBinding := entity.(*model.EnvBinding)
+		manifest.EnvBindings = append(manifest.EnvBindings, &apisv1.EnvBinding{
+			Name:              envBinding.Name,
+			Alias:             envBinding.Alias,
+			Description:       envBinding.Description,
+			TargetNames:       envBinding.TargetNames,
+			ComponentSelector: (*apisv1.ComponentSelector)(envBinding.ComponentSelector),
+		})
+		for _, targetName := range envBinding.TargetNames {
+			if targetNames[targetName] {
+				continue
+			}
+			targetNames[targetName] = true
+			target, err := c.deliveryTargetUsecase.GetDeliveryTarget(ctx, targetName)
+			if err != nil {
+				log.Logger.Errorf("get the delivery target %s of env %s failure %s", targetName, envBinding.Name, err.Error())
+				return nil, bcode.ErrFoundEnvbindingDeliveryTarget
+			}
+			manifest.DeliveryTargets = append(manifest.DeliveryTargets, apisv1.CreateDeliveryTargetRequest{
+				Name:        target.Name,
+				Project:     target.Project,
+				Alias:       target.Alias,
+				Description: target.Description,
+				Cluster:     (*apisv1.ClusterTarget)(target.Cluster),
+				Variable:    target.Variable,
+			})
+		}
+	}
+
+	workflows, err := c.ds.List(ctx, &model.Workflow{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	envWorkflows := map[string]*model.Workflow{}
+	for _, entity := range workflows {
+		workflow := entity.(*model.Workflow)
+		base := converWorkflowBase(workflow)
+		manifest.Workflows = append(manifest.Workflows, apisv1.CreateWorkflowRequest{
+			Name:        base.Name,
+			Alias:       base.Alias,
+			Description: base.Description,
+			Steps:       base.Steps,
+			Default:     workflow.Default,
+			EnvName:     base.EnvName,
+		})
+		if _, exist := envWorkflows[workflow.EnvName]; !exist || convertBool(workflow.Default) {
+			envWorkflows[workflow.EnvName] = workflow
+		}
+	}
+
+	files := map[string]string{}
+	if err := addYAMLFile(files, ApplicationManifestFile, manifest); err != nil {
+		return nil, err
+	}
+	spec, err := c.renderApplicationSpec(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if err := addYAMLFile(files, ApplicationSpecFile, spec); err != nil {
+		return nil, err
+	}
+	// the env application could not be rendered without components
+	if len(spec.Spec.Components) == 0 {
+		return &apisv1.ExportApplicationResponse{Files: files}, nil
+	}
+	for _, entity := range envBindings {
+		envBinding := entity.(*model.EnvBinding)
+		workflow, ok := envWorkflows[envBinding.Name]
+		if !ok {
+			continue
+		}
+		envApp, err := c.renderOAMApplication(ctx, app, workflow.Name, "")
+		if err != nil {
+			return nil, err
+		}
+		// remove the fields that changes every time, so that the exported files are stable
+		envApp.ResourceVersion = ""
+		delete(envApp.Annotations, oam.AnnotationDeployVersion)
+		delete(envApp.Annotations, oam.AnnotationPublishVersion)
+		if err := addYAMLFile(files, fmt.Sprintf(ApplicationEnvFileFormat, envBinding.Name), envApp); err != nil {
+			return nil, err
+		}
+	}
+	return &apisv1.ExportApplicationResponse{Files: files}, nil
+}
+
+// ImportApplication creates or updates the application and its entities from the declarative files.
+// The entities absent in the files are kept, the env application files are ignored because they are rendered.
+func (c *applicationUsecaseImpl) ImportApplication(ctx context.Context, req apisv1.ImportApplicationRequest) (*apisv1.ApplicationBase, error) {
+	manifest, spec, err := parseApplicationFiles(req.Files)
+	if err != nil {
+		return nil, err
+	}
+	if req.Project != "" {
+		manifest.Project = req.Project
+	}
+
+	app := &model.Application{Name: manifest.Name}
+	exist := true
+	if err := c.ds.Get(ctx, app); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, err
+		}
+		exist = false
+		app.Project = manifest.Project
+	}
+	project, err := c.projectUsecase.GetProject(ctx, app.Project)
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		app.Namespace = project.Namespace
+		app.Project = project.Name
+		if err := checkApplicationQuota(ctx, c.ds, project); err != nil {
+			return nil, err
+		}
+	}
+	app.Alias = manifest.Alias
+	app.Description = manifest.Description
+	app.Icon = manifest.Icon
+	app.Labels = manifest.Labels
+
+	var definitionTypes, targetNames []string
+	for _, component := range spec.Spec.Components {
+		definitionTypes = append(definitionTypes, component.Type)
+		for _, trait := range component.Traits {
+			definitionTypes = append(definitionTypes, trait.Type)
+		}
+	}
+	for _, envBinding := range manifest.EnvBindings {
+		targetNames = append(targetNames, envBinding.TargetNames...)
+	}
+	if err := checkDefinitionQuota(project, definitionTypes...); err != nil {
+		return nil, err
+	}
+	if err := checkDeliveryTargetQuota(project, targetNames...); err != nil {
+		return nil, err
+	}
+
+	if err := c.importDeliveryTargets(ctx, project, manifest.DeliveryTargets); err != nil {
+		return nil, err
+	}
+	if exist {
+		err = c.ds.Put(ctx, app)
+	} else {
+		err = c.ds.Add(ctx, app)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range spec.Spec.Components {
+		componentModel, err := convertToComponentModel(app, component)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.importApplicationComponent(ctx, componentModel); err != nil {
+			return nil, err
+		}
+	}
+	for _, policy := range spec.Spec.Policies {
+		if policy.Type == string(EnvBindingPolicy) {
+			continue
+		}
+		policyModel, err := convertToPolicyModel(app, policy)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.importApplicationPolicy(ctx, policyModel); err != nil {
+			return nil, err
+		}
+	}
+	for _, envBinding := range manifest.EnvBindings {
+		if err := c.importEnvBinding(ctx, convertToEnvBindingModel(app, *envBinding)); err != nil {
+			return nil, err
+		}
+	}
+	for _, workflow := range manifest.Workflows {
+		if _, err := c.workflowUsecase.CreateOrUpdateWorkflow(ctx, app, workflow); err != nil {
+			return nil, err
+		}
+	}
+	log.Logger.Infof("imported application %s with %d components", app.Name, len(spec.Spec.Components))
+	return c.converAppModelToBase(ctx, app), nil
+}
+
+// renderApplicationSpec render the components and policies of the application, the env binding policies are excluded
+func (c *applicationUsecaseImpl) renderApplicationSpec(ctx context.Context, app *model.Application) (*v1beta1.Application, error) {
+	listOptions := &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}}}
+	spec := &v1beta1.Application{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Application",
+			APIVersion: "core.oam.dev/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      app.Name,
+			Namespace: app.Namespace,
+			Labels:    app.Labels,
+		},
+	}
+	components, err := c.ds.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range components {
+		component := entity.(*model.ApplicationComponent)
+		bc := common.ApplicationComponent{
+			Name:             component.Name,
+			Type:             component.Type,
+			ExternalRevision: component.ExternalRevision,
+			DependsOn:        component.DependsOn,
+			Inputs:           component.Inputs,
+			Outputs:          component.Outputs,
+			Scopes:           component.Scopes,
+		}
+		if component.Properties != nil {
+			bc.Properties = component.Properties.RawExtension()
+		}
+		for _, trait := range component.Traits {
+			aTrait := common.ApplicationTrait{Type: trait.Type}
+			if trait.Properties != nil {
+				aTrait.Properties = trait.Properties.RawExtension()
+			}
+			bc.Traits = append(bc.Traits, aTrait)
+		}
+		spec.Spec.Components = append(spec.Spec.Components, bc)
+	}
+	policies, err := c.ds.List(ctx, &model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range policies {
+		policy := entity.(*model.ApplicationPolicy)
+		appPolicy := v1beta1.AppPolicy{Name: policy.Name, Type: policy.Type}
+		if policy.Properties != nil {
+			appPolicy.Properties = policy.Properties.RawExtension()
+		}
+		spec.Spec.Policies = append(spec.Spec.Policies, appPolicy)
+	}
+	return spec, nil
+}
+
+// importDeliveryTargets creates or updates the delivery targets in the project of the application,
+// the targets owned by other projects are refused
+func (c *applicationUsecaseImpl) importDeliveryTargets(ctx context.Context, project *model.Project, targets []apisv1.CreateDeliveryTargetRequest) error {
+	for _, target := range targets {
+		existing, err := c.deliveryTargetUsecase.GetDeliveryTarget(ctx, target.Name)
+		if err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		if existing != nil {
+			if existing.Project != "" && existing.Project != project.Name {
+				return bcode.ErrProjectDeliveryTargetNotAllowed
+			}
+			if _, err := c.deliveryTargetUsecase.UpdateDeliveryTarget(ctx, existing, apisv1.UpdateDeliveryTargetRequest{
+				Alias:       target.Alias,
+				Description: target.Description,
+				Cluster:     target.Cluster,
+				Variable:    target.Variable,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		target.Project = project.Name
+		if _, err := c.deliveryTargetUsecase.CreateDeliveryTarget(ctx, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importApplicationComponent creates or updates the component, the alias and description of the existing component and traits are kept
+func (c *applicationUsecaseImpl) importApplicationComponent(ctx context.Context, component *model.ApplicationComponent) error {
+	existing := &model.ApplicationComponent{AppPrimaryKey: component.AppPrimaryKey, Name: component.Name}
+	if err := c.ds.Get(ctx, existing); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		return c.ds.Add(ctx, component)
+	}
+	for i, trait := range component.Traits {
+		for _, existingTrait := range existing.Traits {
+			if existingTrait.Type == trait.Type {
+				component.Traits[i].Alias = existingTrait.Alias
+				component.Traits[i].Description = existingTrait.Description
+				component.Traits[i].CreateTime = existingTrait.CreateTime
+			}
+		}
+	}
+	existing.Type = component.Type
+	existing.ExternalRevision = component.ExternalRevision
+	existing.DependsOn = component.DependsOn
+	existing.Inputs = component.Inputs
+	existing.Outputs = component.Outputs
+	existing.Scopes = component.Scopes
+	existing.Traits = component.Traits
+	existing.Properties = component.Properties
+	return c.ds.Put(ctx, existing)
+}
+
+func (c *applicationUsecaseImpl) importApplicationPolicy(ctx context.Context, policy *model.ApplicationPolicy) error {
+	existing := &model.ApplicationPolicy{AppPrimaryKey: policy.AppPrimaryKey, Name: policy.Name}
+	if err := c.ds.Get(ctx, existing); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		return c.ds.Add(ctx, policy)
+	}
+	existing.Type = policy.Type
+	existing.Properties = policy.Properties
+	return c.ds.Put(ctx, existing)
+}
+
+func (c *applicationUsecaseImpl) importEnvBinding(ctx context.Context, envBinding *model.EnvBinding) error {
+	existing := &model.EnvBinding{AppPrimaryKey: envBinding.AppPrimaryKey, Name: envBinding.Name}
+	if err := c.ds.Get(ctx, existing); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		return c.ds.Add(ctx, envBinding)
+	}
+	existing.Alias = envBinding.Alias
+	existing.Description = envBinding.Description
+	existing.TargetNames = envBinding.TargetNames
+	existing.ComponentSelector = envBinding.ComponentSelector
+	return c.ds.Put(ctx, existing)
+}
+
+func parseApplicationFiles(files map[string]string) (*apisv1.ApplicationManifest, *v1beta1.Application, error) {
+	content, ok := files[ApplicationManifestFile]
+	if !ok {
+		log.Logger.Errorf("the application manifest file %s is missing", ApplicationManifestFile)
+		return nil, nil, bcode.ErrApplicationManifestInvalid
+	}
+	manifest := &apisv1.ApplicationManifest{}
+	if err := yaml.Unmarshal([]byte(content), manifest); err != nil {
+		log.Logger.Errorf("decode the application manifest failure %s", err.Error())
+		return nil, nil, bcode.ErrApplicationManifestInvalid
+	}
+	if manifest.Name == "" {
+		log.Logger.Errorf("the name of the application is not specified in the manifest")
+		return nil, nil, bcode.ErrApplicationManifestInvalid
+	}
+	spec := &v1beta1.Application{}
+	if content, ok := files[ApplicationSpecFile]; ok {
+		if err := yaml.Unmarshal([]byte(content), spec); err != nil {
+			log.Logger.Errorf("decode the application spec failure %s", err.Error())
+			return nil, nil, bcode.ErrApplicationManifestInvalid
+		}
+	}
+	return manifest, spec, nil
+}
+
+func addYAMLFile(files map[string]string, name string, obj interface{}) error {
+	content, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	files[name] = string(content)
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+var _ = Describe("Test application manifest usecase functions", func() {
+	var (
+		appUsecase            *applicationUsecaseImpl
+		projectUsecase        *projectUsecaseImpl
+		deliveryTargetUsecase *deliveryTargetUsecaseImpl
+		testProject           = "manifest-project"
+	)
+
+	BeforeEach(func() {
+		workflowUsecase := &workflowUsecaseImpl{ds: ds}
+		definitionUsecase := &definitionUsecaseImpl{kubeClient: k8sClient}
+		projectUsecase = &projectUsecaseImpl{ds: ds, kubeClient: k8sClient}
+		deliveryTargetUsecase = &deliveryTargetUsecaseImpl{ds: ds, projectUsecase: projectUsecase}
+		appUsecase = &applicationUsecaseImpl{
+			ds:                    ds,
+			workflowUsecase:       workflowUsecase,
+			apply:                 apply.NewAPIApplicator(k8sClient),
+			kubeClient:            k8sClient,
+			envBindingUsecase:     &envBindingUsecaseImpl{ds: ds, workflowUsecase: workflowUsecase, kubeClient: k8sClient, definitionUsecase: definitionUsecase},
+			definitionUsecase:     definitionUsecase,
+			deliveryTargetUsecase: deliveryTargetUsecase,
+			projectUsecase:        projectUsecase,
+		}
+	})
+
+	It("Test export and import application", func() {
+		_, err := projectUsecase.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: testProject})
+		Expect(err).Should(BeNil())
+		_, err = deliveryTargetUsecase.CreateDeliveryTarget(context.TODO(), apisv1.CreateDeliveryTargetRequest{
+			Name:    "manifest-target",
+			Project: testProject,
+			Cluster: &apisv1.ClusterTarget{ClusterName: "local", Namespace: "manifest"},
+		})
+		Expect(err).Should(BeNil())
+		_, err = appUsecase.CreateApplication(context.TODO(), apisv1.CreateApplicationRequest{
+			Name:       "manifest-app",
+			Alias:      "Manifest",
+			Project:    testProject,
+			EnvBinding: []*apisv1.EnvBinding{{Name: "dev", TargetNames: []string{"manifest-target"}}},
+			Component: &apisv1.CreateComponentRequest{
+				Name:          "web",
+				ComponentType: "webservice",
+				Properties:    `{"image":"nginx:1.20"}`,
+			},
+		})
+		Expect(err).Should(BeNil())
+		app, err := appUsecase.GetApplication(context.TODO(), "manifest-app")
+		Expect(err).Should(BeNil())
+
+		By("export the application")
+		exported, err := appUsecase.ExportApplication(context.TODO(), app)
+		Expect(err).Should(BeNil())
+		Expect(exported.Files).Should(HaveKey(ApplicationManifestFile))
+		Expect(exported.Files).Should(HaveKey(ApplicationSpecFile))
+		Expect(exported.Files).Should(HaveKey(fmt.Sprintf(ApplicationEnvFileFormat, "dev")))
+
+		By("import the application after it is deleted")
+		Expect(appUsecase.DeleteApplication(context.TODO(), app)).Should(BeNil())
+		Expect(deliveryTargetUsecase.DeleteDeliveryTarget(context.TODO(), "manifest-target")).Should(BeNil())
+		base, err := appUsecase.ImportApplication(context.TODO(), apisv1.ImportApplicationRequest{Files: exported.Files})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(base.Alias, "Manifest")).Should(BeEmpty())
+		app, err = appUsecase.GetApplication(context.TODO(), "manifest-app")
+		Expect(err).Should(BeNil())
+		reexported, err := appUsecase.ExportApplication(context.TODO(), app)
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(reexported.Files, exported.Files)).Should(BeEmpty())
+
+		By("import the application again")
+		_, err = appUsecase.ImportApplication(context.TODO(), apisv1.ImportApplicationRequest{Files: exported.Files})
+		Expect(err).Should(BeNil())
+		components, err := ds.List(context.TODO(), &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(components)).Should(Equal(1))
+
+		By("import the application into another project with the delivery target of this project")
+		_, err = projectUsecase.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: "manifest-other-project"})
+		Expect(err).Should(BeNil())
+		files := map[string]string{}
+		for name, content := range exported.Files {
+			files[name] = content
+		}
+		files[ApplicationManifestFile] = strings.Replace(files[ApplicationManifestFile], "\nname: manifest-app\n", "\nname: manifest-app-other\n", 1)
+		_, err = appUsecase.ImportApplication(context.TODO(), apisv1.ImportApplicationRequest{Files: files, Project: "manifest-other-project"})
+		Expect(cmp.Equal(err, bcode.ErrProjectDeliveryTargetNotAllowed, cmpopts.EquateErrors())).Should(BeTrue())
+		target, err := deliveryTargetUsecase.GetDeliveryTarget(context.TODO(), "manifest-target")
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(target.Project, testProject)).Should(BeEmpty())
+
+		By("import the invalid files")
+		_, err = appUsecase.ImportApplication(context.TODO(), apisv1.ImportApplicationRequest{Files: map[string]string{ApplicationSpecFile: ""}})
+		Expect(cmp.Equal(err, bcode.ErrApplicationManifestInvalid, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+})
@@ -0,0 +1,286 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// SyncApplications syncs the application CRs created by CLI or kubectl into the datastore,
+// the applications deployed by the apiserver and the addons are skipped.
+func (c *applicationUsecaseImpl) SyncApplications(ctx context.Context) error {
+	apps := &v1beta1.ApplicationList{}
+	if err := c.kubeClient.List(ctx, apps); err != nil {
+		return err
+	}
+	existing := map[string]bool{}
+	for i := range apps.Items {
+		app := &apps.Items[i]
+		existing[fmt.Sprintf("%s/%s", app.Namespace, app.Name)] = true
+		if _, ok := app.Annotations[oam.AnnotationAppName]; ok {
+			continue
+		}
+		if _, ok := app.Labels[oam.LabelAddonName]; ok {
+			continue
+		}
+		if !app.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if err := c.syncApplication(ctx, app); err != nil {
+			log.Logger.Errorf("failed to sync application %s/%s: %s", app.Namespace, app.Name, err.Error())
+		}
+	}
+
+	// remove the synced applications whose CR has been deleted
+	entities, err := c.ds.List(ctx, &model.Application{}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range entities {
+		appModel := entity.(*model.Application)
+		if !appModel.IsSynced() || existing[fmt.Sprintf("%s/%s", appModel.Labels[model.LabelSyncNamespace], appModel.Name)] {
+			continue
+		}
+		log.Logger.Infof("the application CR of the synced application %s is deleted, remove it", appModel.Name)
+		if err := c.DeleteApplication(ctx, appModel); err != nil {
+			log.Logger.Errorf("failed to delete synced application %s: %s", appModel.Name, err.Error())
+		}
+	}
+	return nil
+}
+
+func (c *applicationUsecaseImpl) syncApplication(ctx context.Context, app *v1beta1.Application) error {
+	generation := strconv.FormatInt(app.Generation, 10)
+	appModel := &model.Application{Name: app.Name}
+	exist := true
+	if err := c.ds.Get(ctx, appModel); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+		exist = false
+	}
+	if exist {
+		if !appModel.IsSynced() || appModel.Labels[model.LabelSyncNamespace] != app.Namespace {
+			log.Logger.Warnf("the application %s/%s conflicts with the application %s in the datastore, skip syncing", app.Namespace, app.Name, appModel.Name)
+			return nil
+		}
+		if appModel.Labels[model.LabelSyncGeneration] == generation {
+			return nil
+		}
+	} else {
+		project, err := c.getOrCreateSyncProject(ctx, app.Namespace)
+		if err != nil {
+			return err
+		}
+		appModel.Project = project.Name
+		appModel.Namespace = project.Namespace
+	}
+
+	appModel.Labels = map[string]string{}
+	for key, value := range app.Labels {
+		appModel.Labels[key] = value
+	}
+	appModel.Labels[model.LabelSourceOfTruth] = model.FromCR
+	appModel.Labels[model.LabelSyncNamespace] = app.Namespace
+	appModel.Labels[model.LabelSyncGeneration] = generation
+	if alias, ok := app.Annotations[oam.AnnotationAppAlias]; ok {
+		appModel.Alias = alias
+	}
+	var err error
+	if exist {
+		err = c.ds.Put(ctx, appModel)
+	} else {
+		err = c.ds.Add(ctx, appModel)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.syncApplicationComponents(ctx, appModel, app); err != nil {
+		return err
+	}
+	if err := c.syncApplicationPolicies(ctx, appModel, app); err != nil {
+		return err
+	}
+	if err := c.syncApplicationEnv(ctx, appModel, app); err != nil {
+		return err
+	}
+	log.Logger.Infof("synced application %s/%s of generation %s", app.Namespace, app.Name, generation)
+	return nil
+}
+
+func (c *applicationUsecaseImpl) syncApplicationComponents(ctx context.Context, appModel *model.Application, app *v1beta1.Application) error {
+	names := map[string]bool{}
+	for _, component := range app.Spec.Components {
+		componentModel, err := convertToComponentModel(appModel, component)
+		if err != nil {
+			return err
+		}
+		if err := c.importApplicationComponent(ctx, componentModel); err != nil {
+			return err
+		}
+		names[component.Name] = true
+	}
+	components, err := c.ds.List(ctx, &model.ApplicationComponent{AppPrimaryKey: appModel.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range components {
+		if component := entity.(*model.ApplicationComponent); !names[component.Name] {
+			if err := c.ds.Delete(ctx, component); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *applicationUsecaseImpl) syncApplicationPolicies(ctx context.Context, appModel *model.Application, app *v1beta1.Application) error {
+	names := map[string]bool{}
+	for _, policy := range app.Spec.Policies {
+		if policy.Type == string(EnvBindingPolicy) {
+			continue
+		}
+		policyModel, err := convertToPolicyModel(appModel, policy)
+		if err != nil {
+			return err
+		}
+		if err := c.importApplicationPolicy(ctx, policyModel); err != nil {
+			return err
+		}
+		names[policy.Name] = true
+	}
+	policies, err := c.ds.List(ctx, &model.ApplicationPolicy{AppPrimaryKey: appModel.PrimaryKey()}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, entity := range policies {
+		if policy := entity.(*model.ApplicationPolicy); !names[policy.Name] {
+			if err := c.ds.Delete(ctx, policy); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncApplicationEnv binds the synced application to the env deploying to the namespace of the local cluster,
+// the workflow of the env is replaced by the workflow of the CR if specified.
+func (c *applicationUsecaseImpl) syncApplicationEnv(ctx context.Context, appModel *model.Application, app *v1beta1.Application) error {
+	envName := app.Namespace
+	if _, err := c.envBindingUsecase.GetEnvBinding(ctx, appModel, envName); err != nil {
+		if !errors.Is(err, bcode.ErrEnvBindingsNotExist) {
+			return err
+		}
+		target, err := c.getOrCreateSyncTarget(ctx, appModel.Project, app.Namespace)
+		if err != nil {
+			return err
+		}
+		if err := c.envBindingUsecase.BatchCreateEnvBinding(ctx, appModel, apisv1.EnvBindingList{{
+			Name:        envName,
+			Alias:       envName,
+			Description: "Created automatically by syncing the application",
+			TargetNames: []string{target.Name},
+		}}); err != nil {
+			return err
+		}
+	}
+	if app.Spec.Workflow == nil || len(app.Spec.Workflow.Steps) == 0 {
+		return nil
+	}
+	var steps []apisv1.WorkflowStep
+	for _, step := range app.Spec.Workflow.Steps {
+		var properties string
+		if step.Properties != nil {
+			jsonStruct, err := model.NewJSONStruct(step.Properties)
+			if err != nil {
+				return err
+			}
+			properties = jsonStruct.JSON()
+		}
+		steps = append(steps, apisv1.WorkflowStep{
+			Name:       step.Name,
+			Type:       step.Type,
+			DependsOn:  step.DependsOn,
+			Properties: properties,
+			Inputs:     step.Inputs,
+			Outputs:    step.Outputs,
+		})
+	}
+	isDefault := true
+	_, err := c.workflowUsecase.CreateOrUpdateWorkflow(ctx, appModel, apisv1.CreateWorkflowRequest{
+		Name:        convertWorkflowName(envName),
+		Alias:       fmt.Sprintf("%s Workflow", envName),
+		Description: "Synced from the application",
+		EnvName:     envName,
+		Steps:       steps,
+		Default:     &isDefault,
+	})
+	return err
+}
+
+// getOrCreateSyncProject returns the project of the namespace, a project named as the namespace is created if absent
+func (c *applicationUsecaseImpl) getOrCreateSyncProject(ctx context.Context, namespace string) (*model.Project, error) {
+	projects, err := c.ds.List(ctx, &model.Project{Namespace: namespace}, &datastore.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if len(projects) > 0 {
+		return projects[0].(*model.Project), nil
+	}
+	if _, err := c.projectUsecase.CreateProject(ctx, apisv1.CreateProjectRequest{
+		Name:        namespace,
+		Namespace:   namespace,
+		Description: "Created automatically by syncing the applications",
+	}); err != nil {
+		return nil, err
+	}
+	return c.projectUsecase.GetProject(ctx, namespace)
+}
+
+// getOrCreateSyncTarget returns the delivery target of the namespace in the local cluster
+func (c *applicationUsecaseImpl) getOrCreateSyncTarget(ctx context.Context, project, namespace string) (*model.DeliveryTarget, error) {
+	name := fmt.Sprintf("%s-%s", multicluster.ClusterLocalName, namespace)
+	target, err := c.deliveryTargetUsecase.GetDeliveryTarget(ctx, name)
+	if err == nil {
+		return target, nil
+	}
+	if !errors.Is(err, datastore.ErrRecordNotExist) {
+		return nil, err
+	}
+	if _, err := c.deliveryTargetUsecase.CreateDeliveryTarget(ctx, apisv1.CreateDeliveryTargetRequest{
+		Name:        name,
+		Project:     project,
+		Description: "Created automatically by syncing the applications",
+		Cluster:     &apisv1.ClusterTarget{ClusterName: multicluster.ClusterLocalName, Namespace: namespace},
+	}); err != nil {
+		return nil, err
+	}
+	return c.deliveryTargetUsecase.GetDeliveryTarget(ctx, name)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+var _ = Describe("Test application sync usecase functions", func() {
+	var (
+		appUsecase    *applicationUsecaseImpl
+		syncNamespace = "sync-apps"
+	)
+
+	BeforeEach(func() {
+		workflowUsecase := &workflowUsecaseImpl{ds: ds, kubeClient: k8sClient}
+		definitionUsecase := &definitionUsecaseImpl{kubeClient: k8sClient}
+		projectUsecase := &projectUsecaseImpl{ds: ds, kubeClient: k8sClient}
+		appUsecase = &applicationUsecaseImpl{
+			ds:                    ds,
+			workflowUsecase:       workflowUsecase,
+			apply:                 apply.NewAPIApplicator(k8sClient),
+			kubeClient:            k8sClient,
+			envBindingUsecase:     &envBindingUsecaseImpl{ds: ds, workflowUsecase: workflowUsecase, kubeClient: k8sClient, definitionUsecase: definitionUsecase},
+			definitionUsecase:     definitionUsecase,
+			deliveryTargetUsecase: &deliveryTargetUsecaseImpl{ds: ds, projectUsecase: projectUsecase},
+			projectUsecase:        projectUsecase,
+		}
+	})
+
+	It("Test sync the application created by CLI", func() {
+		Expect(k8sClient.Create(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: syncNamespace}})).Should(BeNil())
+		app := &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Name: "cli-app", Namespace: syncNamespace},
+			Spec: v1beta1.ApplicationSpec{
+				Components: []common.ApplicationComponent{{
+					Name:       "web",
+					Type:       "webservice",
+					Properties: &runtime.RawExtension{Raw: []byte(`{"image":"nginx"}`)},
+				}},
+			},
+		}
+		Expect(k8sClient.Create(context.TODO(), app)).Should(BeNil())
+		Expect(appUsecase.SyncApplications(context.TODO())).Should(BeNil())
+
+		appModel, err := appUsecase.GetApplication(context.TODO(), "cli-app")
+		Expect(err).Should(BeNil())
+		Expect(appModel.IsSynced()).Should(BeTrue())
+		Expect(cmp.Diff(appModel.Namespace, syncNamespace)).Should(BeEmpty())
+		_, err = appUsecase.envBindingUsecase.GetEnvBinding(context.TODO(), appModel, syncNamespace)
+		Expect(err).Should(BeNil())
+		rendered, err := appUsecase.renderOAMApplication(context.TODO(), appModel, "", "1")
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(rendered.Name, app.Name)).Should(BeEmpty())
+		Expect(cmp.Diff(rendered.Spec.Components[0].Name, "web")).Should(BeEmpty())
+
+		By("sync the changes of the application")
+		Expect(k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(app), app)).Should(BeNil())
+		app.Spec.Components = []common.ApplicationComponent{{Name: "worker", Type: "worker"}}
+		Expect(k8sClient.Update(context.TODO(), app)).Should(BeNil())
+		Expect(appUsecase.SyncApplications(context.TODO())).Should(BeNil())
+		components, err := ds.List(context.TODO(), &model.ApplicationComponent{AppPrimaryKey: appModel.PrimaryKey()}, nil)
+		Expect(err).Should(BeNil())
+		Expect(len(components)).Should(Equal(1))
+		Expect(cmp.Diff(components[0].(*model.ApplicationComponent).Name, "worker")).Should(BeEmpty())
+
+		By("remove the application after the CR is deleted")
+		Expect(k8sClient.Delete(context.TODO(), app)).Should(BeNil())
+		Expect(appUsecase.SyncApplications(context.TODO())).Should(BeNil())
+		_, err = appUsecase.GetApplication(context.TODO(), "cli-app")
+		Expect(err).ShouldNot(BeNil())
+	})
+})
@@ -0,0 +1,487 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/datastore"
+	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+// ApplicationTemplateUsecase application template manage usecase.
+type ApplicationTemplateUsecase interface {
+	ListApplicationTemplates(ctx context.Context, project string) ([]*apisv1.ApplicationTemplateBase, error)
+	GetApplicationTemplate(ctx context.Context, templateName string) (*model.ApplicationTemplate, error)
+	DetailApplicationTemplate(ctx context.Context, template *model.ApplicationTemplate) (*apisv1.ApplicationTemplateBase, error)
+	DeleteApplicationTemplate(ctx context.Context, template *model.ApplicationTemplate) error
+}
+
+type applicationTemplateUsecaseImpl struct {
+	ds datastore.DataStore
+}
+
+// NewApplicationTemplateUsecase new application template usecase
+func NewApplicationTemplateUsecase(ds datastore.DataStore) ApplicationTemplateUsecase {
+	return &applicationTemplateUsecaseImpl{ds: ds}
+}
+
+// ListApplicationTemplates list the application templates, filtered by project if specified
+func (a *applicationTemplateUsecaseImpl) ListApplicationTemplates(ctx context.Context, project string) ([]*apisv1.ApplicationTemplateBase, error) {
+	entities, err := a.ds.List(ctx, &model.ApplicationTemplate{Project: project}, &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var templates []*apisv1.ApplicationTemplateBase
+	for _, entity := range entities {
+		template := entity.(*model.ApplicationTemplate)
+		versions, err := _listApplicationTemplateVersions(ctx, a.ds, template.Name)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, convertTemplateModel2Base(template, versions))
+	}
+	return templates, nil
+}
+
+// GetApplicationTemplate get application template
+func (a *applicationTemplateUsecaseImpl) GetApplicationTemplate(ctx context.Context, templateName string) (*model.ApplicationTemplate, error) {
+	template := &model.ApplicationTemplate{Name: templateName}
+	if err := a.ds.Get(ctx, template); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationTemplateNotExist
+		}
+		return nil, err
+	}
+	return template, nil
+}
+
+// DetailApplicationTemplate detail application template with all versions
+func (a *applicationTemplateUsecaseImpl) DetailApplicationTemplate(ctx context.Context, template *model.ApplicationTemplate) (*apisv1.ApplicationTemplateBase, error) {
+	versions, err := _listApplicationTemplateVersions(ctx, a.ds, template.Name)
+	if err != nil {
+		return nil, err
+	}
+	return convertTemplateModel2Base(template, versions), nil
+}
+
+// DeleteApplicationTemplate delete application template and all versions
+func (a *applicationTemplateUsecaseImpl) DeleteApplicationTemplate(ctx context.Context, template *model.ApplicationTemplate) error {
+	versions, err := _listApplicationTemplateVersions(ctx, a.ds, template.Name)
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if err := a.ds.Delete(ctx, version); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	if err := a.ds.Delete(ctx, template); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrApplicationTemplateNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// PublishApplicationTemplate publish app template
+func (c *applicationUsecaseImpl) PublishApplicationTemplate(ctx context.Context, app *model.Application, req apisv1.CreateApplicationTemplateRequest) (*apisv1.ApplicationTemplateBase, error) {
+	snapshot, err := c.snapshotApplication(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	for i, param := range req.Parameters {
+		properties, err := findParameterProperties(snapshot.Components, param)
+		if err != nil {
+			return nil, err
+		}
+		// the current value of the source application is the default value
+		if value, ok := getJSONStructField(*properties, param.Path); ok && param.Default == nil {
+			req.Parameters[i].Default = value
+		}
+	}
+	snapshot.TemplateName = req.TemplateName
+	snapshot.Version = req.Version
+	snapshot.Description = req.Description
+	snapshot.Parameters = req.Parameters
+
+	template := &model.ApplicationTemplate{Name: req.TemplateName}
+	if err := c.ds.Get(ctx, template); err != nil {
+		if !errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, err
+		}
+		template.Description = req.Description
+		template.Project = app.Project
+		if err := c.ds.Add(ctx, template); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.ds.Add(ctx, snapshot); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrApplicationTemplateVersionExist
+		}
+		return nil, err
+	}
+	versions, err := _listApplicationTemplateVersions(ctx, c.ds, template.Name)
+	if err != nil {
+		return nil, err
+	}
+	return convertTemplateModel2Base(template, versions), nil
+}
+
+// CreateApplicationFromTemplate instantiate the application template into a new application
+func (c *applicationUsecaseImpl) CreateApplicationFromTemplate(ctx context.Context, template *model.ApplicationTemplate, req apisv1.CreateApplicationFromTemplateRequest) (*apisv1.ApplicationBase, error) {
+	snapshot, err := _getApplicationTemplateVersion(ctx, c.ds, template.Name, req.Version)
+	if err != nil {
+		return nil, err
+	}
+	for _, param := range snapshot.Parameters {
+		value, ok := req.Parameters[param.Name]
+		if !ok {
+			if param.Required {
+				log.Logger.Errorf("the required parameter %s of template %s is not specified", param.Name, template.Name)
+				return nil, bcode.ErrApplicationTemplateParameterRequired
+			}
+			continue
+		}
+		properties, err := findParameterProperties(snapshot.Components, param)
+		if err != nil {
+			return nil, err
+		}
+		setJSONStructField(*properties, param.Path, value)
+	}
+	application := &model.Application{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		Icon:        req.Icon,
+		Labels:      req.Labels,
+		Project:     req.Project,
+	}
+	return c.createApplicationFromSnapshot(ctx, application, snapshot)
+}
+
+// CloneApplication create a new application with the components, policies, env bindings and workflows of the application
+func (c *applicationUsecaseImpl) CloneApplication(ctx context.Context, app *model.Application, req apisv1.CloneApplicationRequest) (*apisv1.ApplicationBase, error) {
+	snapshot, err := c.snapshotApplication(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	application := &model.Application{
+		Name:        req.Name,
+		Alias:       req.Alias,
+		Description: req.Description,
+		Icon:        app.Icon,
+		Labels:      app.Labels,
+		Project:     req.Project,
+	}
+	if application.Project == "" {
+		application.Project = app.Project
+	}
+	return c.createApplicationFromSnapshot(ctx, application, snapshot)
+}
+
+// snapshotApplication records the components, policies, env bindings and workflows of the application
+func (c *applicationUsecaseImpl) snapshotApplication(ctx context.Context, app *model.Application) (*model.ApplicationTemplateVersion, error) {
+	snapshot := &model.ApplicationTemplateVersion{SourceApplication: app.Name}
+	listOptions := &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderAscending}}}
+	components, err := c.ds.List(ctx, &model.ApplicationComponent{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range components {
+		snapshot.Components = append(snapshot.Components, *entity.(*model.ApplicationComponent))
+	}
+	policies, err := c.ds.List(ctx, &model.ApplicationPolicy{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range policies {
+		snapshot.Policies = append(snapshot.Policies, *entity.(*model.ApplicationPolicy))
+	}
+	envBindings, err := c.ds.List(ctx, &model.EnvBinding{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range envBindings {
+		snapshot.EnvBindings = append(snapshot.EnvBindings, *entity.(*model.EnvBinding))
+	}
+	workflows, err := c.ds.List(ctx, &model.Workflow{AppPrimaryKey: app.PrimaryKey()}, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	for _, entity := range workflows {
+		snapshot.Workflows = append(snapshot.Workflows, *entity.(*model.Workflow))
+	}
+	return snapshot, nil
+}
+
+// createApplicationFromSnapshot create the application and restore its components, policies, env bindings and workflows from the snapshot
+func (c *applicationUsecaseImpl) createApplicationFromSnapshot(ctx context.Context, application *model.Application, snapshot *model.ApplicationTemplateVersion) (*apisv1.ApplicationBase, error) {
+	exist, err := c.ds.IsExist(ctx, application)
+	if err != nil {
+		log.Logger.Errorf("check application name is exist failure %s", err.Error())
+		return nil, bcode.ErrApplicationExist
+	}
+	if exist {
+		return nil, bcode.ErrApplicationExist
+	}
+	project, err := c.projectUsecase.GetProject(ctx, application.Project)
+	if err != nil {
+		return nil, err
+	}
+	application.Namespace = project.Namespace
+	application.Project = project.Name
+	if err := checkApplicationQuota(ctx, c.ds, project); err != nil {
+		return nil, err
+	}
+
+	var definitionTypes, targetNames []string
+	var entities []datastore.Entity
+	for i := range snapshot.Components {
+		component := snapshot.Components[i]
+		component.Model = model.Model{}
+		component.AppPrimaryKey = application.PrimaryKey()
+		definitionTypes = append(definitionTypes, component.Type)
+		for _, trait := range component.Traits {
+			definitionTypes = append(definitionTypes, trait.Type)
+		}
+		entities = append(entities, &component)
+	}
+	for i := range snapshot.Policies {
+		policy := snapshot.Policies[i]
+		policy.Model = model.Model{}
+		policy.AppPrimaryKey = application.PrimaryKey()
+		entities = append(entities, &policy)
+	}
+	for i := range snapshot.EnvBindings {
+		envBinding := snapshot.EnvBindings[i]
+		envBinding.Model = model.Model{}
+		envBinding.AppPrimaryKey = application.PrimaryKey()
+		envBinding.TargetNames, err = remapDeliveryTargets(ctx, c.ds, project, envBinding.TargetNames)
+		if err != nil {
+			return nil, err
+		}
+		targetNames = append(targetNames, envBinding.TargetNames...)
+		entities = append(entities, &envBinding)
+	}
+	for i := range snapshot.Workflows {
+		workflow := snapshot.Workflows[i]
+		workflow.Model = model.Model{}
+		workflow.AppPrimaryKey = application.PrimaryKey()
+		entities = append(entities, &workflow)
+	}
+	if err := checkDefinitionQuota(project, definitionTypes...); err != nil {
+		return nil, err
+	}
+	if err := checkDeliveryTargetQuota(project, targetNames...); err != nil {
+		return nil, err
+	}
+	// add the application first, so the restored entities are never left without their application
+	if err := c.ds.Add(ctx, application); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrApplicationExist
+		}
+		return nil, err
+	}
+	if len(entities) > 0 {
+		if err := c.ds.BatchAdd(ctx, entities); err != nil {
+			log.Logger.Errorf("restore the application %s from snapshot failure %s", application.Name, err.Error())
+			if err := c.ds.Delete(ctx, application); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+				log.Logger.Errorf("rollback the application %s failure %s", application.Name, err.Error())
+			}
+			return nil, err
+		}
+	}
+	return c.converAppModelToBase(ctx, application), nil
+}
+
+// remapDeliveryTargets maps the delivery targets owned by another project to the targets of the project which deliver
+// to the same cluster and namespace, the targets shared by all projects are kept.
+func remapDeliveryTargets(ctx context.Context, ds datastore.DataStore, project *model.Project, targetNames []string) ([]string, error) {
+	var projectTargets []*model.DeliveryTarget
+	var remapped []string
+	for _, name := range targetNames {
+		target := &model.DeliveryTarget{Name: name}
+		if err := ds.Get(ctx, target); err != nil {
+			if errors.Is(err, datastore.ErrRecordNotExist) {
+				return nil, bcode.ErrEnvbindingDeliveryTargetNotAllExist
+			}
+			return nil, err
+		}
+		if target.Project == "" || target.Project == project.Name {
+			remapped = append(remapped, name)
+			continue
+		}
+		if projectTargets == nil {
+			entities, err := ds.List(ctx, &model.DeliveryTarget{Project: project.Name}, &datastore.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			projectTargets = []*model.DeliveryTarget{}
+			for _, entity := range entities {
+				projectTargets = append(projectTargets, entity.(*model.DeliveryTarget))
+			}
+		}
+		var matched *model.DeliveryTarget
+		for _, candidate := range projectTargets {
+			if candidate.Cluster != nil && target.Cluster != nil && *candidate.Cluster == *target.Cluster {
+				matched = candidate
+				break
+			}
+		}
+		if matched == nil {
+			log.Logger.Errorf("the delivery target %s of project %s has no counterpart in project %s", name, target.Project, project.Name)
+			return nil, bcode.ErrProjectDeliveryTargetNotAllowed
+		}
+		remapped = append(remapped, matched.Name)
+	}
+	return remapped, nil
+}
+
+func _listApplicationTemplateVersions(ctx context.Context, ds datastore.DataStore, templateName string) ([]*model.ApplicationTemplateVersion, error) {
+	entities, err := ds.List(ctx, &model.ApplicationTemplateVersion{TemplateName: templateName}, &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	var versions []*model.ApplicationTemplateVersion
+	for _, entity := range entities {
+		versions = append(versions, entity.(*model.ApplicationTemplateVersion))
+	}
+	return versions, nil
+}
+
+// _getApplicationTemplateVersion get the version of the template, the latest version is returned if the version is empty
+func _getApplicationTemplateVersion(ctx context.Context, ds datastore.DataStore, templateName, version string) (*model.ApplicationTemplateVersion, error) {
+	if version == "" {
+		versions, err := _listApplicationTemplateVersions(ctx, ds, templateName)
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			return nil, bcode.ErrApplicationTemplateVersionNotExist
+		}
+		return versions[0], nil
+	}
+	templateVersion := &model.ApplicationTemplateVersion{TemplateName: templateName, Version: version}
+	if err := ds.Get(ctx, templateVersion); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrApplicationTemplateVersionNotExist
+		}
+		return nil, err
+	}
+	return templateVersion, nil
+}
+
+func convertTemplateModel2Base(template *model.ApplicationTemplate, versions []*model.ApplicationTemplateVersion) *apisv1.ApplicationTemplateBase {
+	base := &apisv1.ApplicationTemplateBase{
+		TemplateName: template.Name,
+		Description:  template.Description,
+		Project:      template.Project,
+		CreateTime:   template.CreateTime,
+		UpdateTime:   template.UpdateTime,
+	}
+	for _, version := range versions {
+		templateVersion := &apisv1.ApplicationTemplateVersion{
+			Version:           version.Version,
+			Description:       version.Description,
+			CreateUser:        version.CreateUser,
+			SourceApplication: version.SourceApplication,
+			Parameters:        version.Parameters,
+			CreateTime:        version.CreateTime,
+			UpdateTime:        version.UpdateTime,
+		}
+		for _, component := range version.Components {
+			templateVersion.Components = append(templateVersion.Components, apisv1.NameAlias{Name: component.Name, Alias: component.Alias})
+		}
+		base.Versions = append(base.Versions, templateVersion)
+	}
+	return base
+}
+
+// findParameterProperties returns the properties of the component or trait that the parameter refers to
+func findParameterProperties(components []model.ApplicationComponent, param model.ApplicationTemplateParameter) (*model.JSONStruct, error) {
+	for i := range components {
+		if components[i].Name != param.Component {
+			continue
+		}
+		if param.Trait == "" {
+			if components[i].Properties == nil {
+				components[i].Properties = &model.JSONStruct{}
+			}
+			return components[i].Properties, nil
+		}
+		for j := range components[i].Traits {
+			trait := &components[i].Traits[j]
+			if trait.Type != param.Trait {
+				continue
+			}
+			if trait.Properties == nil {
+				trait.Properties = &model.JSONStruct{}
+			}
+			return trait.Properties, nil
+		}
+	}
+	log.Logger.Errorf("the component %s or trait %s of the parameter %s is not exist", param.Component, param.Trait, param.Name)
+	return nil, bcode.ErrApplicationTemplateParameterInvalid
+}
+
+// getJSONStructField returns the value of the field path separated by dot
+func getJSONStructField(properties model.JSONStruct, path string) (interface{}, bool) {
+	var current interface{} = map[string]interface{}(properties)
+	for _, key := range strings.Split(path, ".") {
+		fields, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = fields[key]; !ok {
+			return nil, false
+		}
+	}
+	// copy the value, so that the default value won't be affected by the changes of the properties
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// setJSONStructField sets the value of the field path separated by dot, the missing parent fields will be created
+func setJSONStructField(properties model.JSONStruct, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	fields := map[string]interface{}(properties)
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := fields[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			fields[key] = next
+		}
+		fields = next
+	}
+	fields[keys[len(keys)-1]] = value
+}
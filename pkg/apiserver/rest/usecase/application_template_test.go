@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package usecase
+
+import (
+	"context"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/utils/apply"
+)
+
+var _ = Describe("Test application template usecase functions", func() {
+	var (
+		appUsecase      *applicationUsecaseImpl
+		templateUsecase *applicationTemplateUsecaseImpl
+		projectUsecase  *projectUsecaseImpl
+		testProject     = "template-project"
+	)
+
+	BeforeEach(func() {
+		workflowUsecase := &workflowUsecaseImpl{ds: ds}
+		definitionUsecase := &definitionUsecaseImpl{kubeClient: k8sClient}
+		projectUsecase = &projectUsecaseImpl{ds: ds, kubeClient: k8sClient}
+		appUsecase = &applicationUsecaseImpl{
+			ds:                    ds,
+			workflowUsecase:       workflowUsecase,
+			apply:                 apply.NewAPIApplicator(k8sClient),
+			kubeClient:            k8sClient,
+			envBindingUsecase:     &envBindingUsecaseImpl{ds: ds, workflowUsecase: workflowUsecase, kubeClient: k8sClient, definitionUsecase: definitionUsecase},
+			definitionUsecase:     definitionUsecase,
+			deliveryTargetUsecase: &deliveryTargetUsecaseImpl{ds: ds},
+			projectUsecase:        projectUsecase,
+		}
+		templateUsecase = &applicationTemplateUsecaseImpl{ds: ds}
+	})
+
+	It("Test publish, instantiate and delete application template", func() {
+		_, err := projectUsecase.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: testProject})
+		Expect(err).Should(BeNil())
+		_, err = appUsecase.CreateApplication(context.TODO(), apisv1.CreateApplicationRequest{
+			Name:    "template-source",
+			Project: testProject,
+			Component: &apisv1.CreateComponentRequest{
+				Name:          "web",
+				ComponentType: "webservice",
+				Properties:    `{"image":"nginx:1.20","cmd":["sleep","1000"]}`,
+			},
+		})
+		Expect(err).Should(BeNil())
+		app, err := appUsecase.GetApplication(context.TODO(), "template-source")
+		Expect(err).Should(BeNil())
+
+		By("publish the application as a template")
+		_, err = appUsecase.PublishApplicationTemplate(context.TODO(), app, apisv1.CreateApplicationTemplateRequest{
+			TemplateName: "web-template",
+			Version:      "v1",
+			Parameters:   []model.ApplicationTemplateParameter{{Name: "unknown", Component: "unknown", Path: "image"}},
+		})
+		Expect(cmp.Equal(err, bcode.ErrApplicationTemplateParameterInvalid, cmpopts.EquateErrors())).Should(BeTrue())
+		base, err := appUsecase.PublishApplicationTemplate(context.TODO(), app, apisv1.CreateApplicationTemplateRequest{
+			TemplateName: "web-template",
+			Version:      "v1",
+			Parameters:   []model.ApplicationTemplateParameter{{Name: "image", Component: "web", Path: "image", Required: true}},
+		})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(base.Project, testProject)).Should(BeEmpty())
+		Expect(len(base.Versions)).Should(Equal(1))
+		Expect(base.Versions[0].Parameters[0].Default).Should(Equal("nginx:1.20"))
+		_, err = appUsecase.PublishApplicationTemplate(context.TODO(), app, apisv1.CreateApplicationTemplateRequest{
+			TemplateName: "web-template",
+			Version:      "v1",
+		})
+		Expect(cmp.Equal(err, bcode.ErrApplicationTemplateVersionExist, cmpopts.EquateErrors())).Should(BeTrue())
+
+		templates, err := templateUsecase.ListApplicationTemplates(context.TODO(), testProject)
+		Expect(err).Should(BeNil())
+		Expect(len(templates)).Should(Equal(1))
+
+		By("instantiate the template")
+		template, err := templateUsecase.GetApplicationTemplate(context.TODO(), "web-template")
+		Expect(err).Should(BeNil())
+		_, err = appUsecase.CreateApplicationFromTemplate(context.TODO(), template, apisv1.CreateApplicationFromTemplateRequest{
+			Name:    "template-instance",
+			Project: testProject,
+		})
+		Expect(cmp.Equal(err, bcode.ErrApplicationTemplateParameterRequired, cmpopts.EquateErrors())).Should(BeTrue())
+		_, err = appUsecase.CreateApplicationFromTemplate(context.TODO(), template, apisv1.CreateApplicationFromTemplateRequest{
+			Name:       "template-instance",
+			Project:    testProject,
+			Parameters: map[string]interface{}{"image": "nginx:1.21"},
+		})
+		Expect(err).Should(BeNil())
+		component, err := appUsecase.DetailComponent(context.TODO(), &model.Application{Name: "template-instance"}, "web")
+		Expect(err).Should(BeNil())
+		Expect((*component.Properties)["image"]).Should(Equal("nginx:1.21"))
+
+		By("delete the template")
+		Expect(templateUsecase.DeleteApplicationTemplate(context.TODO(), template)).Should(BeNil())
+		_, err = templateUsecase.GetApplicationTemplate(context.TODO(), "web-template")
+		Expect(cmp.Equal(err, bcode.ErrApplicationTemplateNotExist, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+
+	It("Test clone application", func() {
+		app, err := appUsecase.GetApplication(context.TODO(), "template-source")
+		Expect(err).Should(BeNil())
+		base, err := appUsecase.CloneApplication(context.TODO(), app, apisv1.CloneApplicationRequest{Name: "template-source-clone"})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(base.Project.Name, testProject)).Should(BeEmpty())
+		components, err := appUsecase.ListComponents(context.TODO(), &model.Application{Name: "template-source-clone"}, apisv1.ListApplicationComponentOptions{})
+		Expect(err).Should(BeNil())
+		Expect(len(components)).Should(Equal(1))
+
+		_, err = appUsecase.CloneApplication(context.TODO(), app, apisv1.CloneApplicationRequest{Name: "template-source-clone"})
+		Expect(cmp.Equal(err, bcode.ErrApplicationExist, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+
+	It("Test remap delivery targets", func() {
+		cluster := &model.ClusterTarget{ClusterName: "local", Namespace: "remap"}
+		Expect(ds.Add(context.TODO(), &model.DeliveryTarget{Name: "remap-source", Project: "remap-a", Cluster: cluster})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.DeliveryTarget{Name: "remap-dest", Project: "remap-b", Cluster: cluster})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.DeliveryTarget{Name: "remap-shared", Cluster: &model.ClusterTarget{ClusterName: "local"}})).Should(BeNil())
+		Expect(ds.Add(context.TODO(), &model.DeliveryTarget{Name: "remap-other", Project: "remap-a", Cluster: &model.ClusterTarget{ClusterName: "local", Namespace: "other"}})).Should(BeNil())
+
+		targets, err := remapDeliveryTargets(context.TODO(), ds, &model.Project{Name: "remap-b"}, []string{"remap-source", "remap-shared"})
+		Expect(err).Should(BeNil())
+		Expect(targets).Should(Equal([]string{"remap-dest", "remap-shared"}))
+		targets, err = remapDeliveryTargets(context.TODO(), ds, &model.Project{Name: "remap-a"}, []string{"remap-source"})
+		Expect(err).Should(BeNil())
+		Expect(targets).Should(Equal([]string{"remap-source"}))
+		_, err = remapDeliveryTargets(context.TODO(), ds, &model.Project{Name: "remap-b"}, []string{"remap-other"})
+		Expect(cmp.Equal(err, bcode.ErrProjectDeliveryTargetNotAllowed, cmpopts.EquateErrors())).Should(BeTrue())
+		_, err = remapDeliveryTargets(context.TODO(), ds, &model.Project{Name: "remap-b"}, []string{"remap-unknown"})
+		Expect(cmp.Equal(err, bcode.ErrEnvbindingDeliveryTargetNotAllExist, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+})
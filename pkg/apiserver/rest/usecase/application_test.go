@@ -39,6 +39,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	v1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	"github.com/oam-dev/kubevela/pkg/apprevision"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/utils/apply"
 )
@@ -466,6 +467,33 @@ var _ = Describe("Test application usecase function", func() {
 		Expect(revision.DeployUser).Should(Equal("test-user"))
 	})
 
+	It("Test DiffRevisions function", func() {
+		for i, image := range []string{"nginx:1.20", "nginx:1.21"} {
+			err := workflowUsecase.createTestApplicationRevision(context.TODO(), &model.ApplicationRevision{
+				AppPrimaryKey: "test-diff-app",
+				Version:       fmt.Sprintf("diff-%d", i),
+				EnvName:       "dev",
+				ApplyAppConfig: fmt.Sprintf(`spec:
+  components:
+  - name: web
+    type: webservice
+    properties:
+      image: %s
+`, image),
+			})
+			Expect(err).Should(BeNil())
+		}
+		diff, err := appUsecase.DiffRevisions(context.TODO(), "test-diff-app", "diff-0", "diff-1")
+		Expect(err).Should(BeNil())
+		Expect(diff.TargetVersion).Should(Equal("diff-1"))
+		Expect(len(diff.Diffs)).Should(Equal(1))
+		Expect(diff.Diffs[0].DiffType).Should(Equal(apprevision.ModifyDiff))
+		Expect(diff.Diffs[0].Target).Should(ContainSubstring("nginx:1.21"))
+
+		_, err = appUsecase.DiffRevisions(context.TODO(), "test-diff-app", "not-exist", "diff-1")
+		Expect(err).Should(Equal(bcode.ErrApplicationRevisionNotExist))
+	})
+
 	It("Test ApplicationEnvRecycle function", func() {
 		req := v1.CreateApplicationRequest{
 			Name:        "app-env-recycle" + "-dev",
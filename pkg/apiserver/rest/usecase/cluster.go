@@ -18,6 +18,7 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -31,6 +32,7 @@ import (
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/pkg/apiserver/clients"
@@ -48,7 +50,7 @@ import (
 
 // ClusterUsecase cluster manage
 type ClusterUsecase interface {
-	ListKubeClusters(context.Context, string, int, int) (*apis.ListClusterResponse, error)
+	ListKubeClusters(ctx context.Context, query string, selector string, page int, pageSize int) (*apis.ListClusterResponse, error)
 	CreateKubeCluster(context.Context, apis.CreateClusterRequest) (*apis.ClusterBase, error)
 	GetKubeCluster(context.Context, string) (*apis.DetailClusterResponse, error)
 	ModifyKubeCluster(context.Context, apis.CreateClusterRequest, string) (*apis.ClusterBase, error)
@@ -56,6 +58,7 @@ type ClusterUsecase interface {
 
 	CreateClusterNamespace(context.Context, string, apis.CreateClusterNamespaceRequest) (*apis.CreateClusterNamespaceResponse, error)
 
+	ListCloudClusterProviders(context.Context) (*apis.ListCloudClusterProviderResponse, error)
 	ListCloudClusters(context.Context, string, apis.AccessKeyRequest, int, int) (*apis.ListCloudClusterResponse, error)
 	ConnectCloudCluster(context.Context, string, apis.ConnectCloudClusterRequest) (*apis.ClusterBase, error)
 	CreateCloudCluster(context.Context, string, apis.CreateCloudClusterRequest) (*apis.CreateCloudClusterResponse, error)
@@ -153,12 +156,17 @@ func (c *clusterUsecaseImpl) preAddLocalCluster(ctx context.Context) error {
 	return nil
 }
 
-func (c *clusterUsecaseImpl) ListKubeClusters(ctx context.Context, query string, page int, pageSize int) (*apis.ListClusterResponse, error) {
+// ListKubeClusters list the clusters in the data store, the clusters can be filtered by the label selector
+// which matches the labels in the data store and the labels on the cluster secret
+func (c *clusterUsecaseImpl) ListKubeClusters(ctx context.Context, query string, selector string, page int, pageSize int) (*apis.ListClusterResponse, error) {
 	var queries []datastore.FuzzyQueryOption
 	if query != "" {
 		queries = append(queries, datastore.FuzzyQueryOption{Key: "name", Query: query})
 	}
 	fo := datastore.FilterOptions{Queries: queries}
+	if selector != "" {
+		return c.listKubeClustersBySelector(ctx, fo, selector, page, pageSize)
+	}
 	clusters, err := c.ds.List(ctx, &model.Cluster{}, &datastore.ListOptions{
 		Page:          page,
 		PageSize:      pageSize,
@@ -185,6 +193,63 @@ func (c *clusterUsecaseImpl) ListKubeClusters(ctx context.Context, query string,
 	return resp, nil
 }
 
+func (c *clusterUsecaseImpl) listKubeClustersBySelector(ctx context.Context, fo datastore.FilterOptions, selector string, page int, pageSize int) (*apis.ListClusterResponse, error) {
+	labelSelector, err := labels.Parse(selector)
+	if err != nil {
+		return nil, bcode.ErrInvalidClusterSelector
+	}
+	clusters, err := c.ds.List(ctx, &model.Cluster{}, &datastore.ListOptions{
+		SortBy:        []datastore.SortOption{{Key: "model.createTime", Order: datastore.SortOrderDescending}},
+		FilterOptions: fo,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list cluster in data store")
+	}
+	secrets, err := multicluster.ListExistingClusterSecrets(ctx, c.k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	secretLabels := map[string]map[string]string{}
+	for i := range secrets {
+		secretLabels[secrets[i].Name] = multicluster.GetClusterLabels(&secrets[i])
+	}
+	resp := &apis.ListClusterResponse{
+		Clusters: []apis.ClusterBase{},
+	}
+	var matched []*model.Cluster
+	for _, raw := range clusters {
+		cluster, ok := raw.(*model.Cluster)
+		if !ok {
+			continue
+		}
+		clusterLabels := labels.Set{}
+		for k, v := range cluster.Labels {
+			clusterLabels[k] = v
+		}
+		for k, v := range secretLabels[cluster.Name] {
+			clusterLabels[k] = v
+		}
+		if labelSelector.Matches(clusterLabels) {
+			matched = append(matched, cluster)
+		}
+	}
+	resp.Total = int64(len(matched))
+	start, end := 0, len(matched)
+	if page > 0 && pageSize > 0 {
+		start, end = (page-1)*pageSize, page*pageSize
+		if start > len(matched) {
+			start = len(matched)
+		}
+		if end > len(matched) {
+			end = len(matched)
+		}
+	}
+	for _, cluster := range matched[start:end] {
+		resp.Clusters = append(resp.Clusters, *newClusterBaseFromCluster(cluster))
+	}
+	return resp, nil
+}
+
 func joinClusterByKubeConfigString(ctx context.Context, k8sClient client.Client, clusterName string, kubeConfig string) (string, error) {
 	tmpFileName := fmt.Sprintf("/tmp/cluster-secret-%s-%d.kubeconfig", utils.RandomString(8), time.Now().UnixNano())
 	if err := ioutil.WriteFile(tmpFileName, []byte(kubeConfig), 0600); err != nil {
@@ -203,6 +268,23 @@ func joinClusterByKubeConfigString(ctx context.Context, k8sClient client.Client,
 	return cluster.Server, nil
 }
 
+// resolveClusterKubeConfig converts the token credential of the request to kubeconfig, so that the cluster is joined and stored in the same way
+func resolveClusterKubeConfig(req *apis.CreateClusterRequest) error {
+	if req.KubeConfig != "" || req.Token == nil {
+		return nil
+	}
+	caData, err := base64.StdEncoding.DecodeString(req.Token.CAData)
+	if err != nil {
+		return bcode.ErrInvalidClusterToken
+	}
+	kubeConfig, err := multicluster.NewTokenKubeConfig(req.Name, req.Token.Endpoint, caData, req.Token.Token, req.Token.Insecure)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build kubeconfig from the token of cluster %s", req.Name)
+	}
+	req.KubeConfig = string(kubeConfig)
+	return nil
+}
+
 func createClusterModelFromRequest(req apis.CreateClusterRequest, oldCluster *model.Cluster) (newCluster *model.Cluster) {
 	if oldCluster != nil {
 		newCluster = oldCluster.DeepCopy()
@@ -221,6 +303,9 @@ func createClusterModelFromRequest(req apis.CreateClusterRequest, oldCluster *mo
 }
 
 func (c *clusterUsecaseImpl) createKubeCluster(ctx context.Context, req apis.CreateClusterRequest, providerCluster *cloudprovider.CloudCluster) (*apis.ClusterBase, error) {
+	if err := resolveClusterKubeConfig(&req); err != nil {
+		return nil, err
+	}
 	var err error
 	cluster := createClusterModelFromRequest(req, nil)
 	if cluster.Name == multicluster.ClusterLocalName {
@@ -285,11 +370,30 @@ func (c *clusterUsecaseImpl) GetKubeCluster(ctx context.Context, clusterName str
 		return nil, errors.Wrapf(err, "failed to update cluster %s status info", clusterName)
 	}
 	return &apis.DetailClusterResponse{
-		Cluster:      *cluster,
-		ResourceInfo: resourceInfo,
+		Cluster:              *cluster,
+		ResourceInfo:         resourceInfo,
+		CredentialExpiration: c.getClusterCredentialExpiration(ctx, clusterName),
 	}, nil
 }
 
+// getClusterCredentialExpiration returns the expiration time of the cluster credential, nil if never expires or unknown
+func (c *clusterUsecaseImpl) getClusterCredentialExpiration(ctx context.Context, clusterName string) *time.Time {
+	if clusterName == multicluster.ClusterLocalName {
+		return nil
+	}
+	secret := &v12.Secret{}
+	if err := c.k8sClient.Get(ctx, client.ObjectKey{Namespace: multicluster.ClusterGatewaySecretNamespace, Name: clusterName}, secret); err != nil {
+		log.Logger.Warnf("failed to get the secret of cluster %s: %s", clusterName, err.Error())
+		return nil
+	}
+	exp, err := multicluster.GetClusterCredentialExpiration(secret)
+	if err != nil {
+		log.Logger.Warnf("failed to get the credential expiration of cluster %s: %s", clusterName, err.Error())
+		return nil
+	}
+	return exp
+}
+
 func (c *clusterUsecaseImpl) ModifyKubeCluster(ctx context.Context, req apis.CreateClusterRequest, clusterName string) (*apis.ClusterBase, error) {
 	oldCluster, err := c.getClusterFromDataStore(ctx, clusterName)
 	if err != nil {
@@ -299,6 +403,9 @@ func (c *clusterUsecaseImpl) ModifyKubeCluster(ctx context.Context, req apis.Cre
 		return nil, errors.Wrapf(err, "failed to found cluster %s in data store", clusterName)
 	}
 
+	if err := resolveClusterKubeConfig(&req); err != nil {
+		return nil, err
+	}
 	newCluster := createClusterModelFromRequest(req, oldCluster)
 	newCluster.SetUpdateTime(time.Now())
 	if oldCluster.Name != newCluster.Name || oldCluster.KubeConfig != newCluster.KubeConfig || oldCluster.KubeConfigSecret != newCluster.KubeConfigSecret {
@@ -455,12 +562,27 @@ func (c *clusterUsecaseImpl) getClusterResourceInfoFromK8s(ctx context.Context,
 	return clusterResourceInfo, nil
 }
 
-func (c *clusterUsecaseImpl) ListCloudClusters(ctx context.Context, provider string, req apis.AccessKeyRequest, pageNumber int, pageSize int) (*apis.ListCloudClusterResponse, error) {
-	p, err := cloudprovider.GetClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret, c.k8sClient)
+func (c *clusterUsecaseImpl) ListCloudClusterProviders(ctx context.Context) (*apis.ListCloudClusterProviderResponse, error) {
+	return &apis.ListCloudClusterProviderResponse{Providers: cloudprovider.ListClusterProviders()}, nil
+}
+
+func (c *clusterUsecaseImpl) getClusterProvider(provider string, accessKeyID string, accessKeySecret string) (cloudprovider.CloudClusterProvider, error) {
+	p, err := cloudprovider.GetClusterProvider(provider, accessKeyID, accessKeySecret, c.k8sClient)
 	if err != nil {
+		if errors.Is(err, cloudprovider.ErrInvalidAccessKey) {
+			return nil, bcode.ErrInvalidAccessKeyOrSecretKey
+		}
 		log.Logger.Errorf("failed to get cluster provider: %s", err.Error())
 		return nil, bcode.ErrInvalidCloudClusterProvider
 	}
+	return p, nil
+}
+
+func (c *clusterUsecaseImpl) ListCloudClusters(ctx context.Context, provider string, req apis.AccessKeyRequest, pageNumber int, pageSize int) (*apis.ListCloudClusterResponse, error) {
+	p, err := c.getClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret)
+	if err != nil {
+		return nil, err
+	}
 	clusters, total, err := p.ListCloudClusters(pageNumber, pageSize)
 	if err != nil {
 		if p.IsInvalidKey(err) {
@@ -480,10 +602,9 @@ func (c *clusterUsecaseImpl) ListCloudClusters(ctx context.Context, provider str
 }
 
 func (c *clusterUsecaseImpl) ConnectCloudCluster(ctx context.Context, provider string, req apis.ConnectCloudClusterRequest) (*apis.ClusterBase, error) {
-	p, err := cloudprovider.GetClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret, c.k8sClient)
+	p, err := c.getClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret)
 	if err != nil {
-		log.Logger.Errorf("failed to get cluster provider: %s", err.Error())
-		return nil, bcode.ErrInvalidCloudClusterProvider
+		return nil, err
 	}
 	kubeConfig, err := p.GetClusterKubeConfig(req.ClusterID)
 	if err != nil {
@@ -510,10 +631,9 @@ func (c *clusterUsecaseImpl) ConnectCloudCluster(ctx context.Context, provider s
 }
 
 func (c *clusterUsecaseImpl) CreateCloudCluster(ctx context.Context, provider string, req apis.CreateCloudClusterRequest) (*apis.CreateCloudClusterResponse, error) {
-	p, err := cloudprovider.GetClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret, c.k8sClient)
+	p, err := c.getClusterProvider(provider, req.AccessKeyID, req.AccessKeySecret)
 	if err != nil {
-		log.Logger.Errorf("failed to get cluster provider: %s", err.Error())
-		return nil, bcode.ErrInvalidCloudClusterProvider
+		return nil, err
 	}
 	_, err = p.CreateCloudCluster(ctx, req.Name, req.Zone, req.WorkerNumber, req.CPUCoresPerWorker, req.MemoryPerWorker)
 	if err != nil {
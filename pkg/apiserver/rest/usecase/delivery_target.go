@@ -111,6 +111,9 @@ func (dt *deliveryTargetUsecaseImpl) CreateDeliveryTarget(ctx context.Context, r
 	}
 	deliveryTarget.Namespace = project.Namespace
 	deliveryTarget.Project = project.Name
+	if err := checkTargetClusterQuota(project, deliveryTarget.Cluster); err != nil {
+		return nil, err
+	}
 
 	if err := dt.ds.Add(ctx, &deliveryTarget); err != nil {
 		return nil, err
@@ -119,6 +122,13 @@ func (dt *deliveryTargetUsecaseImpl) CreateDeliveryTarget(ctx context.Context, r
 }
 
 func (dt *deliveryTargetUsecaseImpl) UpdateDeliveryTarget(ctx context.Context, deliveryTarget *model.DeliveryTarget, req apisv1.UpdateDeliveryTargetRequest) (*apisv1.DetailDeliveryTargetResponse, error) {
+	project, err := dt.projectUsecase.GetProject(ctx, deliveryTarget.Project)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTargetClusterQuota(project, (*model.ClusterTarget)(req.Cluster)); err != nil {
+		return nil, err
+	}
 	deliveryTargetModel := convertUpdateReqToDeliveryTargetModel(deliveryTarget, req)
 	if err := dt.ds.Put(ctx, deliveryTargetModel); err != nil {
 		return nil, err
@@ -144,6 +154,13 @@ func (dt *deliveryTargetUsecaseImpl) GetDeliveryTarget(ctx context.Context, deli
 	return deliveryTarget, nil
 }
 
+func checkTargetClusterQuota(project *model.Project, cluster *model.ClusterTarget) error {
+	if cluster == nil {
+		return nil
+	}
+	return checkClusterQuota(project, cluster.ClusterName)
+}
+
 func convertUpdateReqToDeliveryTargetModel(deliveryTarget *model.DeliveryTarget, req apisv1.UpdateDeliveryTargetRequest) *model.DeliveryTarget {
 	deliveryTarget.Alias = req.Alias
 	deliveryTarget.Description = req.Description
@@ -141,6 +141,9 @@ func (e *envBindingUsecaseImpl) CreateEnvBinding(ctx context.Context, app *model
 	if envBinding != nil {
 		return nil, bcode.ErrEnvBindingExist
 	}
+	if err := e.checkDeliveryTargetQuota(ctx, app, envReq.TargetNames); err != nil {
+		return nil, err
+	}
 	envBindingModel := convertCreateReqToEnvBindingModel(app, envReq)
 	if err := e.ds.Add(ctx, &envBindingModel); err != nil {
 		return nil, err
@@ -154,6 +157,9 @@ func (e *envBindingUsecaseImpl) CreateEnvBinding(ctx context.Context, app *model
 
 func (e *envBindingUsecaseImpl) BatchCreateEnvBinding(ctx context.Context, app *model.Application, envbindings apisv1.EnvBindingList) error {
 	for i := range envbindings {
+		if err := e.checkDeliveryTargetQuota(ctx, app, envbindings[i].TargetNames); err != nil {
+			return err
+		}
 		envBindingModel := convertToEnvBindingModel(app, *envbindings[i])
 		if err := e.ds.Add(ctx, envBindingModel); err != nil {
 			return err
@@ -166,6 +172,18 @@ func (e *envBindingUsecaseImpl) BatchCreateEnvBinding(ctx context.Context, app *
 	return nil
 }
 
+// checkDeliveryTargetQuota checks whether the delivery targets are allowed by the project of the application
+func (e *envBindingUsecaseImpl) checkDeliveryTargetQuota(ctx context.Context, app *model.Application, targetNames []string) error {
+	if app.Project == "" {
+		return nil
+	}
+	project, err := _getProjectFromDataStore(ctx, e.ds, app.Project)
+	if err != nil {
+		return err
+	}
+	return checkDeliveryTargetQuota(project, targetNames...)
+}
+
 func (e *envBindingUsecaseImpl) getBindingByEnv(ctx context.Context, app *model.Application, envName string) (*model.EnvBinding, error) {
 	var envBinding = model.EnvBinding{
 		AppPrimaryKey: app.PrimaryKey(),
@@ -186,6 +204,9 @@ func (e *envBindingUsecaseImpl) UpdateEnvBinding(ctx context.Context, app *model
 		}
 		return nil, err
 	}
+	if err := e.checkDeliveryTargetQuota(ctx, app, envUpdate.TargetNames); err != nil {
+		return nil, err
+	}
 	convertUpdateReqToEnvBindingModel(envBinding, envUpdate)
 	// update env
 	if err := e.ds.Put(ctx, envBinding); err != nil {
@@ -207,7 +228,7 @@ func (e *envBindingUsecaseImpl) DeleteEnvBinding(ctx context.Context, appModel *
 		return err
 	}
 	var app v1beta1.Application
-	err = e.kubeClient.Get(ctx, types.NamespacedName{Namespace: appModel.Namespace, Name: convertAppName(appModel.Name, envBinding.Name)}, &app)
+	err = e.kubeClient.Get(ctx, types.NamespacedName{Namespace: appModel.Namespace, Name: deployAppName(appModel, envBinding.Name)}, &app)
 	if err == nil || !apierrors.IsNotFound(err) {
 		return bcode.ErrApplicationEnvRefusedDelete
 	}
@@ -322,7 +343,7 @@ func (e *envBindingUsecaseImpl) DetailEnvBinding(ctx context.Context, app *model
 
 func (e *envBindingUsecaseImpl) ApplicationEnvRecycle(ctx context.Context, appModel *model.Application, envBinding *model.EnvBinding) error {
 	var app v1beta1.Application
-	err := e.kubeClient.Get(ctx, types.NamespacedName{Namespace: appModel.Namespace, Name: convertAppName(appModel.Name, envBinding.Name)}, &app)
+	err := e.kubeClient.Get(ctx, types.NamespacedName{Namespace: appModel.Namespace, Name: deployAppName(appModel, envBinding.Name)}, &app)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return nil
@@ -372,7 +393,7 @@ func convertEnvbindingModelToBase(app *model.Application, envBinding *model.EnvB
 		ComponentSelector: (*apisv1.ComponentSelector)(envBinding.ComponentSelector),
 		CreateTime:        envBinding.CreateTime,
 		UpdateTime:        envBinding.UpdateTime,
-		AppDeployName:     convertAppName(app.Name, envBinding.Name),
+		AppDeployName:     deployAppName(app, envBinding.Name),
 	}
 	return ebb
 }
@@ -32,6 +32,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/apiserver/log"
 	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
 	"github.com/oam-dev/kubevela/pkg/oam"
 )
@@ -44,6 +45,13 @@ type ProjectUsecase interface {
 	GetProject(ctx context.Context, projectName string) (*model.Project, error)
 	ListProjects(ctx context.Context) ([]*apisv1.ProjectBase, error)
 	CreateProject(ctx context.Context, req apisv1.CreateProjectRequest) (*apisv1.ProjectBase, error)
+	DetailProject(ctx context.Context, project *model.Project) (*apisv1.ProjectBase, error)
+	UpdateProject(ctx context.Context, project *model.Project, req apisv1.UpdateProjectRequest) (*apisv1.ProjectBase, error)
+	DeleteProject(ctx context.Context, project *model.Project) error
+	ListProjectUsers(ctx context.Context, project *model.Project) (*apisv1.ListProjectUsersResponse, error)
+	AddProjectUser(ctx context.Context, project *model.Project, req apisv1.AddProjectUserRequest) (*apisv1.ProjectUserBase, error)
+	UpdateProjectUser(ctx context.Context, project *model.Project, userName string, req apisv1.UpdateProjectUserRequest) (*apisv1.ProjectUserBase, error)
+	DeleteProjectUser(ctx context.Context, project *model.Project, userName string) error
 }
 
 type projectUsecaseImpl struct {
@@ -62,14 +70,7 @@ func NewProjectUsecase(ds datastore.DataStore) ProjectUsecase {
 
 // GetProject get project
 func (p *projectUsecaseImpl) GetProject(ctx context.Context, projectName string) (*model.Project, error) {
-	project := &model.Project{Name: projectName}
-	if err := p.ds.Get(ctx, project); err != nil {
-		if errors.Is(err, datastore.ErrRecordNotExist) {
-			return nil, bcode.ErrProjectIsNotExist
-		}
-		return nil, err
-	}
-	return project, nil
+	return _getProjectFromDataStore(ctx, p.ds, projectName)
 }
 
 // ListProjects list projects
@@ -104,6 +105,7 @@ func (p *projectUsecaseImpl) CreateProject(ctx context.Context, req apisv1.Creat
 		Description: req.Description,
 		Alias:       req.Alias,
 		Namespace:   fmt.Sprintf("project-%s", req.Name),
+		Quota:       (*model.ProjectQuota)(req.Quota),
 	}
 	if req.Namespace != "" {
 		new.Namespace = req.Namespace
@@ -152,18 +154,200 @@ func (p *projectUsecaseImpl) CreateProject(ctx context.Context, req apisv1.Creat
 		Alias:       new.Alias,
 		Namespace:   new.Namespace,
 		Description: new.Description,
+		Quota:       (*apisv1.ProjectQuota)(new.Quota),
 		CreateTime:  new.CreateTime,
 		UpdateTime:  new.UpdateTime,
 	}, nil
 }
 
+// DetailProject detail project
+func (p *projectUsecaseImpl) DetailProject(ctx context.Context, project *model.Project) (*apisv1.ProjectBase, error) {
+	return convertProjectModel2Base(project), nil
+}
+
+// UpdateProject update the alias, description and quota of the project
+func (p *projectUsecaseImpl) UpdateProject(ctx context.Context, project *model.Project, req apisv1.UpdateProjectRequest) (*apisv1.ProjectBase, error) {
+	project.Alias = req.Alias
+	project.Description = req.Description
+	project.Quota = (*model.ProjectQuota)(req.Quota)
+	if err := p.ds.Put(ctx, project); err != nil {
+		return nil, err
+	}
+	return convertProjectModel2Base(project), nil
+}
+
+// DeleteProject delete the project and its members, the project must not have any applications or delivery targets.
+// The namespace of the project is kept, because it may be shared with the other resources.
+func (p *projectUsecaseImpl) DeleteProject(ctx context.Context, project *model.Project) error {
+	appCount, err := p.ds.Count(ctx, &model.Application{Project: project.Name}, nil)
+	if err != nil {
+		return err
+	}
+	targetCount, err := p.ds.Count(ctx, &model.DeliveryTarget{Project: project.Name}, nil)
+	if err != nil {
+		return err
+	}
+	if appCount > 0 || targetCount > 0 {
+		return bcode.ErrProjectIsNotEmpty
+	}
+	users, err := p.ds.List(ctx, &model.ProjectUser{ProjectName: project.Name}, &datastore.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if err := p.ds.Delete(ctx, user); err != nil && !errors.Is(err, datastore.ErrRecordNotExist) {
+			return err
+		}
+	}
+	if err := p.ds.Delete(ctx, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrProjectIsNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// ListProjectUsers list the members of the project
+func (p *projectUsecaseImpl) ListProjectUsers(ctx context.Context, project *model.Project) (*apisv1.ListProjectUsersResponse, error) {
+	entitys, err := p.ds.List(ctx, &model.ProjectUser{ProjectName: project.Name}, &datastore.ListOptions{SortBy: []datastore.SortOption{{Key: "createTime", Order: datastore.SortOrderDescending}}})
+	if err != nil {
+		return nil, err
+	}
+	resp := &apisv1.ListProjectUsersResponse{Users: []*apisv1.ProjectUserBase{}}
+	for _, entity := range entitys {
+		resp.Users = append(resp.Users, convertProjectUserModel2Base(entity.(*model.ProjectUser)))
+	}
+	resp.Total = int64(len(resp.Users))
+	return resp, nil
+}
+
+// AddProjectUser add the user to the project with the role
+func (p *projectUsecaseImpl) AddProjectUser(ctx context.Context, project *model.Project, req apisv1.AddProjectUserRequest) (*apisv1.ProjectUserBase, error) {
+	user := &model.ProjectUser{
+		ProjectName: project.Name,
+		Username:    req.UserName,
+		Role:        req.Role,
+	}
+	if err := p.ds.Add(ctx, user); err != nil {
+		if errors.Is(err, datastore.ErrRecordExist) {
+			return nil, bcode.ErrProjectUserExist
+		}
+		return nil, err
+	}
+	return convertProjectUserModel2Base(user), nil
+}
+
+// UpdateProjectUser update the role of the project member
+func (p *projectUsecaseImpl) UpdateProjectUser(ctx context.Context, project *model.Project, userName string, req apisv1.UpdateProjectUserRequest) (*apisv1.ProjectUserBase, error) {
+	user := &model.ProjectUser{
+		ProjectName: project.Name,
+		Username:    userName,
+	}
+	if err := p.ds.Get(ctx, user); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrProjectUserNotExist
+		}
+		return nil, err
+	}
+	user.Role = req.Role
+	if err := p.ds.Put(ctx, user); err != nil {
+		return nil, err
+	}
+	return convertProjectUserModel2Base(user), nil
+}
+
+// DeleteProjectUser remove the user from the project
+func (p *projectUsecaseImpl) DeleteProjectUser(ctx context.Context, project *model.Project, userName string) error {
+	if err := p.ds.Delete(ctx, &model.ProjectUser{ProjectName: project.Name, Username: userName}); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return bcode.ErrProjectUserNotExist
+		}
+		return err
+	}
+	return nil
+}
+
 func convertProjectModel2Base(project *model.Project) *apisv1.ProjectBase {
 	return &apisv1.ProjectBase{
 		Name:        project.Name,
 		Namespace:   project.Namespace,
 		Description: project.Description,
 		Alias:       project.Alias,
+		Quota:       (*apisv1.ProjectQuota)(project.Quota),
 		CreateTime:  project.CreateTime,
 		UpdateTime:  project.UpdateTime,
 	}
 }
+
+func convertProjectUserModel2Base(user *model.ProjectUser) *apisv1.ProjectUserBase {
+	return &apisv1.ProjectUserBase{
+		UserName:   user.Username,
+		Role:       user.Role,
+		CreateTime: user.CreateTime,
+		UpdateTime: user.UpdateTime,
+	}
+}
+
+// checkApplicationQuota checks whether one more application could be created in the project
+func checkApplicationQuota(ctx context.Context, ds datastore.DataStore, project *model.Project) error {
+	if project.Quota == nil || project.Quota.MaxApplications == 0 {
+		return nil
+	}
+	count, err := ds.Count(ctx, &model.Application{Project: project.Name}, nil)
+	if err != nil {
+		return err
+	}
+	if count >= project.Quota.MaxApplications {
+		return bcode.ErrProjectApplicationQuotaExceeded
+	}
+	return nil
+}
+
+// checkDefinitionQuota checks whether the component or trait types are allowed in the project
+func checkDefinitionQuota(project *model.Project, definitionTypes ...string) error {
+	if project.Quota == nil || len(project.Quota.DefinitionTypes) == 0 {
+		return nil
+	}
+	for _, definitionType := range definitionTypes {
+		if !utils.StringsContain(project.Quota.DefinitionTypes, definitionType) {
+			return bcode.ErrProjectDefinitionNotAllowed
+		}
+	}
+	return nil
+}
+
+// checkDeliveryTargetQuota checks whether the delivery targets are allowed in the project
+func checkDeliveryTargetQuota(project *model.Project, targetNames ...string) error {
+	if project.Quota == nil || len(project.Quota.DeliveryTargets) == 0 {
+		return nil
+	}
+	for _, targetName := range targetNames {
+		if !utils.StringsContain(project.Quota.DeliveryTargets, targetName) {
+			return bcode.ErrProjectDeliveryTargetNotAllowed
+		}
+	}
+	return nil
+}
+
+// checkClusterQuota checks whether the cluster is allowed in the project
+func checkClusterQuota(project *model.Project, clusterName string) error {
+	if project.Quota == nil || len(project.Quota.Clusters) == 0 {
+		return nil
+	}
+	if !utils.StringsContain(project.Quota.Clusters, clusterName) {
+		return bcode.ErrProjectClusterNotAllowed
+	}
+	return nil
+}
+
+func _getProjectFromDataStore(ctx context.Context, ds datastore.DataStore, projectName string) (*model.Project, error) {
+	project := &model.Project{Name: projectName}
+	if err := ds.Get(ctx, project); err != nil {
+		if errors.Is(err, datastore.ErrRecordNotExist) {
+			return nil, bcode.ErrProjectIsNotExist
+		}
+		return nil, err
+	}
+	return project, nil
+}
@@ -27,6 +27,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
 	"github.com/oam-dev/kubevela/pkg/oam"
@@ -77,4 +78,69 @@ var _ = Describe("Test project usecase functions", func() {
 		_, err := projectUsecase.ListProjects(context.TODO())
 		Expect(err).Should(BeNil())
 	})
+
+	It("Test UpdateProject and DeleteProject function", func() {
+		_, err := projectUsecase.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: "test-update-project"})
+		Expect(err).Should(BeNil())
+		project, err := projectUsecase.GetProject(context.TODO(), "test-update-project")
+		Expect(err).Should(BeNil())
+		base, err := projectUsecase.UpdateProject(context.TODO(), project, apisv1.UpdateProjectRequest{
+			Alias:       "Update",
+			Description: "updated description",
+			Quota:       &apisv1.ProjectQuota{MaxApplications: 1},
+		})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(base.Alias, "Update")).Should(BeEmpty())
+		Expect(base.Quota.MaxApplications).Should(Equal(int64(1)))
+
+		By("test the project with applications can not be deleted")
+		Expect(ds.Add(context.TODO(), &model.Application{Name: "test-project-app", Project: project.Name, Namespace: project.Namespace})).Should(BeNil())
+		Expect(checkApplicationQuota(context.TODO(), ds, project)).Should(Equal(bcode.ErrProjectApplicationQuotaExceeded))
+		err = projectUsecase.DeleteProject(context.TODO(), project)
+		Expect(cmp.Equal(err, bcode.ErrProjectIsNotEmpty, cmpopts.EquateErrors())).Should(BeTrue())
+		Expect(ds.Delete(context.TODO(), &model.Application{Name: "test-project-app"})).Should(BeNil())
+
+		Expect(projectUsecase.DeleteProject(context.TODO(), project)).Should(BeNil())
+		_, err = projectUsecase.GetProject(context.TODO(), "test-update-project")
+		Expect(cmp.Equal(err, bcode.ErrProjectIsNotExist, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+
+	It("Test project users functions", func() {
+		_, err := projectUsecase.CreateProject(context.TODO(), apisv1.CreateProjectRequest{Name: "test-user-project"})
+		Expect(err).Should(BeNil())
+		project, err := projectUsecase.GetProject(context.TODO(), "test-user-project")
+		Expect(err).Should(BeNil())
+
+		user, err := projectUsecase.AddProjectUser(context.TODO(), project, apisv1.AddProjectUserRequest{UserName: "alice", Role: model.ProjectRoleMember})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(user.Role, model.ProjectRoleMember)).Should(BeEmpty())
+		_, err = projectUsecase.AddProjectUser(context.TODO(), project, apisv1.AddProjectUserRequest{UserName: "alice", Role: model.ProjectRoleAdmin})
+		Expect(cmp.Equal(err, bcode.ErrProjectUserExist, cmpopts.EquateErrors())).Should(BeTrue())
+
+		user, err = projectUsecase.UpdateProjectUser(context.TODO(), project, "alice", apisv1.UpdateProjectUserRequest{Role: model.ProjectRoleAdmin})
+		Expect(err).Should(BeNil())
+		Expect(cmp.Diff(user.Role, model.ProjectRoleAdmin)).Should(BeEmpty())
+		users, err := projectUsecase.ListProjectUsers(context.TODO(), project)
+		Expect(err).Should(BeNil())
+		Expect(users.Total).Should(Equal(int64(1)))
+
+		Expect(projectUsecase.DeleteProjectUser(context.TODO(), project, "alice")).Should(BeNil())
+		err = projectUsecase.DeleteProjectUser(context.TODO(), project, "alice")
+		Expect(cmp.Equal(err, bcode.ErrProjectUserNotExist, cmpopts.EquateErrors())).Should(BeTrue())
+	})
+
+	It("Test project quota functions", func() {
+		project := &model.Project{Name: "quota", Quota: &model.ProjectQuota{
+			DeliveryTargets: []string{"dev"},
+			Clusters:        []string{"local"},
+			DefinitionTypes: []string{"webservice", "scaler"},
+		}}
+		Expect(checkDefinitionQuota(project, "webservice", "scaler")).Should(BeNil())
+		Expect(checkDefinitionQuota(project, "worker")).Should(Equal(bcode.ErrProjectDefinitionNotAllowed))
+		Expect(checkDeliveryTargetQuota(project, "dev")).Should(BeNil())
+		Expect(checkDeliveryTargetQuota(project, "dev", "prod")).Should(Equal(bcode.ErrProjectDeliveryTargetNotAllowed))
+		Expect(checkClusterQuota(project, "local")).Should(BeNil())
+		Expect(checkClusterQuota(project, "remote")).Should(Equal(bcode.ErrProjectClusterNotAllowed))
+		Expect(checkClusterQuota(&model.Project{Name: "no-quota"}, "remote")).Should(BeNil())
+	})
 })
@@ -386,7 +386,12 @@ func (w *workflowUsecaseImpl) SyncWorkflowRecord(ctx context.Context) error {
 			klog.ErrorS(err, "failed to get workflow", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
 			continue
 		}
-		appName := convertAppName(record.AppPrimaryKey, workflow.EnvName)
+		appModel := &model.Application{Name: record.AppPrimaryKey}
+		if err := w.ds.Get(ctx, appModel); err != nil {
+			klog.ErrorS(err, "failed to get application", "app name", record.AppPrimaryKey, "workflow name", record.WorkflowName, "record name", record.Name)
+			continue
+		}
+		appName := deployAppName(appModel, workflow.EnvName)
 		if err := w.kubeClient.Get(ctx, types.NamespacedName{
 			Name:      appName,
 			Namespace: record.Namespace,
@@ -718,7 +723,7 @@ func (w *workflowUsecaseImpl) RollbackRecord(ctx context.Context, appModel *mode
 
 func (w *workflowUsecaseImpl) checkRecordRunning(ctx context.Context, appModel *model.Application, envName string) (*v1beta1.Application, error) {
 	oamApp := &v1beta1.Application{}
-	if err := w.kubeClient.Get(ctx, types.NamespacedName{Name: convertAppName(appModel.Name, envName), Namespace: appModel.Namespace}, oamApp); err != nil {
+	if err := w.kubeClient.Get(ctx, types.NamespacedName{Name: deployAppName(appModel, envName), Namespace: appModel.Namespace}, oamApp); err != nil {
 		return nil, err
 	}
 	if oamApp.Status.Workflow != nil && !oamApp.Status.Workflow.Suspend && !oamApp.Status.Workflow.Terminated && !oamApp.Status.Workflow.Finished {
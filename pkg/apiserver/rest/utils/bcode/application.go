@@ -78,3 +78,6 @@ var ErrApplicationRefusedDelete = NewBcode(400, 10019, "The application cannot b
 
 // ErrApplicationEnvRefusedDelete The application env cannot be deleted because it has been deployed
 var ErrApplicationEnvRefusedDelete = NewBcode(400, 10020, "The application envbinding cannot be deleted because it has been deployed")
+
+// ErrApplicationManifestInvalid the imported application files are invalid
+var ErrApplicationManifestInvalid = NewBcode(400, 10021, "the application manifest files are invalid")
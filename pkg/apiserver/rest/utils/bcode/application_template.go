@@ -0,0 +1,32 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bcode
+
+// ErrApplicationTemplateNotExist application template is not exist
+var ErrApplicationTemplateNotExist = NewBcode(404, 11001, "application template is not exist")
+
+// ErrApplicationTemplateVersionExist application template version is exist
+var ErrApplicationTemplateVersionExist = NewBcode(400, 11002, "application template version is exist")
+
+// ErrApplicationTemplateVersionNotExist application template version is not exist
+var ErrApplicationTemplateVersionNotExist = NewBcode(404, 11003, "application template version is not exist")
+
+// ErrApplicationTemplateParameterInvalid the parameter refers to the component or trait not in the application
+var ErrApplicationTemplateParameterInvalid = NewBcode(400, 11004, "application template parameter is invalid")
+
+// ErrApplicationTemplateParameterRequired the required parameter is not specified
+var ErrApplicationTemplateParameterRequired = NewBcode(400, 11005, "application template parameter is required")
@@ -60,3 +60,9 @@ var ErrInvalidAccessKeyOrSecretKey = NewBcode(400, 40013, "access key or secret
 
 // ErrClusterCreateNamespaceNoPermission cluster create namespace is forbidden
 var ErrClusterCreateNamespaceNoPermission = NewBcode(401, 40014, "no permission to create namespace in cluster")
+
+// ErrInvalidClusterSelector the label selector of clusters is invalid
+var ErrInvalidClusterSelector = NewBcode(400, 40015, "the label selector of clusters is invalid")
+
+// ErrInvalidClusterToken the token credential of the cluster is invalid
+var ErrInvalidClusterToken = NewBcode(400, 40016, "the token credential of the cluster is invalid")
@@ -15,3 +15,24 @@ limitations under the License.
 */
 
 package bcode
+
+// ErrProjectIsNotEmpty the project still has applications or delivery targets
+var ErrProjectIsNotEmpty = NewBcode(400, 30005, "the project is not empty, please delete the applications and delivery targets at first")
+
+// ErrProjectUserExist the user is already the member of the project
+var ErrProjectUserExist = NewBcode(400, 30006, "the user is already the member of the project")
+
+// ErrProjectUserNotExist the user is not the member of the project
+var ErrProjectUserNotExist = NewBcode(404, 30007, "the user is not the member of the project")
+
+// ErrProjectApplicationQuotaExceeded the number of the applications exceeds the project quota
+var ErrProjectApplicationQuotaExceeded = NewBcode(400, 30008, "the number of the applications exceeds the project quota")
+
+// ErrProjectDefinitionNotAllowed the component or trait type is not allowed by the project quota
+var ErrProjectDefinitionNotAllowed = NewBcode(400, 30009, "the component or trait type is not allowed in the project")
+
+// ErrProjectDeliveryTargetNotAllowed the delivery target is not allowed by the project quota
+var ErrProjectDeliveryTargetNotAllowed = NewBcode(400, 30010, "the delivery target is not allowed in the project")
+
+// ErrProjectClusterNotAllowed the cluster is not allowed by the project quota
+var ErrProjectClusterNotAllowed = NewBcode(400, 30011, "the cluster is not allowed in the project")
@@ -76,6 +76,14 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.ApplicationBase{}))
 
+	ws.Route(ws.POST("/import").To(c.importApplication).
+		Doc("create or update the application from the declarative files").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.ImportApplicationRequest{}).
+		Returns(200, "", apis.ApplicationBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationBase{}))
+
 	ws.Route(ws.DELETE("/{name}").To(c.deleteApplication).
 		Doc("delete one application").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -132,6 +140,25 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.ApplicationTemplateBase{}))
 
+	ws.Route(ws.GET("/{name}/export").To(c.exportApplication).
+		Doc("export the application as the declarative files").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("name", "identifier of the application ").DataType("string")).
+		Returns(200, "", apis.ExportApplicationResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ExportApplicationResponse{}))
+
+	ws.Route(ws.POST("/{name}/clone").To(c.cloneApplication).
+		Doc("clone the application with its components, policies, env bindings and workflows").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("name", "identifier of the application ").DataType("string")).
+		Reads(apis.CloneApplicationRequest{}).
+		Returns(200, "", apis.ApplicationBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationBase{}))
+
 	ws.Route(ws.POST("/{name}/deploy").To(c.deployApplication).
 		Doc("deploy or upgrade the application").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -292,6 +319,28 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.DetailRevisionResponse{}))
 
+	ws.Route(ws.GET("/{name}/revisions/{revision}/diff").To(c.diffApplicationRevisions).
+		Doc("compare the revision with another revision of the application").
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.PathParameter("revision", "identifier of the base application revision").DataType("string")).
+		Param(ws.QueryParameter("target", "identifier of the target application revision, by default compare with the latest revision of the env").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.ApplicationRevisionDiffResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationRevisionDiffResponse{}))
+
+	ws.Route(ws.POST("/{name}/revisions/{revision}/rollback").To(c.rollbackApplicationRevision).
+		Doc("rollback the application to the revision").
+		Filter(c.appCheckFilter).
+		Param(ws.PathParameter("name", "identifier of the application").DataType("string")).
+		Param(ws.PathParameter("revision", "identifier of the application revision").DataType("string")).
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Reads(apis.ApplicationRollbackRequest{}).
+		Returns(200, "", apis.ApplicationDeployResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationDeployResponse{}))
+
 	ws.Route(ws.GET("/{name}/envs").To(c.listApplicationEnvs).
 		Doc("list policy for application").
 		Filter(c.appCheckFilter).
@@ -345,6 +394,17 @@ func (c *applicationWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.ApplicationStatusResponse{}))
 
+	ws.Route(ws.GET("/{name}/envs/{envName}/endpoints").To(c.listApplicationEndpoints).
+		Doc("list the endpoints exposed by the application in the env").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(c.appCheckFilter).
+		Filter(c.envCheckFilter).
+		Param(ws.PathParameter("name", "identifier of the application ").DataType("string")).
+		Param(ws.PathParameter("envName", "identifier of the application envbinding").DataType("string")).
+		Returns(200, "", apis.ListServiceEndpointsResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ListServiceEndpointsResponse{}))
+
 	ws.Route(ws.POST("/{name}/envs/{envName}/recycle").To(c.recycleApplicationEnv).
 		Doc("get application status").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
@@ -534,7 +594,17 @@ func (c *applicationWebService) detailApplication(req *restful.Request, res *res
 
 func (c *applicationWebService) publishApplicationTemplate(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
-	base, err := c.applicationUsecase.PublishApplicationTemplate(req.Request.Context(), app)
+	// Verify the validity of parameters
+	var createReq apis.CreateApplicationTemplateRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := c.applicationUsecase.PublishApplicationTemplate(req.Request.Context(), app, createReq)
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -546,6 +616,64 @@ func (c *applicationWebService) publishApplicationTemplate(req *restful.Request,
 }
 
 // deployApplication TODO: return event model
+func (c *applicationWebService) cloneApplication(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	// Verify the validity of parameters
+	var cloneReq apis.CloneApplicationRequest
+	if err := req.ReadEntity(&cloneReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&cloneReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := c.applicationUsecase.CloneApplication(req.Request.Context(), app, cloneReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) exportApplication(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	files, err := c.applicationUsecase.ExportApplication(req.Request.Context(), app)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(files); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) importApplication(req *restful.Request, res *restful.Response) {
+	// Verify the validity of parameters
+	var importReq apis.ImportApplicationRequest
+	if err := req.ReadEntity(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&importReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := c.applicationUsecase.ImportApplication(req.Request.Context(), importReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *applicationWebService) deployApplication(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	// Verify the validity of parameters
@@ -839,6 +967,19 @@ func (c *applicationWebService) getApplicationStatus(req *restful.Request, res *
 	}
 }
 
+func (c *applicationWebService) listApplicationEndpoints(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	endpoints, err := c.applicationUsecase.ListServiceEndpoints(req.Request.Context(), app, req.PathParameter("envName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(endpoints); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *applicationWebService) listApplicationRevisions(req *restful.Request, res *restful.Response) {
 	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
 	if err != nil {
@@ -869,6 +1010,41 @@ func (c *applicationWebService) detailApplicationRevision(req *restful.Request,
 	}
 }
 
+func (c *applicationWebService) diffApplicationRevisions(req *restful.Request, res *restful.Response) {
+	diff, err := c.applicationUsecase.DiffRevisions(req.Request.Context(), req.PathParameter("name"), req.PathParameter("revision"), req.QueryParameter("target"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(diff); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (c *applicationWebService) rollbackApplicationRevision(req *restful.Request, res *restful.Response) {
+	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
+	// Verify the validity of parameters
+	var rollbackReq apis.ApplicationRollbackRequest
+	if err := req.ReadEntity(&rollbackReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&rollbackReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	deployRes, err := c.applicationUsecase.RollbackRevision(req.Request.Context(), app, req.PathParameter("revision"), rollbackReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(deployRes); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *applicationWebService) updateApplicationEnv(req *restful.Request, res *restful.Response) {
 	app := req.Request.Context().Value(&apis.CtxKeyApplication).(*model.Application)
 	// Verify the validity of parameters
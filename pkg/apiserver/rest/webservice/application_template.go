@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webservice
+
+import (
+	"context"
+
+	restfulspec "github.com/emicklei/go-restful-openapi/v2"
+	restful "github.com/emicklei/go-restful/v3"
+
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
+	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+type applicationTemplateWebService struct {
+	applicationTemplateUsecase usecase.ApplicationTemplateUsecase
+	applicationUsecase         usecase.ApplicationUsecase
+}
+
+// NewApplicationTemplateWebService new application template webservice
+func NewApplicationTemplateWebService(applicationTemplateUsecase usecase.ApplicationTemplateUsecase, applicationUsecase usecase.ApplicationUsecase) WebService {
+	return &applicationTemplateWebService{
+		applicationTemplateUsecase: applicationTemplateUsecase,
+		applicationUsecase:         applicationUsecase,
+	}
+}
+
+func (t *applicationTemplateWebService) GetWebService() *restful.WebService {
+	ws := new(restful.WebService)
+	ws.Path(versionPrefix+"/templates").
+		Consumes(restful.MIME_XML, restful.MIME_JSON).
+		Produces(restful.MIME_JSON, restful.MIME_XML).
+		Doc("api for application template manage")
+
+	tags := []string{"application template"}
+
+	ws.Route(ws.GET("/").To(t.listApplicationTemplates).
+		Doc("list all application templates").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Param(ws.QueryParameter("project", "Name of the project the templates belong to").DataType("string")).
+		Returns(200, "", apis.ListApplicationTemplateResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ListApplicationTemplateResponse{}))
+
+	ws.Route(ws.GET("/{templateName}").To(t.detailApplicationTemplate).
+		Doc("detail an application template with all versions").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(t.templateCheckFilter).
+		Param(ws.PathParameter("templateName", "identifier of the application template").DataType("string")).
+		Returns(200, "", apis.ApplicationTemplateBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationTemplateBase{}))
+
+	ws.Route(ws.DELETE("/{templateName}").To(t.deleteApplicationTemplate).
+		Doc("delete an application template with all versions").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(t.templateCheckFilter).
+		Param(ws.PathParameter("templateName", "identifier of the application template").DataType("string")).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.POST("/{templateName}/instantiate").To(t.instantiateApplicationTemplate).
+		Doc("create an application from the application template").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(t.templateCheckFilter).
+		Param(ws.PathParameter("templateName", "identifier of the application template").DataType("string")).
+		Reads(apis.CreateApplicationFromTemplateRequest{}).
+		Returns(200, "", apis.ApplicationBase{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ApplicationBase{}))
+	return ws
+}
+
+func (t *applicationTemplateWebService) templateCheckFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	template, err := t.applicationTemplateUsecase.GetApplicationTemplate(req.Request.Context(), req.PathParameter("templateName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyApplicationTemplate, template))
+	chain.ProcessFilter(req, res)
+}
+
+func (t *applicationTemplateWebService) listApplicationTemplates(req *restful.Request, res *restful.Response) {
+	templates, err := t.applicationTemplateUsecase.ListApplicationTemplates(req.Request.Context(), req.QueryParameter("project"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.ListApplicationTemplateResponse{Templates: templates}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (t *applicationTemplateWebService) detailApplicationTemplate(req *restful.Request, res *restful.Response) {
+	template := req.Request.Context().Value(&apis.CtxKeyApplicationTemplate).(*model.ApplicationTemplate)
+	detail, err := t.applicationTemplateUsecase.DetailApplicationTemplate(req.Request.Context(), template)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(detail); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (t *applicationTemplateWebService) deleteApplicationTemplate(req *restful.Request, res *restful.Response) {
+	template := req.Request.Context().Value(&apis.CtxKeyApplicationTemplate).(*model.ApplicationTemplate)
+	if err := t.applicationTemplateUsecase.DeleteApplicationTemplate(req.Request.Context(), template); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (t *applicationTemplateWebService) instantiateApplicationTemplate(req *restful.Request, res *restful.Response) {
+	template := req.Request.Context().Value(&apis.CtxKeyApplicationTemplate).(*model.ApplicationTemplate)
+	// Verify the validity of parameters
+	var createReq apis.CreateApplicationFromTemplateRequest
+	if err := req.ReadEntity(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&createReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	base, err := t.applicationUsecase.CreateApplicationFromTemplate(req.Request.Context(), template, createReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(base); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -50,6 +50,7 @@ func (c *ClusterWebService) GetWebService() *restful.WebService {
 		Doc("list all clusters").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.QueryParameter("query", "Fuzzy search based on name or description").DataType("string")).
+		Param(ws.QueryParameter("selector", "Label selector to filter the clusters, e.g. region=east,env!=test").DataType("string")).
 		Param(ws.QueryParameter("page", "Page for paging").DataType("int").DefaultValue("0")).
 		Param(ws.QueryParameter("pageSize", "PageSize for paging").DataType("int").DefaultValue("20")).
 		Returns(200, "", apis.ListClusterResponse{}).
@@ -98,8 +99,15 @@ func (c *ClusterWebService) GetWebService() *restful.WebService {
 		Returns(400, "", bcode.Bcode{}).
 		Writes(apis.CreateClusterNamespaceResponse{}))
 
+	ws.Route(ws.GET("/cloud-clusters").To(c.listCloudClusterProviders).
+		Doc("list cloud cluster providers").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Returns(200, "", apis.ListCloudClusterProviderResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.ListCloudClusterProviderResponse{}))
+
 	ws.Route(ws.POST("/cloud-clusters/{provider}").To(c.listCloudClusters).
-		Doc("list cloud clusters").
+		Doc("list cloud clusters, the eks, aks and gke providers only list the clusters created by KubeVela").
 		Metadata(restfulspec.KeyOpenAPITags, tags).
 		Param(ws.PathParameter("provider", "identifier of the cloud provider").DataType("string")).
 		Param(ws.QueryParameter("page", "Page for paging").DataType("int").DefaultValue("0")).
@@ -165,7 +173,7 @@ func (c *ClusterWebService) listKubeClusters(req *restful.Request, res *restful.
 	}
 
 	// Call the usecase layer code
-	clusters, err := c.clusterUsecase.ListKubeClusters(req.Request.Context(), query, page, pageSize)
+	clusters, err := c.clusterUsecase.ListKubeClusters(req.Request.Context(), query, req.QueryParameter("selector"), page, pageSize)
 	if err != nil {
 		bcode.ReturnError(req, res, err)
 		return
@@ -292,6 +300,18 @@ func (c *ClusterWebService) createNamespace(req *restful.Request, res *restful.R
 	}
 }
 
+func (c *ClusterWebService) listCloudClusterProviders(req *restful.Request, res *restful.Response) {
+	resp, err := c.clusterUsecase.ListCloudClusterProviders(req.Request.Context())
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(resp); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
 func (c *ClusterWebService) listCloudClusters(req *restful.Request, res *restful.Response) {
 	provider := req.PathParameter("provider")
 	page, pageSize, err := utils.ExtractPagingParams(req, minPageSize, maxPageSize)
@@ -17,10 +17,13 @@ limitations under the License.
 package webservice
 
 import (
+	"context"
+
 	restfulspec "github.com/emicklei/go-restful-openapi/v2"
 	restful "github.com/emicklei/go-restful/v3"
 
 	"github.com/oam-dev/kubevela/pkg/apiserver/log"
+	"github.com/oam-dev/kubevela/pkg/apiserver/model"
 	apis "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/usecase"
 	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
@@ -56,9 +59,81 @@ func (n *projectWebService) GetWebService() *restful.WebService {
 		Reads(apis.CreateProjectRequest{}).
 		Returns(200, "", apis.ProjectBase{}).
 		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.GET("/{projectName}").To(n.detailProject).
+		Doc("detail a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Returns(200, "", apis.ProjectBase{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.PUT("/{projectName}").To(n.updateProject).
+		Doc("update a project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Reads(apis.UpdateProjectRequest{}).
+		Returns(200, "", apis.ProjectBase{}).
+		Writes(apis.ProjectBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}").To(n.deleteProject).
+		Doc("delete a project, the project must not have any applications or delivery targets").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Returns(200, "", apis.EmptyResponse{}).
+		Returns(400, "", bcode.Bcode{}).
+		Writes(apis.EmptyResponse{}))
+
+	ws.Route(ws.GET("/{projectName}/users").To(n.listProjectUsers).
+		Doc("list the members of the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Returns(200, "", apis.ListProjectUsersResponse{}).
+		Writes(apis.ListProjectUsersResponse{}))
+
+	ws.Route(ws.POST("/{projectName}/users").To(n.addProjectUser).
+		Doc("add a member to the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Reads(apis.AddProjectUserRequest{}).
+		Returns(200, "", apis.ProjectUserBase{}).
+		Writes(apis.ProjectUserBase{}))
+
+	ws.Route(ws.PUT("/{projectName}/users/{userName}").To(n.updateProjectUser).
+		Doc("update the role of the project member").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("userName", "identifier of the project member").DataType("string")).
+		Reads(apis.UpdateProjectUserRequest{}).
+		Returns(200, "", apis.ProjectUserBase{}).
+		Writes(apis.ProjectUserBase{}))
+
+	ws.Route(ws.DELETE("/{projectName}/users/{userName}").To(n.deleteProjectUser).
+		Doc("remove the member from the project").
+		Metadata(restfulspec.KeyOpenAPITags, tags).
+		Filter(n.projectCheckFilter).
+		Param(ws.PathParameter("projectName", "identifier of the project").DataType("string")).
+		Param(ws.PathParameter("userName", "identifier of the project member").DataType("string")).
+		Returns(200, "", apis.EmptyResponse{}).
+		Writes(apis.EmptyResponse{}))
 	return ws
 }
 
+func (n *projectWebService) projectCheckFilter(req *restful.Request, res *restful.Response, chain *restful.FilterChain) {
+	project, err := n.projectUsecase.GetProject(req.Request.Context(), req.PathParameter("projectName"))
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	req.Request = req.Request.WithContext(context.WithValue(req.Request.Context(), &apis.CtxKeyProject, project))
+	chain.ProcessFilter(req, res)
+}
+
 func (n *projectWebService) listprojects(req *restful.Request, res *restful.Response) {
 	projects, err := n.projectUsecase.ListProjects(req.Request.Context())
 	if err != nil {
@@ -96,3 +171,122 @@ func (n *projectWebService) createproject(req *restful.Request, res *restful.Res
 		return
 	}
 }
+
+func (n *projectWebService) detailProject(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	projectBase, err := n.projectUsecase.DetailProject(req.Request.Context(), project)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(projectBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) updateProject(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	// Verify the validity of parameters
+	var updateReq apis.UpdateProjectRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	projectBase, err := n.projectUsecase.UpdateProject(req.Request.Context(), project, updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(projectBase); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) deleteProject(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	if err := n.projectUsecase.DeleteProject(req.Request.Context(), project); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) listProjectUsers(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	users, err := n.projectUsecase.ListProjectUsers(req.Request.Context(), project)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(users); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) addProjectUser(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	// Verify the validity of parameters
+	var addReq apis.AddProjectUserRequest
+	if err := req.ReadEntity(&addReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&addReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := n.projectUsecase.AddProjectUser(req.Request.Context(), project, addReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) updateProjectUser(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	// Verify the validity of parameters
+	var updateReq apis.UpdateProjectUserRequest
+	if err := req.ReadEntity(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := validate.Struct(&updateReq); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	user, err := n.projectUsecase.UpdateProjectUser(req.Request.Context(), project, req.PathParameter("userName"), updateReq)
+	if err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(user); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
+
+func (n *projectWebService) deleteProjectUser(req *restful.Request, res *restful.Response) {
+	project := req.Request.Context().Value(&apis.CtxKeyProject).(*model.Project)
+	if err := n.projectUsecase.DeleteProjectUser(req.Request.Context(), project, req.PathParameter("userName")); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+	if err := res.WriteEntity(apis.EmptyResponse{}); err != nil {
+		bcode.ReturnError(req, res, err)
+		return
+	}
+}
@@ -68,8 +68,10 @@ func Init(ds datastore.DataStore) {
 	addonUsecase := usecase.NewAddonUsecase()
 	envBindingUsecase := usecase.NewEnvBindingUsecase(ds, workflowUsecase, definitionUsecase)
 	applicationUsecase := usecase.NewApplicationUsecase(ds, workflowUsecase, envBindingUsecase, deliveryTargetUsecase, definitionUsecase, projectUsecase)
+	applicationTemplateUsecase := usecase.NewApplicationTemplateUsecase(ds)
 	RegistWebService(NewClusterWebService(clusterUsecase))
 	RegistWebService(NewApplicationWebService(applicationUsecase, envBindingUsecase, workflowUsecase))
+	RegistWebService(NewApplicationTemplateWebService(applicationTemplateUsecase, applicationUsecase))
 	RegistWebService(NewProjectWebService(projectUsecase))
 	RegistWebService(NewDefinitionWebservice(definitionUsecase))
 	RegistWebService(NewAddonWebService(addonUsecase))
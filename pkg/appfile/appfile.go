@@ -158,6 +158,8 @@ type Appfile struct {
 	RelatedTraitDefinitions     map[string]*v1beta1.TraitDefinition
 	RelatedComponentDefinitions map[string]*v1beta1.ComponentDefinition
 	RelatedScopeDefinitions     map[string]*v1beta1.ScopeDefinition
+	// RelatedWorkflowStepDefinitions are the workflow step definitions pinned by name@version
+	RelatedWorkflowStepDefinitions map[string]*v1beta1.WorkflowStepDefinition
 
 	Policies      []*Workload
 	WorkflowSteps []v1beta1.WorkflowStep
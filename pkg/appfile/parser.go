@@ -19,6 +19,7 @@ package appfile
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
@@ -134,10 +135,37 @@ func (p *Parser) GenerateAppFile(ctx context.Context, app *v1beta1.Application)
 		appfile.WorkflowMode = common.WorkflowModeStep
 		appfile.WorkflowSteps = wfSpec.Steps
 	}
+	if err := p.parsePinnedWorkflowStepDefinitions(ctx, appfile); err != nil {
+		return nil, fmt.Errorf("failed to parseWorkflowSteps: %w", err)
+	}
 
 	return appfile, nil
 }
 
+// parsePinnedWorkflowStepDefinitions loads the workflow step definitions pinned by name@version, so they can be
+// recorded in the app revision. The steps with the latest definitions are loaded by the workflow when they run.
+func (p *Parser) parsePinnedWorkflowStepDefinitions(ctx context.Context, af *Appfile) error {
+	for _, step := range af.WorkflowSteps {
+		if !strings.Contains(step.Type, "@") {
+			continue
+		}
+		if _, ok := af.RelatedWorkflowStepDefinitions[step.Type]; ok {
+			continue
+		}
+		templ, err := p.tmplLoader.LoadTemplate(ctx, p.dm, p.client, step.Type, types.TypeWorkflowStep)
+		if err != nil {
+			return errors.WithMessagef(err, "fetch workflow step type of %s", step.Name)
+		}
+		if templ.WorkflowStepDefinition == nil {
+			continue
+		}
+		wd := templ.WorkflowStepDefinition.DeepCopy()
+		wd.Status = v1beta1.WorkflowStepDefinitionStatus{}
+		af.RelatedWorkflowStepDefinitions[step.Type] = wd
+	}
+	return nil
+}
+
 func (p *Parser) newAppfile(appName, ns string, app *v1beta1.Application) *Appfile {
 	file := &Appfile{
 		Name:      appName,
@@ -149,6 +177,8 @@ func (p *Parser) newAppfile(appName, ns string, app *v1beta1.Application) *Appfi
 		RelatedComponentDefinitions: make(map[string]*v1beta1.ComponentDefinition),
 		RelatedScopeDefinitions:     make(map[string]*v1beta1.ScopeDefinition),
 
+		RelatedWorkflowStepDefinitions: make(map[string]*v1beta1.WorkflowStepDefinition),
+
 		parser: p,
 		app:    app,
 	}
@@ -224,6 +254,9 @@ func (p *Parser) GenerateAppFileFromRevision(appRev *v1beta1.ApplicationRevision
 	for k, v := range appRev.Spec.ScopeDefinitions {
 		appfile.RelatedScopeDefinitions[k] = v.DeepCopy()
 	}
+	for k, v := range appRev.Spec.WorkflowStepDefinitions {
+		appfile.RelatedWorkflowStepDefinitions[k] = v.DeepCopy()
+	}
 
 	if wfSpec := app.Spec.Workflow; wfSpec != nil {
 		appfile.WorkflowSteps = wfSpec.Steps
@@ -306,6 +339,9 @@ func (p *Parser) convertTemplate2Workload(name, typ string, props *runtime.RawEx
 	if err != nil {
 		wlType = typ
 	}
+	if strings.Contains(typ, "@") {
+		templ.PinnedRevisionName = typ
+	}
 	return &Workload{
 		Traits:             []*Trait{},
 		ScopeDefinition:    []*v1beta1.ScopeDefinition{},
@@ -414,6 +450,9 @@ func (p *Parser) convertTemplate2Trait(name string, properties map[string]interf
 	if err != nil {
 		traitName = name
 	}
+	if strings.Contains(name, "@") {
+		templ.PinnedRevisionName = name
+	}
 	return &Trait{
 		Name:               traitName,
 		CapabilityCategory: templ.CapabilityCategory,
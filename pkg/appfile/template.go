@@ -64,6 +64,10 @@ type Template struct {
 
 	PolicyDefinition       *v1beta1.PolicyDefinition
 	WorkflowStepDefinition *v1beta1.WorkflowStepDefinition
+
+	// PinnedRevisionName is the capability name with the pinned definition revision, e.g. webservice@v3.
+	// It's empty if the latest definition is used.
+	PinnedRevisionName string
 }
 
 // LoadTemplate gets the capability definition from cluster and resolve it.
@@ -234,26 +238,10 @@ func LoadTemplateFromRevision(capName string, capType types.CapType, apprev *v1b
 }
 
 func verifyRevisionName(capName string, capType types.CapType, apprev *v1beta1.ApplicationRevision) string {
-	if strings.Contains(capName, "@") {
+	// the pinned definition revision is recorded with its full name, e.g. webservice@v3
+	if strings.Contains(capName, "@") && !hasDefinitionInRevision(capName, capType, apprev) {
 		splitName := capName[0:strings.LastIndex(capName, "@")]
-		ok := false
-
-		switch capType {
-		case types.TypeComponentDefinition:
-			_, ok = apprev.Spec.ComponentDefinitions[splitName]
-		case types.TypeTrait:
-			_, ok = apprev.Spec.TraitDefinitions[splitName]
-		case types.TypePolicy:
-			_, ok = apprev.Spec.PolicyDefinitions[splitName]
-		case types.TypeWorkflowStep:
-			_, ok = apprev.Spec.WorkflowStepDefinitions[splitName]
-		case types.TypeScope:
-			_, ok = apprev.Spec.ScopeDefinitions[splitName]
-		default:
-			return capName
-		}
-
-		if ok {
+		if hasDefinitionInRevision(splitName, capType, apprev) {
 			return splitName
 		}
 	}
@@ -261,6 +249,24 @@ func verifyRevisionName(capName string, capType types.CapType, apprev *v1beta1.A
 	return capName
 }
 
+func hasDefinitionInRevision(capName string, capType types.CapType, apprev *v1beta1.ApplicationRevision) bool {
+	ok := false
+	switch capType {
+	case types.TypeComponentDefinition:
+		_, ok = apprev.Spec.ComponentDefinitions[capName]
+	case types.TypeTrait:
+		_, ok = apprev.Spec.TraitDefinitions[capName]
+	case types.TypePolicy:
+		_, ok = apprev.Spec.PolicyDefinitions[capName]
+	case types.TypeWorkflowStep:
+		_, ok = apprev.Spec.WorkflowStepDefinitions[capName]
+	case types.TypeScope:
+		_, ok = apprev.Spec.ScopeDefinitions[capName]
+	default:
+	}
+	return ok
+}
+
 // DryRunTemplateLoader return a function that do the same work as
 // LoadTemplate, but load template from provided ones before loading from
 // cluster through LoadTemplate
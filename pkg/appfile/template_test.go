@@ -525,3 +525,43 @@ spec:
 		t.Fatal("failed load template of trait definition ", diff)
 	}
 }
+
+func TestLoadTemplateFromRevisionWithPinnedDefinition(t *testing.T) {
+	newCompDef := func(template string) v1beta1.ComponentDefinition {
+		return v1beta1.ComponentDefinition{
+			Spec: v1beta1.ComponentDefinitionSpec{
+				Workload:  common.WorkloadTypeDescriptor{Type: "deployments.apps"},
+				Schematic: &common.Schematic{CUE: &common.CUE{Template: template}},
+			},
+		}
+	}
+	apprev := &v1beta1.ApplicationRevision{
+		Spec: v1beta1.ApplicationRevisionSpec{
+			ComponentDefinitions: map[string]v1beta1.ComponentDefinition{
+				"worker":    newCompDef("latest"),
+				"worker@v1": newCompDef("v1"),
+			},
+			TraitDefinitions: map[string]v1beta1.TraitDefinition{
+				"scaler": {Spec: v1beta1.TraitDefinitionSpec{Schematic: &common.Schematic{CUE: &common.CUE{Template: "scaler"}}}},
+			},
+		},
+	}
+	testCases := map[string]struct {
+		capName  string
+		capType  types.CapType
+		template string
+	}{
+		"latest definition": {capName: "worker", capType: types.TypeComponentDefinition, template: "latest"},
+		"pinned definition": {capName: "worker@v1", capType: types.TypeComponentDefinition, template: "v1"},
+		"legacy revision":   {capName: "scaler@v2", capType: types.TypeTrait, template: "scaler"},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := LoadTemplateFromRevision(tc.capName, tc.capType, apprev, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.template, tmpl.TemplateStr)
+		})
+	}
+	_, err := LoadTemplateFromRevision("web@v1", types.TypeComponentDefinition, apprev, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apprevision
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+)
+
+// ItemKind is the kind of the parts of the application compared between revisions
+type ItemKind string
+
+// enum kinds of the parts of the application
+const (
+	ComponentKind    ItemKind = "Component"
+	TraitKind        ItemKind = "Trait"
+	PolicyKind       ItemKind = "Policy"
+	WorkflowStepKind ItemKind = "WorkflowStep"
+)
+
+// DiffType is the type of the change of a part of the application
+type DiffType string
+
+// enum types of the changes
+const (
+	AddDiff    DiffType = "ADD"
+	ModifyDiff DiffType = "MODIFY"
+	RemoveDiff DiffType = "REMOVE"
+)
+
+// DiffEntry records the change of a part of the application between two revisions
+type DiffEntry struct {
+	Kind ItemKind `json:"kind"`
+	Name string   `json:"name"`
+	// Component is the name of the component the trait belongs to
+	Component string   `json:"component,omitempty"`
+	DiffType  DiffType `json:"diffType"`
+	// Base and Target are the YAML of the part in the base and the target revision
+	Base   string `json:"base,omitempty"`
+	Target string `json:"target,omitempty"`
+}
+
+type item struct {
+	name string
+	data interface{}
+}
+
+// Diff compares the components, traits, policies and workflow steps of the application in two revisions, and returns
+// the changes from the base to the target. The parts without changes are not returned.
+func Diff(base, target *v1beta1.Application) ([]DiffEntry, error) {
+	var entries []DiffEntry
+	components, err := diffItems(ComponentKind, "", componentItems(base), componentItems(target))
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, components...)
+	for _, name := range componentNames(base, target) {
+		traits, err := diffItems(TraitKind, name, traitItems(base, name), traitItems(target, name))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, traits...)
+	}
+	policies, err := diffItems(PolicyKind, "", policyItems(base), policyItems(target))
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, policies...)
+	steps, err := diffItems(WorkflowStepKind, "", workflowStepItems(base), workflowStepItems(target))
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, steps...), nil
+}
+
+// diffItems compares the items by their names, the items in the target come first in their order, followed by the
+// items removed from the base.
+func diffItems(kind ItemKind, component string, base, target []item) ([]DiffEntry, error) {
+	baseData := map[string]string{}
+	for _, i := range base {
+		data, err := yaml.Marshal(i.data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal %s %s", kind, i.name)
+		}
+		baseData[i.name] = string(data)
+	}
+	var entries []DiffEntry
+	inTarget := map[string]bool{}
+	for _, i := range target {
+		inTarget[i.name] = true
+		data, err := yaml.Marshal(i.data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot marshal %s %s", kind, i.name)
+		}
+		entry := DiffEntry{Kind: kind, Name: i.name, Component: component, Target: string(data)}
+		old, found := baseData[i.name]
+		switch {
+		case !found:
+			entry.DiffType = AddDiff
+		case old != entry.Target:
+			entry.DiffType = ModifyDiff
+			entry.Base = old
+		default:
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for _, i := range base {
+		if !inTarget[i.name] {
+			entries = append(entries, DiffEntry{Kind: kind, Name: i.name, Component: component, DiffType: RemoveDiff, Base: baseData[i.name]})
+		}
+	}
+	return entries, nil
+}
+
+// componentItems returns the components without their traits, the traits are compared separately
+func componentItems(app *v1beta1.Application) []item {
+	var items []item
+	for _, comp := range app.Spec.Components {
+		c := comp.DeepCopy()
+		c.Traits = nil
+		items = append(items, item{name: comp.Name, data: c})
+	}
+	return items
+}
+
+// traitItems returns the traits of the component named by their types and their indexes among the traits of the same
+// type, since a component can have multiple traits of the same type
+func traitItems(app *v1beta1.Application, component string) []item {
+	var items []item
+	for _, comp := range app.Spec.Components {
+		if comp.Name != component {
+			continue
+		}
+		indexes := map[string]int{}
+		for _, trait := range comp.Traits {
+			items = append(items, item{name: fmt.Sprintf("%s[%d]", trait.Type, indexes[trait.Type]), data: trait})
+			indexes[trait.Type]++
+		}
+	}
+	return items
+}
+
+func componentNames(base, target *v1beta1.Application) []string {
+	var names []string
+	found := map[string]bool{}
+	for _, app := range []*v1beta1.Application{target, base} {
+		for _, comp := range app.Spec.Components {
+			if !found[comp.Name] {
+				found[comp.Name] = true
+				names = append(names, comp.Name)
+			}
+		}
+	}
+	return names
+}
+
+func policyItems(app *v1beta1.Application) []item {
+	var items []item
+	for _, policy := range app.Spec.Policies {
+		items = append(items, item{name: policy.Name, data: policy})
+	}
+	return items
+}
+
+func workflowStepItems(app *v1beta1.Application) []item {
+	var items []item
+	if app.Spec.Workflow == nil {
+		return items
+	}
+	for _, step := range app.Spec.Workflow.Steps {
+		items = append(items, item{name: step.Name, data: step})
+	}
+	return items
+}
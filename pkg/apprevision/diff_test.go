@@ -0,0 +1,135 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apprevision
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func raw(s string) *runtime.RawExtension {
+	return &runtime.RawExtension{Raw: []byte(s)}
+}
+
+func TestDiff(t *testing.T) {
+	base := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{
+		Components: []common.ApplicationComponent{{
+			Name:       "express-server",
+			Type:       "webservice",
+			Properties: raw(`{"image":"oamdev/hello-world:v1"}`),
+			Traits: []common.ApplicationTrait{
+				{Type: "scaler", Properties: raw(`{"replicas":1}`)},
+				{Type: "gateway", Properties: raw(`{"domain":"test.com"}`)},
+			},
+		}, {
+			Name:       "worker",
+			Type:       "worker",
+			Properties: raw(`{"image":"busybox"}`),
+			Traits:     []common.ApplicationTrait{{Type: "scaler", Properties: raw(`{"replicas":1}`)}},
+		}},
+		Policies: []v1beta1.AppPolicy{{Name: "topology", Type: "topology", Properties: raw(`{"clusters":["local"]}`)}},
+	}}
+	target := base.DeepCopy()
+	target.Spec.Components[0].Properties = raw(`{"image":"oamdev/hello-world:v2"}`)
+	target.Spec.Components[0].ExternalRevision = "express-server-v1"
+	target.Spec.Components[0].Traits = []common.ApplicationTrait{
+		{Type: "scaler", Properties: raw(`{"replicas":3}`)},
+		{Type: "gateway", Properties: raw(`{"domain":"test.com"}`)},
+		{Type: "gateway", Properties: raw(`{"domain":"example.com"}`)},
+	}
+	target.Spec.Components[1] = common.ApplicationComponent{Name: "cache", Type: "webservice", Properties: raw(`{"image":"redis"}`)}
+	target.Spec.Workflow = &v1beta1.Workflow{Steps: []v1beta1.WorkflowStep{{Name: "deploy", Type: "deploy2env"}}}
+
+	entries, err := Diff(base, target)
+	r := require.New(t)
+	r.NoError(err)
+	var changes []string
+	for _, entry := range entries {
+		changes = append(changes, string(entry.DiffType)+" "+string(entry.Kind)+" "+entry.Component+"/"+entry.Name)
+	}
+	r.Equal([]string{
+		"MODIFY Component /express-server",
+		"ADD Component /cache",
+		"REMOVE Component /worker",
+		"MODIFY Trait express-server/scaler[0]",
+		"ADD Trait express-server/gateway[1]",
+		"REMOVE Trait worker/scaler[0]",
+		"ADD WorkflowStep /deploy",
+	}, changes)
+	r.Contains(entries[0].Base, "oamdev/hello-world:v1")
+	r.Contains(entries[0].Target, "oamdev/hello-world:v2")
+	r.Contains(entries[0].Target, "externalRevision: express-server-v1")
+	r.NotContains(entries[0].Target, "replicas")
+	r.Empty(entries[1].Base)
+	r.Empty(entries[2].Target)
+
+	entries, err = Diff(base, base.DeepCopy())
+	r.NoError(err)
+	r.Empty(entries)
+}
+
+func TestApplicationFromRevision(t *testing.T) {
+	r := require.New(t)
+	rev := &v1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			oam.AnnotationLastAppliedConfig: `{"spec":{"components":[],"workflow":{"steps":[{"name":"deploy","type":"deploy2env"}]}}}`,
+		}},
+		Spec: v1beta1.ApplicationRevisionSpec{Application: v1beta1.Application{Spec: v1beta1.ApplicationSpec{
+			Components: []common.ApplicationComponent{{Name: "express-server", Type: "webservice"}},
+		}}},
+	}
+	app := ApplicationFromRevision(rev)
+	r.Equal(1, len(app.Spec.Components))
+	r.NotNil(app.Spec.Workflow)
+	r.Equal("deploy", app.Spec.Workflow.Steps[0].Name)
+	r.Nil(rev.Spec.Application.Spec.Workflow)
+
+	rev.Annotations = map[string]string{oam.AnnotationLastAppliedConfig: "invalid"}
+	r.Nil(ApplicationFromRevision(rev).Spec.Workflow)
+}
+
+func TestRollback(t *testing.T) {
+	r := require.New(t)
+	rev := &v1beta1.ApplicationRevision{
+		ObjectMeta: metav1.ObjectMeta{Name: "app-v1"},
+		Spec: v1beta1.ApplicationRevisionSpec{Application: v1beta1.Application{Spec: v1beta1.ApplicationSpec{
+			Components: []common.ApplicationComponent{{Name: "express-server", Type: "webservice", ExternalRevision: "express-server-v1"}},
+		}}},
+	}
+	app := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{
+		Components: []common.ApplicationComponent{{Name: "express-server", Type: "webservice"}, {Name: "worker", Type: "worker"}},
+		Policies:   []v1beta1.AppPolicy{{Name: "topology", Type: "topology"}},
+		Workflow:   &v1beta1.Workflow{Steps: []v1beta1.WorkflowStep{{Name: "deploy", Type: "deploy2env"}}},
+	}}
+	Rollback(app, rev)
+	r.Equal(rev.Spec.Application.Spec.Components, app.Spec.Components)
+	r.Empty(app.Spec.Policies)
+	r.Nil(app.Spec.Workflow)
+	r.Empty(app.Annotations)
+
+	app.Annotations = map[string]string{oam.AnnotationPublishVersion: "alpha"}
+	Rollback(app, rev)
+	r.NotEqual("alpha", app.Annotations[oam.AnnotationPublishVersion])
+	r.Contains(app.Annotations[oam.AnnotationPublishVersion], "app-v1")
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apprevision
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ApplicationFromRevision returns the application recorded in the revision. The workflow is not a part of the
+// revision, so it's recovered from the last applied configuration of the application recorded in the revision.
+func ApplicationFromRevision(rev *v1beta1.ApplicationRevision) *v1beta1.Application {
+	app := rev.Spec.Application.DeepCopy()
+	if app.Spec.Workflow != nil {
+		return app
+	}
+	for _, key := range []string{oam.AnnotationLastAppliedConfig, oam.AnnotationLastAppliedConfiguration} {
+		config, ok := rev.GetAnnotations()[key]
+		if !ok {
+			continue
+		}
+		applied := &v1beta1.Application{}
+		if err := json.Unmarshal([]byte(config), applied); err == nil && applied.Spec.Workflow != nil {
+			app.Spec.Workflow = applied.Spec.Workflow
+			break
+		}
+	}
+	return app
+}
+
+// Rollback replaces the components, policies and workflow of the application with the ones recorded in the revision.
+// The workflow is removed if the revision has none. The components keep the external revisions pinned in the revision.
+// If the application is published with a version, a new version is set to publish the application again.
+func Rollback(app *v1beta1.Application, rev *v1beta1.ApplicationRevision) {
+	old := ApplicationFromRevision(rev)
+	app.Spec.Components = old.Spec.Components
+	app.Spec.Policies = old.Spec.Policies
+	app.Spec.Workflow = old.Spec.Workflow
+	if _, ok := app.GetAnnotations()[oam.AnnotationPublishVersion]; ok {
+		app.Annotations[oam.AnnotationPublishVersion] = fmt.Sprintf("%s-rollback-%d", rev.Name, time.Now().Unix())
+	}
+}
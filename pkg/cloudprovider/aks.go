@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// AKSTerraformProvider describes the cloud provider of the AKS clusters in azure created by the terraform modules,
+// the AKS clusters created outside KubeVela are not listed
+type AKSTerraformProvider struct {
+	*terraformClusterProvider
+}
+
+// NewAKSTerraformProvider create azure cloud provider, the access key id is formatted as
+// <subscriptionID>/<tenantID>/<clientID> and the access key secret is the secret of the service principal
+func NewAKSTerraformProvider(accessKeyID string, accessKeySecret string, k8sClient client.Client) (*AKSTerraformProvider, error) {
+	ids := strings.Split(accessKeyID, "/")
+	if len(ids) != 3 {
+		return nil, errors.Wrapf(ErrInvalidAccessKey, "the access key id of azure should be formatted as <subscriptionID>/<tenantID>/<clientID>")
+	}
+	credentials := fmt.Sprintf("armClientID: %s\narmClientSecret: %s\narmSubscriptionID: %s\narmTenantID: %s\n", ids[2], accessKeySecret, ids[0], ids[1])
+	return &AKSTerraformProvider{terraformClusterProvider: &terraformClusterProvider{
+		k8sClient:     k8sClient,
+		provider:      ProviderAKS,
+		tfProvider:    "azure",
+		clusterType:   "AKS",
+		modulePath:    "azure/aks",
+		hashKey:       computeProviderHashKey(ProviderAKS, accessKeyID, accessKeySecret),
+		credentials:   credentials,
+		defaultRegion: "eastus",
+		// the zone of azure is the location of the cluster
+		zoneToRegion: func(zone string) string { return zone },
+	}}, nil
+}
@@ -19,16 +19,13 @@ package cloudprovider
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"strings"
 
 	cs20151215 "github.com/alibabacloud-go/cs-20151215/v2/client"
 	openapi "github.com/alibabacloud-go/darabonba-openapi/client"
 	"github.com/alibabacloud-go/tea/tea"
-	types "github.com/oam-dev/terraform-controller/api/types/crossplane-runtime"
-	v1beta12 "github.com/oam-dev/terraform-controller/api/v1beta1"
 	"github.com/pkg/errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/klog/v2"
 	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -154,9 +151,9 @@ func (provider *AliyunCloudProvider) GetClusterInfo(clusterID string) (*CloudClu
 
 // CreateCloudCluster create cloud cluster
 func (provider *AliyunCloudProvider) CreateCloudCluster(ctx context.Context, clusterName string, zone string, worker int, cpu int64, mem int64) (string, error) {
-	name := GetCloudClusterFullName(ProviderAliyun, clusterName)
-	ns := util.GetRuntimeNamespace()
-	terraformProviderName, err := bootstrapTerraformProvider(ctx, provider.k8sClient, ns, ProviderAliyun, "alibaba", provider.accessKeyID, provider.accessKeySecret, "cn-hongkong")
+	credentials := fmt.Sprintf("accessKeyID: %s\naccessKeySecret: %s\nsecurityToken:\n", provider.accessKeyID, provider.accessKeySecret)
+	terraformProviderName, err := bootstrapTerraformProvider(ctx, provider.k8sClient, util.GetRuntimeNamespace(), ProviderAliyun, "alibaba",
+		computeProviderHashKey(ProviderAliyun, provider.accessKeyID, provider.accessKeySecret), credentials, "cn-hongkong")
 	if err != nil {
 		return "", errors.Wrapf(err, "failed to bootstrap terraform provider")
 	}
@@ -175,39 +172,5 @@ func (provider *AliyunCloudProvider) CreateCloudCluster(ctx context.Context, clu
 	if worker != 0 {
 		properties["k8s_worker_number"] = worker
 	}
-	bs, err := json.Marshal(properties)
-	if err != nil {
-		return name, errors.Wrapf(err, "failed to marshal cloud cluster app properties")
-	}
-
-	cfg := v1beta12.Configuration{
-		ObjectMeta: v1.ObjectMeta{
-			Name:      name,
-			Namespace: ns,
-			Labels: map[string]string{
-				CloudClusterCreatorLabelKey: ProviderAliyun,
-			},
-		},
-		Spec: v1beta12.ConfigurationSpec{
-			BaseConfigurationSpec: v1beta12.BaseConfigurationSpec{
-				ProviderReference: &types.Reference{
-					Name:      terraformProviderName,
-					Namespace: ns,
-				},
-			},
-			Remote:   "https://github.com/kubevela-contrib/terraform-modules.git",
-			Variable: &runtime.RawExtension{Raw: bs},
-		},
-	}
-	cfg.Spec.Path = "alibaba/cs/dedicated-kubernetes"
-	cfg.Spec.WriteConnectionSecretToReference = &types.SecretReference{
-		Name:      name,
-		Namespace: ns,
-	}
-
-	if err = provider.k8sClient.Create(ctx, &cfg); err != nil {
-		return name, errors.Wrapf(err, "failed to create cloud cluster terraform configuration")
-	}
-
-	return name, nil
+	return createTerraformConfiguration(ctx, provider.k8sClient, ProviderAliyun, clusterName, terraformProviderName, "alibaba/cs/dedicated-kubernetes", nil, properties)
 }
@@ -18,6 +18,8 @@ package cloudprovider
 
 import (
 	"context"
+	"sort"
+	"sync"
 
 	"github.com/pkg/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -26,9 +28,13 @@ import (
 const (
 	// CloudClusterCreatorLabelKey labels the creator of cloud cluster
 	CloudClusterCreatorLabelKey = "api.core.oam.dev/cloud-cluster-creator"
+	// CloudClusterCredentialLabelKey labels the hash of the credential used to create the cloud cluster
+	CloudClusterCredentialLabelKey = "api.core.oam.dev/cloud-cluster-credential"
 )
 
-// CloudClusterProvider abstracts the cloud provider to provide cluster access
+// CloudClusterProvider abstracts the cloud provider to provide cluster access.
+// The aliyun provider lists the clusters through the API of the cloud, while the terraform based providers
+// (eks, aks and gke) only list the clusters created by their CreateCloudCluster.
 type CloudClusterProvider interface {
 	IsInvalidKey(err error) bool
 	ListCloudClusters(pageNumber int, pageSize int) ([]*CloudCluster, int, error)
@@ -37,12 +43,53 @@ type CloudClusterProvider interface {
 	CreateCloudCluster(ctx context.Context, clusterName string, zone string, worker int, cpu int64, mem int64) (string, error)
 }
 
+// CloudClusterProviderFactory creates the cloud cluster provider with the access key
+type CloudClusterProviderFactory func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error)
+
+var (
+	providerLock      sync.RWMutex
+	providerFactories = map[string]CloudClusterProviderFactory{
+		ProviderAliyun: func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
+			return NewAliyunCloudProvider(accessKeyID, accessKeySecret, k8sClient)
+		},
+		ProviderEKS: func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
+			return NewEKSTerraformProvider(accessKeyID, accessKeySecret, k8sClient)
+		},
+		ProviderAKS: func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
+			return NewAKSTerraformProvider(accessKeyID, accessKeySecret, k8sClient)
+		},
+		ProviderGKE: func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
+			return NewGKETerraformProvider(accessKeyID, accessKeySecret, k8sClient)
+		},
+	}
+)
+
+// RegisterClusterProvider registers the factory of the cloud cluster provider, the existing one is overridden
+func RegisterClusterProvider(provider string, factory CloudClusterProviderFactory) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+	providerFactories[provider] = factory
+}
+
+// ListClusterProviders lists the names of the registered cloud cluster providers
+func ListClusterProviders() []string {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	var providers []string
+	for provider := range providerFactories {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
 // GetClusterProvider creates interface for getting cloud cluster provider
 func GetClusterProvider(provider string, accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
-	switch provider {
-	case ProviderAliyun:
-		return NewAliyunCloudProvider(accessKeyID, accessKeySecret, k8sClient)
-	default:
+	providerLock.RLock()
+	factory, ok := providerFactories[provider]
+	providerLock.RUnlock()
+	if !ok {
 		return nil, errors.Errorf("cluster provider %s is not implemented", provider)
 	}
+	return factory(accessKeyID, accessKeySecret, k8sClient)
 }
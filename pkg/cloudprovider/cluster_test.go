@@ -0,0 +1,145 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"testing"
+
+	tftypes "github.com/oam-dev/terraform-controller/api/types"
+	v1beta12 "github.com/oam-dev/terraform-controller/api/v1beta1"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: https://1.2.3.4:6443
+  name: test
+contexts:
+- context:
+    cluster: test
+    user: test
+  name: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test
+`
+
+func TestClusterProviderRegistry(t *testing.T) {
+	r := require.New(t)
+	r.Contains(ListClusterProviders(), ProviderAliyun)
+	r.Contains(ListClusterProviders(), ProviderEKS)
+	r.Contains(ListClusterProviders(), ProviderAKS)
+	r.Contains(ListClusterProviders(), ProviderGKE)
+	_, err := GetClusterProvider(ProviderFake, "ak", "sk", nil)
+	r.Error(err)
+
+	fakeProvider := NewFakeCloudProvider("ak", "sk")
+	RegisterClusterProvider(ProviderFake, fakeProvider.Factory())
+	fakeProvider.AddCluster(&CloudCluster{ID: "c-1", Name: "cluster-1"}, testKubeConfig)
+	p, err := GetClusterProvider(ProviderFake, "ak", "sk", nil)
+	r.NoError(err)
+	clusters, total, err := p.ListCloudClusters(1, 10)
+	r.NoError(err)
+	r.Equal(1, total)
+	r.Equal("cluster-1", clusters[0].Name)
+	kubeConfig, err := p.GetClusterKubeConfig("c-1")
+	r.NoError(err)
+	r.Equal(testKubeConfig, kubeConfig)
+	_, err = p.CreateCloudCluster(context.Background(), "cluster-2", "", 0, 0, 0)
+	r.NoError(err)
+	_, total, err = p.ListCloudClusters(1, 10)
+	r.NoError(err)
+	r.Equal(2, total)
+
+	p, err = GetClusterProvider(ProviderFake, "ak", "invalid", nil)
+	r.NoError(err)
+	_, _, err = p.ListCloudClusters(1, 10)
+	r.True(p.IsInvalidKey(err))
+}
+
+func TestTerraformCloudProvider(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	p, err := GetClusterProvider(ProviderEKS, "ak", "sk", cli)
+	r.NoError(err)
+	name, err := p.CreateCloudCluster(ctx, "eks-cluster", "us-west-2a", 3, 2, 4)
+	r.NoError(err)
+	r.Equal("cloud-cluster-eks-eks-cluster", name)
+
+	cfg := &v1beta12.Configuration{}
+	r.NoError(cli.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: name}, cfg))
+	r.Equal("aws/eks", cfg.Spec.Path)
+	terraformProvider := &v1beta12.Provider{}
+	r.NoError(cli.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: cfg.Spec.ProviderReference.Name}, terraformProvider))
+	r.Equal("aws", terraformProvider.Spec.Provider)
+	r.Equal("us-west-2", terraformProvider.Spec.Region)
+
+	clusters, total, err := p.ListCloudClusters(1, 10)
+	r.NoError(err)
+	r.Equal(0, total)
+	r.Empty(clusters)
+
+	cfg.Status.Apply.State = tftypes.Available
+	cfg.Status.Apply.Outputs = map[string]v1beta12.Property{terraformOutputClusterID: {Value: "eks-id"}}
+	r.NoError(cli.Status().Update(ctx, cfg))
+	r.NoError(cli.Create(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "vela-system"},
+		Data:       map[string][]byte{terraformOutputKubeConfig: []byte(testKubeConfig)},
+	}))
+	clusters, total, err = p.ListCloudClusters(1, 10)
+	r.NoError(err)
+	r.Equal(1, total)
+	r.Equal("eks-cluster", clusters[0].Name)
+	r.Equal("us-west-2a", clusters[0].Zone)
+	r.Equal("us-west-2", clusters[0].RegionID)
+	r.Equal("https://1.2.3.4:6443", clusters[0].APIServerURL)
+	kubeConfig, err := p.GetClusterKubeConfig("eks-id")
+	r.NoError(err)
+	r.Equal(testKubeConfig, kubeConfig)
+
+	// the clusters created by other credentials are invisible
+	p, err = GetClusterProvider(ProviderEKS, "ak", "another", cli)
+	r.NoError(err)
+	_, total, err = p.ListCloudClusters(1, 10)
+	r.NoError(err)
+	r.Equal(0, total)
+
+	_, err = GetClusterProvider(ProviderAKS, "client", "secret", cli)
+	r.ErrorIs(err, ErrInvalidAccessKey)
+	_, err = GetClusterProvider(ProviderAKS, "sub/tenant/client", "secret", cli)
+	r.NoError(err)
+}
+
+func TestZoneToRegion(t *testing.T) {
+	r := require.New(t)
+	r.Equal("us-west-2", eksZoneToRegion("us-west-2a"))
+	r.Equal("us-west-2", eksZoneToRegion("us-west-2"))
+	r.Equal("us-central1", gkeZoneToRegion("us-central1-a"))
+	r.Equal("us-central1", gkeZoneToRegion("us-central1"))
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// EKSTerraformProvider describes the cloud provider of the EKS clusters in aws created by the terraform modules,
+// the EKS clusters created outside KubeVela are not listed
+type EKSTerraformProvider struct {
+	*terraformClusterProvider
+}
+
+// NewEKSTerraformProvider create aws cloud provider, the access key is the access key of the IAM user
+func NewEKSTerraformProvider(accessKeyID string, accessKeySecret string, k8sClient client.Client) (*EKSTerraformProvider, error) {
+	credentials := fmt.Sprintf("awsAccessKeyID: %s\nawsSecretAccessKey: %s\nawsSessionToken:\n", accessKeyID, accessKeySecret)
+	return &EKSTerraformProvider{terraformClusterProvider: &terraformClusterProvider{
+		k8sClient:     k8sClient,
+		provider:      ProviderEKS,
+		tfProvider:    "aws",
+		clusterType:   "EKS",
+		modulePath:    "aws/eks",
+		hashKey:       computeProviderHashKey(ProviderEKS, accessKeyID, accessKeySecret),
+		credentials:   credentials,
+		defaultRegion: "us-east-1",
+		zoneToRegion:  eksZoneToRegion,
+	}}, nil
+}
+
+// eksZoneToRegion trims the availability zone letter, e.g. us-west-2a is in the region us-west-2
+func eksZoneToRegion(zone string) string {
+	if last := zone[len(zone)-1]; last >= 'a' && last <= 'z' {
+		return zone[:len(zone)-1]
+	}
+	return zone
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ProviderFake is the name of the fake cloud provider, which is only registered in tests
+	ProviderFake = "fake"
+)
+
+// FakeCloudProvider serves the cloud clusters in memory, it can be registered to test the cloud cluster APIs
+type FakeCloudProvider struct {
+	mu sync.Mutex
+	// AccessKeyID and AccessKeySecret are the only access key accepted by the provider
+	AccessKeyID     string
+	AccessKeySecret string
+	Clusters        []*CloudCluster
+	// KubeConfigs are the kubeconfigs of the clusters indexed by the cluster id
+	KubeConfigs map[string]string
+}
+
+// NewFakeCloudProvider create a fake cloud provider accepting the access key
+func NewFakeCloudProvider(accessKeyID string, accessKeySecret string) *FakeCloudProvider {
+	return &FakeCloudProvider{AccessKeyID: accessKeyID, AccessKeySecret: accessKeySecret, KubeConfigs: map[string]string{}}
+}
+
+// Factory returns the factory which checks the access key and returns the fake provider
+func (provider *FakeCloudProvider) Factory() CloudClusterProviderFactory {
+	return func(accessKeyID string, accessKeySecret string, k8sClient client.Client) (CloudClusterProvider, error) {
+		return &fakeCloudProviderSession{FakeCloudProvider: provider, authorized: accessKeyID == provider.AccessKeyID && accessKeySecret == provider.AccessKeySecret}, nil
+	}
+}
+
+// AddCluster adds a cloud cluster and its kubeconfig into the provider
+func (provider *FakeCloudProvider) AddCluster(cluster *CloudCluster, kubeConfig string) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	cluster.Provider = ProviderFake
+	provider.Clusters = append(provider.Clusters, cluster)
+	provider.KubeConfigs[cluster.ID] = kubeConfig
+}
+
+// fakeCloudProviderSession is the fake provider accessed by one access key
+type fakeCloudProviderSession struct {
+	*FakeCloudProvider
+	authorized bool
+}
+
+// IsInvalidKey check if error is caused by the invalid access key
+func (provider *fakeCloudProviderSession) IsInvalidKey(err error) bool {
+	return errors.Is(err, ErrInvalidAccessKey)
+}
+
+// ListCloudClusters list clusters with page info, return clusters, total count and error
+func (provider *fakeCloudProviderSession) ListCloudClusters(pageNumber int, pageSize int) ([]*CloudCluster, int, error) {
+	if !provider.authorized {
+		return nil, 0, ErrInvalidAccessKey
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	start, end := (pageNumber-1)*pageSize, pageNumber*pageSize
+	if start < 0 {
+		start = 0
+	}
+	if end > len(provider.Clusters) || pageSize <= 0 {
+		end = len(provider.Clusters)
+	}
+	if start > end {
+		start = end
+	}
+	return provider.Clusters[start:end], len(provider.Clusters), nil
+}
+
+// GetClusterKubeConfig get cluster kubeconfig by clusterID
+func (provider *fakeCloudProviderSession) GetClusterKubeConfig(clusterID string) (string, error) {
+	if !provider.authorized {
+		return "", ErrInvalidAccessKey
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	kubeConfig, ok := provider.KubeConfigs[clusterID]
+	if !ok {
+		return "", errors.Errorf("cloud cluster %s not found", clusterID)
+	}
+	return kubeConfig, nil
+}
+
+// GetClusterInfo retrieves cluster info by clusterID
+func (provider *fakeCloudProviderSession) GetClusterInfo(clusterID string) (*CloudCluster, error) {
+	if !provider.authorized {
+		return nil, ErrInvalidAccessKey
+	}
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	for _, cluster := range provider.Clusters {
+		if cluster.ID == clusterID {
+			return cluster, nil
+		}
+	}
+	return nil, errors.Errorf("cloud cluster %s not found", clusterID)
+}
+
+// CreateCloudCluster create cloud cluster, the cluster is running at once and its kubeconfig is empty
+func (provider *fakeCloudProviderSession) CreateCloudCluster(ctx context.Context, clusterName string, zone string, worker int, cpu int64, mem int64) (string, error) {
+	if !provider.authorized {
+		return "", ErrInvalidAccessKey
+	}
+	name := GetCloudClusterFullName(ProviderFake, clusterName)
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+	for _, cluster := range provider.Clusters {
+		if cluster.Name == clusterName {
+			return name, kerrors.NewAlreadyExists(schema.GroupResource{Resource: "cloudclusters"}, clusterName)
+		}
+	}
+	provider.Clusters = append(provider.Clusters, &CloudCluster{
+		Provider: ProviderFake,
+		ID:       name,
+		Name:     clusterName,
+		Zone:     zone,
+		ZoneID:   zone,
+		Labels:   map[string]string{},
+		Status:   "running",
+	})
+	provider.KubeConfigs[name] = ""
+	return name, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// GKETerraformProvider describes the cloud provider of the GKE clusters in gcp created by the terraform modules,
+// the GKE clusters created outside KubeVela are not listed
+type GKETerraformProvider struct {
+	*terraformClusterProvider
+}
+
+// NewGKETerraformProvider create gcp cloud provider, the access key id is the id of the gcp project
+// and the access key secret is the json key of the service account
+func NewGKETerraformProvider(accessKeyID string, accessKeySecret string, k8sClient client.Client) (*GKETerraformProvider, error) {
+	if accessKeyID == "" || accessKeySecret == "" {
+		return nil, errors.Wrapf(ErrInvalidAccessKey, "the project and the service account key of gcp are required")
+	}
+	credentials, err := yaml.Marshal(map[string]string{
+		"gcpCredentialsJSON": accessKeySecret,
+		"gcpProject":         accessKeyID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &GKETerraformProvider{terraformClusterProvider: &terraformClusterProvider{
+		k8sClient:     k8sClient,
+		provider:      ProviderGKE,
+		tfProvider:    "gcp",
+		clusterType:   "GKE",
+		modulePath:    "gcp/gke",
+		hashKey:       computeProviderHashKey(ProviderGKE, accessKeyID, accessKeySecret),
+		credentials:   string(credentials),
+		defaultRegion: "us-central1",
+		zoneToRegion:  gkeZoneToRegion,
+	}}, nil
+}
+
+// gkeZoneToRegion trims the zone suffix, e.g. us-central1-a is in the region us-central1
+func gkeZoneToRegion(zone string) string {
+	if strings.Count(zone, "-") < 2 {
+		return zone
+	}
+	return zone[:strings.LastIndex(zone, "-")]
+}
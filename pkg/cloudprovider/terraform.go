@@ -19,20 +19,35 @@ package cloudprovider
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"strings"
 
+	tftypes "github.com/oam-dev/terraform-controller/api/types"
 	types "github.com/oam-dev/terraform-controller/api/types/crossplane-runtime"
 	v1beta12 "github.com/oam-dev/terraform-controller/api/v1beta1"
 	"github.com/pkg/errors"
 	v12 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/utils/util"
 )
 
-func computeProviderHashKey(provider string, accessKeyID string, accessKeySecret string) string {
-	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join([]string{provider, accessKeyID, accessKeySecret}, "::"))))[:8] // #nosec
+const (
+	terraformModulesRemote = "https://github.com/kubevela-contrib/terraform-modules.git"
+
+	// terraformOutputClusterID is the output of the terraform module recording the id of the cluster
+	terraformOutputClusterID = "CLUSTER_ID"
+	// terraformOutputKubeConfig is the output of the terraform module recording the kubeconfig of the cluster
+	terraformOutputKubeConfig = "KUBECONFIG"
+)
+
+func computeProviderHashKey(provider string, keys ...string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(append([]string{provider}, keys...), "::"))))[:8] // #nosec
 }
 
 // GetCloudClusterFullName construct the full name of cloud cluster which will be used as the name of terraform configuration
@@ -40,8 +55,7 @@ func GetCloudClusterFullName(provider string, clusterName string) string {
 	return fmt.Sprintf("cloud-cluster-%s-%s", provider, clusterName)
 }
 
-func bootstrapTerraformProvider(ctx context.Context, k8sClient client.Client, ns string, provider string, tfProvider string, accessKeyID string, accessKeySecret string, region string) (string, error) {
-	hashKey := computeProviderHashKey(provider, accessKeyID, accessKeySecret)
+func bootstrapTerraformProvider(ctx context.Context, k8sClient client.Client, ns string, provider string, tfProvider string, hashKey string, credentials string, region string) (string, error) {
 	secretName := fmt.Sprintf("tf-provider-cred-%s-%s", provider, hashKey)
 	terraformProviderName := fmt.Sprintf("tf-provider-%s-%s", provider, hashKey)
 	secret := v12.Secret{
@@ -49,7 +63,7 @@ func bootstrapTerraformProvider(ctx context.Context, k8sClient client.Client, ns
 			Name:      secretName,
 			Namespace: ns,
 		},
-		StringData: map[string]string{"credentials": fmt.Sprintf("accessKeyID: %s\naccessKeySecret: %s\nsecurityToken:\n", accessKeyID, accessKeySecret)},
+		StringData: map[string]string{"credentials": credentials},
 		Type:       v12.SecretTypeOpaque,
 	}
 	var err error
@@ -92,3 +106,223 @@ func bootstrapTerraformProvider(ctx context.Context, k8sClient client.Client, ns
 	}
 	return terraformProviderName, nil
 }
+
+// createTerraformConfiguration creates the terraform configuration of the module which creates the cloud cluster
+func createTerraformConfiguration(ctx context.Context, k8sClient client.Client, provider string, clusterName string, terraformProviderName string, modulePath string, labels map[string]string, properties map[string]interface{}) (string, error) {
+	name := GetCloudClusterFullName(provider, clusterName)
+	ns := util.GetRuntimeNamespace()
+	bs, err := json.Marshal(properties)
+	if err != nil {
+		return name, errors.Wrapf(err, "failed to marshal cloud cluster app properties")
+	}
+	cfgLabels := map[string]string{CloudClusterCreatorLabelKey: provider}
+	for k, v := range labels {
+		cfgLabels[k] = v
+	}
+
+	cfg := v1beta12.Configuration{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      name,
+			Namespace: ns,
+			Labels:    cfgLabels,
+		},
+		Spec: v1beta12.ConfigurationSpec{
+			BaseConfigurationSpec: v1beta12.BaseConfigurationSpec{
+				ProviderReference: &types.Reference{
+					Name:      terraformProviderName,
+					Namespace: ns,
+				},
+			},
+			Remote:   terraformModulesRemote,
+			Variable: &runtime.RawExtension{Raw: bs},
+		},
+	}
+	cfg.Spec.Path = modulePath
+	cfg.Spec.WriteConnectionSecretToReference = &types.SecretReference{
+		Name:      name,
+		Namespace: ns,
+	}
+
+	if err = k8sClient.Create(ctx, &cfg); err != nil {
+		return name, errors.Wrapf(err, "failed to create cloud cluster terraform configuration")
+	}
+	return name, nil
+}
+
+// terraformClusterProvider serves the cloud clusters created by the terraform modules, the clusters are discovered
+// from the outputs of the terraform configurations instead of the API of the cloud, so only the clusters created
+// by KubeVela with the same credential can be listed and connected
+type terraformClusterProvider struct {
+	k8sClient   client.Client
+	provider    string
+	tfProvider  string
+	clusterType string
+	modulePath  string
+	hashKey     string
+	credentials string
+
+	defaultRegion string
+	zoneToRegion  func(zone string) string
+}
+
+// IsInvalidKey check if error is caused by the invalid access key
+func (provider *terraformClusterProvider) IsInvalidKey(err error) bool {
+	return errors.Is(err, ErrInvalidAccessKey)
+}
+
+// ErrInvalidAccessKey means the access key is malformed or rejected by the cloud
+var ErrInvalidAccessKey = errors.New("invalid access key")
+
+func (provider *terraformClusterProvider) listConfigurations(ctx context.Context) ([]v1beta12.Configuration, error) {
+	cfgs := v1beta12.ConfigurationList{}
+	if err := provider.k8sClient.List(ctx, &cfgs, client.InNamespace(util.GetRuntimeNamespace()), client.MatchingLabels{
+		CloudClusterCreatorLabelKey:    provider.provider,
+		CloudClusterCredentialLabelKey: provider.hashKey,
+	}); err != nil {
+		return nil, err
+	}
+	var available []v1beta12.Configuration
+	for _, cfg := range cfgs.Items {
+		if cfg.Status.Apply.State != tftypes.Available || cfg.DeletionTimestamp != nil {
+			continue
+		}
+		if _, ok := cfg.Status.Apply.Outputs[terraformOutputClusterID]; !ok {
+			continue
+		}
+		available = append(available, cfg)
+	}
+	return available, nil
+}
+
+func (provider *terraformClusterProvider) getConfiguration(ctx context.Context, clusterID string) (*v1beta12.Configuration, error) {
+	cfgs, err := provider.listConfigurations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, cfg := range cfgs {
+		if cfg.Status.Apply.Outputs[terraformOutputClusterID].Value == clusterID {
+			return &cfgs[i], nil
+		}
+	}
+	return nil, errors.Errorf("cloud cluster %s not found", clusterID)
+}
+
+func (provider *terraformClusterProvider) getKubeConfig(ctx context.Context, cfg *v1beta12.Configuration) (string, error) {
+	if cfg.Spec.WriteConnectionSecretToReference == nil {
+		return "", errors.Errorf("the connection secret of cloud cluster %s is not specified", cfg.Name)
+	}
+	secret := &v12.Secret{}
+	key := client.ObjectKey{Namespace: cfg.Spec.WriteConnectionSecretToReference.Namespace, Name: cfg.Spec.WriteConnectionSecretToReference.Name}
+	if err := provider.k8sClient.Get(ctx, key, secret); err != nil {
+		return "", errors.Wrapf(err, "failed to get the connection secret of cloud cluster %s", cfg.Name)
+	}
+	kubeConfig, ok := secret.Data[terraformOutputKubeConfig]
+	if !ok {
+		return "", errors.Errorf("kubeconfig not found in the connection secret of cloud cluster %s", cfg.Name)
+	}
+	return string(kubeConfig), nil
+}
+
+func (provider *terraformClusterProvider) convertCloudCluster(ctx context.Context, cfg *v1beta12.Configuration) *CloudCluster {
+	cluster := &CloudCluster{
+		Provider: provider.provider,
+		ID:       cfg.Status.Apply.Outputs[terraformOutputClusterID].Value,
+		Name:     strings.TrimPrefix(cfg.Name, GetCloudClusterFullName(provider.provider, "")),
+		Type:     provider.clusterType,
+		Labels:   map[string]string{},
+		Status:   string(cfg.Status.Apply.State),
+	}
+	var variables map[string]interface{}
+	if cfg.Spec.Variable != nil {
+		if err := json.Unmarshal(cfg.Spec.Variable.Raw, &variables); err == nil {
+			if zone, ok := variables["zone"].(string); ok {
+				cluster.Zone, cluster.ZoneID = zone, zone
+			}
+		}
+	}
+	cluster.RegionID = provider.region(cluster.Zone)
+	if kubeConfig, err := provider.getKubeConfig(ctx, cfg); err == nil {
+		if config, err := clientcmd.Load([]byte(kubeConfig)); err == nil {
+			for _, c := range config.Clusters {
+				cluster.APIServerURL = c.Server
+				break
+			}
+		}
+	}
+	return cluster
+}
+
+func (provider *terraformClusterProvider) region(zone string) string {
+	if zone == "" {
+		return provider.defaultRegion
+	}
+	return provider.zoneToRegion(zone)
+}
+
+// ListCloudClusters list the clusters created by the terraform modules with page info, return clusters, total count and error
+func (provider *terraformClusterProvider) ListCloudClusters(pageNumber int, pageSize int) ([]*CloudCluster, int, error) {
+	ctx := context.Background()
+	cfgs, err := provider.listConfigurations(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	start, end := (pageNumber-1)*pageSize, pageNumber*pageSize
+	if start < 0 {
+		start = 0
+	}
+	if end > len(cfgs) || pageSize <= 0 {
+		end = len(cfgs)
+	}
+	var clusters []*CloudCluster
+	for i := start; i < end; i++ {
+		clusters = append(clusters, provider.convertCloudCluster(ctx, &cfgs[i]))
+	}
+	return clusters, len(cfgs), nil
+}
+
+// GetClusterKubeConfig get cluster kubeconfig by clusterID
+func (provider *terraformClusterProvider) GetClusterKubeConfig(clusterID string) (string, error) {
+	ctx := context.Background()
+	cfg, err := provider.getConfiguration(ctx, clusterID)
+	if err != nil {
+		return "", err
+	}
+	return provider.getKubeConfig(ctx, cfg)
+}
+
+// GetClusterInfo retrieves cluster info by clusterID
+func (provider *terraformClusterProvider) GetClusterInfo(clusterID string) (*CloudCluster, error) {
+	ctx := context.Background()
+	cfg, err := provider.getConfiguration(ctx, clusterID)
+	if err != nil {
+		return nil, err
+	}
+	return provider.convertCloudCluster(ctx, cfg), nil
+}
+
+// CreateCloudCluster create cloud cluster
+func (provider *terraformClusterProvider) CreateCloudCluster(ctx context.Context, clusterName string, zone string, worker int, cpu int64, mem int64) (string, error) {
+	region := provider.region(zone)
+	terraformProviderName, err := bootstrapTerraformProvider(ctx, provider.k8sClient, util.GetRuntimeNamespace(), provider.provider, provider.tfProvider,
+		computeProviderHashKey(provider.provider, provider.credentials, region), provider.credentials, region)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to bootstrap terraform provider")
+	}
+	properties := map[string]interface{}{
+		"cluster_name": clusterName,
+	}
+	if zone != "" {
+		properties["zone"] = zone
+	}
+	if cpu != 0 {
+		properties["cpu_core_count"] = cpu
+	}
+	if mem != 0 {
+		properties["memory_size"] = mem
+	}
+	if worker != 0 {
+		properties["node_count"] = worker
+	}
+	labels := map[string]string{CloudClusterCredentialLabelKey: provider.hashKey}
+	return createTerraformConfiguration(ctx, provider.k8sClient, provider.provider, clusterName, terraformProviderName, provider.modulePath, labels, properties)
+}
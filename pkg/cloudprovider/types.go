@@ -19,6 +19,12 @@ package cloudprovider
 const (
 	// ProviderAliyun cloud provider aliyun
 	ProviderAliyun = "aliyun"
+	// ProviderEKS cloud provider aws, the clusters are Elastic Kubernetes Service clusters created by KubeVela
+	ProviderEKS = "eks"
+	// ProviderAKS cloud provider azure, the clusters are Azure Kubernetes Service clusters created by KubeVela
+	ProviderAKS = "aks"
+	// ProviderGKE cloud provider gcp, the clusters are Google Kubernetes Engine clusters created by KubeVela
+	ProviderGKE = "gke"
 )
 
 // CloudCluster describes the interface that cloud provider should return
@@ -65,6 +65,7 @@ func GetRegisteredClusters(c client.Client) ([]types.Cluster, error) {
 			Type:     clusterSecret.GetLabels()[v1alpha1.LabelKeyClusterCredentialType],
 			EndPoint: endpoint,
 			Accepted: true,
+			Labels:   multicluster.GetClusterLabels(clusterSecret.DeepCopy()),
 		})
 	}
 
@@ -87,6 +88,7 @@ func GetRegisteredClusters(c client.Client) ([]types.Cluster, error) {
 				Type:     "OCM ManagedServiceAccount",
 				EndPoint: "-",
 				Accepted: cluster.Spec.HubAcceptsClient,
+				Labels:   cluster.Labels,
 			})
 		}
 	}
@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhealth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+const (
+	defaultProbeInterval           = time.Minute
+	defaultCredentialExpiryWarning = 7 * 24 * time.Hour
+	probeTimeout                   = 30 * time.Second
+	// lastSeenRefreshProbes is the number of probe intervals after which the last seen time of a reachable cluster
+	// is recorded even if nothing else changes
+	lastSeenRefreshProbes = 10
+)
+
+// Reconcile event reasons.
+const (
+	reasonClusterHealthy            = "ClusterHealthy"
+	reasonClusterUnhealthy          = "ClusterUnhealthy"
+	reasonClusterCredentialExpiring = "ClusterCredentialExpiring"
+	reasonClusterCredentialExpired  = "ClusterCredentialExpired"
+)
+
+// Setup adds a controller that periodically probes the health of the managed clusters.
+func Setup(mgr ctrl.Manager, args controller.Args) error {
+	// the nodes of the managed clusters must not be read from the cache of the hub cluster
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return errors.Wrap(err, "cannot create client for cluster health probe")
+	}
+	interval := args.ClusterHealthProbeInterval
+	if interval <= 0 {
+		interval = defaultProbeInterval
+	}
+	expiryWarning := args.ClusterCredentialExpiryWarning
+	if expiryWarning <= 0 {
+		expiryWarning = defaultCredentialExpiryWarning
+	}
+	return mgr.Add(&Reconciler{
+		client:        c,
+		config:        mgr.GetConfig(),
+		record:        event.NewAPIRecorder(mgr.GetEventRecorderFor("vela/cluster-health")),
+		interval:      interval,
+		expiryWarning: expiryWarning,
+	})
+}
+
+// A Reconciler probes the managed clusters through cluster-gateway and records the health status on the cluster secrets.
+type Reconciler struct {
+	client   client.Client
+	config   *rest.Config
+	record   event.Recorder
+	interval time.Duration
+	// expiryWarning is how long ahead of the expiration of the cluster credential to warn
+	expiryWarning time.Duration
+}
+
+// Start probes the clusters periodically until the context is done.
+func (r *Reconciler) Start(ctx context.Context) error {
+	klog.InfoS("Start probing the health of managed clusters", "interval", r.interval)
+	wait.UntilWithContext(ctx, r.ProbeClusters, r.interval)
+	return nil
+}
+
+// NeedLeaderElection makes only the leader probe the clusters.
+func (r *Reconciler) NeedLeaderElection() bool {
+	return true
+}
+
+// ProbeClusters probes all the managed clusters concurrently.
+func (r *Reconciler) ProbeClusters(ctx context.Context) {
+	secrets, err := multicluster.ListExistingClusterSecrets(ctx, r.client)
+	if err != nil {
+		klog.ErrorS(err, "Failed to list managed clusters to probe")
+		return
+	}
+	var wg sync.WaitGroup
+	for i := range secrets {
+		wg.Add(1)
+		go func(secret *corev1.Secret) {
+			defer wg.Done()
+			if err := r.probeCluster(ctx, secret); err != nil {
+				klog.ErrorS(err, "Failed to probe the health of cluster", "cluster", secret.Name)
+			}
+		}(secrets[i].DeepCopy())
+	}
+	wg.Wait()
+}
+
+func (r *Reconciler) probeCluster(ctx context.Context, secret *corev1.Secret) error {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	last, err := multicluster.GetClusterHealthStatus(secret)
+	if err != nil {
+		klog.ErrorS(err, "Ignore the invalid health status of cluster", "cluster", secret.Name)
+	}
+	lastCredentialState := multicluster.ClusterCredentialValid
+	if last != nil {
+		lastCredentialState = last.CredentialState
+	}
+	status := multicluster.ProbeClusterHealth(ctx, r.client, r.config, secret.Name, last)
+	status.CredentialState = r.checkCredentialExpiration(secret, lastCredentialState)
+	healthy := status.IsHealthy()
+	if healthy {
+		metrics.ClusterHealthyGauge.WithLabelValues(secret.Name).Set(1)
+	} else {
+		metrics.ClusterHealthyGauge.WithLabelValues(secret.Name).Set(0)
+	}
+	if last == nil || last.IsHealthy() != healthy {
+		metrics.ClusterHealthChangeCounter.WithLabelValues(secret.Name, fmt.Sprint(healthy)).Inc()
+		if healthy {
+			r.record.Event(secret, event.Normal(reasonClusterHealthy, fmt.Sprintf("Cluster %s is reachable", secret.Name)))
+		} else {
+			cond := status.GetCondition(multicluster.ClusterConditionReachable)
+			r.record.Event(secret, event.Warning(reasonClusterUnhealthy, errors.New(cond.Message)))
+		}
+	}
+	if !multicluster.IsClusterHealthStatusChanged(last, status, lastSeenRefreshProbes*r.interval) {
+		return nil
+	}
+	patch := client.MergeFrom(secret.DeepCopy())
+	if err := multicluster.SetClusterHealthStatus(secret, status); err != nil {
+		return err
+	}
+	return r.client.Patch(ctx, secret, patch)
+}
+
+// checkCredentialExpiration reports the expiration of the cluster credential and returns the state of the expiration,
+// the warning is only emitted when the state changes from the last one
+func (r *Reconciler) checkCredentialExpiration(secret *corev1.Secret, last multicluster.ClusterCredentialState) multicluster.ClusterCredentialState {
+	exp, err := multicluster.GetClusterCredentialExpiration(secret)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the credential expiration of cluster", "cluster", secret.Name)
+		return last
+	}
+	if exp == nil {
+		metrics.ClusterCredentialExpirationGauge.DeleteLabelValues(secret.Name)
+		return multicluster.ClusterCredentialValid
+	}
+	metrics.ClusterCredentialExpirationGauge.WithLabelValues(secret.Name).Set(float64(exp.Unix()))
+	state := multicluster.ClusterCredentialValid
+	switch remaining := time.Until(*exp); {
+	case remaining <= 0:
+		state = multicluster.ClusterCredentialExpired
+	case remaining <= r.expiryWarning:
+		state = multicluster.ClusterCredentialExpiring
+	}
+	if state == last {
+		return state
+	}
+	switch state {
+	case multicluster.ClusterCredentialExpired:
+		r.record.Event(secret, event.Warning(reasonClusterCredentialExpired,
+			errors.Errorf("the credential of cluster %s expired at %s, update it by `vela cluster update-credential`", secret.Name, exp.Format(time.RFC3339))))
+	case multicluster.ClusterCredentialExpiring:
+		r.record.Event(secret, event.Warning(reasonClusterCredentialExpiring,
+			errors.Errorf("the credential of cluster %s expires at %s, update it by `vela cluster update-credential`", secret.Name, exp.Format(time.RFC3339))))
+	}
+	return state
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterhealth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(obj runtime.Object, e event.Event) {
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) WithAnnotations(keysAndValues ...string) event.Recorder {
+	return r
+}
+
+func TestProbeClusters(t *testing.T) {
+	oldClusterGatewaySecretNamespace := multicluster.ClusterGatewaySecretNamespace
+	multicluster.ClusterGatewaySecretNamespace = "vela-system"
+	defer func() {
+		multicluster.ClusterGatewaySecretNamespace = oldClusterGatewaySecretNamespace
+	}()
+	r := require.New(t)
+	reachable := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !reachable || !strings.HasSuffix(req.URL.Path, "/proxy/version") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"gitVersion":"v1.20.4"}`))
+	}))
+	defer server.Close()
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Name:      "probe-cluster",
+		Namespace: "vela-system",
+		Labels:    map[string]string{v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeX509Certificate)},
+	}}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(secret).Build()
+	rec := &recorder{}
+	reconciler := &Reconciler{client: cli, config: &rest.Config{Host: server.URL}, record: rec, interval: time.Minute}
+
+	getStatus := func() *multicluster.ClusterHealthStatus {
+		s := &corev1.Secret{}
+		r.NoError(cli.Get(context.Background(), client.ObjectKeyFromObject(secret), s))
+		status, err := multicluster.GetClusterHealthStatus(s)
+		r.NoError(err)
+		r.NotNil(status)
+		return status
+	}
+
+	reconciler.ProbeClusters(context.Background())
+	r.True(getStatus().IsHealthy())
+	r.Equal("v1.20.4", getStatus().KubernetesVersion)
+	r.Equal(1, len(rec.events))
+	r.Equal(event.Reason(reasonClusterHealthy), rec.events[0].Reason)
+	r.Equal(float64(1), testutil.ToFloat64(metrics.ClusterHealthyGauge.WithLabelValues("probe-cluster")))
+
+	// no event is emitted and the secret is not patched if the health state is not changed
+	getResourceVersion := func() string {
+		s := &corev1.Secret{}
+		r.NoError(cli.Get(context.Background(), client.ObjectKeyFromObject(secret), s))
+		return s.ResourceVersion
+	}
+	resourceVersion := getResourceVersion()
+	reconciler.ProbeClusters(context.Background())
+	r.Equal(1, len(rec.events))
+	r.Equal(resourceVersion, getResourceVersion())
+
+	reachable = false
+	reconciler.ProbeClusters(context.Background())
+	r.False(getStatus().IsHealthy())
+	r.Equal(2, len(rec.events))
+	r.Equal(event.Reason(reasonClusterUnhealthy), rec.events[1].Reason)
+	r.Equal(float64(0), testutil.ToFloat64(metrics.ClusterHealthyGauge.WithLabelValues("probe-cluster")))
+	r.Equal(float64(1), testutil.ToFloat64(metrics.ClusterHealthChangeCounter.WithLabelValues("probe-cluster", "false")))
+}
+
+func TestCheckCredentialExpiration(t *testing.T) {
+	r := require.New(t)
+	rec := &recorder{}
+	reconciler := &Reconciler{record: rec, expiryWarning: 24 * time.Hour}
+	newSecret := func(exp time.Time) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:        "expiring-cluster",
+			Annotations: map[string]string{oam.AnnotationClusterCredentialExpiration: exp.Format(time.RFC3339)},
+		}}
+	}
+
+	exp := time.Now().Add(30 * 24 * time.Hour)
+	state := reconciler.checkCredentialExpiration(newSecret(exp), multicluster.ClusterCredentialValid)
+	r.Equal(multicluster.ClusterCredentialValid, state)
+	r.Equal(0, len(rec.events))
+	r.Equal(float64(exp.Unix()), testutil.ToFloat64(metrics.ClusterCredentialExpirationGauge.WithLabelValues("expiring-cluster")))
+
+	state = reconciler.checkCredentialExpiration(newSecret(time.Now().Add(time.Hour)), state)
+	r.Equal(multicluster.ClusterCredentialExpiring, state)
+	r.Equal(1, len(rec.events))
+	r.Equal(event.Reason(reasonClusterCredentialExpiring), rec.events[0].Reason)
+
+	// the warning is not repeated until the state changes
+	state = reconciler.checkCredentialExpiration(newSecret(time.Now().Add(time.Hour)), state)
+	r.Equal(multicluster.ClusterCredentialExpiring, state)
+	r.Equal(1, len(rec.events))
+
+	state = reconciler.checkCredentialExpiration(newSecret(time.Now().Add(-time.Hour)), state)
+	r.Equal(multicluster.ClusterCredentialExpired, state)
+	r.Equal(2, len(rec.events))
+	r.Equal(event.Reason(reasonClusterCredentialExpired), rec.events[1].Reason)
+}
@@ -19,20 +19,21 @@ package rollout
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/event"
-	kruisev1 "github.com/openkruise/kruise-api/apps/v1alpha1"
-	apps "k8s.io/api/apps/v1"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/pkg/controller/common"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/traffic"
 	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/workloads"
 	"github.com/oam-dev/kubevela/pkg/oam"
 )
@@ -40,6 +41,9 @@ import (
 // the default time to check back if we still have work to do
 const rolloutReconcileRequeueTime = 5 * time.Second
 
+// the default time to keep the source after the traffic is switched to the target in a blue-green rollout
+const defaultScaleDownDelaySeconds = 30
+
 // Controller is the controller that controls the rollout plan resource
 type Controller struct {
 	client           client.Client
@@ -126,6 +130,11 @@ func (r *Controller) Reconcile(ctx context.Context) (res reconcile.Result, statu
 			if err != nil {
 				r.rolloutStatus.RolloutFailing(err.Error())
 			} else if initialized {
+				// the preview service has to select the target before its pods come up
+				if err := r.previewTarget(ctx); err != nil {
+					r.rolloutStatus.RolloutRetry(err.Error())
+					return
+				}
 				r.rolloutStatus.StateTransition(v1alpha1.RollingInitializedEvent)
 			}
 		}
@@ -134,11 +143,32 @@ func (r *Controller) Reconcile(ctx context.Context) (res reconcile.Result, statu
 		r.reconcileBatchInRolling(ctx, workloadController)
 
 	case v1alpha1.RolloutFailingState, v1alpha1.RolloutAbandoningState, v1alpha1.RolloutDeletingState:
+		// roll the traffic back to the source before we finalize the workloads
+		if err := r.routeTraffic(ctx, 0); err != nil {
+			r.rolloutStatus.RolloutRetry(err.Error())
+			return
+		}
 		if succeed := workloadController.Finalize(ctx, false); succeed {
 			r.finalizeRollout(ctx)
 		}
 
 	case v1alpha1.FinalisingState:
+		// all the traffic goes to the target after the rollout succeeds
+		if err := r.routeTraffic(ctx, 100); err != nil {
+			r.rolloutStatus.RolloutRetry(err.Error())
+			return
+		}
+		// keep the source around for a while so that we can switch the traffic back at once
+		if remaining := r.scaleDownDelayRemaining(); remaining > 0 {
+			r.rolloutStatus.RolloutRetry(fmt.Sprintf("waiting %s before scaling down the source",
+				remaining.Round(time.Second)))
+			return
+		}
+		// the stable service takes over the target before the source is scaled down
+		if err := r.resetTraffic(ctx); err != nil {
+			r.rolloutStatus.RolloutRetry(err.Error())
+			return
+		}
 		if succeed := workloadController.Finalize(ctx, true); succeed {
 			r.finalizeRollout(ctx)
 		}
@@ -186,6 +216,14 @@ func (r *Controller) reconcileBatchInRolling(ctx context.Context, workloadContro
 		if err != nil {
 			r.rolloutStatus.RolloutFailing(err.Error())
 		} else if verified {
+			// shift the traffic only after the pods in the batch are ready to serve,
+			// the blue-green rollout switches all the traffic at once when it is finalized
+			if !r.isBlueGreen() {
+				if err := r.routeTraffic(ctx, r.batchTrafficWeight()); err != nil {
+					r.rolloutStatus.RolloutRetry(err.Error())
+					return
+				}
+			}
 			r.rolloutStatus.StateTransition(v1alpha1.OneBatchAvailableEvent)
 		}
 
@@ -322,65 +360,139 @@ func (r *Controller) finalizeRollout(ctx context.Context) {
 	r.rolloutStatus.StateTransition(v1alpha1.RollingFinalizedEvent)
 }
 
-// GetWorkloadController pick the right workload controller to work on the workload
-func (r *Controller) GetWorkloadController() (workloads.WorkloadController, error) {
-	kind := r.targetWorkload.GetObjectKind().GroupVersionKind().Kind
-	target := types.NamespacedName{
-		Namespace: r.targetWorkload.GetNamespace(),
-		Name:      r.targetWorkload.GetName(),
+// routeTraffic routes the percentage of the traffic to the target if the traffic routing is set
+func (r *Controller) routeTraffic(ctx context.Context, weight int32) error {
+	if r.rolloutStatus.TrafficWeight != nil && *r.rolloutStatus.TrafficWeight == weight {
+		return nil
 	}
-	var source types.NamespacedName
-	if r.sourceWorkload != nil {
-		source.Namespace = r.sourceWorkload.GetNamespace()
-		source.Name = r.sourceWorkload.GetName()
-	}
-
-	if r.targetWorkload.GroupVersionKind().Group == kruisev1.GroupVersion.Group {
-		// check if the target workload is CloneSet
-		if r.targetWorkload.GetKind() == reflect.TypeOf(kruisev1.CloneSet{}).Name() {
-			// check whether current rollout plan is for workload rolling or scaling
-			if r.sourceWorkload != nil {
-				klog.InfoS("using cloneset rollout controller for this rolloutplan", "source workload name", source.Name, "namespace",
-					source.Namespace, "target workload name", target.Name, "namespace",
-					target.Namespace)
-				return workloads.NewCloneSetRolloutController(r.client, r.recorder, r.parentController,
-					r.rolloutSpec, r.rolloutStatus, target), nil
-			}
-			klog.InfoS("using cloneset scale controller for this rolloutplan", "target workload name", target.Name, "namespace",
-				target.Namespace)
-			return workloads.NewCloneSetScaleController(r.client, r.recorder, r.parentController,
-				r.rolloutSpec, r.rolloutStatus, target), nil
-		}
+	router, err := r.newRouter()
+	if err != nil {
+		return err
+	}
+	if router == nil {
+		return nil
+	}
+	if err := router.SetWeight(ctx, weight); err != nil {
+		klog.ErrorS(err, "failed to shift the traffic", "weight", weight)
+		r.recorder.Event(r.parentController, event.Warning("Failed to shift traffic", err))
+		return err
 	}
+	klog.InfoS("successfully shifted the traffic", "weight", weight)
+	r.recorder.Event(r.parentController, event.Normal("Traffic shifted",
+		fmt.Sprintf("%d%% of the traffic is routed to the target", weight)))
+	r.rolloutStatus.TrafficWeight = pointer.Int32Ptr(weight)
+	now := metav1.Now()
+	r.rolloutStatus.TrafficShiftedTime = &now
+	return nil
+}
 
-	if r.targetWorkload.GroupVersionKind().Group == apps.GroupName {
-		// check if the target workload is Deployment
-		if r.targetWorkload.GetKind() == reflect.TypeOf(apps.Deployment{}).Name() {
-			// check whether current rollout plan is for workload rolling or scaling
-			if r.sourceWorkload != nil {
-				klog.InfoS("using deployment rollout controller for this rolloutplan", "source workload name", source.Name, "namespace",
-					source.Namespace, "target workload name", target.Name, "namespace",
-					target.Namespace)
-				return workloads.NewDeploymentRolloutController(r.client, r.recorder, r.parentController,
-					r.rolloutSpec, r.rolloutStatus, source, target), nil
-			}
-			klog.InfoS("using deployment scale controller for this rolloutplan", "target workload name", target.Name, "namespace",
-				target.Namespace)
-			return workloads.NewDeploymentScaleController(r.client, r.recorder, r.parentController,
-				r.rolloutSpec, r.rolloutStatus, target), nil
-		}
+// resetTraffic points the stable service to the pods of the target and routes all the traffic back to it, so that the
+// traffic routing is ready for the next rollout. It is idempotent as the finalizing may take several reconciles.
+func (r *Controller) resetTraffic(ctx context.Context) error {
+	if r.rolloutSpec.TrafficRouting == nil {
+		return nil
+	}
+	stable := traffic.NewServiceRouter(r.client, r.targetWorkload.GetNamespace(), r.rolloutSpec.TrafficRouting.StableService,
+		nil, workloads.PodTemplateLabels(r.targetWorkload))
+	if err := stable.SetWeight(ctx, 100); err != nil {
+		return errors.WithMessage(err, "failed to point the stable service to the target")
+	}
+	router, err := traffic.NewRouter(r.client, r.targetWorkload.GetNamespace(), r.rolloutSpec.TrafficRouting)
+	if err != nil {
+		return err
+	}
+	if err := router.Reset(ctx); err != nil {
+		klog.ErrorS(err, "failed to reset the traffic routing")
+		r.recorder.Event(r.parentController, event.Warning("Failed to reset traffic routing", err))
+		return err
+	}
+	return nil
+}
 
-		// check if the target workload is StatefulSet
-		if r.targetWorkload.GetKind() == reflect.TypeOf(apps.StatefulSet{}).Name() {
-			// check whether current rollout plan is for workload rolling or scaling
-			if r.sourceWorkload != nil {
-				return workloads.NewStatefulSetRolloutController(r.client, r.recorder, r.parentController,
-					r.rolloutSpec, r.rolloutStatus, target), nil
-			}
-			return workloads.NewStatefulSetScaleController(r.client, r.recorder, r.parentController,
-				r.rolloutSpec, r.rolloutStatus, target), nil
-		}
+// newRouter returns the router of the traffic, the blue-green rollout switches the selector of the active service
+// if there is no traffic routing. It returns nil if the traffic is not routed by the rollout.
+func (r *Controller) newRouter() (traffic.Router, error) {
+	if r.rolloutSpec.TrafficRouting != nil {
+		return traffic.NewRouter(r.client, r.targetWorkload.GetNamespace(), r.rolloutSpec.TrafficRouting)
+	}
+	if r.isBlueGreen() && r.rolloutSpec.BlueGreen != nil && r.rolloutSpec.BlueGreen.ActiveService != "" &&
+		r.sourceWorkload != nil {
+		return traffic.NewServiceRouter(r.client, r.targetWorkload.GetNamespace(), r.rolloutSpec.BlueGreen.ActiveService,
+			workloads.PodTemplateLabels(r.sourceWorkload), workloads.PodTemplateLabels(r.targetWorkload)), nil
+	}
+	return nil, nil
+}
+
+// previewTarget points the preview service of the blue-green rollout to the target
+func (r *Controller) previewTarget(ctx context.Context) error {
+	if !r.isBlueGreen() || r.rolloutSpec.BlueGreen == nil || r.rolloutSpec.BlueGreen.PreviewService == "" ||
+		r.sourceWorkload == nil {
+		return nil
+	}
+	router := traffic.NewServiceRouter(r.client, r.targetWorkload.GetNamespace(), r.rolloutSpec.BlueGreen.PreviewService,
+		workloads.PodTemplateLabels(r.sourceWorkload), workloads.PodTemplateLabels(r.targetWorkload))
+	if err := router.SetWeight(ctx, 100); err != nil {
+		klog.ErrorS(err, "failed to point the preview service to the target",
+			"service", r.rolloutSpec.BlueGreen.PreviewService)
+		r.recorder.Event(r.parentController, event.Warning("Failed to preview the target", err))
+		return err
+	}
+	return nil
+}
+
+// scaleDownDelayRemaining returns how long the source of the blue-green rollout still has to be kept
+// after all the traffic is switched to the target
+func (r *Controller) scaleDownDelayRemaining() time.Duration {
+	if !r.isBlueGreen() || r.rolloutStatus.TrafficShiftedTime == nil {
+		return 0
+	}
+	delay := time.Duration(defaultScaleDownDelaySeconds) * time.Second
+	if r.rolloutSpec.BlueGreen != nil && r.rolloutSpec.BlueGreen.ScaleDownDelaySeconds != nil {
+		delay = time.Duration(*r.rolloutSpec.BlueGreen.ScaleDownDelaySeconds) * time.Second
+	}
+	return time.Until(r.rolloutStatus.TrafficShiftedTime.Add(delay))
+}
+
+func (r *Controller) isBlueGreen() bool {
+	return r.rolloutSpec.RolloutStrategy == v1alpha1.BlueGreenRolloutStrategyType
+}
+
+// batchTrafficWeight returns the traffic weight of the current batch,
+// which is the percentage of the upgraded pods if not specified
+func (r *Controller) batchTrafficWeight() int32 {
+	batch := r.rolloutSpec.RolloutBatches[r.rolloutStatus.CurrentBatch]
+	if batch.TrafficWeight != nil {
+		return *batch.TrafficWeight
+	}
+	if r.rolloutStatus.RolloutTargetSize <= 0 {
+		return 100
+	}
+	weight := r.rolloutStatus.UpgradedReplicas * 100 / r.rolloutStatus.RolloutTargetSize
+	if weight > 100 {
+		weight = 100
 	}
+	return weight
+}
 
-	return nil, fmt.Errorf("the workload kind `%s` is not supported", kind)
+// GetWorkloadController pick the right workload controller registered for the kind of the target workload
+func (r *Controller) GetWorkloadController() (workloads.WorkloadController, error) {
+	args := workloads.ControllerArgs{
+		Client:           r.client,
+		Recorder:         r.recorder,
+		ParentController: r.parentController,
+		RolloutSpec:      r.rolloutSpec,
+		RolloutStatus:    r.rolloutStatus,
+		Target: types.NamespacedName{
+			Namespace: r.targetWorkload.GetNamespace(),
+			Name:      r.targetWorkload.GetName(),
+		},
+	}
+	// the rollout plan scales the target if there is no source workload
+	if r.sourceWorkload != nil {
+		args.Source = &types.NamespacedName{
+			Namespace: r.sourceWorkload.GetNamespace(),
+			Name:      r.sourceWorkload.GetName(),
+		}
+	}
+	return workloads.NewController(r.targetWorkload.GroupVersionKind(), args)
 }
@@ -17,11 +17,24 @@ limitations under the License.
 package rollout
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/common/rollout/traffic"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 func Test_TryMovingToNextBatch(t *testing.T) {
@@ -63,3 +76,142 @@ func Test_TryMovingToNextBatch(t *testing.T) {
 		})
 	}
 }
+
+func Test_BatchTrafficWeight(t *testing.T) {
+	tests := map[string]struct {
+		rolloutSpec   *v1alpha1.RolloutPlan
+		rolloutStatus *v1alpha1.RolloutStatus
+		wantWeight    int32
+	}{
+		"use the weight of the batch": {
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{TrafficWeight: pointer.Int32Ptr(10)}},
+			},
+			rolloutStatus: &v1alpha1.RolloutStatus{
+				RolloutTargetSize: 10,
+				UpgradedReplicas:  5,
+			},
+			wantWeight: 10,
+		},
+		"follow the upgraded replicas": {
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{}, {}},
+			},
+			rolloutStatus: &v1alpha1.RolloutStatus{
+				CurrentBatch:      1,
+				RolloutTargetSize: 8,
+				UpgradedReplicas:  2,
+			},
+			wantWeight: 25,
+		},
+		"no target size": {
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{}},
+			},
+			rolloutStatus: &v1alpha1.RolloutStatus{},
+			wantWeight:    100,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &Controller{
+				rolloutSpec:   tt.rolloutSpec,
+				rolloutStatus: tt.rolloutStatus,
+			}
+			if got := r.batchTrafficWeight(); got != tt.wantWeight {
+				t.Errorf("\n%s\nweight miss match: want weight `%d`, got weight:`%d`\n", name, tt.wantWeight, got)
+			}
+		})
+	}
+}
+
+func Test_ScaleDownDelayRemaining(t *testing.T) {
+	justShifted := metav1.Now()
+	longAgo := metav1.NewTime(time.Now().Add(-time.Hour))
+	tests := map[string]struct {
+		rolloutSpec   *v1alpha1.RolloutPlan
+		rolloutStatus *v1alpha1.RolloutStatus
+		wantWaiting   bool
+	}{
+		"not a blue-green rollout": {
+			rolloutSpec:   &v1alpha1.RolloutPlan{},
+			rolloutStatus: &v1alpha1.RolloutStatus{TrafficShiftedTime: &justShifted},
+			wantWaiting:   false,
+		},
+		"the traffic is not shifted": {
+			rolloutSpec:   &v1alpha1.RolloutPlan{RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType},
+			rolloutStatus: &v1alpha1.RolloutStatus{},
+			wantWaiting:   false,
+		},
+		"use the default delay": {
+			rolloutSpec:   &v1alpha1.RolloutPlan{RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType},
+			rolloutStatus: &v1alpha1.RolloutStatus{TrafficShiftedTime: &justShifted},
+			wantWaiting:   true,
+		},
+		"the delay has passed": {
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+				BlueGreen:       &v1alpha1.BlueGreenStrategy{ScaleDownDelaySeconds: pointer.Int32Ptr(60)},
+			},
+			rolloutStatus: &v1alpha1.RolloutStatus{TrafficShiftedTime: &longAgo},
+			wantWaiting:   false,
+		},
+		"scale down right away": {
+			rolloutSpec: &v1alpha1.RolloutPlan{
+				RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+				BlueGreen:       &v1alpha1.BlueGreenStrategy{ScaleDownDelaySeconds: pointer.Int32Ptr(0)},
+			},
+			rolloutStatus: &v1alpha1.RolloutStatus{TrafficShiftedTime: &justShifted},
+			wantWaiting:   false,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &Controller{
+				rolloutSpec:   tt.rolloutSpec,
+				rolloutStatus: tt.rolloutStatus,
+			}
+			if got := r.scaleDownDelayRemaining() > 0; got != tt.wantWaiting {
+				t.Errorf("\n%s\nwaiting miss match: want waiting `%t`, got waiting:`%t`\n", name, tt.wantWaiting, got)
+			}
+		})
+	}
+}
+
+func Test_ResetTraffic(t *testing.T) {
+	ctx := context.Background()
+	stable := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "stable"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "demo", "version": "v1"}},
+	}
+	canaryIngress := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: traffic.CanaryIngressName("ingress")}}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(stable, canaryIngress).Build()
+	target := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"namespace": "default", "name": "demo-v2"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "demo", "version": "v2"}},
+		}},
+	}}
+	r := &Controller{
+		client:         cli,
+		recorder:       event.NewNopRecorder(),
+		targetWorkload: target,
+		rolloutSpec: &v1alpha1.RolloutPlan{TrafficRouting: &v1alpha1.TrafficRouting{
+			StableService: "stable",
+			CanaryService: "canary",
+			Nginx:         &v1alpha1.NginxTrafficRouting{Ingress: "ingress"},
+		}},
+		rolloutStatus: &v1alpha1.RolloutStatus{TrafficWeight: pointer.Int32Ptr(100)},
+	}
+	// resetting twice works as the finalizing may take several reconciles
+	for i := 0; i < 2; i++ {
+		require.NoError(t, r.resetTraffic(ctx))
+		svc := &corev1.Service{}
+		require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "stable"}, svc))
+		require.Equal(t, map[string]string{"app": "demo", "version": "v2"}, svc.Spec.Selector)
+		err := cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: traffic.CanaryIngressName("ingress")}, &networkingv1.Ingress{})
+		require.True(t, apierrors.IsNotFound(err))
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// VirtualServiceGVK is the group version kind of the Istio VirtualService
+var VirtualServiceGVK = schema.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "VirtualService"}
+
+// istioRouter shifts the traffic by rewriting the destinations of the http routes in the VirtualService
+type istioRouter struct {
+	client    client.Client
+	namespace string
+	routing   *v1alpha1.TrafficRouting
+}
+
+func (r *istioRouter) SetWeight(ctx context.Context, weight int32) error {
+	return r.updateRoutes(ctx, weight, false)
+}
+
+// Reset routes the traffic of the managed destinations to the stable service and removes the canary destination
+func (r *istioRouter) Reset(ctx context.Context) error {
+	return r.updateRoutes(ctx, 0, true)
+}
+
+func (r *istioRouter) updateRoutes(ctx context.Context, weight int32, removeCanary bool) error {
+	vs := &unstructured.Unstructured{}
+	vs.SetGroupVersionKind(VirtualServiceGVK)
+	name := r.routing.Istio.VirtualService
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: name}, vs); err != nil {
+		return errors.Wrapf(err, "failed to get the virtual service %s", name)
+	}
+	httpRoutes, _, err := unstructured.NestedSlice(vs.Object, "spec", "http")
+	if err != nil {
+		return errors.Wrapf(err, "invalid http routes in the virtual service %s", name)
+	}
+	updated := 0
+	for i := range httpRoutes {
+		route, ok := httpRoutes[i].(map[string]interface{})
+		if !ok || !r.shouldUpdate(route) {
+			continue
+		}
+		destinations, _, err := unstructured.NestedSlice(route, "route")
+		if err != nil {
+			return errors.Wrapf(err, "invalid destinations in the virtual service %s", name)
+		}
+		route["route"] = r.weightedDestinations(destinations, weight, removeCanary)
+		updated++
+	}
+	if updated == 0 {
+		return fmt.Errorf("no http route to update in the virtual service %s", name)
+	}
+	if err := unstructured.SetNestedSlice(vs.Object, httpRoutes, "spec", "http"); err != nil {
+		return err
+	}
+	return r.client.Update(ctx, vs)
+}
+
+func (r *istioRouter) shouldUpdate(route map[string]interface{}) bool {
+	if len(r.routing.Istio.Routes) == 0 {
+		return true
+	}
+	for _, name := range r.routing.Istio.Routes {
+		if route["name"] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// weightedDestinations patches the weights of the destinations to the stable and the canary service, which share
+// the weight left by the other destinations. The other destinations and the other fields of the managed ones such
+// as the port are kept, the missing managed destinations are appended.
+func (r *istioRouter) weightedDestinations(destinations []interface{}, weight int32, removeCanary bool) []interface{} {
+	var others int64
+	for _, item := range destinations {
+		if host := destinationHost(item); host != r.routing.StableService && host != r.routing.CanaryService {
+			w, _, _ := unstructured.NestedInt64(item.(map[string]interface{}), "weight")
+			others += w
+		}
+	}
+	remaining := 100 - others
+	if remaining < 0 {
+		remaining = 0
+	}
+	canaryWeight := remaining * int64(weight) / 100
+	weights := map[string]int64{
+		r.routing.StableService: remaining - canaryWeight,
+		r.routing.CanaryService: canaryWeight,
+	}
+
+	var patched []interface{}
+	found := map[string]bool{}
+	for _, item := range destinations {
+		host := destinationHost(item)
+		w, managed := weights[host]
+		if !managed {
+			patched = append(patched, item)
+			continue
+		}
+		if found[host] || (removeCanary && host == r.routing.CanaryService) {
+			continue
+		}
+		found[host] = true
+		destination := item.(map[string]interface{})
+		destination["weight"] = w
+		patched = append(patched, destination)
+	}
+	if !found[r.routing.StableService] {
+		patched = append(patched, map[string]interface{}{
+			"destination": map[string]interface{}{"host": r.routing.StableService},
+			"weight":      weights[r.routing.StableService],
+		})
+	}
+	if !found[r.routing.CanaryService] && !removeCanary {
+		patched = append(patched, map[string]interface{}{
+			"destination": map[string]interface{}{"host": r.routing.CanaryService},
+			"weight":      weights[r.routing.CanaryService],
+		})
+	}
+	return patched
+}
+
+func destinationHost(item interface{}) string {
+	destination, ok := item.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	host, _, _ := unstructured.NestedString(destination, "destination", "host")
+	return host
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+const (
+	// AnnotationNginxCanary marks the ingress as the canary of the ingress with the same host and path
+	AnnotationNginxCanary = "nginx.ingress.kubernetes.io/canary"
+	// AnnotationNginxCanaryWeight is the percentage of the traffic routed to the canary ingress
+	AnnotationNginxCanaryWeight = "nginx.ingress.kubernetes.io/canary-weight"
+)
+
+// nginxRouter shifts the traffic by a canary ingress routing to the canary service
+type nginxRouter struct {
+	client    client.Client
+	namespace string
+	routing   *v1alpha1.TrafficRouting
+}
+
+// CanaryIngressName returns the name of the canary ingress created for the ingress
+func CanaryIngressName(ingress string) string {
+	return fmt.Sprintf("%s-canary", ingress)
+}
+
+func (r *nginxRouter) SetWeight(ctx context.Context, weight int32) error {
+	name := r.routing.Nginx.Ingress
+	stable := &networkingv1.Ingress{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: name}, stable); err != nil {
+		return errors.Wrapf(err, "failed to get the ingress %s", name)
+	}
+	spec := stable.Spec.DeepCopy()
+	// the canary ingress has to share the same hosts and paths with the stable one, tls is configured by the stable one
+	spec.TLS = nil
+	r.replaceBackend(spec.DefaultBackend)
+	for i := range spec.Rules {
+		if spec.Rules[i].HTTP == nil {
+			continue
+		}
+		for j := range spec.Rules[i].HTTP.Paths {
+			r.replaceBackend(&spec.Rules[i].HTTP.Paths[j].Backend)
+		}
+	}
+
+	canary := &networkingv1.Ingress{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: CanaryIngressName(name)}, canary)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get the canary ingress of %s", name)
+	}
+	if canary.Annotations == nil {
+		canary.Annotations = map[string]string{}
+	}
+	canary.Annotations[AnnotationNginxCanary] = "true"
+	canary.Annotations[AnnotationNginxCanaryWeight] = strconv.Itoa(int(weight))
+	canary.Spec = *spec
+	if apierrors.IsNotFound(err) {
+		canary.ObjectMeta = metav1.ObjectMeta{
+			Name:        CanaryIngressName(name),
+			Namespace:   r.namespace,
+			Labels:      stable.Labels,
+			Annotations: canary.Annotations,
+		}
+		return r.client.Create(ctx, canary)
+	}
+	return r.client.Update(ctx, canary)
+}
+
+// Reset removes the canary ingress so all the traffic goes through the stable ingress
+func (r *nginxRouter) Reset(ctx context.Context) error {
+	canary := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Namespace: r.namespace, Name: CanaryIngressName(r.routing.Nginx.Ingress)}}
+	if err := r.client.Delete(ctx, canary); err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to delete the canary ingress of %s", r.routing.Nginx.Ingress)
+	}
+	return nil
+}
+
+func (r *nginxRouter) replaceBackend(backend *networkingv1.IngressBackend) {
+	if backend != nil && backend.Service != nil && backend.Service.Name == r.routing.StableService {
+		backend.Service.Name = r.routing.CanaryService
+	}
+}
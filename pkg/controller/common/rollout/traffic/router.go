@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// Router is the interface that all the traffic routing implements
+type Router interface {
+	// SetWeight routes the percentage of the traffic to the canary service and the rest to the stable service
+	SetWeight(ctx context.Context, weight int32) error
+	// Reset routes all the traffic to the stable service and removes the canary routing, it is called after
+	// the stable service selects the pods of the target once the rollout succeeds
+	Reset(ctx context.Context) error
+}
+
+// NewRouter picks the router according to the traffic routing of the rollout plan
+// the resources referred by the traffic routing are in the given namespace
+func NewRouter(c client.Client, namespace string, routing *v1alpha1.TrafficRouting) (Router, error) {
+	switch {
+	case routing.Istio != nil:
+		return &istioRouter{client: c, namespace: namespace, routing: routing}, nil
+	case routing.SMI != nil:
+		return &smiRouter{client: c, namespace: namespace, routing: routing}, nil
+	case routing.Nginx != nil:
+		return &nginxRouter{client: c, namespace: namespace, routing: routing}, nil
+	default:
+		return nil, fmt.Errorf("no traffic routing provider is specified")
+	}
+}
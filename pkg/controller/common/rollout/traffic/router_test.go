@@ -0,0 +1,250 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestNewRouter(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	_, err := NewRouter(cli, "default", &v1alpha1.TrafficRouting{StableService: "stable", CanaryService: "canary"})
+	require.Error(t, err)
+	router, err := NewRouter(cli, "default", &v1alpha1.TrafficRouting{Nginx: &v1alpha1.NginxTrafficRouting{Ingress: "ingress"}})
+	require.NoError(t, err)
+	require.IsType(t, &nginxRouter{}, router)
+}
+
+func TestIstioRouter(t *testing.T) {
+	vs := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"http": []interface{}{
+				map[string]interface{}{
+					"name": "primary",
+					"route": []interface{}{
+						map[string]interface{}{
+							"destination": map[string]interface{}{
+								"host": "stable",
+								"port": map[string]interface{}{"number": int64(80)},
+							},
+						},
+					},
+				},
+				map[string]interface{}{
+					"name": "mirror",
+					"route": []interface{}{
+						map[string]interface{}{"destination": map[string]interface{}{"host": "stable"}, "weight": int64(50)},
+						map[string]interface{}{"destination": map[string]interface{}{"host": "legacy"}, "weight": int64(50)},
+					},
+				},
+				map[string]interface{}{
+					"name": "other",
+					"route": []interface{}{
+						map[string]interface{}{"destination": map[string]interface{}{"host": "other"}},
+					},
+				},
+			},
+		},
+	}}
+	vs.SetGroupVersionKind(VirtualServiceGVK)
+	vs.SetNamespace("default")
+	vs.SetName("vs")
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(vs).Build()
+	router, err := NewRouter(cli, "default", &v1alpha1.TrafficRouting{
+		StableService: "stable",
+		CanaryService: "canary",
+		Istio:         &v1alpha1.IstioTrafficRouting{VirtualService: "vs", Routes: []string{"primary", "mirror"}},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	require.NoError(t, router.SetWeight(ctx, 20))
+
+	getHTTPRoutes := func() []interface{} {
+		updated := &unstructured.Unstructured{}
+		updated.SetGroupVersionKind(VirtualServiceGVK)
+		require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "vs"}, updated))
+		httpRoutes, _, err := unstructured.NestedSlice(updated.Object, "spec", "http")
+		require.NoError(t, err)
+		return httpRoutes
+	}
+	httpRoutes := getHTTPRoutes()
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host": "stable",
+				"port": map[string]interface{}{"number": int64(80)},
+			},
+			"weight": int64(80),
+		},
+		map[string]interface{}{
+			"destination": map[string]interface{}{"host": "canary"},
+			"weight":      int64(20),
+		},
+	}, httpRoutes[0].(map[string]interface{})["route"])
+	// only the managed destinations are patched, they share the weight left by the others
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"destination": map[string]interface{}{"host": "stable"}, "weight": int64(40)},
+		map[string]interface{}{"destination": map[string]interface{}{"host": "legacy"}, "weight": int64(50)},
+		map[string]interface{}{"destination": map[string]interface{}{"host": "canary"}, "weight": int64(10)},
+	}, httpRoutes[1].(map[string]interface{})["route"])
+	// the routes not specified are left unchanged
+	require.Len(t, httpRoutes[2].(map[string]interface{})["route"], 1)
+
+	require.NoError(t, router.Reset(ctx))
+	httpRoutes = getHTTPRoutes()
+	require.Equal(t, []interface{}{
+		map[string]interface{}{
+			"destination": map[string]interface{}{
+				"host": "stable",
+				"port": map[string]interface{}{"number": int64(80)},
+			},
+			"weight": int64(100),
+		},
+	}, httpRoutes[0].(map[string]interface{})["route"])
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"destination": map[string]interface{}{"host": "stable"}, "weight": int64(50)},
+		map[string]interface{}{"destination": map[string]interface{}{"host": "legacy"}, "weight": int64(50)},
+	}, httpRoutes[1].(map[string]interface{})["route"])
+
+	router, err = NewRouter(cli, "default", &v1alpha1.TrafficRouting{
+		StableService: "stable",
+		CanaryService: "canary",
+		Istio:         &v1alpha1.IstioTrafficRouting{VirtualService: "vs", Routes: []string{"unknown"}},
+	})
+	require.NoError(t, err)
+	require.Error(t, router.SetWeight(ctx, 20))
+}
+
+func TestSMIRouter(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	router, err := NewRouter(cli, "default", &v1alpha1.TrafficRouting{
+		StableService: "stable",
+		CanaryService: "canary",
+		SMI:           &v1alpha1.SMITrafficRouting{TrafficSplit: "ts"},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, weight := range []int32{10, 60} {
+		require.NoError(t, router.SetWeight(ctx, weight))
+		ts := &unstructured.Unstructured{}
+		ts.SetGroupVersionKind(TrafficSplitGVK)
+		require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "ts"}, ts))
+		service, _, _ := unstructured.NestedString(ts.Object, "spec", "service")
+		require.Equal(t, "stable", service)
+		backends, _, _ := unstructured.NestedSlice(ts.Object, "spec", "backends")
+		require.Equal(t, []interface{}{
+			map[string]interface{}{"service": "stable", "weight": int64(100 - weight)},
+			map[string]interface{}{"service": "canary", "weight": int64(weight)},
+		}, backends)
+	}
+	require.NoError(t, router.Reset(ctx))
+	ts := &unstructured.Unstructured{}
+	ts.SetGroupVersionKind(TrafficSplitGVK)
+	require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "ts"}, ts))
+	backends, _, _ := unstructured.NestedSlice(ts.Object, "spec", "backends")
+	require.Equal(t, []interface{}{map[string]interface{}{"service": "stable", "weight": int64(100)}}, backends)
+}
+
+func TestNginxRouter(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	stable := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "ingress", Labels: map[string]string{"app": "demo"}},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"demo.example.com"}, SecretName: "tls"}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "demo.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: "stable",
+							Port: networkingv1.ServiceBackendPort{Number: 80},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(stable).Build()
+	router, err := NewRouter(cli, "default", &v1alpha1.TrafficRouting{
+		StableService: "stable",
+		CanaryService: "canary",
+		Nginx:         &v1alpha1.NginxTrafficRouting{Ingress: "ingress"},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+	for _, weight := range []int32{30, 0} {
+		require.NoError(t, router.SetWeight(ctx, weight))
+		canary := &networkingv1.Ingress{}
+		require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: CanaryIngressName("ingress")}, canary))
+		require.Equal(t, "true", canary.Annotations[AnnotationNginxCanary])
+		require.Equal(t, map[string]string{"app": "demo"}, canary.Labels)
+		require.Nil(t, canary.Spec.TLS)
+		require.Equal(t, "canary", canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+		require.Equal(t, int32(80), canary.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Port.Number)
+		require.Equal(t, strconv.Itoa(int(weight)), canary.Annotations[AnnotationNginxCanaryWeight])
+	}
+	// the stable ingress is left unchanged
+	require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "ingress"}, stable))
+	require.Equal(t, "stable", stable.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+
+	require.NoError(t, router.Reset(ctx))
+	err = cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: CanaryIngressName("ingress")}, &networkingv1.Ingress{})
+	require.True(t, apierrors.IsNotFound(err))
+	require.NoError(t, router.Reset(ctx))
+}
+
+func TestServiceRouter(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "active"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "demo", "version": "v1"}},
+	}
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(svc).Build()
+	router := NewServiceRouter(cli, "default", "active",
+		map[string]string{"app": "demo", "version": "v1"}, map[string]string{"app": "demo", "revision": "v2"})
+	ctx := context.Background()
+
+	require.NoError(t, router.SetWeight(ctx, 100))
+	svc = &corev1.Service{}
+	require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "active"}, svc))
+	require.Equal(t, map[string]string{"app": "demo", "revision": "v2"}, svc.Spec.Selector)
+
+	require.NoError(t, router.SetWeight(ctx, 0))
+	svc = &corev1.Service{}
+	require.NoError(t, cli.Get(ctx, types.NamespacedName{Namespace: "default", Name: "active"}, svc))
+	require.Equal(t, map[string]string{"app": "demo", "version": "v1"}, svc.Spec.Selector)
+
+	// the service can't split the traffic
+	require.Error(t, router.SetWeight(ctx, 50))
+	require.Error(t, NewServiceRouter(cli, "default", "active", nil, nil).SetWeight(ctx, 0))
+	require.Error(t, NewServiceRouter(cli, "default", "not-exist", nil, map[string]string{"app": "demo"}).SetWeight(ctx, 100))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// serviceRouter switches all the traffic at once by pointing the selector of a service to the pods of
+// either the source or the target, so it can't split the traffic between them
+type serviceRouter struct {
+	client         client.Client
+	namespace      string
+	service        string
+	stableSelector map[string]string
+	canarySelector map[string]string
+}
+
+// NewServiceRouter creates a router that switches the selector of the service between the stable selector
+// (weight 0) and the canary selector (weight 100)
+func NewServiceRouter(c client.Client, namespace, service string, stableSelector, canarySelector map[string]string) Router {
+	return &serviceRouter{
+		client:         c,
+		namespace:      namespace,
+		service:        service,
+		stableSelector: stableSelector,
+		canarySelector: canarySelector,
+	}
+}
+
+func (r *serviceRouter) SetWeight(ctx context.Context, weight int32) error {
+	var selector map[string]string
+	switch weight {
+	case 0:
+		selector = r.stableSelector
+	case 100:
+		selector = r.canarySelector
+	default:
+		return fmt.Errorf("the service %s can only route all the traffic to either the source or the target, weight = %d",
+			r.service, weight)
+	}
+	if len(selector) == 0 {
+		return fmt.Errorf("the service %s can not select all the pods in the namespace", r.service)
+	}
+
+	svc := &corev1.Service{}
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: r.service}, svc); err != nil {
+		return errors.Wrapf(err, "failed to get the service %s", r.service)
+	}
+	svcPatch := client.MergeFrom(svc.DeepCopy())
+	svc.Spec.Selector = selector
+	return r.client.Patch(ctx, svc, svcPatch)
+}
+
+// Reset keeps the selector of the service, the service switches the traffic by itself and has no canary routing
+func (r *serviceRouter) Reset(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package traffic
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+// TrafficSplitGVK is the group version kind of the SMI TrafficSplit
+var TrafficSplitGVK = schema.GroupVersionKind{Group: "split.smi-spec.io", Version: "v1alpha2", Kind: "TrafficSplit"}
+
+// smiRouter shifts the traffic by updating the backends of the TrafficSplit
+type smiRouter struct {
+	client    client.Client
+	namespace string
+	routing   *v1alpha1.TrafficRouting
+}
+
+func (r *smiRouter) SetWeight(ctx context.Context, weight int32) error {
+	return r.setBackends(ctx, []interface{}{
+		map[string]interface{}{"service": r.routing.StableService, "weight": int64(100 - weight)},
+		map[string]interface{}{"service": r.routing.CanaryService, "weight": int64(weight)},
+	})
+}
+
+// Reset routes all the traffic of the root service to the stable service
+func (r *smiRouter) Reset(ctx context.Context) error {
+	return r.setBackends(ctx, []interface{}{
+		map[string]interface{}{"service": r.routing.StableService, "weight": int64(100)},
+	})
+}
+
+func (r *smiRouter) setBackends(ctx context.Context, backends []interface{}) error {
+	name := r.routing.SMI.TrafficSplit
+	rootService := r.routing.SMI.RootService
+	if rootService == "" {
+		rootService = r.routing.StableService
+	}
+
+	ts := &unstructured.Unstructured{}
+	ts.SetGroupVersionKind(TrafficSplitGVK)
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: r.namespace, Name: name}, ts)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to get the traffic split %s", name)
+	}
+	if err := unstructured.SetNestedField(ts.Object, rootService, "spec", "service"); err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedSlice(ts.Object, backends, "spec", "backends"); err != nil {
+		return err
+	}
+	if apierrors.IsNotFound(err) {
+		ts.SetNamespace(r.namespace)
+		ts.SetName(name)
+		return r.client.Create(ctx, ts)
+	}
+	return r.client.Update(ctx, ts)
+}
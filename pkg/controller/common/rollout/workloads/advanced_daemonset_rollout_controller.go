@@ -0,0 +1,311 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// AdvancedDaemonSetRolloutController is responsible for handle rollout Kruise Advanced DaemonSet type of workloads,
+// the pods are upgraded batch by batch by the partition of the rolling update
+type AdvancedDaemonSetRolloutController struct {
+	workloadController
+	targetNamespacedName types.NamespacedName
+	daemonSet            *kruise.DaemonSet
+}
+
+// NewAdvancedDaemonSetRolloutController creates Advanced DaemonSet rollout controller
+func NewAdvancedDaemonSetRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus, targetNamespacedName types.NamespacedName) *AdvancedDaemonSetRolloutController {
+	return &AdvancedDaemonSetRolloutController{
+		workloadController: workloadController{
+			client:           client,
+			recorder:         recorder,
+			parentController: parentController,
+			rolloutSpec:      rolloutSpec,
+			rolloutStatus:    rolloutStatus,
+		},
+		targetNamespacedName: targetNamespacedName,
+	}
+}
+
+// VerifySpec verifies that the rollout resource is consistent with the rollout spec
+func (s *AdvancedDaemonSetRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			s.recorder.Event(s.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	currentReplicas, verifyErr := s.size(ctx)
+	if verifyErr != nil {
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+	// record the size and we will use this value to drive the rest of the batches
+	klog.InfoS("record the target size", "total replicas", currentReplicas)
+	s.rolloutStatus.RolloutTargetSize = currentReplicas
+	s.rolloutStatus.RolloutOriginalSize = currentReplicas
+
+	// make sure that the spec is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(s.daemonSet.Spec)
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if targetHash == s.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		return false, fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+	}
+
+	if currentReplicas != s.daemonSet.Status.CurrentNumberScheduled {
+		verifyErr = fmt.Errorf("the Advanced DaemonSet is still scheduling, target = %d, scheduled = %d",
+			currentReplicas, s.daemonSet.Status.CurrentNumberScheduled)
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		return false, verifyErr
+	}
+
+	// check if the rollout batch replicas added up to the number of the Advanced DaemonSet pods
+	if verifyErr = verifyBatchesWithRollout(s.rolloutSpec, currentReplicas); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// check if the Advanced DaemonSet has any controller
+	if controller := metav1.GetControllerOf(s.daemonSet); controller != nil {
+		return false, fmt.Errorf("the Advanced DaemonSet %s has a controller owner %s",
+			s.daemonSet.GetName(), controller.String())
+	}
+
+	// mark the rollout verified
+	s.recorder.Event(s.parentController, event.Normal("Rollout Verified",
+		"Rollout spec and the Advanced DaemonSet resource are verified"))
+	// record the new pod template identifier on success
+	s.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the Advanced DaemonSet is under our control and no pod is upgraded yet
+func (s *AdvancedDaemonSetRolloutController) Initialize(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	if !isControlledByRollout(s.daemonSet) {
+		daemonSetPatch := client.MergeFrom(s.daemonSet.DeepCopy())
+		// add the parent controller to the owner of the Advanced DaemonSet
+		ref := metav1.NewControllerRef(s.parentController, s.parentController.GetObjectKind().GroupVersionKind())
+		s.daemonSet.SetOwnerReferences(append(s.daemonSet.GetOwnerReferences(), *ref))
+		if err := s.client.Patch(ctx, s.daemonSet, daemonSetPatch, client.FieldOwner(s.parentController.GetUID())); err != nil {
+			s.recorder.Event(s.parentController, event.Warning("Failed to the start the Advanced DaemonSet update", err))
+			s.rolloutStatus.RolloutRetry(err.Error())
+			return false, nil
+		}
+	}
+
+	// keep all the pods in the old revision, the update paused by the application controller is resumed here
+	if err := s.setPartition(ctx, currentReplicas); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	s.recorder.Event(s.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods calculates the number of pods we can upgrade once according to the rollout spec
+// and then set the partition accordingly
+func (s *AdvancedDaemonSetRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	if err := s.setPartition(ctx, currentReplicas-newPodTarget); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", s.rolloutStatus.CurrentBatch,
+		"target size", newPodTarget)
+	s.recorder.Event(s.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", s.rolloutStatus.CurrentBatch)))
+	s.rolloutStatus.UpgradedReplicas = newPodTarget
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods in the new revision are all available according to the rollout plan
+func (s *AdvancedDaemonSetRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	if len(s.rolloutSpec.RolloutBatches) <= int(s.rolloutStatus.CurrentBatch) {
+		err := errors.New("somehow, currentBatch number exceeded the rolloutBatches spec")
+		klog.ErrorS(err, "total batch", len(s.rolloutSpec.RolloutBatches), "current batch",
+			s.rolloutStatus.CurrentBatch)
+		return false, err
+	}
+
+	if s.daemonSet.Status.ObservedGeneration < s.daemonSet.Generation || s.daemonSet.Status.DaemonSetHash == "" {
+		s.rolloutStatus.RolloutRetry("the Advanced DaemonSet is not observed by its controller yet")
+		return false, nil
+	}
+	pods, err := listDaemonSetPods(ctx, s.client, s.daemonSet, s.daemonSet.Spec.Selector, s.daemonSet.Status.DaemonSetHash)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	readyPodCount := pods.readyUpdated()
+	currentBatch := s.rolloutSpec.RolloutBatches[s.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable, int(currentReplicas), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", s.rolloutStatus.CurrentBatch,
+		"new pod count target", newPodTarget, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	s.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+readyPodCount >= int(newPodTarget) {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", s.rolloutStatus.CurrentBatch)
+		s.recorder.Event(s.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", s.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", s.rolloutStatus.CurrentBatch)
+	s.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (s *AdvancedDaemonSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	return finalizeOneRolloutBatch(s.rolloutSpec, s.rolloutStatus)
+}
+
+// Finalize makes sure the Advanced DaemonSet is released
+func (s *AdvancedDaemonSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := s.fetchDaemonSet(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	// release the Advanced DaemonSet
+	if newOwnerList, found := removeRolloutOwner(s.daemonSet); found {
+		daemonSetPatch := client.MergeFrom(s.daemonSet.DeepCopy())
+		s.daemonSet.SetOwnerReferences(newOwnerList)
+		if err := s.client.Patch(ctx, s.daemonSet, daemonSetPatch, client.FieldOwner(s.parentController.GetUID())); err != nil {
+			s.recorder.Event(s.parentController, event.Warning("Failed to the release the Advanced DaemonSet", err))
+			s.rolloutStatus.RolloutRetry(err.Error())
+			return false
+		}
+	}
+
+	// mark the resource finalized
+	s.rolloutStatus.LastAppliedPodTemplateIdentifier = s.rolloutStatus.NewPodTemplateIdentifier
+	s.recorder.Event(s.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+// set the partition of the Advanced DaemonSet, the update is resumed in case it is paused before the rollout
+func (s *AdvancedDaemonSetRolloutController) setPartition(ctx context.Context, partition int32) error {
+	daemonSetPatch := client.MergeFrom(s.daemonSet.DeepCopy())
+	s.daemonSet.Spec.UpdateStrategy.Type = kruise.RollingUpdateDaemonSetStrategyType
+	if s.daemonSet.Spec.UpdateStrategy.RollingUpdate == nil {
+		s.daemonSet.Spec.UpdateStrategy.RollingUpdate = &kruise.RollingUpdateDaemonSet{}
+	}
+	s.daemonSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(partition)
+	s.daemonSet.Spec.UpdateStrategy.RollingUpdate.Paused = pointer.BoolPtr(false)
+
+	// patch the Advanced DaemonSet
+	if err := s.client.Patch(ctx, s.daemonSet, daemonSetPatch, client.FieldOwner(s.parentController.GetUID())); err != nil {
+		s.recorder.Event(s.parentController, event.Warning(event.Reason(fmt.Sprintf(
+			"Failed to update the partition of Advanced DaemonSet %s to the correct target %d", s.daemonSet.GetName(), partition)), err))
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+
+	klog.InfoS("Submitted upgrade quest for Advanced DaemonSet", "DaemonSet",
+		s.daemonSet.GetName(), "target partition", partition, "batch", s.rolloutStatus.CurrentBatch)
+	return nil
+}
+
+// the number of the pods in the new revision for the current batch
+func (s *AdvancedDaemonSetRolloutController) calculateCurrentTarget(totalSize int32) int32 {
+	targetSize := int32(calculateNewBatchTarget(s.rolloutSpec, 0, int(totalSize), int(s.rolloutStatus.CurrentBatch)))
+	klog.InfoS("Calculated the number of pods in the new revision after current batch",
+		"current batch", s.rolloutStatus.CurrentBatch, "target DaemonSet size", targetSize)
+	return targetSize
+}
+
+func (s *AdvancedDaemonSetRolloutController) fetchDaemonSet(ctx context.Context) error {
+	workload := kruise.DaemonSet{}
+	if err := s.client.Get(ctx, s.targetNamespacedName, &workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			s.recorder.Event(s.parentController, event.Warning("Failed to get the Advanced DaemonSet", err))
+		}
+		return err
+	}
+	s.daemonSet = &workload
+	return nil
+}
+
+// size returns the number of the nodes that should run the Advanced DaemonSet pod
+func (s *AdvancedDaemonSetRolloutController) size(ctx context.Context) (int32, error) {
+	if s.daemonSet == nil {
+		if err := s.fetchDaemonSet(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return s.daemonSet.Status.DesiredNumberScheduled, nil
+}
@@ -0,0 +1,335 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ = Describe("Advanced DaemonSet rollout controller", func() {
+	var (
+		c              AdvancedDaemonSetRolloutController
+		ns             corev1.Namespace
+		name           string
+		namespace      string
+		daemonSet      kruise.DaemonSet
+		namespacedName client.ObjectKey
+	)
+
+	const updateRevision = "update-revision"
+
+	BeforeEach(func() {
+		namespace = "rollout-ns"
+		name = "advanced-daemonset-rollout"
+		appRollout := v1beta1.AppRollout{TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind}, ObjectMeta: metav1.ObjectMeta{Name: name}}
+		namespacedName = client.ObjectKey{Name: name, Namespace: namespace}
+		c = AdvancedDaemonSetRolloutController{
+			workloadController: workloadController{
+				client: k8sClient,
+				rolloutSpec: &v1alpha1.RolloutPlan{
+					RolloutBatches: []v1alpha1.RolloutBatch{
+						{
+							Replicas: intstr.FromInt(1),
+						},
+					},
+				},
+				rolloutStatus:    &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState},
+				parentController: &appRollout,
+				recorder: event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+					WithAnnotations("controller", "AppRollout"),
+			},
+			targetNamespacedName: namespacedName,
+		}
+
+		daemonSet = kruise.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kruise.SchemeGroupVersion.String(), Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: kruise.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"app": name},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+				},
+				UpdateStrategy: kruise.DaemonSetUpdateStrategy{
+					Type: kruise.RollingUpdateDaemonSetStrategyType,
+					RollingUpdate: &kruise.RollingUpdateDaemonSet{
+						Paused: pointer.BoolPtr(true),
+					},
+				},
+			},
+		}
+
+		ns = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		By("Create a namespace")
+		Expect(k8sClient.Create(ctx, &ns)).Should(SatisfyAny(Succeed(), &util.AlreadyExistMatcher{}))
+	})
+
+	AfterEach(func() {
+		By("clean up")
+		k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(namespace), client.MatchingLabels{"app": name},
+			client.GracePeriodSeconds(0))
+		k8sClient.Delete(ctx, &daemonSet)
+	})
+
+	Context("TestNewAdvancedDaemonSetRolloutController", func() {
+		It("init an Advanced DaemonSet Rollout Controller", func() {
+			recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+				WithAnnotations("controller", "AppRollout")
+			parentController := &v1beta1.AppRollout{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			rolloutSpec := &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{
+					Replicas: intstr.FromInt(1),
+				},
+				},
+			}
+			rolloutStatus := &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState}
+			workloadNamespacedName := client.ObjectKey{Name: name, Namespace: namespace}
+			got := NewAdvancedDaemonSetRolloutController(k8sClient, recorder, parentController, rolloutSpec, rolloutStatus, workloadNamespacedName)
+			c := &AdvancedDaemonSetRolloutController{
+				workloadController: workloadController{
+					client:           k8sClient,
+					recorder:         recorder,
+					parentController: parentController,
+					rolloutSpec:      rolloutSpec,
+					rolloutStatus:    rolloutStatus,
+				},
+				targetNamespacedName: workloadNamespacedName,
+			}
+			Expect(got).Should(Equal(c))
+		})
+	})
+
+	Context("VerifySpec", func() {
+		It("could not fetch Advanced DaemonSet workload", func() {
+			consistent, err := c.VerifySpec(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("the Advanced DaemonSet need to be scheduled", func() {
+			By("create the Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 5
+			daemonSet.Status.CurrentNumberScheduled = 3
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("setting a dummy pod identifier so it's different")
+			c.rolloutStatus.LastAppliedPodTemplateIdentifier = "abc"
+
+			By("verify should fail because the Advanced DaemonSet is still scheduling")
+			consistent, err := c.VerifySpec(ctx)
+			Expect(consistent).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("is still scheduling"))
+			Expect(c.rolloutStatus.NewPodTemplateIdentifier).Should(BeEmpty())
+		})
+
+		It("spec is valid", func() {
+			By("Create an Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 1
+			daemonSet.Status.CurrentNumberScheduled = 1
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("setting a dummy pod identifier so it's different")
+			c.rolloutStatus.LastAppliedPodTemplateIdentifier = "abc"
+
+			By("verify should succeed")
+			consistent, err := c.VerifySpec(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeTrue())
+			Expect(c.rolloutStatus.RolloutTargetSize).Should(BeEquivalentTo(1))
+			Expect(c.rolloutStatus.NewPodTemplateIdentifier).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("TestInitialize", func() {
+		It("could not fetch Advanced DaemonSet workload", func() {
+			consistent, err := c.Initialize(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("successfully initialized Advanced DaemonSet", func() {
+			By("create Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 10
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("initialize succeeds")
+			c.parentController.SetUID("1231586900")
+			initialized, err := c.Initialize(ctx)
+			Expect(initialized).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &daemonSet)).Should(Succeed())
+			Expect(len(daemonSet.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			By("the update should be resumed without upgrading any pod")
+			Expect(*daemonSet.Spec.UpdateStrategy.RollingUpdate.Partition).Should(BeEquivalentTo(10))
+			Expect(*daemonSet.Spec.UpdateStrategy.RollingUpdate.Paused).Should(BeFalse())
+		})
+	})
+
+	Context("TestRolloutOneBatchPods", func() {
+		It("successfully rollout the second batch", func() {
+			By("Create an Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 10
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("rollout the second batch of current Advanced DaemonSet")
+			c.rolloutStatus.CurrentBatch = 1
+			c.rolloutSpec.RolloutBatches = []v1alpha1.RolloutBatch{
+				{
+					Replicas: intstr.FromInt(1),
+				},
+				{
+					Replicas: intstr.FromString("20%"),
+				},
+				{
+					Replicas: intstr.FromString("80%"),
+				},
+			}
+			done, err := c.RolloutOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReplicas).Should(BeEquivalentTo(3))
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &daemonSet)).Should(Succeed())
+			Expect(*daemonSet.Spec.UpdateStrategy.RollingUpdate.Partition).Should(BeEquivalentTo(7))
+		})
+	})
+
+	Context("TestCheckOneBatchPods", func() {
+		BeforeEach(func() {
+			c.rolloutSpec.RolloutBatches = []v1alpha1.RolloutBatch{
+				{
+					Replicas: intstr.FromInt(2),
+				},
+				{
+					Replicas: intstr.FromString("20%"),
+				},
+				{
+					Replicas: intstr.FromString("80%"),
+				},
+			}
+		})
+
+		It("the Advanced DaemonSet is not observed yet", func() {
+			By("Create the Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 10
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("checking should wait for the Advanced DaemonSet controller")
+			c.rolloutStatus.CurrentBatch = 1
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("there are enough pods ready", func() {
+			By("Create the Advanced DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 10
+			daemonSet.Status.ObservedGeneration = daemonSet.Generation
+			daemonSet.Status.DaemonSetHash = updateRevision
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("Create the pods in the update revision")
+			for i := 0; i < 4; i++ {
+				pod := corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Namespace: namespace,
+						Name:      fmt.Sprintf("%s-%d", name, i),
+						Labels: map[string]string{
+							"app":                               name,
+							apps.DefaultDaemonSetUniqueLabelKey: updateRevision,
+						},
+						OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&daemonSet,
+							kruise.SchemeGroupVersion.WithKind("DaemonSet"))},
+					},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+				}
+				Expect(k8sClient.Create(ctx, &pod)).Should(Succeed())
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+				Expect(k8sClient.Status().Update(ctx, &pod)).Should(Succeed())
+			}
+
+			By("checking the second batch")
+			c.rolloutStatus.CurrentBatch = 1
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReadyReplicas).Should(BeEquivalentTo(4))
+		})
+	})
+
+	Context("TestFinalize", func() {
+		It("failed to fetch Advanced DaemonSet", func() {
+			By("finalizing")
+			finalized := c.Finalize(ctx, true)
+			Expect(finalized).Should(BeFalse())
+		})
+
+		It("successfully to finalize Advanced DaemonSet", func() {
+			By("Create an Advanced DaemonSet")
+			daemonSet.SetOwnerReferences([]metav1.OwnerReference{
+				{
+					APIVersion: v1beta1.SchemeGroupVersion.String(),
+					Kind:       v1beta1.AppRolloutKind,
+					Name:       "def",
+					UID:        "123456",
+					Controller: pointer.Bool(true),
+				},
+				{
+					APIVersion: corev1.SchemeGroupVersion.String(),
+					Kind:       "Deployment",
+					Name:       "def",
+					UID:        "998877745",
+				},
+			})
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+
+			By("finalizing with patch")
+			finalized := c.Finalize(ctx, true)
+			Expect(finalized).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &daemonSet)).Should(Succeed())
+			Expect(len(daemonSet.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			Expect(daemonSet.GetOwnerReferences()[0].Kind).Should(Equal("Deployment"))
+		})
+	})
+})
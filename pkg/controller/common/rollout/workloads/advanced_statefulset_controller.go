@@ -0,0 +1,144 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// advancedStatefulSetController is the place to hold fields needed for handle the Kruise Advanced StatefulSet
+type advancedStatefulSetController struct {
+	workloadController
+	targetNamespacedName types.NamespacedName
+	statefulSet          *kruisev1beta1.StatefulSet
+}
+
+// add the parent controller to the owner of the Advanced StatefulSet
+func (c *advancedStatefulSetController) claimStatefulSet(ctx context.Context) (bool, error) {
+	if isControlledByRollout(c.statefulSet) {
+		// it's already there
+		return true, nil
+	}
+
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+
+	// add the parent controller to the owner of the Advanced StatefulSet
+	ref := metav1.NewControllerRef(c.parentController, c.parentController.GetObjectKind().GroupVersionKind())
+	c.statefulSet.SetOwnerReferences(append(c.statefulSet.GetOwnerReferences(), *ref))
+
+	// patch the Advanced StatefulSet
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the start the Advanced StatefulSet update", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false, err
+	}
+	return false, nil
+}
+
+// scale the Advanced StatefulSet
+func (c *advancedStatefulSetController) scaleStatefulSet(ctx context.Context, size int32) error {
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	c.statefulSet.Spec.Replicas = pointer.Int32Ptr(size)
+
+	// patch the Advanced StatefulSet
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(fmt.Sprintf(
+			"Failed to update the Advanced StatefulSet %s to the correct target %d", c.statefulSet.GetName(), size)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+
+	klog.InfoS("Submitted upgrade quest for Advanced StatefulSet", "StatefulSet",
+		c.statefulSet.GetName(), "target replica size", size, "batch", c.rolloutStatus.CurrentBatch)
+	return nil
+}
+
+// set the partition of the Advanced StatefulSet, the update is resumed in case it is paused before the rollout
+func (c *advancedStatefulSetController) setPartition(ctx context.Context, partition int32) error {
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	if c.statefulSet.Spec.UpdateStrategy.RollingUpdate == nil {
+		c.statefulSet.Spec.UpdateStrategy.RollingUpdate = &kruisev1beta1.RollingUpdateStatefulSetStrategy{}
+	}
+	c.statefulSet.Spec.UpdateStrategy.RollingUpdate.Partition = pointer.Int32Ptr(partition)
+	c.statefulSet.Spec.UpdateStrategy.RollingUpdate.Paused = false
+
+	// patch the Advanced StatefulSet
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(fmt.Sprintf(
+			"Failed to update the partition of Advanced StatefulSet %s to the correct target %d", c.statefulSet.GetName(), partition)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+
+	klog.InfoS("Submitted upgrade quest for Advanced StatefulSet", "StatefulSet",
+		c.statefulSet.GetName(), "target partition", partition, "batch", c.rolloutStatus.CurrentBatch)
+	return nil
+}
+
+// remove the parent controller from the Advanced StatefulSet's owner list
+func (c *advancedStatefulSetController) releaseStatefulSet(ctx context.Context) (bool, error) {
+	newOwnerList, found := removeRolloutOwner(c.statefulSet)
+	if !found {
+		klog.InfoS("the Advanced StatefulSet is already released", "StatefulSet", c.statefulSet.Name)
+		return true, nil
+	}
+	statefulSetPatch := client.MergeFrom(c.statefulSet.DeepCopy())
+	c.statefulSet.SetOwnerReferences(newOwnerList)
+
+	// patch the Advanced StatefulSet
+	if err := c.client.Patch(ctx, c.statefulSet, statefulSetPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning("Failed to the release the Advanced StatefulSet", err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return false, err
+	}
+	return false, nil
+}
+
+func (c *advancedStatefulSetController) size(ctx context.Context) (int32, error) {
+	if c.statefulSet == nil {
+		if err := c.fetchStatefulSet(ctx); err != nil {
+			return 0, err
+		}
+	}
+	// default is 1
+	if c.statefulSet.Spec.Replicas == nil {
+		return 1, nil
+	}
+	return *c.statefulSet.Spec.Replicas, nil
+}
+
+func (c *advancedStatefulSetController) fetchStatefulSet(ctx context.Context) error {
+	workload := kruisev1beta1.StatefulSet{}
+	if err := c.client.Get(ctx, c.targetNamespacedName, &workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.recorder.Event(c.parentController, event.Warning("Failed to get the Advanced StatefulSet", err))
+		}
+		return err
+	}
+	c.statefulSet = &workload
+	return nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// AdvancedStatefulSetRolloutController is responsible for handle rollout Kruise Advanced StatefulSet type of workloads
+type AdvancedStatefulSetRolloutController struct {
+	advancedStatefulSetController
+}
+
+// NewAdvancedStatefulSetRolloutController creates Advanced StatefulSet rollout controller
+func NewAdvancedStatefulSetRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus, targetNamespacedName types.NamespacedName) *AdvancedStatefulSetRolloutController {
+	return &AdvancedStatefulSetRolloutController{
+		advancedStatefulSetController: advancedStatefulSetController{
+			workloadController: workloadController{
+				client:           client,
+				recorder:         recorder,
+				parentController: parentController,
+				rolloutSpec:      rolloutSpec,
+				rolloutStatus:    rolloutStatus,
+			},
+			targetNamespacedName: targetNamespacedName,
+		},
+	}
+}
+
+// VerifySpec verifies that the rollout resource is consistent with the rollout spec
+func (s *AdvancedStatefulSetRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			s.recorder.Event(s.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	currentReplicas, verifyErr := s.size(ctx)
+	if verifyErr != nil {
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+	// record the size and we will use this value to drive the rest of the batches
+	klog.InfoS("record the target size", "total replicas", currentReplicas)
+	s.rolloutStatus.RolloutTargetSize = currentReplicas
+	s.rolloutStatus.RolloutOriginalSize = currentReplicas
+
+	// make sure that the updateRevision is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(s.statefulSet.Spec)
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if targetHash == s.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		return false, fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+	}
+
+	if s.statefulSet.Spec.Replicas != nil && currentReplicas != s.statefulSet.Status.Replicas {
+		verifyErr = fmt.Errorf("the Advanced StatefulSet is still scaling, target = %d, statefulSet size = %d",
+			currentReplicas, s.statefulSet.Status.Replicas)
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		return false, verifyErr
+	}
+
+	// check if the rollout batch replicas added up to the Advanced StatefulSet replicas
+	if verifyErr = verifyBatchesWithRollout(s.rolloutSpec, currentReplicas); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// check if the Advanced StatefulSet has any controller
+	if controller := metav1.GetControllerOf(s.statefulSet); controller != nil {
+		return false, fmt.Errorf("the Advanced StatefulSet %s has a controller owner %s",
+			s.statefulSet.GetName(), controller.String())
+	}
+
+	// mark the rollout verified
+	s.recorder.Event(s.parentController, event.Normal("Rollout Verified",
+		"Rollout spec and the Advanced StatefulSet resource are verified"))
+	// record the new pod template identifier on success
+	s.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the Advanced StatefulSet is under our control and no pod is upgraded yet
+func (s *AdvancedStatefulSetRolloutController) Initialize(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	if _, err := s.claimStatefulSet(ctx); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// keep all the pods in the old revision, the update paused by the application controller is resumed here
+	if err := s.setPartition(ctx, currentReplicas); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	s.recorder.Event(s.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods calculates the number of pods we can upgrade once according to the rollout spec
+// and then set the partition accordingly
+func (s *AdvancedStatefulSetRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	if err := s.setPartition(ctx, currentReplicas-newPodTarget); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", s.rolloutStatus.CurrentBatch,
+		"target size", newPodTarget)
+	s.recorder.Event(s.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", s.rolloutStatus.CurrentBatch)))
+	s.rolloutStatus.UpgradedReplicas = newPodTarget
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods are all available according to the rollout plan
+func (s *AdvancedStatefulSetRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	if len(s.rolloutSpec.RolloutBatches) <= int(s.rolloutStatus.CurrentBatch) {
+		err := errors.New("somehow, currentBatch number exceeded the rolloutBatches spec")
+		klog.ErrorS(err, "total batch", len(s.rolloutSpec.RolloutBatches), "current batch",
+			s.rolloutStatus.CurrentBatch)
+		return false, err
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	readyPodCount := int(s.statefulSet.Status.ReadyReplicas)
+	currentBatch := s.rolloutSpec.RolloutBatches[s.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable, int(currentReplicas), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", s.rolloutStatus.CurrentBatch,
+		"new pod count target", newPodTarget, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	s.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+readyPodCount >= int(newPodTarget) {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", s.rolloutStatus.CurrentBatch)
+		s.recorder.Event(s.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", s.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", s.rolloutStatus.CurrentBatch)
+	s.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (s *AdvancedStatefulSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	return finalizeOneRolloutBatch(s.rolloutSpec, s.rolloutStatus)
+}
+
+// Finalize makes sure the Advanced StatefulSet is all upgraded
+func (s *AdvancedStatefulSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := s.fetchStatefulSet(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	// release the Advanced StatefulSet
+	if _, err := s.releaseStatefulSet(ctx); err != nil {
+		return false
+	}
+
+	// mark the resource finalized
+	s.rolloutStatus.LastAppliedPodTemplateIdentifier = s.rolloutStatus.NewPodTemplateIdentifier
+	s.recorder.Event(s.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+// the target Advanced StatefulSet size for the current batch
+func (s *AdvancedStatefulSetRolloutController) calculateCurrentTarget(totalSize int32) int32 {
+	targetSize := int32(calculateNewBatchTarget(s.rolloutSpec, 0, int(totalSize), int(s.rolloutStatus.CurrentBatch)))
+	klog.InfoS("Calculated the number of pods in the target Advanced StatefulSet after current batch",
+		"current batch", s.rolloutStatus.CurrentBatch, "target StatefulSet size", targetSize)
+	return targetSize
+}
@@ -0,0 +1,264 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ = Describe("Advanced StatefulSet scale controller", func() {
+	var (
+		s              AdvancedStatefulSetScaleController
+		ns             corev1.Namespace
+		name           string
+		namespace      string
+		statefulSet    kruisev1beta1.StatefulSet
+		namespacedName client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		namespace = "rollout-ns"
+		name = "advanced-sts-scale"
+		appRollout := v1beta1.AppRollout{TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind}, ObjectMeta: metav1.ObjectMeta{Name: name}}
+		namespacedName = client.ObjectKey{Name: name, Namespace: namespace}
+
+		s = AdvancedStatefulSetScaleController{
+			advancedStatefulSetController: advancedStatefulSetController{
+				workloadController: workloadController{
+					client: k8sClient,
+					rolloutSpec: &v1alpha1.RolloutPlan{
+						TargetSize: pointer.Int32Ptr(10),
+						RolloutBatches: []v1alpha1.RolloutBatch{
+							{
+								Replicas: intstr.FromInt(1),
+							},
+							{
+								Replicas: intstr.FromString("20%"),
+							},
+							{
+								Replicas: intstr.FromString("80%"),
+							},
+						},
+					},
+					rolloutStatus:    &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState},
+					parentController: &appRollout,
+					recorder: event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+						WithAnnotations("controller", "AppRollout"),
+				},
+				targetNamespacedName: namespacedName,
+			},
+		}
+
+		statefulSet = kruisev1beta1.StatefulSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: kruisev1beta1.SchemeGroupVersion.String(), Kind: "StatefulSet"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: kruisev1beta1.StatefulSetSpec{
+				Replicas: pointer.Int32Ptr(1),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "staging"},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "staging"}},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+				},
+			},
+		}
+
+		ns = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		By("Create a namespace")
+		Expect(k8sClient.Create(ctx, &ns)).Should(SatisfyAny(Succeed(), &util.AlreadyExistMatcher{}))
+	})
+
+	AfterEach(func() {
+		By("clean up")
+		k8sClient.Delete(ctx, &statefulSet)
+	})
+
+	Context("TestNewAdvancedStatefulSetScaleController", func() {
+		It("init an Advanced StatefulSet Scale Controller", func() {
+			recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+				WithAnnotations("controller", "AppRollout")
+			parentController := &v1beta1.AppRollout{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			rolloutSpec := &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{
+					Replicas: intstr.FromInt(1),
+				},
+				},
+			}
+			rolloutStatus := &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState}
+			workloadNamespacedName := client.ObjectKey{Name: name, Namespace: namespace}
+			got := NewAdvancedStatefulSetScaleController(k8sClient, recorder, parentController, rolloutSpec, rolloutStatus, workloadNamespacedName)
+			c := &AdvancedStatefulSetScaleController{
+				advancedStatefulSetController: advancedStatefulSetController{
+					workloadController: workloadController{
+						client:           k8sClient,
+						recorder:         recorder,
+						parentController: parentController,
+						rolloutSpec:      rolloutSpec,
+						rolloutStatus:    rolloutStatus,
+					},
+					targetNamespacedName: workloadNamespacedName,
+				},
+			}
+			Expect(got).Should(Equal(c))
+		})
+	})
+
+	Context("TestVerifySpec", func() {
+		It("rollout need a target size", func() {
+			s.rolloutSpec.TargetSize = nil
+			ligit, err := s.VerifySpec(ctx)
+			Expect(ligit).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("without a target"))
+		})
+
+		It("could not fetch Advanced StatefulSet workload", func() {
+			ligit, err := s.VerifySpec(ctx)
+			Expect(ligit).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("the Advanced StatefulSet is in the middle of scaling", func() {
+			By("Create an Advanced StatefulSet")
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+
+			By("verify should fail because replica does not match")
+			consistent, err := s.VerifySpec(ctx)
+			Expect(consistent).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("spec is valid", func() {
+			By("Create an Advanced StatefulSet")
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+			By("Update the Advanced StatefulSet status")
+			statefulSet.Status.Replicas = 1
+			statefulSet.Status.UpdatedReplicas = 1
+			statefulSet.Status.ReadyReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, &statefulSet)).Should(Succeed())
+
+			By("verify should pass and record the size")
+			consistent, err := s.VerifySpec(ctx)
+			Expect(consistent).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(s.rolloutStatus.RolloutTargetSize).Should(BeEquivalentTo(10))
+			Expect(s.rolloutStatus.RolloutOriginalSize).Should(BeEquivalentTo(1))
+		})
+	})
+
+	Context("TestInitialize", func() {
+		It("could not fetch Advanced StatefulSet workload", func() {
+			consistent, err := s.Initialize(ctx)
+			Expect(consistent).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("successfully initialized Advanced StatefulSet", func() {
+			By("Create an Advanced StatefulSet")
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+
+			By("initialize succeeds")
+			s.parentController.SetUID("1231586900")
+			initialized, err := s.Initialize(ctx)
+			Expect(initialized).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(k8sClient.Get(ctx, s.targetNamespacedName, &statefulSet)).Should(Succeed())
+			Expect(len(statefulSet.GetOwnerReferences())).Should(BeEquivalentTo(1))
+		})
+	})
+
+	Context("TestRolloutOneBatchPods", func() {
+		It("successfully scale the second batch", func() {
+			By("Create an Advanced StatefulSet")
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+
+			By("scale the second batch of current Advanced StatefulSet")
+			s.rolloutStatus.CurrentBatch = 1
+			s.rolloutStatus.RolloutOriginalSize = 0
+			s.rolloutStatus.RolloutTargetSize = 10
+			done, err := s.RolloutOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(s.rolloutStatus.UpgradedReplicas).Should(BeEquivalentTo(3))
+			Expect(k8sClient.Get(ctx, s.targetNamespacedName, &statefulSet)).Should(Succeed())
+			Expect(*statefulSet.Spec.Replicas).Should(BeEquivalentTo(3))
+		})
+	})
+
+	Context("TestCheckOneBatchPods", func() {
+		It("current ready Pods are less than expected during scale-up", func() {
+			By("Create the Advanced StatefulSet")
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+			By("Update the Advanced StatefulSet status")
+			statefulSet.Status.Replicas = 3
+			statefulSet.Status.ReadyReplicas = 3
+			Expect(k8sClient.Status().Update(ctx, &statefulSet)).Should(Succeed())
+
+			By("checking should fail as not enough pod ready")
+			s.rolloutStatus.CurrentBatch = 1
+			s.rolloutStatus.RolloutOriginalSize = 2
+			s.rolloutStatus.RolloutTargetSize = 10
+			done, err := s.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeFalse())
+			Expect(err).Should(BeNil())
+			Expect(s.rolloutStatus.UpgradedReadyReplicas).Should(BeEquivalentTo(statefulSet.Status.ReadyReplicas))
+		})
+	})
+
+	Context("TestFinalize", func() {
+		It("failed to fetch Advanced StatefulSet workload", func() {
+			finalized := s.Finalize(ctx, true)
+			Expect(finalized).Should(BeFalse())
+		})
+
+		It("successfully to finalize Advanced StatefulSet", func() {
+			By("Create an Advanced StatefulSet")
+			statefulSet.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       v1beta1.AppRolloutKind,
+				Name:       "def",
+				UID:        "123456",
+				Controller: pointer.BoolPtr(true),
+			}})
+			Expect(k8sClient.Create(ctx, &statefulSet)).Should(Succeed())
+
+			By("finalizing with patch")
+			finalized := s.Finalize(ctx, true)
+			Expect(finalized).Should(BeTrue())
+			var released kruisev1beta1.StatefulSet
+			Expect(k8sClient.Get(ctx, s.targetNamespacedName, &released)).Should(Succeed())
+			Expect(len(released.GetOwnerReferences())).Should(BeEquivalentTo(0))
+		})
+	})
+})
@@ -0,0 +1,379 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// BlueGreenRolloutController is responsible for the blue-green rollout of any workload that has a replicas field,
+// the target is scaled to the full size alongside the source which is kept untouched until the rollout plan
+// switches the traffic to the target, and the source is scaled down only when the rollout is finalized
+type BlueGreenRolloutController struct {
+	workloadController
+	workloadGVK          schema.GroupVersionKind
+	sourceNamespacedName types.NamespacedName
+	targetNamespacedName types.NamespacedName
+	source               *unstructured.Unstructured
+	target               *unstructured.Unstructured
+}
+
+// NewBlueGreenRolloutController creates a blue-green rollout controller
+func NewBlueGreenRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus, workloadGVK schema.GroupVersionKind,
+	sourceNamespacedName, targetNamespacedName types.NamespacedName) *BlueGreenRolloutController {
+	return &BlueGreenRolloutController{
+		workloadController: workloadController{
+			client:           client,
+			recorder:         recorder,
+			parentController: parentController,
+			rolloutSpec:      rolloutSpec,
+			rolloutStatus:    rolloutStatus,
+		},
+		workloadGVK:          workloadGVK,
+		sourceNamespacedName: sourceNamespacedName,
+		targetNamespacedName: targetNamespacedName,
+	}
+}
+
+// VerifySpec verifies that the source and the target are two different workloads that can be scaled
+func (c *BlueGreenRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			c.recorder.Event(c.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	if c.sourceNamespacedName == c.targetNamespacedName {
+		verifyErr = fmt.Errorf("the blue-green rollout needs different source and target workloads, got %s",
+			c.targetNamespacedName.Name)
+		return false, verifyErr
+	}
+
+	if err := c.fetchWorkloads(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// do not fail the rollout just because we can't get the resource
+		// nolint:nilerr
+		return false, nil
+	}
+
+	sourceSize, found, verifyErr := unstructured.NestedInt64(c.source.Object, "spec", "replicas")
+	if verifyErr != nil || !found {
+		verifyErr = fmt.Errorf("the %s %s can not be rolled out in blue-green since it has no replicas",
+			c.workloadGVK.Kind, c.source.GetName())
+		return false, verifyErr
+	}
+	// the target is in the full size of the source unless the target size is set
+	targetSize := int32(sourceSize)
+	if c.rolloutSpec.TargetSize != nil {
+		targetSize = *c.rolloutSpec.TargetSize
+	}
+	c.rolloutStatus.RolloutOriginalSize = int32(sourceSize)
+	c.rolloutStatus.RolloutTargetSize = targetSize
+
+	// make sure that the target is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(c.target.Object["spec"])
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+	if targetHash == c.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		verifyErr = fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+		return false, verifyErr
+	}
+
+	// check if the rollout batch replicas added up to the target size
+	if verifyErr = verifyBatchesWithRollout(c.rolloutSpec, targetSize); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// the source has to be stable to take over the traffic in case of a rollback
+	sourceReplicas, _, _ := unstructured.NestedInt64(c.source.Object, "status", "replicas")
+	if sourceReplicas != sourceSize {
+		verifyErr = fmt.Errorf("the source %s %s is still being reconciled, target = %d, size = %d",
+			c.workloadGVK.Kind, c.source.GetName(), sourceSize, sourceReplicas)
+		c.rolloutStatus.RolloutRetry(verifyErr.Error())
+		return false, nil
+	}
+
+	// the services switched between the source and the target have to tell their pods apart
+	if c.rolloutSpec.BlueGreen != nil &&
+		(c.rolloutSpec.BlueGreen.ActiveService != "" || c.rolloutSpec.BlueGreen.PreviewService != "") &&
+		reflect.DeepEqual(PodTemplateLabels(c.source), PodTemplateLabels(c.target)) {
+		verifyErr = fmt.Errorf("the pods of the source %s and the target %s have the same labels",
+			c.source.GetName(), c.target.GetName())
+		return false, verifyErr
+	}
+
+	for _, workload := range []*unstructured.Unstructured{c.source, c.target} {
+		if controller := metav1.GetControllerOf(workload); controller != nil {
+			verifyErr = fmt.Errorf("the %s %s has a controller owner %s", c.workloadGVK.Kind,
+				workload.GetName(), controller.String())
+			return false, verifyErr
+		}
+	}
+
+	// mark the rollout verified
+	c.recorder.Event(c.parentController, event.Normal("Rollout Verified",
+		fmt.Sprintf("Rollout spec and the %s resources are verified", c.workloadGVK.Kind)))
+	// record the new pod template hash on success
+	c.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the source and the target are under our control and the target starts from zero
+func (c *BlueGreenRolloutController) Initialize(ctx context.Context) (bool, error) {
+	if err := c.fetchWorkloads(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if err := c.claimWorkload(ctx, c.source, false); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+	if err := c.claimWorkload(ctx, c.target, true); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	c.recorder.Event(c.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods scales up the target according to the rollout spec, the source is not touched
+func (c *BlueGreenRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchWorkloads(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	targetSize := int32(calculateNewBatchTarget(c.rolloutSpec, 0, int(c.rolloutStatus.RolloutTargetSize),
+		int(c.rolloutStatus.CurrentBatch)))
+	if err := c.scaleWorkload(ctx, c.target, targetSize); err != nil {
+		// nolint:nilerr
+		return false, nil
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", c.rolloutStatus.CurrentBatch, "target size", targetSize)
+	c.recorder.Event(c.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", c.rolloutStatus.CurrentBatch)))
+	c.rolloutStatus.UpgradedReplicas = targetSize
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods of the target are all ready according to the rollout plan
+func (c *BlueGreenRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	if err := c.fetchWorkloads(ctx); err != nil {
+		c.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	targetGoal := calculateNewBatchTarget(c.rolloutSpec, 0, int(c.rolloutStatus.RolloutTargetSize),
+		int(c.rolloutStatus.CurrentBatch))
+	readyPodCount, _, _ := unstructured.NestedInt64(c.target.Object, "status", "readyReplicas")
+	currentBatch := c.rolloutSpec.RolloutBatches[c.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable,
+			int(c.rolloutStatus.RolloutTargetSize), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", c.rolloutStatus.CurrentBatch,
+		"new pod count target", targetGoal, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	c.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+int(readyPodCount) >= targetGoal {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", c.rolloutStatus.CurrentBatch)
+		c.recorder.Event(c.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", c.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", c.rolloutStatus.CurrentBatch)
+	c.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (c *BlueGreenRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	return finalizeOneRolloutBatch(c.rolloutSpec, c.rolloutStatus)
+}
+
+// Finalize scales down the source if the rollout succeeds and releases both the source and the target,
+// the source is kept in its full size if the rollout fails so that it still serves the traffic
+func (c *BlueGreenRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := c.fetchWorkloads(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	if succeed {
+		if err := c.scaleWorkload(ctx, c.source, 0); err != nil {
+			return false
+		}
+	}
+	for _, workload := range []*unstructured.Unstructured{c.source, c.target} {
+		if err := c.releaseWorkload(ctx, workload); err != nil {
+			return false
+		}
+	}
+
+	// mark the resource finalized
+	c.rolloutStatus.LastAppliedPodTemplateIdentifier = c.rolloutStatus.NewPodTemplateIdentifier
+	c.recorder.Event(c.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+/*
+	----------------------------------
+
+The functions below are helper functions
+-------------------------------------
+*/
+func (c *BlueGreenRolloutController) fetchWorkloads(ctx context.Context) error {
+	source, err := c.fetchWorkload(ctx, c.sourceNamespacedName)
+	if err != nil {
+		return err
+	}
+	target, err := c.fetchWorkload(ctx, c.targetNamespacedName)
+	if err != nil {
+		return err
+	}
+	c.source, c.target = source, target
+	return nil
+}
+
+func (c *BlueGreenRolloutController) fetchWorkload(ctx context.Context,
+	namespacedName types.NamespacedName) (*unstructured.Unstructured, error) {
+	workload := &unstructured.Unstructured{}
+	workload.SetGroupVersionKind(c.workloadGVK)
+	if err := c.client.Get(ctx, namespacedName, workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			c.recorder.Event(c.parentController, event.Warning(event.Reason(
+				fmt.Sprintf("Failed to get the %s", c.workloadGVK.Kind)), err))
+		}
+		return nil, err
+	}
+	return workload, nil
+}
+
+// add the parent controller to the owner of the workload, the target is resumed and starts from zero
+func (c *BlueGreenRolloutController) claimWorkload(ctx context.Context, workload *unstructured.Unstructured,
+	isTarget bool) error {
+	if isControlledByRollout(workload) {
+		// it's already there
+		return nil
+	}
+
+	workloadPatch := client.MergeFrom(workload.DeepCopy())
+	ref := metav1.NewControllerRef(c.parentController, c.parentController.GetObjectKind().GroupVersionKind())
+	workload.SetOwnerReferences(append(workload.GetOwnerReferences(), *ref))
+	if isTarget {
+		// the target is paused by the application controller before the rollout
+		for _, pausedPath := range [][]string{{"spec", "paused"}, {"spec", "updateStrategy", "paused"}} {
+			if paused, found, _ := unstructured.NestedBool(workload.Object, pausedPath...); found && paused {
+				_ = unstructured.SetNestedField(workload.Object, false, pausedPath...)
+			}
+		}
+		_ = unstructured.SetNestedField(workload.Object, int64(0), "spec", "replicas")
+	}
+
+	if err := c.client.Patch(ctx, workload, workloadPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(
+			fmt.Sprintf("Failed to the start the %s update", c.workloadGVK.Kind)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+	return nil
+}
+
+// scale the workload
+func (c *BlueGreenRolloutController) scaleWorkload(ctx context.Context, workload *unstructured.Unstructured,
+	size int32) error {
+	if replicas, found, _ := unstructured.NestedInt64(workload.Object, "spec", "replicas"); found &&
+		replicas == int64(size) {
+		return nil
+	}
+	workloadPatch := client.MergeFrom(workload.DeepCopy())
+	_ = unstructured.SetNestedField(workload.Object, int64(size), "spec", "replicas")
+
+	if err := c.client.Patch(ctx, workload, workloadPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(fmt.Sprintf(
+			"Failed to update the %s %s to the correct target %d", c.workloadGVK.Kind, workload.GetName(), size)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+
+	klog.InfoS("Submitted scale quest for the workload", "kind", c.workloadGVK.Kind, "name", workload.GetName(),
+		"target replica size", size, "batch", c.rolloutStatus.CurrentBatch)
+	return nil
+}
+
+// remove the parent controller from the workload's owner list
+func (c *BlueGreenRolloutController) releaseWorkload(ctx context.Context, workload *unstructured.Unstructured) error {
+	newOwnerList, found := removeRolloutOwner(workload)
+	if !found {
+		klog.InfoS("the workload is already released", "kind", c.workloadGVK.Kind, "name", workload.GetName())
+		return nil
+	}
+	workloadPatch := client.MergeFrom(workload.DeepCopy())
+	workload.SetOwnerReferences(newOwnerList)
+
+	if err := c.client.Patch(ctx, workload, workloadPatch, client.FieldOwner(c.parentController.GetUID())); err != nil {
+		c.recorder.Event(c.parentController, event.Warning(event.Reason(
+			fmt.Sprintf("Failed to the release the %s", c.workloadGVK.Kind)), err))
+		c.rolloutStatus.RolloutRetry(err.Error())
+		return err
+	}
+	return nil
+}
+
+// PodTemplateLabels returns the labels in the pod template of the workload
+func PodTemplateLabels(workload *unstructured.Unstructured) map[string]string {
+	labels, _, _ := unstructured.NestedStringMap(workload.Object, "spec", "template", "metadata", "labels")
+	return labels
+}
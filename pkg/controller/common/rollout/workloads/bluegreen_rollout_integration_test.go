@@ -0,0 +1,302 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ = Describe("blue-green rollout controller", func() {
+	var (
+		c                    *BlueGreenRolloutController
+		ns                   corev1.Namespace
+		namespaceName        string
+		sourceName           string
+		targetName           string
+		sourceDeploy         appsv1.Deployment
+		targetDeploy         appsv1.Deployment
+		sourceNamespacedName client.ObjectKey
+		targetNamespacedName client.ObjectKey
+	)
+
+	BeforeEach(func() {
+		By("setup before each test")
+		namespaceName = "rollout-ns"
+		sourceName = "source-blue"
+		targetName = "target-green"
+		appRollout := v1beta1.AppRollout{TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind}, ObjectMeta: metav1.ObjectMeta{Name: "test-rollout"}}
+		sourceNamespacedName = client.ObjectKey{Name: sourceName, Namespace: namespaceName}
+		targetNamespacedName = client.ObjectKey{Name: targetName, Namespace: namespaceName}
+		c = NewBlueGreenRolloutController(k8sClient, event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+			WithAnnotations("controller", "AppRollout"), &appRollout,
+			&v1alpha1.RolloutPlan{
+				RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+				BlueGreen:       &v1alpha1.BlueGreenStrategy{ActiveService: "active"},
+				RolloutBatches: []v1alpha1.RolloutBatch{
+					{
+						Replicas: intstr.FromString("50%"),
+					},
+					{
+						Replicas: intstr.FromString("50%"),
+					},
+				},
+			},
+			&v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState},
+			appsv1.SchemeGroupVersion.WithKind("Deployment"), sourceNamespacedName, targetNamespacedName)
+
+		sourceDeploy = appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespaceName, Name: sourceName},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32Ptr(4),
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "staging", "version": "blue"},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "staging", "version": "blue"}},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: sourceName,
+						Image: "stefanprodan/podinfo:4.0.6"}}},
+				},
+			},
+		}
+
+		targetDeploy = appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: "Deployment"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespaceName, Name: targetName},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32Ptr(4),
+				Paused:   true,
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "staging", "version": "green"},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "staging", "version": "green"}},
+					Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: targetName,
+						Image: "stefanprodan/podinfo:5.0.3"}}},
+				},
+			},
+		}
+
+		ns = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespaceName,
+			},
+		}
+		By("Create a namespace")
+		Expect(k8sClient.Create(ctx, &ns)).Should(SatisfyAny(Succeed(), &util.AlreadyExistMatcher{}))
+	})
+
+	AfterEach(func() {
+		By("clean up")
+		k8sClient.Delete(ctx, &sourceDeploy)
+		k8sClient.Delete(ctx, &targetDeploy)
+	})
+
+	Context("TestVerifySpec", func() {
+		It("the source and the target have to be different", func() {
+			c.targetNamespacedName = sourceNamespacedName
+			verified, err := c.VerifySpec(ctx)
+			Expect(verified).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("different source and target"))
+		})
+
+		It("could not fetch the workloads", func() {
+			verified, err := c.VerifySpec(ctx)
+			Expect(verified).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("the source is still being reconciled", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+
+			By("verify should fail because the source is not stable")
+			verified, err := c.VerifySpec(ctx)
+			Expect(verified).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("the pods of the source and the target can not be told apart", func() {
+			By("Create the source and the target deployment with the same pod labels")
+			targetDeploy.Spec.Selector.MatchLabels = sourceDeploy.Spec.Selector.MatchLabels
+			targetDeploy.Spec.Template.Labels = sourceDeploy.Spec.Template.Labels
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+			sourceDeploy.Status.Replicas = 4
+			Expect(k8sClient.Status().Update(ctx, &sourceDeploy)).Should(Succeed())
+
+			By("verify should fail because the service can not switch between them")
+			verified, err := c.VerifySpec(ctx)
+			Expect(verified).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("the same labels"))
+		})
+
+		It("spec is valid", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+			sourceDeploy.Status.Replicas = 4
+			Expect(k8sClient.Status().Update(ctx, &sourceDeploy)).Should(Succeed())
+
+			By("verify should pass and record the size")
+			verified, err := c.VerifySpec(ctx)
+			Expect(verified).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.RolloutTargetSize).Should(BeEquivalentTo(4))
+			Expect(c.rolloutStatus.RolloutOriginalSize).Should(BeEquivalentTo(4))
+			Expect(c.rolloutStatus.NewPodTemplateIdentifier).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("TestInitialize", func() {
+		It("could not fetch the workloads", func() {
+			initialized, err := c.Initialize(ctx)
+			Expect(initialized).Should(BeFalse())
+			Expect(err).Should(BeNil())
+		})
+
+		It("successfully claims the workloads and resumes the target from zero", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+
+			c.parentController.SetUID("1231586900")
+			initialized, err := c.Initialize(ctx)
+			Expect(initialized).Should(BeTrue())
+			Expect(err).Should(BeNil())
+
+			var source, target appsv1.Deployment
+			Expect(k8sClient.Get(ctx, sourceNamespacedName, &source)).Should(Succeed())
+			Expect(len(source.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			Expect(*source.Spec.Replicas).Should(BeEquivalentTo(4))
+			Expect(k8sClient.Get(ctx, targetNamespacedName, &target)).Should(Succeed())
+			Expect(len(target.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			Expect(target.Spec.Paused).Should(BeFalse())
+			Expect(*target.Spec.Replicas).Should(BeEquivalentTo(0))
+		})
+	})
+
+	Context("TestRolloutOneBatchPods", func() {
+		It("only scales up the target", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			targetDeploy.Spec.Replicas = pointer.Int32Ptr(0)
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+
+			By("rollout the second batch")
+			c.rolloutStatus.CurrentBatch = 1
+			c.rolloutStatus.RolloutTargetSize = 4
+			done, err := c.RolloutOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReplicas).Should(BeEquivalentTo(4))
+
+			var source, target appsv1.Deployment
+			Expect(k8sClient.Get(ctx, targetNamespacedName, &target)).Should(Succeed())
+			Expect(*target.Spec.Replicas).Should(BeEquivalentTo(4))
+			Expect(k8sClient.Get(ctx, sourceNamespacedName, &source)).Should(Succeed())
+			Expect(*source.Spec.Replicas).Should(BeEquivalentTo(4))
+		})
+	})
+
+	Context("TestCheckOneBatchPods", func() {
+		It("the target is not ready yet", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+			targetDeploy.Status.Replicas = 2
+			targetDeploy.Status.ReadyReplicas = 1
+			Expect(k8sClient.Status().Update(ctx, &targetDeploy)).Should(Succeed())
+
+			By("checking should fail as not enough pod ready")
+			c.rolloutStatus.CurrentBatch = 0
+			c.rolloutStatus.RolloutTargetSize = 4
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeFalse())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReadyReplicas).Should(BeEquivalentTo(1))
+		})
+
+		It("the target is ready", func() {
+			By("Create the source and the target deployment")
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+			targetDeploy.Status.Replicas = 2
+			targetDeploy.Status.ReadyReplicas = 2
+			Expect(k8sClient.Status().Update(ctx, &targetDeploy)).Should(Succeed())
+
+			By("checking should pass")
+			c.rolloutStatus.CurrentBatch = 0
+			c.rolloutStatus.RolloutTargetSize = 4
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+		})
+	})
+
+	Context("TestFinalize", func() {
+		BeforeEach(func() {
+			owner := []metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       v1beta1.AppRolloutKind,
+				Name:       "def",
+				UID:        "123456",
+				Controller: pointer.BoolPtr(true),
+			}}
+			sourceDeploy.SetOwnerReferences(owner)
+			targetDeploy.SetOwnerReferences(owner)
+			Expect(k8sClient.Create(ctx, &sourceDeploy)).Should(Succeed())
+			Expect(k8sClient.Create(ctx, &targetDeploy)).Should(Succeed())
+		})
+
+		It("scales down the source when the rollout succeeds", func() {
+			c.rolloutStatus.NewPodTemplateIdentifier = "abc"
+			Expect(c.Finalize(ctx, true)).Should(BeTrue())
+			Expect(c.rolloutStatus.LastAppliedPodTemplateIdentifier).Should(Equal("abc"))
+
+			var source, target appsv1.Deployment
+			Expect(k8sClient.Get(ctx, sourceNamespacedName, &source)).Should(Succeed())
+			Expect(*source.Spec.Replicas).Should(BeEquivalentTo(0))
+			Expect(len(source.GetOwnerReferences())).Should(BeEquivalentTo(0))
+			Expect(k8sClient.Get(ctx, targetNamespacedName, &target)).Should(Succeed())
+			Expect(len(target.GetOwnerReferences())).Should(BeEquivalentTo(0))
+		})
+
+		It("keeps the source when the rollout fails", func() {
+			Expect(c.Finalize(ctx, false)).Should(BeTrue())
+
+			var source appsv1.Deployment
+			Expect(k8sClient.Get(ctx, sourceNamespacedName, &source)).Should(Succeed())
+			Expect(*source.Spec.Replicas).Should(BeEquivalentTo(4))
+			Expect(len(source.GetOwnerReferences())).Should(BeEquivalentTo(0))
+		})
+	})
+})
@@ -20,9 +20,11 @@ import (
 	"fmt"
 
 	apps "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/klog/v2"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
 )
 
@@ -150,3 +152,64 @@ func getStatefulSetReplicas(statefulSet *apps.StatefulSet) int32 {
 	}
 	return 1
 }
+
+// isRolloutOwner checks if the owner reference points to an AppRollout or a Rollout
+func isRolloutOwner(owner metav1.OwnerReference) bool {
+	return owner.Kind == v1beta1.AppRolloutKind && owner.APIVersion == v1beta1.SchemeGroupVersion.String() ||
+		owner.Kind == v1alpha1.RolloutKind && owner.APIVersion == v1alpha1.SchemeGroupVersion.String()
+}
+
+// isControlledByRollout checks if the workload is already claimed by an AppRollout or a Rollout
+func isControlledByRollout(workload metav1.Object) bool {
+	controller := metav1.GetControllerOf(workload)
+	return controller != nil && isRolloutOwner(*controller)
+}
+
+// removeRolloutOwner returns the owner references of the workload without the rollout controller,
+// and whether the rollout controller is found
+func removeRolloutOwner(workload metav1.Object) ([]metav1.OwnerReference, bool) {
+	var newOwnerList []metav1.OwnerReference
+	found := false
+	for _, owner := range workload.GetOwnerReferences() {
+		if isRolloutOwner(owner) && owner.Controller != nil && *owner.Controller {
+			found = true
+			continue
+		}
+		newOwnerList = append(newOwnerList, owner)
+	}
+	return newOwnerList, found
+}
+
+// finalizeOneRolloutBatch makes sure that the upgraded replicas in the status is consistent with the current batch
+// of the rollout plan that upgrades the workload in place
+func finalizeOneRolloutBatch(spec *v1alpha1.RolloutPlan, status *v1alpha1.RolloutStatus) (bool, error) {
+	if spec.BatchPartition != nil && *spec.BatchPartition < status.CurrentBatch {
+		err := fmt.Errorf("the current batch value in the status is greater than the batch partition")
+		klog.ErrorS(err, "we have moved past the user defined partition", "user specified batch partition",
+			*spec.BatchPartition, "current batch we are working on", status.CurrentBatch)
+		return false, err
+	}
+
+	upgradedReplicas := int(status.UpgradedReplicas)
+	currentBatch := int(status.CurrentBatch)
+	// calculate the lower bound of the possible pod count just before the current batch
+	podCount := calculateNewBatchTarget(spec, 0, int(status.RolloutTargetSize), currentBatch-1)
+	// the recorded number should be at least as much as the all the pods before the current batch
+	if podCount > upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is less than all the pods in the previous batch")
+		klog.ErrorS(err, "rollout status inconsistent", "upgraded num status", upgradedReplicas,
+			"pods in all the previous batches", podCount)
+		return false, err
+	}
+
+	// calculate the upper bound with the current batch
+	podCount = calculateNewBatchTarget(spec, 0, int(status.RolloutTargetSize), currentBatch)
+	// the recorded number should be not as much as the all the pods including the active batch
+	if podCount < upgradedReplicas {
+		err := fmt.Errorf("the upgraded replica in the status is greater than all the pods in the current batch")
+		klog.ErrorS(err, "rollout status inconsistent", "total target size", status.RolloutTargetSize,
+			"upgraded num status", upgradedReplicas, "pods in the batches including the current batch", podCount)
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// daemonSetPods holds the pods of a DaemonSet grouped by their revisions
+type daemonSetPods struct {
+	// updated are the pods in the latest revision
+	updated []corev1.Pod
+	// old are the pods in the previous revisions that are not being deleted
+	old []corev1.Pod
+	// terminating is the number of the pods in the previous revisions that are being deleted
+	terminating int
+}
+
+// readyUpdated returns the number of the ready pods in the latest revision
+func (p *daemonSetPods) readyUpdated() int {
+	ready := 0
+	for i := range p.updated {
+		if isPodReady(&p.updated[i]) {
+			ready++
+		}
+	}
+	return ready
+}
+
+// listDaemonSetPods lists the pods controlled by the DaemonSet and groups them by the revision hash
+func listDaemonSetPods(ctx context.Context, c client.Client, daemonSet client.Object, selector *metav1.LabelSelector,
+	revisionHash string) (*daemonSetPods, error) {
+	podSelector, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	podList := &corev1.PodList{}
+	if err := c.List(ctx, podList, client.InNamespace(daemonSet.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: podSelector}); err != nil {
+		return nil, err
+	}
+	pods := &daemonSetPods{}
+	for i := range podList.Items {
+		pod := podList.Items[i]
+		if !metav1.IsControlledBy(&pod, daemonSet) {
+			continue
+		}
+		switch {
+		case pod.Labels[apps.DefaultDaemonSetUniqueLabelKey] == revisionHash:
+			pods.updated = append(pods.updated, pod)
+		case pod.DeletionTimestamp != nil:
+			pods.terminating++
+		default:
+			pods.old = append(pods.old, pod)
+		}
+	}
+	// upgrade the pods in a stable order
+	sort.Slice(pods.old, func(i, j int) bool {
+		return pods.old[i].Name < pods.old[j].Name
+	})
+	return pods, nil
+}
+
+// latestDaemonSetRevisionHash returns the hash of the latest ControllerRevision of the DaemonSet,
+// which is the value of the controller-revision-hash label of the pods in the latest revision
+func latestDaemonSetRevisionHash(ctx context.Context, c client.Client, daemonSet *apps.DaemonSet) (string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(daemonSet.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	revisions := &apps.ControllerRevisionList{}
+	if err := c.List(ctx, revisions, client.InNamespace(daemonSet.Namespace),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", err
+	}
+	var latest *apps.ControllerRevision
+	for i := range revisions.Items {
+		revision := &revisions.Items[i]
+		if !metav1.IsControlledBy(revision, daemonSet) {
+			continue
+		}
+		if latest == nil || revision.Revision > latest.Revision {
+			latest = revision
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("the DaemonSet %s has no revision yet", daemonSet.Name)
+	}
+	return latest.Labels[apps.DefaultDaemonSetUniqueLabelKey], nil
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/pkg/errors"
+	apps "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/controller/utils"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// DaemonSetRolloutController is responsible for handle rollout DaemonSet type of workloads,
+// the DaemonSet is switched to the OnDelete update strategy during the rollout and the pods
+// in the old revision are deleted batch by batch to be recreated in the new revision
+type DaemonSetRolloutController struct {
+	workloadController
+	targetNamespacedName types.NamespacedName
+	daemonSet            *apps.DaemonSet
+}
+
+// NewDaemonSetRolloutController creates DaemonSet rollout controller
+func NewDaemonSetRolloutController(client client.Client, recorder event.Recorder, parentController oam.Object,
+	rolloutSpec *v1alpha1.RolloutPlan, rolloutStatus *v1alpha1.RolloutStatus, targetNamespacedName types.NamespacedName) *DaemonSetRolloutController {
+	return &DaemonSetRolloutController{
+		workloadController: workloadController{
+			client:           client,
+			recorder:         recorder,
+			parentController: parentController,
+			rolloutSpec:      rolloutSpec,
+			rolloutStatus:    rolloutStatus,
+		},
+		targetNamespacedName: targetNamespacedName,
+	}
+}
+
+// VerifySpec verifies that the rollout resource is consistent with the rollout spec
+func (s *DaemonSetRolloutController) VerifySpec(ctx context.Context) (bool, error) {
+	var verifyErr error
+
+	defer func() {
+		if verifyErr != nil {
+			klog.Error(verifyErr)
+			s.recorder.Event(s.parentController, event.Warning("VerifyFailed", verifyErr))
+		}
+	}()
+
+	currentReplicas, verifyErr := s.size(ctx)
+	if verifyErr != nil {
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+	// record the size and we will use this value to drive the rest of the batches
+	klog.InfoS("record the target size", "total replicas", currentReplicas)
+	s.rolloutStatus.RolloutTargetSize = currentReplicas
+	s.rolloutStatus.RolloutOriginalSize = currentReplicas
+
+	// make sure that the spec is different from what we have already done
+	targetHash, verifyErr := utils.ComputeSpecHash(s.daemonSet.Spec)
+	if verifyErr != nil {
+		// do not fail the rollout because we can't compute the hash value for some reason
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	if targetHash == s.rolloutStatus.LastAppliedPodTemplateIdentifier {
+		return false, fmt.Errorf("there is no difference between the source and target, hash = %s", targetHash)
+	}
+
+	if currentReplicas != s.daemonSet.Status.CurrentNumberScheduled {
+		verifyErr = fmt.Errorf("the DaemonSet is still scheduling, target = %d, scheduled = %d",
+			currentReplicas, s.daemonSet.Status.CurrentNumberScheduled)
+		s.rolloutStatus.RolloutRetry(verifyErr.Error())
+		return false, verifyErr
+	}
+
+	// check if the rollout batch replicas added up to the number of the DaemonSet pods
+	if verifyErr = verifyBatchesWithRollout(s.rolloutSpec, currentReplicas); verifyErr != nil {
+		return false, verifyErr
+	}
+
+	// check if the DaemonSet has any controller
+	if controller := metav1.GetControllerOf(s.daemonSet); controller != nil {
+		return false, fmt.Errorf("the DaemonSet %s has a controller owner %s",
+			s.daemonSet.GetName(), controller.String())
+	}
+
+	// mark the rollout verified
+	s.recorder.Event(s.parentController, event.Normal("Rollout Verified",
+		"Rollout spec and the DaemonSet resource are verified"))
+	// record the new pod template identifier on success
+	s.rolloutStatus.NewPodTemplateIdentifier = targetHash
+	return true, nil
+}
+
+// Initialize makes sure that the DaemonSet is under our control and stops replacing the pods by itself
+func (s *DaemonSetRolloutController) Initialize(ctx context.Context) (bool, error) {
+	if err := s.fetchDaemonSet(ctx); err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		// nolint: nilerr
+		return false, nil
+	}
+
+	if isControlledByRollout(s.daemonSet) && s.daemonSet.Spec.UpdateStrategy.Type == apps.OnDeleteDaemonSetStrategyType {
+		// it's already there
+		return true, nil
+	}
+
+	daemonSetPatch := client.MergeFrom(s.daemonSet.DeepCopy())
+	// add the parent controller to the owner of the DaemonSet
+	if !isControlledByRollout(s.daemonSet) {
+		ref := metav1.NewControllerRef(s.parentController, s.parentController.GetObjectKind().GroupVersionKind())
+		s.daemonSet.SetOwnerReferences(append(s.daemonSet.GetOwnerReferences(), *ref))
+	}
+	// the pods are replaced by the rollout batch by batch instead of the DaemonSet controller
+	s.daemonSet.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}
+
+	// patch the DaemonSet
+	if err := s.client.Patch(ctx, s.daemonSet, daemonSetPatch, client.FieldOwner(s.parentController.GetUID())); err != nil {
+		s.recorder.Event(s.parentController, event.Warning("Failed to the start the DaemonSet update", err))
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	// mark the rollout initialized
+	s.recorder.Event(s.parentController, event.Normal("Rollout Initialized", "Rollout resource are initialized"))
+	return true, nil
+}
+
+// RolloutOneBatchPods calculates the number of pods we can upgrade once according to the rollout spec
+// and then deletes the pods in the old revision so that the DaemonSet recreates them in the new revision
+func (s *DaemonSetRolloutController) RolloutOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	pods, err := s.listPods(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	// the pods being deleted are going to be recreated in the new revision
+	toUpgrade := int(newPodTarget) - len(pods.updated) - pods.terminating
+	for i := 0; i < len(pods.old) && toUpgrade > 0; i++ {
+		if err := s.client.Delete(ctx, &pods.old[i]); err != nil && !apierrors.IsNotFound(err) {
+			s.recorder.Event(s.parentController, event.Warning(event.Reason(fmt.Sprintf(
+				"Failed to upgrade the pod %s of DaemonSet %s", pods.old[i].Name, s.daemonSet.GetName())), err))
+			s.rolloutStatus.RolloutRetry(err.Error())
+			return false, nil
+		}
+		toUpgrade--
+	}
+
+	// record the finished upgrade action
+	klog.InfoS("upgraded one batch", "current batch", s.rolloutStatus.CurrentBatch,
+		"target size", newPodTarget)
+	s.recorder.Event(s.parentController, event.Normal("Batch Rollout",
+		fmt.Sprintf("Finished submiting all upgrade quests for batch %d", s.rolloutStatus.CurrentBatch)))
+	s.rolloutStatus.UpgradedReplicas = newPodTarget
+	return true, nil
+}
+
+// CheckOneBatchPods checks to see if the pods in the new revision are all available according to the rollout plan
+func (s *DaemonSetRolloutController) CheckOneBatchPods(ctx context.Context) (bool, error) {
+	currentReplicas, err := s.size(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		return false, nil
+	}
+
+	if len(s.rolloutSpec.RolloutBatches) <= int(s.rolloutStatus.CurrentBatch) {
+		err := errors.New("somehow, currentBatch number exceeded the rolloutBatches spec")
+		klog.ErrorS(err, "total batch", len(s.rolloutSpec.RolloutBatches), "current batch",
+			s.rolloutStatus.CurrentBatch)
+		return false, err
+	}
+
+	pods, err := s.listPods(ctx)
+	if err != nil {
+		s.rolloutStatus.RolloutRetry(err.Error())
+		// nolint:nilerr
+		return false, nil
+	}
+
+	newPodTarget := s.calculateCurrentTarget(currentReplicas)
+	readyPodCount := pods.readyUpdated()
+	currentBatch := s.rolloutSpec.RolloutBatches[s.rolloutStatus.CurrentBatch]
+	maxUnavail := 0
+	if currentBatch.MaxUnavailable != nil {
+		maxUnavail, _ = intstr.GetValueFromIntOrPercent(currentBatch.MaxUnavailable, int(currentReplicas), true)
+	}
+	klog.InfoS("checking the rolling out progress", "current batch", s.rolloutStatus.CurrentBatch,
+		"new pod count target", newPodTarget, "new ready pod count", readyPodCount,
+		"max unavailable pod allowed", maxUnavail)
+	s.rolloutStatus.UpgradedReadyReplicas = int32(readyPodCount)
+
+	if maxUnavail+readyPodCount >= int(newPodTarget) {
+		// record the successful upgrade
+		klog.InfoS("all pods in current batch are ready", "current batch", s.rolloutStatus.CurrentBatch)
+		s.recorder.Event(s.parentController, event.Normal("Batch Available",
+			fmt.Sprintf("Batch %d is available", s.rolloutStatus.CurrentBatch)))
+		return true, nil
+	}
+
+	// continue to verify
+	klog.InfoS("the batch is not ready yet", "current batch", s.rolloutStatus.CurrentBatch)
+	s.rolloutStatus.RolloutRetry("the batch is not ready yet")
+	return false, nil
+}
+
+// FinalizeOneBatch makes sure that the rollout status are updated correctly
+func (s *DaemonSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
+	return finalizeOneRolloutBatch(s.rolloutSpec, s.rolloutStatus)
+}
+
+// Finalize releases the DaemonSet, the rolling update strategy is restored only if the rollout succeeds
+// so that the pods not upgraded by a failed rollout stay in the old revision
+func (s *DaemonSetRolloutController) Finalize(ctx context.Context, succeed bool) bool {
+	if err := s.fetchDaemonSet(ctx); err != nil {
+		// don't fail the rollout just because of we can't get the resource
+		return false
+	}
+
+	newOwnerList, found := removeRolloutOwner(s.daemonSet)
+	restoreStrategy := succeed && s.daemonSet.Spec.UpdateStrategy.Type == apps.OnDeleteDaemonSetStrategyType
+	if found || restoreStrategy {
+		daemonSetPatch := client.MergeFrom(s.daemonSet.DeepCopy())
+		s.daemonSet.SetOwnerReferences(newOwnerList)
+		if restoreStrategy {
+			s.daemonSet.Spec.UpdateStrategy.Type = apps.RollingUpdateDaemonSetStrategyType
+		}
+		// patch the DaemonSet
+		if err := s.client.Patch(ctx, s.daemonSet, daemonSetPatch, client.FieldOwner(s.parentController.GetUID())); err != nil {
+			s.recorder.Event(s.parentController, event.Warning("Failed to the release the DaemonSet", err))
+			s.rolloutStatus.RolloutRetry(err.Error())
+			return false
+		}
+	}
+
+	// mark the resource finalized
+	s.rolloutStatus.LastAppliedPodTemplateIdentifier = s.rolloutStatus.NewPodTemplateIdentifier
+	s.recorder.Event(s.parentController, event.Normal("Rollout Finalized",
+		fmt.Sprintf("Rollout resource are finalized, succeed := %t", succeed)))
+	return true
+}
+
+// the number of the pods in the new revision for the current batch
+func (s *DaemonSetRolloutController) calculateCurrentTarget(totalSize int32) int32 {
+	targetSize := int32(calculateNewBatchTarget(s.rolloutSpec, 0, int(totalSize), int(s.rolloutStatus.CurrentBatch)))
+	klog.InfoS("Calculated the number of pods in the new revision after current batch",
+		"current batch", s.rolloutStatus.CurrentBatch, "target DaemonSet size", targetSize)
+	return targetSize
+}
+
+// listPods lists the pods of the DaemonSet once the DaemonSet controller observes the latest spec
+func (s *DaemonSetRolloutController) listPods(ctx context.Context) (*daemonSetPods, error) {
+	if s.daemonSet.Status.ObservedGeneration < s.daemonSet.Generation {
+		return nil, fmt.Errorf("the DaemonSet %s is not observed by its controller yet", s.daemonSet.Name)
+	}
+	revisionHash, err := latestDaemonSetRevisionHash(ctx, s.client, s.daemonSet)
+	if err != nil {
+		return nil, err
+	}
+	return listDaemonSetPods(ctx, s.client, s.daemonSet, s.daemonSet.Spec.Selector, revisionHash)
+}
+
+func (s *DaemonSetRolloutController) fetchDaemonSet(ctx context.Context) error {
+	workload := apps.DaemonSet{}
+	if err := s.client.Get(ctx, s.targetNamespacedName, &workload); err != nil {
+		if !apierrors.IsNotFound(err) {
+			s.recorder.Event(s.parentController, event.Warning("Failed to get the DaemonSet", err))
+		}
+		return err
+	}
+	s.daemonSet = &workload
+	return nil
+}
+
+// size returns the number of the nodes that should run the DaemonSet pod
+func (s *DaemonSetRolloutController) size(ctx context.Context) (int32, error) {
+	if s.daemonSet == nil {
+		if err := s.fetchDaemonSet(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return s.daemonSet.Status.DesiredNumberScheduled, nil
+}
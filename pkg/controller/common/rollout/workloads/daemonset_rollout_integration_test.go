@@ -0,0 +1,416 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	apps "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+var _ = Describe("DaemonSet rollout controller", func() {
+	var (
+		c              DaemonSetRolloutController
+		ns             corev1.Namespace
+		name           string
+		namespace      string
+		daemonSet      apps.DaemonSet
+		namespacedName client.ObjectKey
+	)
+
+	const (
+		oldRevision = "old-revision"
+		newRevision = "new-revision"
+	)
+
+	// createPods creates the pods of the DaemonSet in the given revision
+	createPods := func(revision string, count int, ready bool) {
+		for i := 0; i < count; i++ {
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      fmt.Sprintf("%s-%s-%d", name, revision, i),
+					Labels: map[string]string{
+						"env":                               "staging",
+						apps.DefaultDaemonSetUniqueLabelKey: revision,
+					},
+					OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&daemonSet,
+						apps.SchemeGroupVersion.WithKind("DaemonSet"))},
+				},
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+			}
+			Expect(k8sClient.Create(ctx, &pod)).Should(Succeed())
+			if ready {
+				pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+				Expect(k8sClient.Status().Update(ctx, &pod)).Should(Succeed())
+			}
+		}
+	}
+
+	// createDaemonSet creates the DaemonSet with its latest revision and marks all the nodes scheduled
+	createDaemonSet := func(size int32) {
+		Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+		revision := apps.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: namespace,
+				Name:      fmt.Sprintf("%s-%s", name, newRevision),
+				Labels: map[string]string{
+					"env":                               "staging",
+					apps.DefaultDaemonSetUniqueLabelKey: newRevision,
+				},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(&daemonSet,
+					apps.SchemeGroupVersion.WithKind("DaemonSet"))},
+			},
+			Data:     runtime.RawExtension{Raw: []byte("{}")},
+			Revision: 2,
+		}
+		Expect(k8sClient.Create(ctx, &revision)).Should(Succeed())
+		daemonSet.Status.DesiredNumberScheduled = size
+		daemonSet.Status.CurrentNumberScheduled = size
+		daemonSet.Status.ObservedGeneration = daemonSet.Generation
+		Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+	}
+
+	BeforeEach(func() {
+		namespace = "rollout-ns"
+		name = "daemonset-rollout"
+		appRollout := v1beta1.AppRollout{TypeMeta: metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.AppRolloutKind}, ObjectMeta: metav1.ObjectMeta{Name: name}}
+		namespacedName = client.ObjectKey{Name: name, Namespace: namespace}
+		c = DaemonSetRolloutController{
+			workloadController: workloadController{
+				client: k8sClient,
+				rolloutSpec: &v1alpha1.RolloutPlan{
+					RolloutBatches: []v1alpha1.RolloutBatch{
+						{
+							Replicas: intstr.FromInt(1),
+						},
+					},
+				},
+				rolloutStatus:    &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState},
+				parentController: &appRollout,
+				recorder: event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+					WithAnnotations("controller", "AppRollout"),
+			},
+			targetNamespacedName: namespacedName,
+		}
+
+		daemonSet = apps.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{APIVersion: apps.SchemeGroupVersion.String(), Kind: "DaemonSet"},
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Spec: apps.DaemonSetSpec{
+				Selector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{"env": "staging"},
+				},
+				Template: corev1.PodTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"env": "staging"}},
+					Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: name, Image: "nginx"}}},
+				},
+			},
+		}
+
+		ns = corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		By("Create a namespace")
+		Expect(k8sClient.Create(ctx, &ns)).Should(SatisfyAny(Succeed(), &util.AlreadyExistMatcher{}))
+	})
+
+	AfterEach(func() {
+		By("clean up")
+		k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace(namespace), client.MatchingLabels{"env": "staging"},
+			client.GracePeriodSeconds(0))
+		k8sClient.DeleteAllOf(ctx, &apps.ControllerRevision{}, client.InNamespace(namespace),
+			client.MatchingLabels{"env": "staging"})
+		k8sClient.Delete(ctx, &daemonSet)
+	})
+
+	Context("TestNewDaemonSetRolloutController", func() {
+		It("init a DaemonSet Rollout Controller", func() {
+			recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor("AppRollout")).
+				WithAnnotations("controller", "AppRollout")
+			parentController := &v1beta1.AppRollout{ObjectMeta: metav1.ObjectMeta{Name: name}}
+			rolloutSpec := &v1alpha1.RolloutPlan{
+				RolloutBatches: []v1alpha1.RolloutBatch{{
+					Replicas: intstr.FromInt(1),
+				},
+				},
+			}
+			rolloutStatus := &v1alpha1.RolloutStatus{RollingState: v1alpha1.RolloutSucceedState}
+			workloadNamespacedName := client.ObjectKey{Name: name, Namespace: namespace}
+			got := NewDaemonSetRolloutController(k8sClient, recorder, parentController, rolloutSpec, rolloutStatus, workloadNamespacedName)
+			c := &DaemonSetRolloutController{
+				workloadController: workloadController{
+					client:           k8sClient,
+					recorder:         recorder,
+					parentController: parentController,
+					rolloutSpec:      rolloutSpec,
+					rolloutStatus:    rolloutStatus,
+				},
+				targetNamespacedName: workloadNamespacedName,
+			}
+			Expect(got).Should(Equal(c))
+		})
+	})
+
+	Context("VerifySpec", func() {
+		It("could not fetch DaemonSet workload", func() {
+			consistent, err := c.VerifySpec(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("the DaemonSet need to be scheduled", func() {
+			By("create the DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+			daemonSet.Status.DesiredNumberScheduled = 5
+			daemonSet.Status.CurrentNumberScheduled = 3
+			Expect(k8sClient.Status().Update(ctx, &daemonSet)).Should(Succeed())
+
+			By("setting a dummy pod identifier so it's different")
+			c.rolloutStatus.LastAppliedPodTemplateIdentifier = "abc"
+
+			By("verify should fail because the DaemonSet is still scheduling")
+			consistent, err := c.VerifySpec(ctx)
+			Expect(consistent).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("is still scheduling"))
+			Expect(c.rolloutStatus.RolloutTargetSize).Should(BeEquivalentTo(5))
+			Expect(c.rolloutStatus.NewPodTemplateIdentifier).Should(BeEmpty())
+		})
+
+		It("the DaemonSet should not have controller", func() {
+			By("Create a DaemonSet")
+			daemonSet.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       v1beta1.ApplicationKind,
+				Name:       "def",
+				UID:        "123456",
+				Controller: pointer.BoolPtr(true),
+			}})
+			createDaemonSet(1)
+
+			By("setting a dummy pod identifier so it's different")
+			c.rolloutStatus.LastAppliedPodTemplateIdentifier = "abc"
+
+			By("verify should fail because the DaemonSet still has a controller")
+			consistent, err := c.VerifySpec(ctx)
+			Expect(consistent).Should(BeFalse())
+			Expect(err.Error()).Should(ContainSubstring("has a controller owner"))
+		})
+
+		It("spec is valid", func() {
+			By("Create a DaemonSet")
+			createDaemonSet(1)
+
+			By("setting a dummy pod identifier so it's different")
+			c.rolloutStatus.LastAppliedPodTemplateIdentifier = "abc"
+
+			By("verify should succeed")
+			consistent, err := c.VerifySpec(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeTrue())
+			Expect(c.rolloutStatus.RolloutTargetSize).Should(BeEquivalentTo(1))
+			Expect(c.rolloutStatus.NewPodTemplateIdentifier).ShouldNot(BeEmpty())
+		})
+	})
+
+	Context("TestInitialize", func() {
+		It("could not fetch DaemonSet workload", func() {
+			consistent, err := c.Initialize(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("successfully initialized DaemonSet", func() {
+			By("create DaemonSet")
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+
+			By("initialize succeeds")
+			c.parentController.SetUID("1231586900")
+			initialized, err := c.Initialize(ctx)
+			Expect(initialized).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &daemonSet)).Should(Succeed())
+			Expect(len(daemonSet.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			Expect(daemonSet.Spec.UpdateStrategy.Type).Should(Equal(apps.OnDeleteDaemonSetStrategyType))
+		})
+	})
+
+	Context("TestRolloutOneBatchPods", func() {
+		BeforeEach(func() {
+			c.rolloutSpec.RolloutBatches = []v1alpha1.RolloutBatch{
+				{
+					Replicas: intstr.FromInt(1),
+				},
+				{
+					Replicas: intstr.FromString("20%"),
+				},
+				{
+					Replicas: intstr.FromString("80%"),
+				},
+			}
+		})
+
+		It("could not fetch DaemonSet workload", func() {
+			consistent, err := c.RolloutOneBatchPods(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("successfully rollout the second batch", func() {
+			By("Create a DaemonSet with one pod upgraded")
+			createDaemonSet(10)
+			createPods(newRevision, 1, true)
+			createPods(oldRevision, 9, true)
+
+			By("rollout the second batch of current DaemonSet")
+			c.rolloutStatus.CurrentBatch = 1
+			done, err := c.RolloutOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReplicas).Should(BeEquivalentTo(3))
+
+			By("two more pods in the old revision should be deleted")
+			pods, err := listDaemonSetPods(ctx, k8sClient, &daemonSet, daemonSet.Spec.Selector, newRevision)
+			Expect(err).Should(BeNil())
+			Expect(len(pods.updated)).Should(BeEquivalentTo(1))
+			Expect(len(pods.old)).Should(BeEquivalentTo(7))
+		})
+	})
+
+	Context("TestCheckOneBatchPods", func() {
+		BeforeEach(func() {
+			c.rolloutSpec.RolloutBatches = []v1alpha1.RolloutBatch{
+				{
+					Replicas: intstr.FromInt(2),
+				},
+				{
+					Replicas: intstr.FromString("20%"),
+				},
+				{
+					Replicas: intstr.FromString("80%"),
+				},
+			}
+		})
+
+		It("could not fetch DaemonSet workload", func() {
+			consistent, err := c.CheckOneBatchPods(ctx)
+			Expect(err).Should(BeNil())
+			Expect(consistent).Should(BeFalse())
+		})
+
+		It("current ready Pod is less than expected", func() {
+			By("Create the DaemonSet")
+			createDaemonSet(10)
+			createPods(newRevision, 4, false)
+
+			By("checking should fail as not enough pod ready")
+			c.rolloutStatus.CurrentBatch = 1
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeFalse())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReadyReplicas).Should(BeEquivalentTo(0))
+		})
+
+		It("there are enough pods ready", func() {
+			By("Create the DaemonSet")
+			createDaemonSet(10)
+			createPods(newRevision, 4, true)
+			createPods(oldRevision, 6, true)
+
+			By("checking the second batch")
+			c.rolloutStatus.CurrentBatch = 1
+			done, err := c.CheckOneBatchPods(ctx)
+			Expect(done).Should(BeTrue())
+			Expect(err).Should(BeNil())
+			Expect(c.rolloutStatus.UpgradedReadyReplicas).Should(BeEquivalentTo(4))
+		})
+	})
+
+	Context("TestFinalize", func() {
+		It("failed to fetch DaemonSet", func() {
+			By("finalizing")
+			finalized := c.Finalize(ctx, true)
+			Expect(finalized).Should(BeFalse())
+		})
+
+		It("keep the OnDelete strategy when the rollout fails", func() {
+			By("Create a DaemonSet")
+			daemonSet.SetOwnerReferences([]metav1.OwnerReference{{
+				APIVersion: v1beta1.SchemeGroupVersion.String(),
+				Kind:       v1beta1.AppRolloutKind,
+				Name:       "def",
+				UID:        "123456",
+				Controller: pointer.Bool(true),
+			}})
+			daemonSet.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+
+			By("finalizing with patch")
+			finalized := c.Finalize(ctx, false)
+			Expect(finalized).Should(BeTrue())
+			var released apps.DaemonSet
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &released)).Should(Succeed())
+			Expect(len(released.GetOwnerReferences())).Should(BeEquivalentTo(0))
+			Expect(released.Spec.UpdateStrategy.Type).Should(Equal(apps.OnDeleteDaemonSetStrategyType))
+		})
+
+		It("successfully to finalize DaemonSet", func() {
+			By("Create a DaemonSet")
+			daemonSet.SetOwnerReferences([]metav1.OwnerReference{
+				{
+					APIVersion: v1beta1.SchemeGroupVersion.String(),
+					Kind:       v1beta1.AppRolloutKind,
+					Name:       "def",
+					UID:        "123456",
+					Controller: pointer.Bool(true),
+				},
+				{
+					APIVersion: corev1.SchemeGroupVersion.String(),
+					Kind:       "Deployment",
+					Name:       "def",
+					UID:        "998877745",
+				},
+			})
+			daemonSet.Spec.UpdateStrategy = apps.DaemonSetUpdateStrategy{Type: apps.OnDeleteDaemonSetStrategyType}
+			Expect(k8sClient.Create(ctx, &daemonSet)).Should(Succeed())
+
+			By("finalizing with patch")
+			finalized := c.Finalize(ctx, true)
+			Expect(finalized).Should(BeTrue())
+			Expect(k8sClient.Get(ctx, c.targetNamespacedName, &daemonSet)).Should(Succeed())
+			Expect(len(daemonSet.GetOwnerReferences())).Should(BeEquivalentTo(1))
+			Expect(daemonSet.GetOwnerReferences()[0].Kind).Should(Equal("Deployment"))
+			Expect(daemonSet.Spec.UpdateStrategy.Type).Should(Equal(apps.RollingUpdateDaemonSetStrategyType))
+		})
+	})
+})
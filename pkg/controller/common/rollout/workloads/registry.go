@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// ControllerArgs holds everything needed to create a WorkloadController for the rollout plan
+type ControllerArgs struct {
+	Client           client.Client
+	Recorder         event.Recorder
+	ParentController oam.Object
+	RolloutSpec      *v1alpha1.RolloutPlan
+	RolloutStatus    *v1alpha1.RolloutStatus
+	// Source is the workload to roll out from, it is nil if the rollout plan only scales the target
+	Source *types.NamespacedName
+	// Target is the workload to roll out to
+	Target types.NamespacedName
+	// GroupVersionKind is the kind of the source and the target workload
+	GroupVersionKind schema.GroupVersionKind
+}
+
+// ControllerFactory creates the WorkloadController for one kind of workload
+type ControllerFactory func(args ControllerArgs) (WorkloadController, error)
+
+var (
+	controllerFactories         = map[schema.GroupKind]ControllerFactory{}
+	strategyControllerFactories = map[v1alpha1.RolloutStrategyType]ControllerFactory{}
+	controllerFactoriesLock     sync.RWMutex
+)
+
+// RegisterController registers the factory of the WorkloadController for the workload kind of all the versions,
+// the factory registered before for the same kind is replaced
+func RegisterController(gk schema.GroupKind, factory ControllerFactory) {
+	controllerFactoriesLock.Lock()
+	defer controllerFactoriesLock.Unlock()
+	controllerFactories[gk] = factory
+}
+
+// RegisterStrategyController registers the factory of the WorkloadController for the rollout strategy, which works on
+// any kind of workload. It takes precedence over the factories of the kinds when rolling out from a source workload.
+func RegisterStrategyController(strategy v1alpha1.RolloutStrategyType, factory ControllerFactory) {
+	controllerFactoriesLock.Lock()
+	defer controllerFactoriesLock.Unlock()
+	strategyControllerFactories[strategy] = factory
+}
+
+// NewController creates the WorkloadController registered for the rollout strategy, or the one registered for the group
+// and kind of the workload whatever its version
+func NewController(gvk schema.GroupVersionKind, args ControllerArgs) (WorkloadController, error) {
+	args.GroupVersionKind = gvk
+	controllerFactoriesLock.RLock()
+	factory, ok := controllerFactories[gvk.GroupKind()]
+	if args.Source != nil && args.RolloutSpec != nil {
+		if strategyFactory, found := strategyControllerFactories[args.RolloutSpec.RolloutStrategy]; found {
+			factory, ok = strategyFactory, true
+		}
+	}
+	controllerFactoriesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("the workload kind `%s` is not supported", gvk.String())
+	}
+	return factory(args)
+}
+
+func init() {
+	RegisterController(schema.GroupKind{Group: kruise.GroupVersion.Group, Kind: reflect.TypeOf(kruise.CloneSet{}).Name()}, newCloneSetController)
+	RegisterController(schema.GroupKind{Group: apps.GroupName, Kind: reflect.TypeOf(apps.Deployment{}).Name()}, newDeploymentController)
+	RegisterController(schema.GroupKind{Group: apps.GroupName, Kind: reflect.TypeOf(apps.StatefulSet{}).Name()}, newStatefulSetController)
+	RegisterController(schema.GroupKind{Group: apps.GroupName, Kind: reflect.TypeOf(apps.DaemonSet{}).Name()}, newDaemonSetController)
+	// the advanced StatefulSet of v1alpha1 is served by the same object of v1beta1
+	RegisterController(schema.GroupKind{Group: kruise.GroupVersion.Group, Kind: reflect.TypeOf(kruise.StatefulSet{}).Name()}, newAdvancedStatefulSetController)
+	RegisterController(schema.GroupKind{Group: kruise.GroupVersion.Group, Kind: reflect.TypeOf(kruise.DaemonSet{}).Name()}, newAdvancedDaemonSetController)
+	// the blue-green rollout works on any kind of workload with replicas
+	RegisterStrategyController(v1alpha1.BlueGreenRolloutStrategyType, newBlueGreenController)
+}
+
+func newBlueGreenController(args ControllerArgs) (WorkloadController, error) {
+	klog.InfoS("using blue-green rollout controller for this rolloutplan", "kind", args.GroupVersionKind.Kind,
+		"source workload name", args.Source.Name, "target workload name", args.Target.Name,
+		"namespace", args.Target.Namespace)
+	return NewBlueGreenRolloutController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.GroupVersionKind, *args.Source, args.Target), nil
+}
+
+func newCloneSetController(args ControllerArgs) (WorkloadController, error) {
+	// check whether current rollout plan is for workload rolling or scaling
+	if args.Source != nil {
+		klog.InfoS("using cloneset rollout controller for this rolloutplan", "source workload name", args.Source.Name, "namespace",
+			args.Source.Namespace, "target workload name", args.Target.Name, "namespace", args.Target.Namespace)
+		return NewCloneSetRolloutController(args.Client, args.Recorder, args.ParentController,
+			args.RolloutSpec, args.RolloutStatus, args.Target), nil
+	}
+	klog.InfoS("using cloneset scale controller for this rolloutplan", "target workload name", args.Target.Name, "namespace",
+		args.Target.Namespace)
+	return NewCloneSetScaleController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
+
+func newDeploymentController(args ControllerArgs) (WorkloadController, error) {
+	// check whether current rollout plan is for workload rolling or scaling
+	if args.Source != nil {
+		klog.InfoS("using deployment rollout controller for this rolloutplan", "source workload name", args.Source.Name, "namespace",
+			args.Source.Namespace, "target workload name", args.Target.Name, "namespace", args.Target.Namespace)
+		return NewDeploymentRolloutController(args.Client, args.Recorder, args.ParentController,
+			args.RolloutSpec, args.RolloutStatus, *args.Source, args.Target), nil
+	}
+	klog.InfoS("using deployment scale controller for this rolloutplan", "target workload name", args.Target.Name, "namespace",
+		args.Target.Namespace)
+	return NewDeploymentScaleController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
+
+func newStatefulSetController(args ControllerArgs) (WorkloadController, error) {
+	// check whether current rollout plan is for workload rolling or scaling
+	if args.Source != nil {
+		return NewStatefulSetRolloutController(args.Client, args.Recorder, args.ParentController,
+			args.RolloutSpec, args.RolloutStatus, args.Target), nil
+	}
+	return NewStatefulSetScaleController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
+
+func newDaemonSetController(args ControllerArgs) (WorkloadController, error) {
+	// the size of a DaemonSet is decided by the nodes, so it is always rolled out in place
+	klog.InfoS("using daemonset rollout controller for this rolloutplan", "target workload name", args.Target.Name,
+		"namespace", args.Target.Namespace)
+	return NewDaemonSetRolloutController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
+
+func newAdvancedStatefulSetController(args ControllerArgs) (WorkloadController, error) {
+	// check whether current rollout plan is for workload rolling or scaling
+	if args.Source != nil {
+		klog.InfoS("using advanced statefulset rollout controller for this rolloutplan", "target workload name",
+			args.Target.Name, "namespace", args.Target.Namespace)
+		return NewAdvancedStatefulSetRolloutController(args.Client, args.Recorder, args.ParentController,
+			args.RolloutSpec, args.RolloutStatus, args.Target), nil
+	}
+	klog.InfoS("using advanced statefulset scale controller for this rolloutplan", "target workload name",
+		args.Target.Name, "namespace", args.Target.Namespace)
+	return NewAdvancedStatefulSetScaleController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
+
+func newAdvancedDaemonSetController(args ControllerArgs) (WorkloadController, error) {
+	// the size of a DaemonSet is decided by the nodes, so it is always rolled out in place
+	klog.InfoS("using advanced daemonset rollout controller for this rolloutplan", "target workload name",
+		args.Target.Name, "namespace", args.Target.Namespace)
+	return NewAdvancedDaemonSetRolloutController(args.Client, args.Recorder, args.ParentController,
+		args.RolloutSpec, args.RolloutStatus, args.Target), nil
+}
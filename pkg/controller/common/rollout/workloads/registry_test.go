@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workloads
+
+import (
+	"reflect"
+	"testing"
+
+	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
+	apps "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/apis/standard.oam.dev/v1alpha1"
+)
+
+type fakeWorkloadController struct {
+	WorkloadController
+	args ControllerArgs
+}
+
+func TestNewController(t *testing.T) {
+	source := &types.NamespacedName{Namespace: "default", Name: "source"}
+	target := types.NamespacedName{Namespace: "default", Name: "target"}
+	blueGreen := &v1alpha1.RolloutPlan{RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType}
+	cases := map[string]struct {
+		gvk    schema.GroupVersionKind
+		source *types.NamespacedName
+		spec   *v1alpha1.RolloutPlan
+		want   WorkloadController
+	}{
+		"CloneSetRollout": {
+			gvk:    kruise.SchemeGroupVersion.WithKind(reflect.TypeOf(kruise.CloneSet{}).Name()),
+			source: source,
+			want:   &CloneSetRolloutController{},
+		},
+		"CloneSetScale": {
+			gvk:  kruise.SchemeGroupVersion.WithKind(reflect.TypeOf(kruise.CloneSet{}).Name()),
+			want: &CloneSetScaleController{},
+		},
+		"DeploymentRollout": {
+			gvk:    apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.Deployment{}).Name()),
+			source: source,
+			want:   &DeploymentRolloutController{},
+		},
+		"DeploymentScale": {
+			gvk:  apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.Deployment{}).Name()),
+			want: &DeploymentScaleController{},
+		},
+		"StatefulSetRollout": {
+			gvk:    apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.StatefulSet{}).Name()),
+			source: source,
+			want:   &StatefulSetRolloutController{},
+		},
+		"StatefulSetScale": {
+			gvk:  apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.StatefulSet{}).Name()),
+			want: &StatefulSetScaleController{},
+		},
+		"DaemonSetRollout": {
+			gvk:    apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.DaemonSet{}).Name()),
+			source: source,
+			want:   &DaemonSetRolloutController{},
+		},
+		"DaemonSetWithoutSource": {
+			gvk:  apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.DaemonSet{}).Name()),
+			want: &DaemonSetRolloutController{},
+		},
+		"AdvancedStatefulSetRollout": {
+			gvk:    kruisev1beta1.SchemeGroupVersion.WithKind(reflect.TypeOf(kruisev1beta1.StatefulSet{}).Name()),
+			source: source,
+			want:   &AdvancedStatefulSetRolloutController{},
+		},
+		"AdvancedStatefulSetScale": {
+			gvk:  kruisev1beta1.SchemeGroupVersion.WithKind(reflect.TypeOf(kruisev1beta1.StatefulSet{}).Name()),
+			want: &AdvancedStatefulSetScaleController{},
+		},
+		"AdvancedStatefulSetV1alpha1": {
+			gvk:    kruise.SchemeGroupVersion.WithKind(reflect.TypeOf(kruise.StatefulSet{}).Name()),
+			source: source,
+			want:   &AdvancedStatefulSetRolloutController{},
+		},
+		"DeploymentOfAnotherVersion": {
+			gvk:    schema.GroupVersionKind{Group: apps.GroupName, Version: "v1beta2", Kind: reflect.TypeOf(apps.Deployment{}).Name()},
+			source: source,
+			want:   &DeploymentRolloutController{},
+		},
+		"AdvancedDaemonSetRollout": {
+			gvk:    kruise.SchemeGroupVersion.WithKind(reflect.TypeOf(kruise.DaemonSet{}).Name()),
+			source: source,
+			want:   &AdvancedDaemonSetRolloutController{},
+		},
+		"DeploymentBlueGreen": {
+			gvk:    apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.Deployment{}).Name()),
+			source: source,
+			spec:   blueGreen,
+			want:   &BlueGreenRolloutController{},
+		},
+		"UnregisteredKindBlueGreen": {
+			gvk:    schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Workload"},
+			source: source,
+			spec:   blueGreen,
+			want:   &BlueGreenRolloutController{},
+		},
+		"DeploymentScaleBlueGreen": {
+			gvk:  apps.SchemeGroupVersion.WithKind(reflect.TypeOf(apps.Deployment{}).Name()),
+			spec: blueGreen,
+			want: &DeploymentScaleController{},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewController(tc.gvk, ControllerArgs{Source: tc.source, Target: target, RolloutSpec: tc.spec})
+			if err != nil {
+				t.Fatalf("\n%s\nNewController(...): unexpected error %v", name, err)
+			}
+			if reflect.TypeOf(got) != reflect.TypeOf(tc.want) {
+				t.Errorf("\n%s\nNewController(...): want %T, got %T", name, tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRegisterController(t *testing.T) {
+	gk := schema.GroupKind{Group: "example.com", Kind: "Workload"}
+	if _, err := NewController(gk.WithVersion("v1"), ControllerArgs{}); err == nil {
+		t.Fatal("NewController(...): the unregistered workload kind should not be supported")
+	}
+
+	RegisterController(gk, func(args ControllerArgs) (WorkloadController, error) {
+		return &fakeWorkloadController{args: args}, nil
+	})
+	defer func() {
+		controllerFactoriesLock.Lock()
+		delete(controllerFactories, gk)
+		controllerFactoriesLock.Unlock()
+	}()
+
+	target := types.NamespacedName{Namespace: "default", Name: "target"}
+	// the controller is registered for all the versions of the kind
+	for _, version := range []string{"v1", "v2beta1"} {
+		got, err := NewController(gk.WithVersion(version), ControllerArgs{Target: target})
+		if err != nil {
+			t.Fatalf("NewController(...): unexpected error %v", err)
+		}
+		controller, ok := got.(*fakeWorkloadController)
+		if !ok {
+			t.Fatalf("NewController(...): want the registered controller, got %T", got)
+		}
+		if controller.args.Target != target {
+			t.Errorf("NewController(...): want target %s, got %s", target, controller.args.Target)
+		}
+	}
+	if _, err := NewController(schema.GroupVersionKind{Group: "another.example.com", Version: "v1", Kind: "Workload"}, ControllerArgs{}); err == nil {
+		t.Fatal("NewController(...): the kind of another group should not be supported")
+	}
+}
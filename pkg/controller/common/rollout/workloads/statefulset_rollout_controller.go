@@ -211,38 +211,7 @@ func (s *StatefulSetRolloutController) CheckOneBatchPods(ctx context.Context) (b
 
 // FinalizeOneBatch makes sure that the rollout status are updated correctly
 func (s *StatefulSetRolloutController) FinalizeOneBatch(ctx context.Context) (bool, error) {
-	status := s.rolloutStatus
-	spec := s.rolloutSpec
-
-	if spec.BatchPartition != nil && *spec.BatchPartition < status.CurrentBatch {
-		err := fmt.Errorf("the current batch value in the status is greater than the batch partition")
-		klog.ErrorS(err, "we have moved past the user defined partition", "user specified batch partition",
-			*spec.BatchPartition, "current batch we are working on", status.CurrentBatch)
-		return false, err
-	}
-
-	upgradedReplicas := int(status.UpgradedReplicas)
-	currentBatch := int(status.CurrentBatch)
-	// calculate the lower bound of the possible pod count just before the current batch
-	podCount := calculateNewBatchTarget(s.rolloutSpec, 0, int(s.rolloutStatus.RolloutTargetSize), currentBatch-1)
-	// the recorded number should be at least as much as the all the pods before the current batch
-	if podCount > upgradedReplicas {
-		err := fmt.Errorf("the upgraded replica in the status is less than all the pods in the previous batch")
-		klog.ErrorS(err, "rollout status inconsistent", "upgraded num status", upgradedReplicas,
-			"pods in all the previous batches", podCount)
-		return false, err
-	}
-
-	// calculate the upper bound with the current batch
-	podCount = calculateNewBatchTarget(s.rolloutSpec, 0, int(s.rolloutStatus.RolloutTargetSize), currentBatch)
-	// the recorded number should be not as much as the all the pods including the active batch
-	if podCount < upgradedReplicas {
-		err := fmt.Errorf("the upgraded replica in the status is greater than all the pods in the current batch")
-		klog.ErrorS(err, "rollout status inconsistent", "total target size", s.rolloutStatus.RolloutTargetSize,
-			"upgraded num status", upgradedReplicas, "pods in the batches including the current batch", podCount)
-		return false, err
-	}
-	return true, nil
+	return finalizeOneRolloutBatch(s.rolloutSpec, s.rolloutStatus)
 }
 
 // Finalize makes sure the StatefulSet is all upgraded
@@ -28,6 +28,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -70,6 +71,7 @@ var _ = BeforeSuite(func(done Done) {
 	Expect(err).NotTo(HaveOccurred())
 
 	Expect(kruise.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
+	Expect(kruisev1beta1.AddToScheme(scheme.Scheme)).NotTo(HaveOccurred())
 
 	By("Create the k8s client")
 	k8sClient, err = client.New(cfg, client.Options{Scheme: scheme.Scheme})
@@ -80,4 +80,20 @@ type Args struct {
 
 	// OAMSpecVer is the oam spec version controller want to setup
 	OAMSpecVer string
+
+	// ClusterHealthProbeInterval is the interval to probe the health of the managed clusters
+	ClusterHealthProbeInterval time.Duration
+
+	// ClusterCredentialExpiryWarning is how long ahead of the expiration of the cluster credential to warn
+	ClusterCredentialExpiryWarning time.Duration
+
+	// EnableSharding makes the controller only reconcile the applications assigned to its shard
+	EnableSharding bool
+	// ShardID is the id of the shard the controller runs for, the master shard also assigns the applications to the
+	// live shards
+	ShardID string
+	// ShardLeaseDuration is how long a shard is considered alive after its last heartbeat
+	ShardLeaseDuration time.Duration
+	// ShardLeaseNamespace is the namespace of the leases recording the heartbeats of the shards
+	ShardLeaseNamespace string
 }
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	ctrlEvent "sigs.k8s.io/controller-runtime/pkg/event"
 	ctrlHandler "sigs.k8s.io/controller-runtime/pkg/handler"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -40,6 +41,7 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/event"
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
@@ -49,8 +51,11 @@ import (
 	common2 "github.com/oam-dev/kubevela/pkg/controller/common"
 	core "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/assemble"
+	"github.com/oam-dev/kubevela/pkg/controller/sharding"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	monitorContext "github.com/oam-dev/kubevela/pkg/monitor/context"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	"github.com/oam-dev/kubevela/pkg/monitor/tracing"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
@@ -81,6 +86,8 @@ type Reconciler struct {
 	Recorder             event.Recorder
 	appRevisionLimit     int
 	concurrentReconciles int
+	// shardID is the id of the shard the controller runs for, empty if sharding is disabled
+	shardID string
 }
 
 // +kubebuilder:rbac:groups=core.oam.dev,resources=applications,verbs=get;list;watch;create;update;patch;delete
@@ -93,6 +100,7 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	defer cancel()
 
 	logCtx := monitorContext.NewTraceContext(ctx, "").AddTag("application", req.String(), "controller", "application")
+	monitorContext.SetSpanName(logCtx, "reconcile")
 	logCtx.Info("Reconcile application")
 	defer logCtx.Commit("Reconcile application")
 	app := new(v1beta1.Application)
@@ -105,10 +113,19 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
+	// the master shard caches the applications of all the shards, only reconcile the ones assigned to it
+	if !sharding.IsAssigned(app, r.shardID) {
+		return ctrl.Result{}, nil
+	}
+
+	defer func(begin time.Time) {
+		metrics.ApplicationReconcileTimeHistogram.WithLabelValues(string(app.Status.Phase)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
 
 	logCtx.AddTag("resource_version", app.ResourceVersion)
 	ctx = oamutil.SetNamespaceInCtx(ctx, app.Namespace)
 	logCtx.SetContext(ctx)
+	ctx = logCtx.GetContext()
 	if annotations := app.GetAnnotations(); annotations == nil || annotations[oam.AnnotationKubeVelaVersion] == "" {
 		metav1.SetMetaDataAnnotation(&app.ObjectMeta, oam.AnnotationKubeVelaVersion, version.VelaVersion)
 	}
@@ -128,7 +145,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		return result, nil
 	}
 
-	appFile, err := appParser.GenerateAppFile(logCtx, app)
+	parseCtx, parseSpan := tracing.StartSpan(logCtx, "parse")
+	appFile, err := appParser.GenerateAppFile(parseCtx, app)
+	tracing.EndSpan(parseSpan, err)
 	if err != nil {
 		logCtx.Error(err, "Failed to parse application")
 		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedParse, err))
@@ -137,16 +156,22 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 	app.Status.SetConditions(condition.ReadyCondition("Parsed"))
 	r.Recorder.Event(app, event.Normal(velatypes.ReasonParsed, velatypes.MessageParsed))
 
-	if err := handler.PrepareCurrentAppRevision(logCtx, appFile); err != nil {
+	revisionCtx, revisionSpan := tracing.StartSpan(logCtx, "revision")
+	if err := handler.PrepareCurrentAppRevision(revisionCtx, appFile); err != nil {
+		tracing.EndSpan(revisionSpan, err)
 		logCtx.Error(err, "Failed to prepare app revision")
 		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
 		return r.endWithNegativeCondition(logCtx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
 	}
-	if err := handler.FinalizeAndApplyAppRevision(logCtx); err != nil {
+	if err := handler.FinalizeAndApplyAppRevision(revisionCtx); err != nil {
+		tracing.EndSpan(revisionSpan, err)
 		logCtx.Error(err, "Failed to apply app revision")
 		r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRevision, err))
 		return r.endWithNegativeCondition(logCtx, app, condition.ErrorCondition("Revision", err), common.ApplicationRendering)
 	}
+	revisionSpan.SetAttributes(attribute.String("revision", handler.currentAppRev.Name),
+		attribute.Bool("isNewRevision", handler.isNewRevision))
+	tracing.EndSpan(revisionSpan, nil)
 	logCtx.Info("Successfully prepare current app revision", "revisionName", handler.currentAppRev.Name,
 		"revisionHash", handler.currentRevHash, "isNewRevision", handler.isNewRevision)
 	app.Status.SetConditions(condition.ReadyCondition("Revision"))
@@ -232,7 +257,9 @@ func (r *Reconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Resu
 		}
 	} else {
 		var comps []*velatypes.ComponentManifest
+		_, renderSpan := tracing.StartSpan(logCtx, "render")
 		comps, err = appFile.GenerateComponentManifests()
+		tracing.EndSpan(renderSpan, err)
 		if err != nil {
 			logCtx.Error(err, "Failed to render components")
 			r.Recorder.Event(app, event.Warning(velatypes.ReasonFailedRender, err))
@@ -359,6 +386,7 @@ func (r *Reconciler) handleFinalizers(ctx monitorContext.Context, app *v1beta1.A
 }
 
 func (r *Reconciler) endWithNegativeCondition(ctx context.Context, app *v1beta1.Application, condition condition.Condition, phase common.ApplicationPhase) (ctrl.Result, error) {
+	metrics.ApplicationReconcileErrorCounter.WithLabelValues(string(phase)).Inc()
 	app.SetConditions(condition)
 	if err := r.patchStatus(ctx, app, phase); err != nil {
 		return ctrl.Result{}, errors.WithMessage(err, "cannot update application status")
@@ -498,6 +526,13 @@ func Setup(mgr ctrl.Manager, args core.Args) error {
 		appRevisionLimit:     args.AppRevisionLimit,
 		concurrentReconciles: args.ConcurrentReconciles,
 	}
+	if args.EnableSharding {
+		reconciler.shardID = args.ShardID
+	}
+	// the applications and resource trackers are read from the cache shared with the controller
+	if err := ctrlmetrics.Registry.Register(metrics.NewApplicationCollector(mgr.GetCache())); err != nil {
+		klog.ErrorS(err, "failed to register the application metrics")
+	}
 	return reconciler.SetupWithManager(mgr)
 }
 
@@ -130,7 +130,9 @@ func PrepareWorkloadForRollout(rolloutComp string) WorkloadOption {
 			// below are the resources that we know how to disable
 			cloneSetDisablePath            = "spec.updateStrategy.paused"
 			advancedStatefulSetDisablePath = "spec.updateStrategy.rollingUpdate.paused"
+			advancedDaemonSetDisablePath   = "spec.updateStrategy.rollingUpdate.paused"
 			deploymentDisablePath          = "spec.paused"
+			daemonSetUpdateStrategyPath    = "spec.updateStrategy.type"
 		)
 		pv := fieldpath.Pave(assembledWorkload.UnstructuredContent())
 		// TODO: we can get the workloadDefinition name from workload.GetLabels()["oam.WorkloadTypeLabel"]
@@ -154,6 +156,14 @@ func PrepareWorkloadForRollout(rolloutComp string) WorkloadOption {
 				klog.InfoS("we render an advanced statefulset assembledWorkload.paused on the first time",
 					"kind", assembledWorkload.GetKind(), "instance name", assembledWorkload.GetName())
 				return nil
+			case reflect.TypeOf(kruisev1alpha1.DaemonSet{}).Name():
+				err := pv.SetBool(advancedDaemonSetDisablePath, true)
+				if err != nil {
+					return err
+				}
+				klog.InfoS("we render an advanced daemonset assembledWorkload.paused on the first time",
+					"kind", assembledWorkload.GetKind(), "instance name", assembledWorkload.GetName())
+				return nil
 			}
 		}
 
@@ -169,6 +179,14 @@ func PrepareWorkloadForRollout(rolloutComp string) WorkloadOption {
 			case reflect.TypeOf(appsv1.StatefulSet{}).Name():
 				// TODO: Pause StatefulSet here.
 				return nil
+			case reflect.TypeOf(appsv1.DaemonSet{}).Name():
+				// the DaemonSet can't be paused, it stops replacing the pods by itself with the OnDelete strategy
+				if err := pv.SetString(daemonSetUpdateStrategyPath, string(appsv1.OnDeleteDaemonSetStrategyType)); err != nil {
+					return err
+				}
+				klog.InfoS("we render a daemonset assembledWorkload with OnDelete update strategy on the first time",
+					"kind", assembledWorkload.GetKind(), "instance name", assembledWorkload.GetName())
+				return nil
 			}
 		}
 
@@ -22,6 +22,8 @@ import (
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -31,6 +33,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/application/assemble"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/monitor/tracing"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
@@ -130,8 +133,8 @@ func convertStepProperties(step *v1beta1.WorkflowStep, app *v1beta1.Application)
 }
 
 func (h *AppHandler) renderComponentFunc(appParser *appfile.Parser, appRev *v1beta1.ApplicationRevision, af *appfile.Appfile) oamProvider.ComponentRender {
-	return func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
-		ctx := multicluster.ContextWithClusterName(context.Background(), clusterName)
+	return func(baseCtx context.Context, comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
+		ctx := multicluster.ContextWithClusterName(contextWithSpan(baseCtx), clusterName)
 
 		_, manifest, err := h.prepareWorkloadAndManifests(ctx, appParser, comp, appRev, patcher, af)
 		if err != nil {
@@ -142,8 +145,8 @@ func (h *AppHandler) renderComponentFunc(appParser *appfile.Parser, appRev *v1be
 }
 
 func (h *AppHandler) applyComponentFunc(appParser *appfile.Parser, appRev *v1beta1.ApplicationRevision, af *appfile.Appfile) oamProvider.ComponentApply {
-	return func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
-		ctx := multicluster.ContextWithClusterName(context.Background(), clusterName)
+	return func(baseCtx context.Context, comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error) {
+		ctx := multicluster.ContextWithClusterName(contextWithSpan(baseCtx), clusterName)
 		ctx = contextWithComponentRevisionNamespace(ctx, overrideNamespace)
 		ctx = envbinding.ContextWithEnvName(ctx, env)
 
@@ -192,14 +195,12 @@ func (h *AppHandler) prepareWorkloadAndManifests(ctx context.Context,
 	appRev *v1beta1.ApplicationRevision,
 	patcher *value.Value,
 	af *appfile.Appfile) (*appfile.Workload, *types.ComponentManifest, error) {
-	wl, err := appParser.ParseWorkloadFromRevision(comp, appRev)
+	_, span := tracing.StartSpan(ctx, "render", attribute.String("component", comp.Name),
+		attribute.String("cluster", multicluster.ClusterNameInContext(ctx)))
+	wl, manifest, err := renderComponentManifest(appParser, comp, appRev, patcher, af)
+	tracing.EndSpan(span, err)
 	if err != nil {
-		return nil, nil, errors.WithMessage(err, "ParseWorkload")
-	}
-	wl.Patch = patcher
-	manifest, err := af.GenerateComponentManifest(wl)
-	if err != nil {
-		return nil, nil, errors.WithMessage(err, "GenerateComponentManifest")
+		return nil, nil, err
 	}
 	if err := af.SetOAMContract(manifest); err != nil {
 		return nil, nil, errors.WithMessage(err, "SetOAMContract")
@@ -211,6 +212,27 @@ func (h *AppHandler) prepareWorkloadAndManifests(ctx context.Context,
 	return wl, manifest, nil
 }
 
+// renderComponentManifest renders the manifest of the component from the CUE templates of the definitions
+func renderComponentManifest(appParser *appfile.Parser, comp common.ApplicationComponent, appRev *v1beta1.ApplicationRevision,
+	patcher *value.Value, af *appfile.Appfile) (*appfile.Workload, *types.ComponentManifest, error) {
+	wl, err := appParser.ParseWorkloadFromRevision(comp, appRev)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "ParseWorkload")
+	}
+	wl.Patch = patcher
+	manifest, err := af.GenerateComponentManifest(wl)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "GenerateComponentManifest")
+	}
+	return wl, manifest, nil
+}
+
+// contextWithSpan returns a context only carrying the span of the step, so the component is traced within the step
+// without inheriting the deadline of the reconcile
+func contextWithSpan(ctx context.Context) context.Context {
+	return trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+}
+
 func renderComponentsAndTraits(client client.Client, manifest *types.ComponentManifest, appRev *v1beta1.ApplicationRevision, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, error) {
 	readyWorkload, readyTraits, err := assemble.PrepareBeforeApply(manifest, appRev, []assemble.WorkloadOption{assemble.DiscoveryHelmBasedWorkload(context.TODO(), client)})
 	if err != nil {
@@ -237,7 +237,7 @@ var _ = Describe("Test Application workflow generator", func() {
 				},
 			},
 		}
-		_, _, err = renderFunc(comp, nil, "", "", "")
+		_, _, err = renderFunc(context.Background(), comp, nil, "", "", "")
 		Expect(err).Should(BeNil())
 	})
 })
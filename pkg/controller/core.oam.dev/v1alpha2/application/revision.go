@@ -198,7 +198,7 @@ func (h *AppHandler) gatherRevisionSpec(af *appfile.Appfile) (*v1beta1.Applicati
 		if w.FullTemplate.ComponentDefinition != nil {
 			cd := w.FullTemplate.ComponentDefinition.DeepCopy()
 			cd.Status = v1beta1.ComponentDefinitionStatus{}
-			appRev.Spec.ComponentDefinitions[w.FullTemplate.ComponentDefinition.Name] = *cd
+			appRev.Spec.ComponentDefinitions[snapshotName(cd.Name, w.FullTemplate)] = *cd
 		}
 		if w.FullTemplate.WorkloadDefinition != nil {
 			wd := w.FullTemplate.WorkloadDefinition.DeepCopy()
@@ -212,7 +212,7 @@ func (h *AppHandler) gatherRevisionSpec(af *appfile.Appfile) (*v1beta1.Applicati
 			if t.FullTemplate.TraitDefinition != nil {
 				td := t.FullTemplate.TraitDefinition.DeepCopy()
 				td.Status = v1beta1.TraitDefinitionStatus{}
-				appRev.Spec.TraitDefinitions[t.FullTemplate.TraitDefinition.Name] = *td
+				appRev.Spec.TraitDefinitions[snapshotName(td.Name, t.FullTemplate)] = *td
 			}
 		}
 		for _, s := range w.ScopeDefinition {
@@ -232,9 +232,12 @@ func (h *AppHandler) gatherRevisionSpec(af *appfile.Appfile) (*v1beta1.Applicati
 		if p.FullTemplate.PolicyDefinition != nil {
 			pd := p.FullTemplate.PolicyDefinition.DeepCopy()
 			pd.Status = v1beta1.PolicyDefinitionStatus{}
-			appRev.Spec.PolicyDefinitions[p.FullTemplate.PolicyDefinition.Name] = *pd
+			appRev.Spec.PolicyDefinitions[snapshotName(pd.Name, p.FullTemplate)] = *pd
 		}
 	}
+	for name, wd := range af.RelatedWorkflowStepDefinitions {
+		appRev.Spec.WorkflowStepDefinitions[name] = *wd.DeepCopy()
+	}
 
 	appRevisionHash, err := ComputeAppRevisionHash(appRev)
 	if err != nil {
@@ -258,6 +261,15 @@ func (h *AppHandler) getLatestAppRevision(ctx context.Context) error {
 	return nil
 }
 
+// snapshotName returns the key of the definition snapshot in app revision,
+// the pinned definition revision is recorded as name@version to avoid overriding the latest one
+func snapshotName(defName string, tmpl *appfile.Template) string {
+	if tmpl.PinnedRevisionName != "" {
+		return tmpl.PinnedRevisionName
+	}
+	return defName
+}
+
 // ComputeAppRevisionHash computes a single hash value for an appRevision object
 // Spec of Application/WorkloadDefinitions/ComponentDefinitions/TraitDefinitions/ScopeDefinitions will be taken into compute
 func ComputeAppRevisionHash(appRevision *v1beta1.ApplicationRevision) (string, error) {
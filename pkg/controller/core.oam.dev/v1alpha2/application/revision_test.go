@@ -20,12 +20,14 @@ import (
 	"context"
 	"encoding/json"
 	"strconv"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/yaml"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
@@ -44,6 +47,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/cue/model"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 )
 
 var _ = Describe("test generate revision ", func() {
@@ -801,3 +805,61 @@ var _ = Describe("Test remove SkipAppRev func", func() {
 		Expect(res.Components[0].Traits[1].Type).Should(BeEquivalentTo("service"))
 	})
 })
+
+func TestGatherRevisionSpecWithPinnedDefinitions(t *testing.T) {
+	r := require.New(t)
+	newDefRev := func(name string, defType common.DefinitionType, spec v1beta1.DefinitionRevisionSpec) *v1beta1.DefinitionRevision {
+		spec.DefinitionType = defType
+		return &v1beta1.DefinitionRevision{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: oam.SystemDefinitonNamespace}, Spec: spec}
+	}
+	schematic := &common.Schematic{CUE: &common.CUE{Template: "parameter: {}"}}
+	cli := fake.NewClientBuilder().WithScheme(common2.Scheme).WithObjects(
+		newDefRev("worker-v1", common.ComponentType, v1beta1.DefinitionRevisionSpec{ComponentDefinition: v1beta1.ComponentDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+			Spec:       v1beta1.ComponentDefinitionSpec{Workload: common.WorkloadTypeDescriptor{Type: "deployments.apps"}, Schematic: schematic},
+		}}),
+		newDefRev("scaler-v2", common.TraitType, v1beta1.DefinitionRevisionSpec{TraitDefinition: v1beta1.TraitDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler"},
+			Spec:       v1beta1.TraitDefinitionSpec{Schematic: schematic},
+		}}),
+		newDefRev("override-v1", common.PolicyType, v1beta1.DefinitionRevisionSpec{PolicyDefinition: v1beta1.PolicyDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "override"},
+			Spec:       v1beta1.PolicyDefinitionSpec{Schematic: schematic},
+		}}),
+		newDefRev("notify-v1", common.WorkflowStepType, v1beta1.DefinitionRevisionSpec{WorkflowStepDefinition: v1beta1.WorkflowStepDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: "notify"},
+			Spec:       v1beta1.WorkflowStepDefinitionSpec{Schematic: schematic},
+		}}),
+	).Build()
+
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "pinned", Namespace: "default"},
+		Spec: v1beta1.ApplicationSpec{
+			Components: []common.ApplicationComponent{{
+				Name:   "web",
+				Type:   "worker@v1",
+				Traits: []common.ApplicationTrait{{Type: "scaler@v2"}},
+			}},
+			Policies: []v1beta1.AppPolicy{{Name: "override", Type: "override@v1"}},
+			Workflow: &v1beta1.Workflow{Steps: []v1beta1.WorkflowStep{
+				{Name: "deploy", Type: "apply-component"},
+				{Name: "notify", Type: "notify@v1"},
+			}},
+		},
+	}
+	af, err := appfile.NewApplicationParser(cli, nil, nil).GenerateAppFile(context.Background(), app)
+	r.NoError(err)
+	handler := &AppHandler{app: app}
+	appRev, _, err := handler.gatherRevisionSpec(af)
+	r.NoError(err)
+
+	r.Contains(appRev.Spec.ComponentDefinitions, "worker@v1")
+	r.Contains(appRev.Spec.TraitDefinitions, "scaler@v2")
+	r.Contains(appRev.Spec.PolicyDefinitions, "override@v1")
+	r.Equal(1, len(appRev.Spec.WorkflowStepDefinitions))
+	r.Equal("notify", appRev.Spec.WorkflowStepDefinitions["notify@v1"].Name)
+
+	af, err = appfile.NewApplicationParser(nil, nil, nil).GenerateAppFileFromRevision(appRev)
+	r.NoError(err)
+	r.Contains(af.RelatedWorkflowStepDefinitions, "notify@v1")
+}
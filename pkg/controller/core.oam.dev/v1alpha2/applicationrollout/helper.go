@@ -48,14 +48,17 @@ func RolloutWorkloadName(rolloutComp string) assemble.WorkloadOption {
 		}
 
 		// we hard code the behavior depends on the workload group/kind for now. The only in-place upgradable resources
-		// we support is cloneset/statefulset for now. We can easily add more later.
+		// we support is cloneset/statefulset/daemonset and their kruise advanced versions for now. We can easily add more later.
 		supportInplaceUpgrade := false
 		if w.GroupVersionKind().Group == v1alpha1.GroupVersion.Group {
-			if w.GetKind() == reflect.TypeOf(v1alpha1.CloneSet{}).Name() {
+			switch w.GetKind() {
+			case reflect.TypeOf(v1alpha1.CloneSet{}).Name(), reflect.TypeOf(v1alpha1.StatefulSet{}).Name(),
+				reflect.TypeOf(v1alpha1.DaemonSet{}).Name():
 				supportInplaceUpgrade = true
 			}
 		} else if w.GroupVersionKind().Group == appsv1.GroupName {
-			if w.GetKind() == reflect.TypeOf(appsv1.StatefulSet{}).Name() {
+			switch w.GetKind() {
+			case reflect.TypeOf(appsv1.StatefulSet{}).Name(), reflect.TypeOf(appsv1.DaemonSet{}).Name():
 				supportInplaceUpgrade = true
 			}
 		}
@@ -86,6 +89,11 @@ func HandleReplicas(ctx context.Context, rolloutComp string, c client.Client) as
 			return nil
 		}
 
+		// the number of the DaemonSet pods is decided by the nodes instead of the replicas
+		if u.GetKind() == reflect.TypeOf(appsv1.DaemonSet{}).Name() {
+			return nil
+		}
+
 		pv := fieldpath.Pave(u.UnstructuredContent())
 
 		// we hard code here, but we can easily support more types of workload by add more cases logic in switch
@@ -28,10 +28,15 @@ import (
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core/policies/policydefinition"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core/traits/traitdefinition"
 	"github.com/oam-dev/kubevela/pkg/controller/core.oam.dev/v1alpha2/core/workflow/workflowstepdefinition"
+	"github.com/oam-dev/kubevela/pkg/controller/sharding"
 )
 
 // Setup workload controllers.
 func Setup(mgr ctrl.Manager, args controller.Args) error {
+	if !sharding.IsMaster(args) {
+		// the shards other than the master only reconcile the applications assigned to them
+		return application.Setup(mgr, args)
+	}
 	switch args.OAMSpecVer {
 	case "all":
 		for _, setup := range []func(ctrl.Manager, controller.Args) error{
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// A Heartbeat keeps renewing the lease of the shard, so the scheduler knows the shard is alive.
+type Heartbeat struct {
+	client        client.Client
+	shardID       string
+	namespace     string
+	leaseDuration time.Duration
+}
+
+// Start renews the lease periodically until the context is done.
+func (h *Heartbeat) Start(ctx context.Context) error {
+	klog.InfoS("Start the heartbeat of the shard", "shard", h.shardID, "leaseDuration", h.leaseDuration)
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := h.RenewLease(ctx); err != nil {
+			klog.ErrorS(err, "Failed to renew the lease of the shard", "shard", h.shardID)
+		}
+	}, h.leaseDuration/3)
+	return nil
+}
+
+// NeedLeaderElection makes only the leader of the shard renew the lease, the shard is alive as long as it has a leader.
+func (h *Heartbeat) NeedLeaderElection() bool {
+	return true
+}
+
+// RenewLease creates the lease of the shard if it doesn't exist, or updates its renew time.
+func (h *Heartbeat) RenewLease(ctx context.Context) error {
+	now := metav1.NewMicroTime(time.Now())
+	lease := &coordinationv1.Lease{}
+	err := h.client.Get(ctx, client.ObjectKey{Namespace: h.namespace, Name: leaseNamePrefix + h.shardID}, lease)
+	if kerrors.IsNotFound(err) {
+		lease = &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: h.namespace,
+				Name:      leaseNamePrefix + h.shardID,
+				Labels:    map[string]string{oam.LabelControllerShardID: h.shardID},
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       pointer.StringPtr(h.shardID),
+				LeaseDurationSeconds: pointer.Int32Ptr(int32(h.leaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}
+		return h.client.Create(ctx, lease)
+	}
+	if err != nil {
+		return err
+	}
+	lease.Spec.LeaseDurationSeconds = pointer.Int32Ptr(int32(h.leaseDuration.Seconds()))
+	lease.Spec.RenewTime = &now
+	return h.client.Update(ctx, lease)
+}
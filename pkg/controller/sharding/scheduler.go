@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/metadata/metadatainformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+// A Scheduler assigns the applications without a shard or on a dead shard to the live shards. The applications are
+// watched by their metadata only, and the shards are alive as long as their leases are renewed. The applications on live
+// shards are never moved, so a new shard only takes the applications created or released by dead shards after it joins.
+type Scheduler struct {
+	client        client.Client
+	metadata      metadata.Interface
+	namespace     string
+	leaseDuration time.Duration
+
+	queue    workqueue.RateLimitingInterface
+	informer cache.SharedIndexInformer
+
+	mu     sync.RWMutex
+	shards []string
+}
+
+// NewScheduler creates a scheduler assigning the applications to the shards whose leases are in the namespace.
+func NewScheduler(c client.Client, metadataClient metadata.Interface, namespace string, leaseDuration time.Duration) *Scheduler {
+	return &Scheduler{
+		client:        c,
+		metadata:      metadataClient,
+		namespace:     namespace,
+		leaseDuration: leaseDuration,
+		queue:         workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "shard-scheduler"),
+	}
+}
+
+// Start watches the applications and schedules them until the context is done.
+func (s *Scheduler) Start(ctx context.Context) error {
+	klog.InfoS("Start the shard scheduler", "leaseNamespace", s.namespace)
+	defer s.queue.ShutDown()
+
+	s.informer = metadatainformer.NewFilteredMetadataInformer(s.metadata, v1beta1.SchemeGroupVersion.WithResource("applications"),
+		metav1.NamespaceAll, 0, cache.Indexers{}, nil).Informer()
+	s.informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    s.enqueue,
+		UpdateFunc: func(_, obj interface{}) { s.enqueue(obj) },
+	})
+	go s.informer.Run(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), s.informer.HasSynced) {
+		return errors.New("failed to wait for the caches of the applications to sync")
+	}
+
+	go wait.UntilWithContext(ctx, s.RefreshShards, s.leaseDuration/2)
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		for s.processNextItem(ctx) {
+		}
+	}, time.Second)
+	<-ctx.Done()
+	return nil
+}
+
+// NeedLeaderElection makes only the leader of the master shard schedule the applications.
+func (s *Scheduler) NeedLeaderElection() bool {
+	return true
+}
+
+// RefreshShards finds out the live shards by their leases, the applications are scheduled again if the shards change.
+func (s *Scheduler) RefreshShards(ctx context.Context) {
+	leases := &coordinationv1.LeaseList{}
+	if err := s.client.List(ctx, leases, client.InNamespace(s.namespace), client.HasLabels{oam.LabelControllerShardID}); err != nil {
+		klog.ErrorS(err, "Failed to list the leases of the shards")
+		return
+	}
+	shards := aliveShards(leases.Items, time.Now())
+
+	s.mu.Lock()
+	changed := !reflect.DeepEqual(shards, s.shards)
+	s.shards = shards
+	s.mu.Unlock()
+	if !changed {
+		return
+	}
+	klog.InfoS("The live shards changed", "shards", shards)
+	if s.informer == nil {
+		return
+	}
+	for _, obj := range s.informer.GetStore().List() {
+		s.enqueue(obj)
+	}
+}
+
+// aliveShards returns the sorted ids of the shards whose leases have not expired.
+func aliveShards(leases []coordinationv1.Lease, now time.Time) []string {
+	var shards []string
+	for _, lease := range leases {
+		if lease.Spec.RenewTime == nil || lease.Spec.LeaseDurationSeconds == nil {
+			continue
+		}
+		expiry := lease.Spec.RenewTime.Add(time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second)
+		if now.Before(expiry) {
+			shards = append(shards, lease.Labels[oam.LabelControllerShardID])
+		}
+	}
+	sort.Strings(shards)
+	return shards
+}
+
+func (s *Scheduler) liveShards() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.shards
+}
+
+func (s *Scheduler) enqueue(obj interface{}) {
+	accessor, ok := obj.(metav1.Object)
+	if !ok || !needSchedule(accessor.GetLabels()[oam.LabelControllerShardID], s.liveShards()) {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		klog.ErrorS(err, "Failed to get the key of the application")
+		return
+	}
+	s.queue.Add(key)
+}
+
+func (s *Scheduler) processNextItem(ctx context.Context) bool {
+	item, shutdown := s.queue.Get()
+	if shutdown {
+		return false
+	}
+	defer s.queue.Done(item)
+	key := item.(string)
+
+	obj, exists, err := s.informer.GetStore().GetByKey(key)
+	if err != nil || !exists {
+		s.queue.Forget(item)
+		return true
+	}
+	app, ok := obj.(metav1.Object)
+	if !ok {
+		s.queue.Forget(item)
+		return true
+	}
+	if err := s.Schedule(ctx, app.GetNamespace(), app.GetName(), app.GetLabels()[oam.LabelControllerShardID]); err != nil {
+		klog.ErrorS(err, "Failed to schedule the application", "application", key)
+		s.queue.AddRateLimited(item)
+		return true
+	}
+	s.queue.Forget(item)
+	return true
+}
+
+// Schedule assigns the application to a live shard if it has no shard or its shard is dead.
+func (s *Scheduler) Schedule(ctx context.Context, namespace, name, currentShard string) error {
+	shards := s.liveShards()
+	if !needSchedule(currentShard, shards) {
+		return nil
+	}
+	shard := pickShard(namespace+"/"+name, shards)
+	if shard == "" {
+		klog.InfoS("No live shard to schedule the application to", "application", klog.KRef(namespace, name))
+		return nil
+	}
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, oam.LabelControllerShardID, shard)
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	if err := s.client.Patch(ctx, app, client.RawPatch(ktypes.MergePatchType, []byte(patch))); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	klog.InfoS("Schedule the application", "application", klog.KRef(namespace, name), "from", currentShard, "to", shard)
+	return nil
+}
+
+// needSchedule checks if the application needs to be assigned to another shard.
+func needSchedule(currentShard string, shards []string) bool {
+	if len(shards) == 0 {
+		return false
+	}
+	if currentShard == "" {
+		return true
+	}
+	i := sort.SearchStrings(shards, currentShard)
+	return i == len(shards) || shards[i] != currentShard
+}
+
+// pickShard picks one of the shards by the hash of the key, so that the applications are spread over the shards.
+func pickShard(key string, shards []string) string {
+	if len(shards) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return shards[h.Sum32()%uint32(len(shards))]
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/metadata"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// MasterShardID is the id of the master shard, which assigns the applications to the live shards besides
+	// reconciling the applications assigned to itself
+	MasterShardID = "master"
+
+	defaultLeaseDuration = 40 * time.Second
+	// leaseNamePrefix is the prefix of the names of the leases recording the heartbeats of the shards
+	leaseNamePrefix = "kubevela-shard-"
+)
+
+// IsMaster checks if the controller runs for the master shard, which is always true if sharding is disabled.
+func IsMaster(args controller.Args) bool {
+	return !args.EnableSharding || args.ShardID == MasterShardID
+}
+
+// NewCache builds the cache of the shards other than the master, which only lists and watches the applications assigned
+// to the shard. The master shard keeps the cache of all the applications, as the controllers only running in the master
+// shard, such as the AppRollout controller and the HealthScope controller, read the applications of all the shards.
+func NewCache(shardID string) cache.NewCacheFunc {
+	return cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{
+			&v1beta1.Application{}: {Label: labels.SelectorFromSet(labels.Set{oam.LabelControllerShardID: shardID})},
+		},
+	})
+}
+
+// IsAssigned checks if the object is assigned to the shard, the shard id is empty if sharding is disabled and everything
+// is assigned to the only controller then.
+func IsAssigned(obj metav1.Object, shardID string) bool {
+	return shardID == "" || obj.GetLabels()[oam.LabelControllerShardID] == shardID
+}
+
+// Setup adds the heartbeat of the shard to the manager, and the scheduler assigning the applications to the live
+// shards if it is the master shard. Nothing is added if sharding is disabled.
+func Setup(mgr ctrl.Manager, args controller.Args) error {
+	if !args.EnableSharding {
+		return nil
+	}
+	if args.ShardID == "" {
+		return errors.New("the shard id must be set if sharding is enabled")
+	}
+	leaseDuration := args.ShardLeaseDuration
+	if leaseDuration <= 0 {
+		leaseDuration = defaultLeaseDuration
+	}
+	namespace := args.ShardLeaseNamespace
+	if namespace == "" {
+		namespace = types.DefaultKubeVelaNS
+	}
+	// the leases are not read from the cache, so that the leases of all the nodes are not cached
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return errors.Wrap(err, "cannot create client for sharding")
+	}
+	if err := mgr.Add(&Heartbeat{
+		client:        c,
+		shardID:       args.ShardID,
+		namespace:     namespace,
+		leaseDuration: leaseDuration,
+	}); err != nil {
+		return err
+	}
+	if args.ShardID != MasterShardID {
+		return nil
+	}
+	metadataClient, err := metadata.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return errors.Wrap(err, "cannot create metadata client for the shard scheduler")
+	}
+	return mgr.Add(NewScheduler(c, metadataClient, namespace, leaseDuration))
+}
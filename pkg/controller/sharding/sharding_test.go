@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	metadatafake "k8s.io/client-go/metadata/fake"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newLease(shard string, renewTime time.Time) *coordinationv1.Lease {
+	renew := metav1.NewMicroTime(renewTime)
+	return &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "vela-system",
+			Name:      leaseNamePrefix + shard,
+			Labels:    map[string]string{oam.LabelControllerShardID: shard},
+		},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       pointer.StringPtr(shard),
+			LeaseDurationSeconds: pointer.Int32Ptr(40),
+			RenewTime:            &renew,
+		},
+	}
+}
+
+func TestIsMaster(t *testing.T) {
+	require.True(t, IsMaster(controller.Args{}))
+	require.True(t, IsMaster(controller.Args{EnableSharding: true, ShardID: MasterShardID}))
+	require.False(t, IsMaster(controller.Args{EnableSharding: true, ShardID: "shard-1"}))
+}
+
+func TestIsAssigned(t *testing.T) {
+	app := &v1beta1.Application{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{oam.LabelControllerShardID: "shard-1"}}}
+	require.True(t, IsAssigned(app, ""))
+	require.True(t, IsAssigned(app, "shard-1"))
+	require.False(t, IsAssigned(app, MasterShardID))
+	require.False(t, IsAssigned(&v1beta1.Application{}, MasterShardID))
+}
+
+func TestAliveShards(t *testing.T) {
+	now := time.Now()
+	leases := []coordinationv1.Lease{
+		*newLease("shard-2", now.Add(-10*time.Second)),
+		*newLease("shard-1", now),
+		*newLease("dead", now.Add(-time.Minute)),
+		{ObjectMeta: metav1.ObjectMeta{Name: "unknown"}},
+	}
+	require.Equal(t, []string{"shard-1", "shard-2"}, aliveShards(leases, now))
+	require.Nil(t, aliveShards(nil, now))
+}
+
+func TestPickShard(t *testing.T) {
+	shards := []string{"master", "shard-1", "shard-2"}
+	require.Equal(t, "", pickShard("default/app", nil))
+	require.Equal(t, pickShard("default/app", shards), pickShard("default/app", shards))
+	picked := map[string]bool{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		picked[pickShard("default/"+name, shards)] = true
+	}
+	require.Equal(t, 3, len(picked))
+
+	require.False(t, needSchedule("", nil))
+	require.True(t, needSchedule("", shards))
+	require.True(t, needSchedule("dead", shards))
+	require.False(t, needSchedule("shard-1", shards))
+}
+
+func TestHeartbeat(t *testing.T) {
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	h := &Heartbeat{client: cli, shardID: "shard-1", namespace: "vela-system", leaseDuration: 30 * time.Second}
+	ctx := context.Background()
+	require.NoError(t, h.RenewLease(ctx))
+	lease := &coordinationv1.Lease{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "kubevela-shard-shard-1"}, lease))
+	require.Equal(t, "shard-1", lease.Labels[oam.LabelControllerShardID])
+	require.Equal(t, int32(30), *lease.Spec.LeaseDurationSeconds)
+	firstRenew := lease.Spec.RenewTime.Time
+
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, h.RenewLease(ctx))
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "kubevela-shard-shard-1"}, lease))
+	require.True(t, lease.Spec.RenewTime.After(firstRenew))
+	require.Equal(t, []string{"shard-1"}, aliveShards([]coordinationv1.Lease{*lease}, time.Now()))
+}
+
+func TestScheduler(t *testing.T) {
+	now := time.Now()
+	apps := []*v1beta1.Application{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "new"}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "on-dead-shard", Labels: map[string]string{oam.LabelControllerShardID: "dead"}}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "on-live-shard", Labels: map[string]string{oam.LabelControllerShardID: "master"}}},
+	}
+	var objs, metas []runtime.Object
+	for _, app := range apps {
+		objs = append(objs, app)
+		metas = append(metas, &metav1.PartialObjectMetadata{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1beta1.SchemeGroupVersion.String(), Kind: v1beta1.ApplicationKind},
+			ObjectMeta: *app.ObjectMeta.DeepCopy(),
+		})
+	}
+	objs = append(objs, newLease("master", now), newLease("shard-1", now), newLease("dead", now.Add(-time.Minute)))
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).WithRuntimeObjects(objs...).Build()
+	metaScheme := runtime.NewScheme()
+	require.NoError(t, metav1.AddMetaToScheme(metaScheme))
+	s := NewScheduler(cli, metadatafake.NewSimpleMetadataClient(metaScheme, metas...), "vela-system", 2*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		require.NoError(t, s.Start(ctx))
+	}()
+
+	shardOf := func(name string) func() string {
+		return func() string {
+			app := &v1beta1.Application{}
+			if err := cli.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, app); err != nil {
+				return err.Error()
+			}
+			return app.Labels[oam.LabelControllerShardID]
+		}
+	}
+	live := []string{"master", "shard-1"}
+	require.Eventually(t, func() bool { return pickShard("default/new", live) == shardOf("new")() }, 10*time.Second, 50*time.Millisecond)
+	require.Eventually(t, func() bool { return pickShard("default/on-dead-shard", live) == shardOf("on-dead-shard")() }, 10*time.Second, 50*time.Millisecond)
+	require.Equal(t, "master", shardOf("on-live-shard")())
+	require.Equal(t, live, s.liveShards())
+
+	// the applications of the shard are moved to the other shards once the lease of the shard expires
+	lease := &coordinationv1.Lease{}
+	require.NoError(t, cli.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "kubevela-shard-master"}, lease))
+	expired := metav1.NewMicroTime(now.Add(-time.Minute))
+	lease.Spec.RenewTime = &expired
+	require.NoError(t, cli.Update(ctx, lease))
+	s.RefreshShards(ctx)
+	require.Equal(t, []string{"shard-1"}, s.liveShards())
+	require.NoError(t, s.Schedule(ctx, "default", "on-live-shard", "master"))
+	require.Equal(t, "shard-1", shardOf("on-live-shard")())
+	require.NoError(t, s.Schedule(ctx, "default", "not-found", ""))
+}
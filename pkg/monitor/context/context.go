@@ -18,9 +18,13 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/oam-dev/kubevela/pkg/utils"
-
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/klog/v2"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/tracing"
+	"github.com/oam-dev/kubevela/pkg/utils"
 )
 
 const (
@@ -59,18 +63,25 @@ type traceContext struct {
 	tags      []interface{}
 	exporters []Exporter
 	parent    *traceContext
+
+	// span is the OpenTelemetry span recording the context, it is a no-op span if tracing is disabled
+	span trace.Span
 }
 
 // Fork a child Context extends parent Context
 func (t *traceContext) Fork(id string, exporters ...Exporter) Context {
+	spanName := id
 	if id == "" {
 		id = t.id
+		spanName = "fork"
 	} else {
 		id = t.id + "." + id
 	}
 
+	ctx, span := tracing.StartSpan(t.Context, spanName)
 	return &traceContext{
-		Context:        t.Context,
+		Context:        ctx,
+		span:           span,
 		id:             id,
 		tags:           copySlice(t.tags),
 		logLevel:       t.logLevel,
@@ -82,6 +93,8 @@ func (t *traceContext) Fork(id string, exporters ...Exporter) Context {
 
 // Commit finish the span record
 func (t *traceContext) Commit(msg string) {
+	t.span.SetAttributes(attribute.String("message", msg))
+	defer t.span.End()
 	msg = fmt.Sprintf("[Finished]: %s(%s)", t.id, msg)
 	duration := time.Since(t.beginTimestamp)
 	for _, export := range t.exporters {
@@ -106,9 +119,9 @@ func (t *traceContext) GetContext() stdctx.Context {
 	return t.Context
 }
 
-// SetContext set raw context.
+// SetContext set raw context, the span of the context is kept in it.
 func (t *traceContext) SetContext(ctx stdctx.Context) {
-	t.Context = ctx
+	t.Context = trace.ContextWithSpan(ctx, t.span)
 }
 
 // InfoDepth acts as Info but uses depth to determine which call frame to log.
@@ -119,11 +132,19 @@ func (t *traceContext) InfoDepth(depth int, msg string, keysAndValues ...interfa
 // Error logs an error, with the given message and key/value pairs as context.
 func (t *traceContext) Error(err error, msg string, keysAndValues ...interface{}) {
 	klog.ErrorSDepth(1, err, msg, t.getTagsWith(keysAndValues...)...)
+	t.recordError(err, msg)
 }
 
 // ErrorDepth acts as Error but uses depth to determine which call frame to log.
 func (t *traceContext) ErrorDepth(depth int, err error, msg string, keysAndValues ...interface{}) {
 	klog.ErrorSDepth(depth+1, err, msg, t.getTagsWith(keysAndValues...)...)
+	t.recordError(err, msg)
+}
+
+// recordError records the error on the span and marks the span failed.
+func (t *traceContext) recordError(err error, msg string) {
+	t.span.RecordError(err, trace.WithAttributes(attribute.String("message", msg)))
+	t.span.SetStatus(codes.Error, msg)
 }
 
 // Printf formats according to a format specifier and logs.
@@ -139,21 +160,42 @@ func (t *traceContext) V(level int) {
 // AddTag adds some key-value pairs of context to a logger.
 func (t *traceContext) AddTag(keysAndValues ...interface{}) Context {
 	t.tags = append(t.tags, keysAndValues...)
+	if t.span.IsRecording() {
+		t.span.SetAttributes(spanAttributes(keysAndValues)...)
+	}
 	return t
 }
 
 // NewTraceContext new a TraceContext
 func NewTraceContext(ctx stdctx.Context, id string) Context {
+	spanName := id
 	if id == "" {
 		id = "i-" + utils.RandomString(8)
+		spanName = "trace"
 	}
+	ctx, span := tracing.StartSpan(ctx, spanName)
 	return &traceContext{
 		Context:        ctx,
+		span:           span,
 		id:             id,
 		beginTimestamp: time.Now(),
 	}
 }
 
+// SetSpanName names the span recording the context, the span is named after the id of the context by default.
+func SetSpanName(ctx stdctx.Context, name string) {
+	trace.SpanFromContext(ctx).SetName(name)
+}
+
+// spanAttributes converts the key-value pairs of the tags to the attributes of the span.
+func spanAttributes(keysAndValues []interface{}) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		attrs = append(attrs, attribute.String(fmt.Sprint(keysAndValues[i]), fmt.Sprint(keysAndValues[i+1])))
+	}
+	return attrs
+}
+
 func copySlice(in []interface{}) []interface{} {
 	out := make([]interface{}, len(in))
 	copy(out, in)
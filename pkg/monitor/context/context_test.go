@@ -20,6 +20,13 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -43,3 +50,37 @@ func TestLog(t *testing.T) {
 	spanCtx.Commit("finished")
 
 }
+
+func TestSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	ctx := NewTraceContext(context.Background(), "").AddTag("application", "default/test-app")
+	SetSpanName(ctx, "reconcile")
+	ctx.SetContext(context.WithValue(ctx.GetContext(), struct{}{}, "value"))
+	stepCtx := ctx.Fork("step-id")
+	SetSpanName(stepCtx, "step apply")
+	stepCtx.AddTag("step_type", "apply-component")
+	stepCtx.Error(errors.New("mock error"), "apply")
+	stepCtx.Commit("failed")
+	ctx.Commit("done")
+
+	spans := exporter.GetSpans()
+	require.Equal(t, 2, len(spans))
+	step, root := spans[0], spans[1]
+	require.Equal(t, "reconcile", root.Name)
+	require.False(t, root.Parent.IsValid())
+	require.Contains(t, root.Attributes, attribute.String("application", "default/test-app"))
+	require.Equal(t, codes.Unset, root.Status.Code)
+
+	require.Equal(t, "step apply", step.Name)
+	require.Equal(t, root.SpanContext.SpanID(), step.Parent.SpanID())
+	require.Equal(t, root.SpanContext.TraceID(), step.SpanContext.TraceID())
+	require.Contains(t, step.Attributes, attribute.String("step_type", "apply-component"))
+	require.NotContains(t, step.Attributes, attribute.String("application", "default/test-app"))
+	require.Equal(t, codes.Error, step.Status.Code)
+	require.Equal(t, "apply", step.Status.Description)
+	require.Equal(t, 1, len(step.Events))
+	require.Contains(t, step.Attributes, attribute.String("message", "failed"))
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+var (
+	// ApplicationReconcileTimeHistogram report the reconcile duration of the application by the phase it ends up with.
+	ApplicationReconcileTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "application_reconcile_time_seconds",
+		Help:    "application reconcile duration distributions.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"phase"})
+
+	// ApplicationReconcileErrorCounter report the number of the application reconciles ending with an error by phase.
+	ApplicationReconcileErrorCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "application_reconcile_errors_total",
+		Help: "number of the application reconciles that end up with an error.",
+	}, []string{"phase"})
+)
+
+func init() {
+	for _, collector := range []prometheus.Collector{ApplicationReconcileTimeHistogram, ApplicationReconcileErrorCounter} {
+		if err := metrics.Registry.Register(collector); err != nil {
+			klog.Error(err)
+		}
+	}
+}
+
+var (
+	applicationPhaseDesc = prometheus.NewDesc("application_phase_number",
+		"number of the applications in each phase.", []string{"namespace", "phase"}, nil)
+	resourceTrackerSizeDesc = prometheus.NewDesc("resource_tracker_managed_resources",
+		"number of the resources managed by the resource trackers of the application.",
+		[]string{"namespace", "application", "type"}, nil)
+)
+
+// the time to wait for listing the objects from the reader in one scrape
+const collectTimeout = 10 * time.Second
+
+// applicationCollector counts the applications and the resources tracked for them when it is scraped,
+// so the numbers never go stale after the applications are deleted
+type applicationCollector struct {
+	reader client.Reader
+}
+
+// NewApplicationCollector creates a collector reporting the phases of the applications and the sizes of the
+// resource trackers. The reader is expected to be backed by an informer cache.
+func NewApplicationCollector(reader client.Reader) prometheus.Collector {
+	return &applicationCollector{reader: reader}
+}
+
+// Describe implements prometheus.Collector
+func (c *applicationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- applicationPhaseDesc
+	ch <- resourceTrackerSizeDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *applicationCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	apps := &v1beta1.ApplicationList{}
+	if err := c.reader.List(ctx, apps); err != nil {
+		klog.ErrorS(err, "failed to list applications for metrics")
+	} else {
+		phases := map[[2]string]int{}
+		for _, app := range apps.Items {
+			phases[[2]string{app.Namespace, string(app.Status.Phase)}]++
+		}
+		for key, count := range phases {
+			ch <- prometheus.MustNewConstMetric(applicationPhaseDesc, prometheus.GaugeValue, float64(count), key[0], key[1])
+		}
+	}
+
+	rts := &v1beta1.ResourceTrackerList{}
+	if err := c.reader.List(ctx, rts); err != nil {
+		klog.ErrorS(err, "failed to list resource trackers for metrics")
+		return
+	}
+	sizes := map[[3]string]int{}
+	for _, rt := range rts.Items {
+		labels := rt.GetLabels()
+		if labels[oam.LabelAppName] == "" {
+			continue
+		}
+		sizes[[3]string{labels[oam.LabelAppNamespace], labels[oam.LabelAppName], string(rt.Spec.Type)}] += len(rt.Spec.ManagedResources)
+	}
+	for key, size := range sizes {
+		ch <- prometheus.MustNewConstMetric(resourceTrackerSizeDesc, prometheus.GaugeValue, float64(size), key[0], key[1], key[2])
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+func TestApplicationCollector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+	newApp := func(namespace, name string, phase common.ApplicationPhase) *v1beta1.Application {
+		return &v1beta1.Application{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Status:     common.AppStatus{Phase: phase},
+		}
+	}
+	newRT := func(name, app string, rtType v1beta1.ResourceTrackerType, size int) *v1beta1.ResourceTracker {
+		rt := &v1beta1.ResourceTracker{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+				oam.LabelAppNamespace: "default",
+				oam.LabelAppName:      app,
+			}},
+			Spec: v1beta1.ResourceTrackerSpec{Type: rtType},
+		}
+		for i := 0; i < size; i++ {
+			rt.Spec.ManagedResources = append(rt.Spec.ManagedResources, v1beta1.ManagedResource{})
+		}
+		return rt
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newApp("default", "app-1", common.ApplicationRunning),
+		newApp("default", "app-2", common.ApplicationRunning),
+		newApp("default", "app-3", common.ApplicationWorkflowSuspending),
+		newApp("vela-system", "app-4", common.ApplicationRunning),
+		newRT("app-1-root", "app-1", v1beta1.ResourceTrackerTypeRoot, 1),
+		newRT("app-1-v1", "app-1", v1beta1.ResourceTrackerTypeVersioned, 2),
+		newRT("app-1-v2", "app-1", v1beta1.ResourceTrackerTypeVersioned, 3),
+		&v1beta1.ResourceTracker{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+	).Build()
+
+	expected := `
+# HELP application_phase_number number of the applications in each phase.
+# TYPE application_phase_number gauge
+application_phase_number{namespace="default",phase="running"} 2
+application_phase_number{namespace="default",phase="workflowSuspending"} 1
+application_phase_number{namespace="vela-system",phase="running"} 1
+# HELP resource_tracker_managed_resources number of the resources managed by the resource trackers of the application.
+# TYPE resource_tracker_managed_resources gauge
+resource_tracker_managed_resources{application="app-1",namespace="default",type="root"} 1
+resource_tracker_managed_resources{application="app-1",namespace="default",type="versioned"} 5
+`
+	require.NoError(t, testutil.CollectAndCompare(NewApplicationCollector(cli), strings.NewReader(expected)))
+}
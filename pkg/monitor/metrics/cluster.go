@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// ClusterHealthyGauge report whether the managed cluster is healthy, 1 for healthy and 0 for unhealthy.
+	ClusterHealthyGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cluster_healthy",
+		Help: "whether the managed cluster is reachable through cluster-gateway.",
+	}, []string{"cluster"})
+
+	// ClusterHealthChangeCounter report the number of the health state changes of the managed cluster.
+	ClusterHealthChangeCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cluster_health_state_changes_total",
+		Help: "number of the health state changes of the managed cluster.",
+	}, []string{"cluster", "healthy"})
+
+	// ClusterCredentialExpirationGauge report the expiration time of the credential of the managed cluster in unix seconds.
+	ClusterCredentialExpirationGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cluster_credential_expiration_timestamp_seconds",
+		Help: "the expiration time of the credential used to access the managed cluster in unix seconds.",
+	}, []string{"cluster"})
+
+	// ClusterGatewayRequestTimeHistogram report the duration of the requests sent to the managed cluster through cluster-gateway.
+	ClusterGatewayRequestTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cluster_gateway_request_time_seconds",
+		Help:    "latency distributions of the requests proxied by cluster-gateway.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"cluster", "method", "code"})
+)
+
+func init() {
+	for _, collector := range []prometheus.Collector{ClusterHealthyGauge, ClusterHealthChangeCounter, ClusterCredentialExpirationGauge,
+		ClusterGatewayRequestTimeHistogram} {
+		if err := metrics.Registry.Register(collector); err != nil {
+			klog.Error(err)
+		}
+	}
+}
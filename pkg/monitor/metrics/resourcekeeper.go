@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// DispatchResourceCounter report the number of the resources dispatched by the resource keeper.
+	DispatchResourceCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "resourcekeeper_dispatch_resources_total",
+		Help: "number of the resources dispatched by the resource keeper.",
+	}, []string{"result"})
+
+	// DispatchTimeHistogram report the duration of dispatching the resources of one call.
+	DispatchTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "resourcekeeper_dispatch_time_seconds",
+		Help:    "resource dispatching duration distributions.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"result"})
+
+	// GCCounter report the number of the garbage collections run by the resource keeper.
+	GCCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "resourcekeeper_gc_total",
+		Help: "number of the garbage collections run by the resource keeper.",
+	}, []string{"result"})
+
+	// GCTimeHistogram report the duration of the garbage collections.
+	GCTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "resourcekeeper_gc_time_seconds",
+		Help:    "garbage collection duration distributions.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 14),
+	}, []string{"result"})
+)
+
+func init() {
+	for _, collector := range []prometheus.Collector{DispatchResourceCounter, DispatchTimeHistogram, GCCounter, GCTimeHistogram} {
+		if err := metrics.Registry.Register(collector); err != nil {
+			klog.Error(err)
+		}
+	}
+}
+
+// Result returns the value of the result label for the error
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// WebhookAdmissionTimeHistogram report the duration of the admission requests handled by the webhooks.
+	WebhookAdmissionTimeHistogram = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_admission_time_seconds",
+		Help:    "admission latency distributions of the webhooks.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 2, 14),
+	}, []string{"webhook", "operation", "allowed"})
+)
+
+func init() {
+	if err := metrics.Registry.Register(WebhookAdmissionTimeHistogram); err != nil {
+		klog.Error(err)
+	}
+}
@@ -27,10 +27,24 @@ var (
 		Objectives:  map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		ConstLabels: prometheus.Labels{},
 	}, []string{"application", "workflow_revision", "step_name", "step_type"})
+
+	// StepPhaseCounter report the number of the steps that end up succeeded, failed or stopped by the step type and phase.
+	StepPhaseCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "step_phase_total",
+		Help: "number of the steps transitioned into succeeded, failed or stopped phase by step type and phase.",
+	}, []string{"step_type", "phase"})
+
+	// WorkflowRunCounter report the number of the workflow runs that end up succeeded, suspended or terminated.
+	WorkflowRunCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "workflow_run_total",
+		Help: "number of the workflow runs by the state they end up with.",
+	}, []string{"state"})
 )
 
 func init() {
-	if err := metrics.Registry.Register(StepDurationSummary); err != nil {
-		klog.Error(err)
+	for _, collector := range []prometheus.Collector{StepDurationSummary, StepPhaseCounter, WorkflowRunCounter} {
+		if err := metrics.Registry.Register(collector); err != nil {
+			klog.Error(err)
+		}
 	}
 }
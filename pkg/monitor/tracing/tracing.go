@@ -0,0 +1,126 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/oam-dev/kubevela/version"
+)
+
+const (
+	// instrumentationName is the name of the instrumentation library reported with the spans
+	instrumentationName = "github.com/oam-dev/kubevela"
+	// serviceName is the name of the service reported with the spans
+	serviceName = "kubevela"
+	// otlpExportTimeout is the timeout of exporting one batch of spans
+	otlpExportTimeout = 10 * time.Second
+)
+
+// Options configures how the traces are sampled and exported
+type Options struct {
+	// Endpoint is the OTLP/HTTP endpoint the spans are exported to, e.g. http://otel-collector:4318.
+	// Tracing is disabled if it's empty.
+	Endpoint string
+	// SamplingRatio is the ratio of the traces that are sampled, the child spans follow the decision of the parent.
+	SamplingRatio float64
+}
+
+// Setup installs the global tracer provider exporting the spans to the OTLP endpoint, the returned function flushes
+// the spans left and stops the provider. Nothing is installed if the endpoint is empty, so the spans are not recorded.
+func Setup(opts Options) (func(context.Context) error, error) {
+	if opts.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+	if opts.SamplingRatio < 0 || opts.SamplingRatio > 1 {
+		return nil, fmt.Errorf("the sampling ratio of the traces must be within [0, 1], got %v", opts.SamplingRatio)
+	}
+	exporter, err := NewOTLPExporter(opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	tp := NewTracerProvider(opts.SamplingRatio, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	return tp.Shutdown, nil
+}
+
+// NewOTLPExporter creates a span exporter posting the spans to the OTLP/HTTP endpoint in the binary protobuf encoding,
+// the spans are posted to /v1/traces if the endpoint has no path
+func NewOTLPExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid tracing endpoint %s", endpoint)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("the scheme of the tracing endpoint %s must be either http or https", endpoint)
+	}
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host), otlptracehttp.WithTimeout(otlpExportTimeout)}
+	if u.Scheme == "http" {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+	if u.Path != "" && u.Path != "/" {
+		opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to create the exporter of the tracing endpoint %s", endpoint)
+	}
+	return exporter, nil
+}
+
+// NewTracerProvider creates a tracer provider sampling the traces by the ratio and reporting kubevela as the service
+func NewTracerProvider(samplingRatio float64, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceNameKey.String(serviceName),
+			semconv.ServiceVersionKey.String(version.VelaVersion),
+		)),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
+
+// Tracer returns the tracer of kubevela from the global tracer provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts a span as the child of the span in the context
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan records the error on the span if there is one and ends it
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,96 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+     http://www.apache.org/licenses/LICENSE-2.0
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSetup(t *testing.T) {
+	shutdown, err := Setup(Options{})
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	_, span := StartSpan(context.Background(), "disabled")
+	require.False(t, span.IsRecording())
+
+	_, err = Setup(Options{Endpoint: "http://localhost:4318", SamplingRatio: 2})
+	require.Error(t, err)
+	_, err = Setup(Options{Endpoint: "localhost:4318", SamplingRatio: 1})
+	require.Error(t, err)
+}
+
+func TestSampling(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(NewTracerProvider(0, sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	ctx, span := StartSpan(context.Background(), "reconcile")
+	_, child := StartSpan(ctx, "dispatch")
+	EndSpan(child, nil)
+	EndSpan(span, nil)
+	require.Equal(t, 0, len(exporter.GetSpans()))
+
+	otel.SetTracerProvider(NewTracerProvider(1, sdktrace.WithSyncer(exporter)))
+	ctx, span = StartSpan(context.Background(), "reconcile")
+	_, child = StartSpan(ctx, "dispatch", attribute.String("cluster", "local"))
+	EndSpan(child, errors.New("mock error"))
+	EndSpan(span, nil)
+	spans := exporter.GetSpans()
+	require.Equal(t, 2, len(spans))
+	require.Equal(t, "dispatch", spans[0].Name)
+	require.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	require.Contains(t, spans[0].Attributes, attribute.String("cluster", "local"))
+	require.Equal(t, "mock error", spans[0].Status.Description)
+}
+
+func TestOTLPExporter(t *testing.T) {
+	requests := make(chan int, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		requests <- len(body)
+	}))
+	defer server.Close()
+
+	exporter, err := NewOTLPExporter(server.URL)
+	require.NoError(t, err)
+	tp := NewTracerProvider(1, sdktrace.WithSyncer(exporter))
+	ctx, span := tp.Tracer(instrumentationName).Start(context.Background(), "reconcile")
+	_, child := tp.Tracer(instrumentationName).Start(ctx, "dispatch", trace.WithAttributes(attribute.String("kind", "Deployment")))
+	EndSpan(child, errors.New("mock error"))
+	EndSpan(span, nil)
+	require.NoError(t, tp.Shutdown(context.Background()))
+	for i := 0; i < 2; i++ {
+		require.NotZero(t, <-requests)
+	}
+
+	_, err = NewOTLPExporter("localhost:4318")
+	require.Error(t, err)
+}
@@ -19,8 +19,12 @@ package multicluster
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	v1alpha12 "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	clustergatewayconfig "github.com/oam-dev/cluster-gateway/pkg/config"
 	"github.com/pkg/errors"
 	v1 "k8s.io/api/core/v1"
 	v14 "k8s.io/api/storage/v1"
@@ -98,52 +102,83 @@ func GetMutableClusterSecret(ctx context.Context, c client.Client, clusterName s
 	return clusterSecret, nil
 }
 
-// JoinClusterByKubeConfig add child cluster by kubeconfig path, return cluster info and error
-func JoinClusterByKubeConfig(_ctx context.Context, k8sClient client.Client, kubeconfigPath string, clusterName string) (*api.Cluster, error) {
+// loadKubeConfigCluster loads the cluster and the auth info of the current context from the kubeconfig
+func loadKubeConfigCluster(kubeconfigPath string) (string, *api.Cluster, *api.AuthInfo, error) {
 	config, err := clientcmd.LoadFromFile(kubeconfigPath)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to get kubeconfig")
+		return "", nil, nil, errors.Wrapf(err, "failed to get kubeconfig")
 	}
 	if len(config.CurrentContext) == 0 {
-		return nil, fmt.Errorf("current-context is not set")
+		return "", nil, nil, fmt.Errorf("current-context is not set")
 	}
 	ctx, ok := config.Contexts[config.CurrentContext]
 	if !ok {
-		return nil, fmt.Errorf("current-context %s not found", config.CurrentContext)
+		return "", nil, nil, fmt.Errorf("current-context %s not found", config.CurrentContext)
 	}
 	cluster, ok := config.Clusters[ctx.Cluster]
 	if !ok {
-		return nil, fmt.Errorf("cluster %s not found", ctx.Cluster)
+		return "", nil, nil, fmt.Errorf("cluster %s not found", ctx.Cluster)
 	}
 	authInfo, ok := config.AuthInfos[ctx.AuthInfo]
 	if !ok {
-		return nil, fmt.Errorf("authInfo %s not found", ctx.AuthInfo)
+		return "", nil, nil, fmt.Errorf("authInfo %s not found", ctx.AuthInfo)
+	}
+	return ctx.Cluster, cluster, authInfo, nil
+}
+
+// ensureClusterNameAvailable checks if the cluster name can be used to join a new cluster
+func ensureClusterNameAvailable(ctx context.Context, k8sClient client.Client, clusterName string) error {
+	if clusterName == ClusterLocalName {
+		return fmt.Errorf("cannot use `%s` as cluster name, it is reserved as the local cluster", ClusterLocalName)
+	}
+	if err := ensureClusterNotExists(ctx, k8sClient, clusterName); err != nil {
+		return errors.Wrapf(err, "cannot use cluster name %s", clusterName)
+	}
+	return nil
+}
+
+// createClusterSecret creates the cluster secret for cluster-gateway and ensures the namespace in the joined cluster,
+// the cluster secret is removed if the namespace cannot be created
+func createClusterSecret(ctx context.Context, k8sClient client.Client, secret *v1.Secret, createNamespace string) error {
+	if err := setClusterCredentialExpiration(secret); err != nil {
+		return err
+	}
+	if err := k8sClient.Create(ctx, secret); err != nil {
+		return errors.Wrapf(err, "failed to add cluster to kubernetes")
 	}
+	if err := ensureVelaSystemNamespaceInstalled(ctx, k8sClient, secret.Name, createNamespace); err != nil {
+		_ = k8sClient.Delete(ctx, secret)
+		return errors.Wrapf(err, "failed to create namespace %s in cluster %s", createNamespace, secret.Name)
+	}
+	return nil
+}
 
+// JoinClusterByKubeConfig add child cluster by kubeconfig path, return cluster info and error
+func JoinClusterByKubeConfig(_ctx context.Context, k8sClient client.Client, kubeconfigPath string, clusterName string) (*api.Cluster, error) {
+	contextClusterName, cluster, authInfo, err := loadKubeConfigCluster(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
 	if clusterName == "" {
-		clusterName = ctx.Cluster
+		clusterName = contextClusterName
 	}
-	if clusterName == ClusterLocalName {
-		return cluster, fmt.Errorf("cannot use `%s` as cluster name, it is reserved as the local cluster", ClusterLocalName)
+	if err := ensureClusterNameAvailable(_ctx, k8sClient, clusterName); err != nil {
+		return cluster, err
 	}
 
-	if err := ensureClusterNotExists(_ctx, k8sClient, clusterName); err != nil {
-		return cluster, errors.Wrapf(err, "cannot use cluster name %s", clusterName)
+	if err := JoinClusterByCredential(_ctx, k8sClient, cluster, authInfo, clusterName, types.DefaultKubeVelaNS); err != nil {
+		return cluster, err
 	}
+	return cluster, nil
+}
 
-	var credentialType v1alpha12.CredentialType
-	data := map[string][]byte{
-		"endpoint": []byte(cluster.Server),
-		"ca.crt":   cluster.CertificateAuthorityData,
-	}
-	if len(authInfo.Token) > 0 {
-		credentialType = v1alpha12.CredentialTypeServiceAccountToken
-		data["token"] = []byte(authInfo.Token)
-	} else {
-		credentialType = v1alpha12.CredentialTypeX509Certificate
-		data["tls.crt"] = authInfo.ClientCertificateData
-		data["tls.key"] = authInfo.ClientKeyData
+// JoinClusterByCredential add child cluster with the cluster and the credential of the auth info in kubeconfig,
+// the createNamespace is created in the joined cluster
+func JoinClusterByCredential(ctx context.Context, k8sClient client.Client, cluster *api.Cluster, authInfo *api.AuthInfo, clusterName string, createNamespace string) error {
+	if err := ensureClusterNameAvailable(ctx, k8sClient, clusterName); err != nil {
+		return err
 	}
+	credentialType, data := newClusterCredential(cluster, authInfo)
 	secret := &v1.Secret{
 		ObjectMeta: v12.ObjectMeta{
 			Name:      clusterName,
@@ -155,16 +190,7 @@ func JoinClusterByKubeConfig(_ctx context.Context, k8sClient client.Client, kube
 		Type: v1.SecretTypeOpaque,
 		Data: data,
 	}
-
-	if err := k8sClient.Create(_ctx, secret); err != nil {
-		return cluster, errors.Wrapf(err, "failed to add cluster to kubernetes")
-	}
-
-	if err := ensureVelaSystemNamespaceInstalled(_ctx, k8sClient, clusterName, types.DefaultKubeVelaNS); err != nil {
-		return nil, errors.Wrapf(err, "failed to create vela namespace in cluster %s", clusterName)
-	}
-
-	return cluster, nil
+	return createClusterSecret(ctx, k8sClient, secret, createNamespace)
 }
 
 // DetachCluster detach cluster by name, if cluster is using by application, it will return error
@@ -218,6 +244,8 @@ type ClusterInfo struct {
 	CPUAllocatable    resource.Quantity
 	PodAllocatable    resource.Quantity
 	StorageClasses    *v14.StorageClassList
+	// CredentialExpiration is the expiration time of the credential used to access the cluster, nil if never expires
+	CredentialExpiration *time.Time
 }
 
 // GetClusterInfo retrieves current cluster info from cluster
@@ -248,16 +276,110 @@ func GetClusterInfo(_ctx context.Context, k8sClient client.Client, clusterName s
 	if err := k8sClient.List(ctx, storageClasses); err != nil {
 		return nil, errors.Wrapf(err, "failed to list storage classes")
 	}
+	var credentialExpiration *time.Time
+	if clusterName != ClusterLocalName {
+		secret := &v1.Secret{}
+		if err := k8sClient.Get(_ctx, types2.NamespacedName{Namespace: ClusterGatewaySecretNamespace, Name: clusterName}, secret); err != nil && !errors2.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to get cluster secret")
+		} else if err == nil {
+			if credentialExpiration, err = GetClusterCredentialExpiration(secret); err != nil {
+				return nil, err
+			}
+		}
+	}
 	return &ClusterInfo{
-		Nodes:             nodes,
-		WorkerNumber:      workerNumber,
-		MasterNumber:      masterNumber,
-		MemoryCapacity:    memoryCapacity,
-		CPUCapacity:       cpuCapacity,
-		PodCapacity:       podCapacity,
-		MemoryAllocatable: memoryAllocatable,
-		CPUAllocatable:    cpuAllocatable,
-		PodAllocatable:    podAllcatable,
-		StorageClasses:    storageClasses,
+		Nodes:                nodes,
+		WorkerNumber:         workerNumber,
+		MasterNumber:         masterNumber,
+		MemoryCapacity:       memoryCapacity,
+		CPUCapacity:          cpuCapacity,
+		PodCapacity:          podCapacity,
+		MemoryAllocatable:    memoryAllocatable,
+		CPUAllocatable:       cpuAllocatable,
+		PodAllocatable:       podAllcatable,
+		StorageClasses:       storageClasses,
+		CredentialExpiration: credentialExpiration,
 	}, nil
 }
+
+// isReservedClusterLabel checks if the label of the cluster secret is reserved by cluster-gateway
+func isReservedClusterLabel(key string) bool {
+	return strings.HasPrefix(key, clustergatewayconfig.MetaApiGroupName+"/")
+}
+
+// GetClusterLabels returns the labels of the cluster stored on the cluster secret, the labels reserved by cluster-gateway are excluded
+func GetClusterLabels(secret *v1.Secret) map[string]string {
+	labels := map[string]string{}
+	for k, v := range secret.GetLabels() {
+		if !isReservedClusterLabel(k) {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+func getClusterSecret(ctx context.Context, c client.Client, clusterName string) (*v1.Secret, error) {
+	if clusterName == ClusterLocalName {
+		return nil, ErrReservedLocalClusterName
+	}
+	secret := &v1.Secret{}
+	if err := c.Get(ctx, types2.NamespacedName{Namespace: ClusterGatewaySecretNamespace, Name: clusterName}, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to find target cluster secret %s", clusterName)
+	}
+	return secret, nil
+}
+
+// AddClusterLabels adds or overrides the labels of the cluster
+func AddClusterLabels(ctx context.Context, c client.Client, clusterName string, labels map[string]string) error {
+	secret, err := getClusterSecret(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	for k, v := range labels {
+		if isReservedClusterLabel(k) {
+			return errors.Errorf("label %s is reserved by cluster-gateway", k)
+		}
+		secret.Labels[k] = v
+	}
+	return c.Update(ctx, secret)
+}
+
+// DeleteClusterLabels deletes the labels of the cluster by keys
+func DeleteClusterLabels(ctx context.Context, c client.Client, clusterName string, keys ...string) error {
+	secret, err := getClusterSecret(ctx, c, clusterName)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if isReservedClusterLabel(k) {
+			return errors.Errorf("label %s is reserved by cluster-gateway", k)
+		}
+		if _, ok := secret.Labels[k]; !ok {
+			return errors.Errorf("label %s not found in cluster %s", k, clusterName)
+		}
+		delete(secret.Labels, k)
+	}
+	return c.Update(ctx, secret)
+}
+
+// ListClustersByLabels lists the names of the clusters matching the labels, the local cluster cannot be labelled
+func ListClustersByLabels(ctx context.Context, c client.Client, labels map[string]string) ([]string, error) {
+	for k := range labels {
+		if isReservedClusterLabel(k) {
+			return nil, errors.Errorf("label %s is reserved by cluster-gateway", k)
+		}
+	}
+	secrets := &v1.SecretList{}
+	if err := c.List(ctx, secrets, client.InNamespace(ClusterGatewaySecretNamespace), client.HasLabels{v1alpha12.LabelKeyClusterCredentialType}, client.MatchingLabels(labels)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list cluster secrets")
+	}
+	var clusters []string
+	for _, secret := range secrets.Items {
+		clusters = append(clusters, secret.Name)
+	}
+	sort.Strings(clusters)
+	return clusters, nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestClusterLabels(t *testing.T) {
+	oldClusterGatewaySecretNamespace := ClusterGatewaySecretNamespace
+	ClusterGatewaySecretNamespace = "default"
+	defer func() {
+		ClusterGatewaySecretNamespace = oldClusterGatewaySecretNamespace
+	}()
+	r := require.New(t)
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	for _, name := range []string{"cluster-a", "cluster-b"} {
+		r.NoError(c.Create(ctx, &v1.Secret{
+			ObjectMeta: v12.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeX509Certificate)},
+			},
+		}))
+	}
+
+	r.NoError(AddClusterLabels(ctx, c, "cluster-a", map[string]string{"region": "east", "env": "prod"}))
+	r.NoError(AddClusterLabels(ctx, c, "cluster-b", map[string]string{"region": "west", "env": "prod"}))
+	r.Error(AddClusterLabels(ctx, c, "cluster-a", map[string]string{v1alpha1.LabelKeyClusterCredentialType: "x"}))
+	r.ErrorIs(AddClusterLabels(ctx, c, ClusterLocalName, map[string]string{"region": "east"}), ErrReservedLocalClusterName)
+
+	secret := &v1.Secret{}
+	r.NoError(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-a"}, secret))
+	r.Equal(map[string]string{"region": "east", "env": "prod"}, GetClusterLabels(secret))
+
+	clusters, err := ListClustersByLabels(ctx, c, map[string]string{"env": "prod"})
+	r.NoError(err)
+	r.Equal([]string{"cluster-a", "cluster-b"}, clusters)
+	clusters, err = ListClustersByLabels(ctx, c, map[string]string{"region": "east"})
+	r.NoError(err)
+	r.Equal([]string{"cluster-a"}, clusters)
+
+	r.NoError(DeleteClusterLabels(ctx, c, "cluster-a", "env"))
+	r.Error(DeleteClusterLabels(ctx, c, "cluster-a", "env"))
+	clusters, err = ListClustersByLabels(ctx, c, map[string]string{"env": "prod"})
+	r.NoError(err)
+	r.Equal([]string{"cluster-b"}, clusters)
+}
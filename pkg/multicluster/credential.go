@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	v1alpha12 "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils"
+)
+
+// newClusterCredential builds the data of the cluster secret from the cluster and the auth info in kubeconfig
+func newClusterCredential(cluster *api.Cluster, authInfo *api.AuthInfo) (v1alpha12.CredentialType, map[string][]byte) {
+	data := map[string][]byte{
+		"endpoint": []byte(cluster.Server),
+		"ca.crt":   cluster.CertificateAuthorityData,
+	}
+	if len(authInfo.Token) > 0 {
+		data["token"] = []byte(authInfo.Token)
+		return v1alpha12.CredentialTypeServiceAccountToken, data
+	}
+	data["tls.crt"] = authInfo.ClientCertificateData
+	data["tls.key"] = authInfo.ClientKeyData
+	return v1alpha12.CredentialTypeX509Certificate, data
+}
+
+// parseCredentialExpiration parses the expiration time of the client certificate or the bearer token,
+// nil is returned if the credential never expires
+func parseCredentialExpiration(data map[string][]byte) (*time.Time, error) {
+	if crt := data["tls.crt"]; len(crt) > 0 {
+		block, _ := pem.Decode(crt)
+		if block == nil {
+			return nil, errors.New("the client certificate is not PEM encoded")
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid client certificate")
+		}
+		return &cert.NotAfter, nil
+	}
+	// the token is treated as a JWT, the tokens of other formats are assumed to never expire
+	parts := strings.Split(string(data["token"]), ".")
+	if len(parts) != 3 {
+		return nil, nil
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, nil
+	}
+	claims := struct {
+		Exp *int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == nil {
+		return nil, nil
+	}
+	exp := time.Unix(*claims.Exp, 0)
+	return &exp, nil
+}
+
+// setClusterCredentialExpiration records the expiration time of the credential on the cluster secret
+func setClusterCredentialExpiration(secret *v1.Secret) error {
+	exp, err := parseCredentialExpiration(secret.Data)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the credential of cluster %s", secret.Name)
+	}
+	if exp == nil {
+		delete(secret.Annotations, oam.AnnotationClusterCredentialExpiration)
+		return nil
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[oam.AnnotationClusterCredentialExpiration] = exp.UTC().Format(time.RFC3339)
+	return nil
+}
+
+// GetClusterCredentialExpiration returns the expiration time of the credential in the cluster secret,
+// nil is returned if the credential never expires
+func GetClusterCredentialExpiration(secret *v1.Secret) (*time.Time, error) {
+	raw, ok := secret.GetAnnotations()[oam.AnnotationClusterCredentialExpiration]
+	if !ok {
+		// the clusters joined before the expiration is recorded
+		return parseCredentialExpiration(secret.Data)
+	}
+	exp, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid credential expiration of cluster %s", secret.Name)
+	}
+	return &exp, nil
+}
+
+// isSameClusterEndpoint checks if the endpoints are the same after the scheme and the port are completed
+func isSameClusterEndpoint(a, b string) bool {
+	if endpoint, err := utils.ParseAPIServerEndpoint(a); err == nil {
+		a = endpoint
+	}
+	if endpoint, err := utils.ParseAPIServerEndpoint(b); err == nil {
+		b = endpoint
+	}
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// UpdateClusterCredential replaces the credential of the cluster with the one in kubeconfig without detaching the cluster,
+// the kubeconfig must point to the same endpoint of the cluster
+func UpdateClusterCredential(ctx context.Context, k8sClient client.Client, kubeconfigPath string, clusterName string) (*api.Cluster, error) {
+	secret, err := getClusterSecret(ctx, k8sClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+	_, cluster, authInfo, err := loadKubeConfigCluster(kubeconfigPath)
+	if err != nil {
+		return nil, err
+	}
+	if !isSameClusterEndpoint(string(secret.Data["endpoint"]), cluster.Server) {
+		return cluster, errors.Wrapf(ErrClusterEndpointMismatch, "the kubeconfig points to %s instead of cluster %s at %s", cluster.Server, clusterName, secret.Data["endpoint"])
+	}
+	credentialType, data := newClusterCredential(cluster, authInfo)
+	data["endpoint"] = secret.Data["endpoint"]
+	if secret.Labels == nil {
+		secret.Labels = map[string]string{}
+	}
+	secret.Labels[v1alpha12.LabelKeyClusterCredentialType] = string(credentialType)
+	secret.Data = data
+	// the credential is not issued for the service account created by KubeVela any more
+	delete(secret.Annotations, oam.AnnotationClusterServiceAccount)
+	delete(secret.Annotations, oam.AnnotationClusterServiceAccountToken)
+	if err := setClusterCredentialExpiration(secret); err != nil {
+		return cluster, err
+	}
+	if err := k8sClient.Update(ctx, secret); err != nil {
+		return cluster, errors.Wrapf(err, "failed to update the credential of cluster %s", clusterName)
+	}
+	return cluster, nil
+}
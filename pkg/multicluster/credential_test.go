@@ -0,0 +1,156 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func newTestCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newTestJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode([]byte(claims)) + ".signature"
+}
+
+func TestParseCredentialExpiration(t *testing.T) {
+	r := require.New(t)
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	exp, err := parseCredentialExpiration(map[string][]byte{"tls.crt": newTestCertificate(t, notAfter)})
+	r.NoError(err)
+	r.True(notAfter.Equal(*exp))
+
+	_, err = parseCredentialExpiration(map[string][]byte{"tls.crt": []byte("invalid")})
+	r.Error(err)
+
+	exp, err = parseCredentialExpiration(map[string][]byte{"token": []byte(newTestJWT(fmt.Sprintf(`{"exp":%d}`, notAfter.Unix())))})
+	r.NoError(err)
+	r.True(notAfter.Equal(*exp))
+
+	exp, err = parseCredentialExpiration(map[string][]byte{"token": []byte(newTestJWT(`{"sub":"system:serviceaccount:vela-system:sa"}`))})
+	r.NoError(err)
+	r.Nil(exp)
+
+	exp, err = parseCredentialExpiration(map[string][]byte{"token": []byte("opaque-token")})
+	r.NoError(err)
+	r.Nil(exp)
+}
+
+func TestUpdateClusterCredential(t *testing.T) {
+	oldClusterGatewaySecretNamespace := ClusterGatewaySecretNamespace
+	ClusterGatewaySecretNamespace = "default"
+	defer func() {
+		ClusterGatewaySecretNamespace = oldClusterGatewaySecretNamespace
+	}()
+	r := require.New(t)
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(&v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      "cluster-a",
+			Namespace: "default",
+			Labels: map[string]string{
+				v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeServiceAccountToken),
+				"region":                               "east",
+			},
+			Annotations: map[string]string{
+				oam.AnnotationClusterServiceAccount:      "vela-system/sa",
+				oam.AnnotationClusterServiceAccountToken: "sa-token",
+			},
+		},
+		Data: map[string][]byte{"endpoint": []byte("https://1.2.3.4:6443"), "token": []byte("old")},
+	}).Build()
+
+	notAfter := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-a
+  cluster:
+    server: %s
+contexts:
+- name: cluster-a
+  context:
+    cluster: cluster-a
+    user: admin
+current-context: cluster-a
+users:
+- name: admin
+  user:
+    client-certificate-data: %s
+    client-key-data: a2V5
+`
+	certData := base64.StdEncoding.EncodeToString(newTestCertificate(t, notAfter))
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+
+	// the kubeconfig of another cluster is refused
+	r.NoError(os.WriteFile(kubeconfigPath, []byte(fmt.Sprintf(kubeconfig, "https://5.6.7.8:6443", certData)), 0600))
+	_, err := UpdateClusterCredential(ctx, c, kubeconfigPath, "cluster-a")
+	r.ErrorIs(err, ErrClusterEndpointMismatch)
+
+	r.NoError(os.WriteFile(kubeconfigPath, []byte(fmt.Sprintf(kubeconfig, "1.2.3.4:6443", certData)), 0600))
+	_, err = UpdateClusterCredential(ctx, c, kubeconfigPath, "cluster-a")
+	r.NoError(err)
+	secret := &v1.Secret{}
+	r.NoError(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-a"}, secret))
+	r.Equal(string(v1alpha1.CredentialTypeX509Certificate), secret.Labels[v1alpha1.LabelKeyClusterCredentialType])
+	r.Equal("east", secret.Labels["region"])
+	r.Equal("https://1.2.3.4:6443", string(secret.Data["endpoint"]))
+	r.Equal("key", string(secret.Data["tls.key"]))
+	r.NotContains(secret.Data, "token")
+	r.NotContains(secret.Annotations, oam.AnnotationClusterServiceAccount)
+	exp, err := GetClusterCredentialExpiration(secret)
+	r.NoError(err)
+	r.True(notAfter.Equal(*exp))
+
+	_, err = UpdateClusterCredential(ctx, c, kubeconfigPath, ClusterLocalName)
+	r.ErrorIs(err, ErrReservedLocalClusterName)
+}
@@ -23,6 +23,12 @@ var (
 	ErrClusterExists = ClusterManagementError(fmt.Errorf("cluster already exists"))
 	// ErrReservedLocalClusterName reserved cluster name is used
 	ErrReservedLocalClusterName = ClusterManagementError(fmt.Errorf("cluster name `local` is reserved for kubevela hub cluster"))
+	// ErrClusterNotJoinedByServiceAccount the cluster credential is not a service account token created by kubevela
+	ErrClusterNotJoinedByServiceAccount = ClusterManagementError(fmt.Errorf("cluster is not joined by the service account created by kubevela"))
+	// ErrClusterEndpointMismatch the new credential of the cluster points to another endpoint
+	ErrClusterEndpointMismatch = ClusterManagementError(fmt.Errorf("cluster endpoint mismatch"))
+	// ErrClusterCANotFound the certificate authority of the cluster is not provided and the TLS verification is not skipped
+	ErrClusterCANotFound = ClusterManagementError(fmt.Errorf("certificate authority data of the cluster is required"))
 )
 
 // ClusterManagementError multicluster management error
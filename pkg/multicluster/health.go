@@ -0,0 +1,228 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// ClusterConditionReachable indicates whether the cluster can be accessed through cluster-gateway
+	ClusterConditionReachable condition.ConditionType = "Reachable"
+	// ClusterConditionResourceSynced indicates whether the resource capacity of the cluster is collected
+	ClusterConditionResourceSynced condition.ConditionType = "ResourceSynced"
+
+	// ReasonClusterReachable the cluster is reachable
+	ReasonClusterReachable condition.ConditionReason = "ClusterReachable"
+	// ReasonClusterUnreachable the cluster is unreachable
+	ReasonClusterUnreachable condition.ConditionReason = "ClusterUnreachable"
+)
+
+// ClusterCredentialState is the state of the expiration of the cluster credential
+type ClusterCredentialState string
+
+const (
+	// ClusterCredentialValid the credential of the cluster never expires or is far from the expiration
+	ClusterCredentialValid ClusterCredentialState = ""
+	// ClusterCredentialExpiring the credential of the cluster expires soon
+	ClusterCredentialExpiring ClusterCredentialState = "Expiring"
+	// ClusterCredentialExpired the credential of the cluster has expired
+	ClusterCredentialExpired ClusterCredentialState = "Expired"
+)
+
+// ClusterHealthStatus is the health status of the managed cluster recorded on the cluster secret
+type ClusterHealthStatus struct {
+	condition.ConditionedStatus `json:",inline"`
+
+	KubernetesVersion string            `json:"kubernetesVersion,omitempty"`
+	NodeCount         int               `json:"nodeCount"`
+	CPUCapacity       resource.Quantity `json:"cpuCapacity"`
+	MemoryCapacity    resource.Quantity `json:"memoryCapacity"`
+	// CredentialState is the state of the expiration of the cluster credential, so the warnings are only emitted when it changes
+	CredentialState ClusterCredentialState `json:"credentialState,omitempty"`
+	// LastSeenTime is the last time the cluster is reachable, it is recorded along with the changes of the other fields
+	// or when the recorded one is older than the refresh interval, so the cluster secret is not updated by every probe
+	LastSeenTime *v12.Time `json:"lastSeenTime,omitempty"`
+}
+
+// IsClusterHealthStatusChanged checks if the health status is changed, the LastSeenTime is refreshed by every successful
+// probe, so it is only treated as changed when the last recorded one is older than the refreshInterval
+func IsClusterHealthStatusChanged(last, current *ClusterHealthStatus, refreshInterval time.Duration) bool {
+	if last == nil || current == nil {
+		return last != current
+	}
+	return isLastSeenTimeStale(last.LastSeenTime, current.LastSeenTime, refreshInterval) ||
+		!last.ConditionedStatus.Equal(&current.ConditionedStatus) ||
+		last.KubernetesVersion != current.KubernetesVersion ||
+		last.NodeCount != current.NodeCount ||
+		last.CredentialState != current.CredentialState ||
+		last.CPUCapacity.Cmp(current.CPUCapacity) != 0 ||
+		last.MemoryCapacity.Cmp(current.MemoryCapacity) != 0
+}
+
+func isLastSeenTimeStale(last, current *v12.Time, refreshInterval time.Duration) bool {
+	if current == nil {
+		return false
+	}
+	return last == nil || current.Sub(last.Time) >= refreshInterval
+}
+
+// IsHealthy checks if the cluster is reachable
+func (s *ClusterHealthStatus) IsHealthy() bool {
+	return s.GetCondition(ClusterConditionReachable).Status == v1.ConditionTrue
+}
+
+// GetClusterHealthStatus returns the health status recorded on the cluster secret, nil is returned if the cluster has not been probed
+func GetClusterHealthStatus(secret *v1.Secret) (*ClusterHealthStatus, error) {
+	raw, ok := secret.GetAnnotations()[oam.AnnotationClusterHealthStatus]
+	if !ok {
+		return nil, nil
+	}
+	status := &ClusterHealthStatus{}
+	if err := json.Unmarshal([]byte(raw), status); err != nil {
+		return nil, errors.Wrapf(err, "invalid health status of cluster %s", secret.Name)
+	}
+	return status, nil
+}
+
+// SetClusterHealthStatus records the health status on the cluster secret
+func SetClusterHealthStatus(secret *v1.Secret, status *ClusterHealthStatus) error {
+	bs, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[oam.AnnotationClusterHealthStatus] = string(bs)
+	return nil
+}
+
+// IsClusterHealthy checks the health status of the cluster, the local cluster and the clusters not probed yet are treated as healthy
+func IsClusterHealthy(ctx context.Context, c client.Client, clusterName string) (bool, error) {
+	if clusterName == ClusterLocalName {
+		return true, nil
+	}
+	secret, err := getClusterSecret(ctx, c, clusterName)
+	if err != nil {
+		return false, err
+	}
+	status, err := GetClusterHealthStatus(secret)
+	if err != nil || status == nil {
+		return true, nil
+	}
+	return status.IsHealthy(), nil
+}
+
+// ProbeClusterHealth probes the cluster through cluster-gateway and returns the new health status based on the last one,
+// the config should be the config of the hub cluster
+func ProbeClusterHealth(ctx context.Context, c client.Client, config *rest.Config, clusterName string, last *ClusterHealthStatus) *ClusterHealthStatus {
+	status := &ClusterHealthStatus{}
+	if last != nil {
+		status = last.DeepCopy()
+	}
+	now := v12.Now()
+	info, err := getClusterVersion(ctx, config, clusterName)
+	if err != nil {
+		status.SetConditions(condition.Condition{
+			Type:               ClusterConditionReachable,
+			Status:             v1.ConditionFalse,
+			LastTransitionTime: now,
+			Reason:             ReasonClusterUnreachable,
+			Message:            err.Error(),
+		})
+		return status
+	}
+	status.SetConditions(condition.Condition{
+		Type:               ClusterConditionReachable,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             ReasonClusterReachable,
+	})
+	status.KubernetesVersion = info.GitVersion
+	status.LastSeenTime = &now
+
+	clusterInfo, err := GetClusterInfo(ctx, c, clusterName)
+	if err != nil {
+		status.SetConditions(condition.Condition{
+			Type:               ClusterConditionResourceSynced,
+			Status:             v1.ConditionFalse,
+			LastTransitionTime: now,
+			Reason:             condition.ReasonReconcileError,
+			Message:            err.Error(),
+		})
+		return status
+	}
+	status.SetConditions(condition.Condition{
+		Type:               ClusterConditionResourceSynced,
+		Status:             v1.ConditionTrue,
+		LastTransitionTime: now,
+		Reason:             condition.ReasonReconcileSuccess,
+	})
+	status.NodeCount = len(clusterInfo.Nodes.Items)
+	status.CPUCapacity = clusterInfo.CPUCapacity
+	status.MemoryCapacity = clusterInfo.MemoryCapacity
+	return status
+}
+
+func getClusterVersion(ctx context.Context, config *rest.Config, clusterName string) (*version.Info, error) {
+	cfg := rest.CopyConfig(config)
+	cfg.Wrap(NewClusterGatewayRoundTripperWrapperGenerator(clusterName))
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	body, err := dc.RESTClient().Get().AbsPath("/version").Do(ctx).Raw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the version of cluster %s", clusterName)
+	}
+	info := &version.Info{}
+	if err := json.Unmarshal(body, info); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode the version of cluster %s", clusterName)
+	}
+	return info, nil
+}
+
+// DeepCopy copies the health status
+func (s *ClusterHealthStatus) DeepCopy() *ClusterHealthStatus {
+	out := &ClusterHealthStatus{
+		KubernetesVersion: s.KubernetesVersion,
+		NodeCount:         s.NodeCount,
+		CPUCapacity:       s.CPUCapacity.DeepCopy(),
+		MemoryCapacity:    s.MemoryCapacity.DeepCopy(),
+		CredentialState:   s.CredentialState,
+	}
+	out.Conditions = append(out.Conditions, s.Conditions...)
+	if s.LastSeenTime != nil {
+		out.LastSeenTime = s.LastSeenTime.DeepCopy()
+	}
+	return out
+}
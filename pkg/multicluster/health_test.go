@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+func TestProbeClusterHealth(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !strings.HasSuffix(req.URL.Path, "/clustergateways/healthy-cluster/proxy/version") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"gitVersion":"v1.20.4"}`))
+	}))
+	defer server.Close()
+	c := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(&v1.Node{
+		ObjectMeta: v12.ObjectMeta{Name: "node"},
+		Status: v1.NodeStatus{Capacity: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("4"),
+			v1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}).Build()
+	config := &rest.Config{Host: server.URL}
+
+	status := ProbeClusterHealth(ctx, c, config, "healthy-cluster", nil)
+	r.True(status.IsHealthy())
+	r.Equal("v1.20.4", status.KubernetesVersion)
+	r.Equal(1, status.NodeCount)
+	r.Equal("4", status.CPUCapacity.String())
+	r.Equal("8Gi", status.MemoryCapacity.String())
+	r.NotNil(status.LastSeenTime)
+
+	reprobed := ProbeClusterHealth(ctx, c, config, "healthy-cluster", status)
+	r.False(IsClusterHealthStatusChanged(status, reprobed, time.Hour))
+	r.True(IsClusterHealthStatusChanged(nil, reprobed, time.Hour))
+	expiring := reprobed.DeepCopy()
+	expiring.CredentialState = ClusterCredentialExpiring
+	r.True(IsClusterHealthStatusChanged(reprobed, expiring, time.Hour))
+	stale := status.DeepCopy()
+	stale.LastSeenTime = &v12.Time{Time: reprobed.LastSeenTime.Add(-2 * time.Hour)}
+	r.True(IsClusterHealthStatusChanged(stale, reprobed, time.Hour))
+
+	status = ProbeClusterHealth(ctx, c, config, "unhealthy-cluster", status)
+	r.True(IsClusterHealthStatusChanged(reprobed, status, time.Hour))
+	r.False(status.IsHealthy())
+	r.Equal("v1.20.4", status.KubernetesVersion)
+	r.NotEmpty(status.GetCondition(ClusterConditionReachable).Message)
+
+	secret := &v1.Secret{ObjectMeta: v12.ObjectMeta{Name: "unhealthy-cluster"}}
+	status2, err := GetClusterHealthStatus(secret)
+	r.NoError(err)
+	r.Nil(status2)
+	r.NoError(SetClusterHealthStatus(secret, status))
+	status2, err = GetClusterHealthStatus(secret)
+	r.NoError(err)
+	r.False(status2.IsHealthy())
+	r.Equal(status.KubernetesVersion, status2.KubernetesVersion)
+}
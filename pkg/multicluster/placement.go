@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+)
+
+// MakePlacementDecisions selects the clusters and namespace for the env with the placement. The clusters are selected
+// by labels, and the name of cluster selector further restricts the selected clusters. The unhealthy clusters are
+// skipped. If no cluster is selected, the cluster specified by name or the local cluster is used.
+func MakePlacementDecisions(ctx context.Context, c client.Client, env string, placement *v1alpha1.EnvPlacement) ([]v1alpha1.PlacementDecision, error) {
+	var namespace, clusterName string
+	var clusterNames []string
+	// check if namespace selector is valid
+	if placement.NamespaceSelector != nil {
+		if len(placement.NamespaceSelector.Labels) != 0 {
+			return nil, errors.Errorf("invalid env %s: namespace selector in cluster-gateway does not support label selector for now", env)
+		}
+		namespace = placement.NamespaceSelector.Name
+	}
+	// select clusters by labels, the name of cluster selector further restricts the selected clusters
+	if placement.ClusterSelector != nil {
+		clusterName = placement.ClusterSelector.Name
+		if len(placement.ClusterSelector.Labels) != 0 {
+			clusters, err := ListClustersByLabels(ctx, c, placement.ClusterSelector.Labels)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to select clusters for env %s", env)
+			}
+			for _, cluster := range clusters {
+				if clusterName != "" && cluster != clusterName {
+					continue
+				}
+				// skip the unhealthy clusters
+				healthy, err := IsClusterHealthy(ctx, c, cluster)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to check the health of cluster %s for env %s", cluster, env)
+				}
+				if healthy {
+					clusterNames = append(clusterNames, cluster)
+				}
+			}
+			if len(clusterNames) == 0 {
+				return nil, errors.Errorf("invalid env %s: no healthy cluster matches the cluster selector", env)
+			}
+		}
+	}
+	if len(clusterNames) == 0 {
+		// set fallback cluster
+		if clusterName == "" {
+			clusterName = ClusterLocalName
+		}
+		// check if target cluster exists
+		if clusterName != ClusterLocalName {
+			if _, err := getClusterSecret(ctx, c, clusterName); err != nil {
+				return nil, errors.Wrapf(err, "failed to get cluster %s for env %s", clusterName, env)
+			}
+			if healthy, err := IsClusterHealthy(ctx, c, clusterName); err == nil && !healthy {
+				return nil, errors.Errorf("cluster %s for env %s is unhealthy", clusterName, env)
+			}
+		}
+		clusterNames = []string{clusterName}
+	}
+	var decisions []v1alpha1.PlacementDecision
+	for _, cluster := range clusterNames {
+		decisions = append(decisions, v1alpha1.PlacementDecision{
+			Cluster:   cluster,
+			Namespace: namespace,
+		})
+	}
+	return decisions, nil
+}
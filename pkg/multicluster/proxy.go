@@ -18,13 +18,17 @@ package multicluster
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/client-go/transport"
 	"k8s.io/klog/v2"
 
 	clusterapi "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
 )
 
 type secretMultiClusterRoundTripper struct {
@@ -53,7 +57,19 @@ func (rt *secretMultiClusterRoundTripper) RoundTrip(req *http.Request) (*http.Re
 	}
 
 	req.URL.Path = FormatProxyURL(clusterName, req.URL.Path)
-	return rt.rt.RoundTrip(req)
+	return roundTripWithMetrics(rt.rt, req, clusterName)
+}
+
+// roundTripWithMetrics sends the request to the managed cluster and records the latency by the response code
+func roundTripWithMetrics(rt http.RoundTripper, req *http.Request, clusterName string) (*http.Response, error) {
+	begin := time.Now()
+	resp, err := rt.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.ClusterGatewayRequestTimeHistogram.WithLabelValues(clusterName, req.Method, code).Observe(time.Since(begin).Seconds())
+	return resp, err
 }
 
 func tryCancelRequest(rt http.RoundTripper, req *http.Request) {
@@ -85,10 +101,11 @@ type secretMultiClusterRoundTripperForCluster struct {
 
 // RoundTrip is the main function for the re-write API path logic
 func (rt *secretMultiClusterRoundTripperForCluster) RoundTrip(req *http.Request) (*http.Response, error) {
-	if rt.clusterName != "" && rt.clusterName != ClusterLocalName {
-		req.URL.Path = FormatProxyURL(rt.clusterName, req.URL.Path)
+	if rt.clusterName == "" || rt.clusterName == ClusterLocalName {
+		return rt.rt.RoundTrip(req)
 	}
-	return rt.rt.RoundTrip(req)
+	req.URL.Path = FormatProxyURL(rt.clusterName, req.URL.Path)
+	return roundTripWithMetrics(rt.rt, req, rt.clusterName)
 }
 
 // NewSecretModeMultiClusterRoundTripperForCluster will re-write the API path to the specific cluster
@@ -17,9 +17,16 @@ limitations under the License.
 package multicluster
 
 import (
+	"context"
+	"errors"
+	"net/http"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
 )
 
 func TestFormatProxyURL(t *testing.T) {
@@ -44,3 +51,42 @@ func TestFormatProxyURL(t *testing.T) {
 		assert.Equal(t, cc.expectResult, gotResult)
 	}
 }
+
+type fakeRoundTripper func(req *http.Request) (*http.Response, error)
+
+func (f fakeRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClusterGatewayRequestMetrics(t *testing.T) {
+	var path string
+	rt := NewSecretModeMultiClusterRoundTripper(fakeRoundTripper(func(req *http.Request) (*http.Response, error) {
+		path = req.URL.Path
+		if req.Method == http.MethodDelete {
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+	observed := func(cluster, method, code string) uint64 {
+		m := &dto.Metric{}
+		assert.NoError(t, metrics.ClusterGatewayRequestTimeHistogram.WithLabelValues(cluster, method, code).(prometheus.Metric).Write(m))
+		return m.GetHistogram().GetSampleCount()
+	}
+
+	req, _ := http.NewRequestWithContext(ContextWithClusterName(context.Background(), ClusterLocalName), http.MethodGet, "/api/pods", nil)
+	_, err := rt.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/pods", path)
+	assert.Equal(t, uint64(0), observed(ClusterLocalName, http.MethodGet, "200"))
+
+	req, _ = http.NewRequestWithContext(ContextWithClusterName(context.Background(), "metrics-cluster"), http.MethodGet, "/api/pods", nil)
+	_, err = rt.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, FormatProxyURL("metrics-cluster", "/api/pods"), path)
+	assert.Equal(t, uint64(1), observed("metrics-cluster", http.MethodGet, "200"))
+
+	req, _ = http.NewRequestWithContext(ContextWithClusterName(context.Background(), "metrics-cluster"), http.MethodDelete, "/api/pods", nil)
+	_, err = rt.RoundTrip(req)
+	assert.Error(t, err)
+	assert.Equal(t, uint64(1), observed("metrics-cluster", http.MethodDelete, "error"))
+}
@@ -0,0 +1,388 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1alpha12 "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	errors2 "k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd/api"
+	clientcmdapiv1 "k8s.io/client-go/tools/clientcmd/api/v1"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/oam"
+)
+
+const (
+	// DefaultClusterServiceAccountName is the default name of the service account created in the managed cluster,
+	// the cluster role and the cluster role binding share the same name
+	DefaultClusterServiceAccountName = "kubevela-cluster-gateway"
+)
+
+var (
+	// DefaultClusterServiceAccountRules is the default rules granted to the service account in the whole managed cluster,
+	// which covers the resources dispatched by the built-in definitions and the information collected from the cluster.
+	// The secrets, service accounts and pods can only be written in the namespaces granted by DefaultNamespaceServiceAccountRules.
+	DefaultClusterServiceAccountRules = []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"namespaces", "configmaps", "services", "persistentvolumeclaims", "events"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{""},
+		Resources: []string{"serviceaccounts", "pods", "pods/log", "endpoints", "nodes"},
+		Verbs:     []string{"get", "list", "watch"},
+	}, {
+		APIGroups: []string{"apps"},
+		Resources: []string{"deployments", "statefulsets", "daemonsets", "replicasets", "controllerrevisions"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"batch"},
+		Resources: []string{"jobs", "cronjobs"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"networking.k8s.io"},
+		Resources: []string{"ingresses", "networkpolicies"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"autoscaling"},
+		Resources: []string{"horizontalpodautoscalers"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"policy"},
+		Resources: []string{"poddisruptionbudgets"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"core.oam.dev"},
+		Resources: []string{"*"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}, {
+		APIGroups: []string{"storage.k8s.io"},
+		Resources: []string{"storageclasses"},
+		Verbs:     []string{"get", "list", "watch"},
+	}, {
+		NonResourceURLs: []string{"/version", "/healthz"},
+		Verbs:           []string{"get"},
+	}}
+
+	// DefaultNamespaceServiceAccountRules is the default rules granted to the service account in the namespaces of the
+	// managed cluster it works in, the namespace of the service account is always included to rotate its tokens
+	DefaultNamespaceServiceAccountRules = []rbacv1.PolicyRule{{
+		APIGroups: []string{""},
+		Resources: []string{"secrets", "serviceaccounts", "pods"},
+		Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+	}}
+
+	serviceAccountTokenPollInterval = time.Second
+	serviceAccountTokenPollTimeout  = time.Minute
+)
+
+// ServiceAccountOptions describes the service account created in the managed cluster for KubeVela to access
+type ServiceAccountOptions struct {
+	// Name of the service account, defaults to DefaultClusterServiceAccountName
+	Name string
+	// Namespace of the service account, defaults to vela-system
+	Namespace string
+	// Rules granted to the service account in the whole cluster, defaults to DefaultClusterServiceAccountRules
+	Rules []rbacv1.PolicyRule
+	// NamespaceRules granted to the service account in the Namespaces, defaults to DefaultNamespaceServiceAccountRules
+	NamespaceRules []rbacv1.PolicyRule
+	// Namespaces where the NamespaceRules are granted, the namespace of the service account is always included
+	Namespaces []string
+}
+
+func (opts ServiceAccountOptions) complete() ServiceAccountOptions {
+	if opts.Name == "" {
+		opts.Name = DefaultClusterServiceAccountName
+	}
+	if opts.Namespace == "" {
+		opts.Namespace = types.DefaultKubeVelaNS
+	}
+	if len(opts.Rules) == 0 {
+		opts.Rules = DefaultClusterServiceAccountRules
+	}
+	if len(opts.NamespaceRules) == 0 {
+		opts.NamespaceRules = DefaultNamespaceServiceAccountRules
+	}
+	namespaces := []string{opts.Namespace}
+	for _, ns := range opts.Namespaces {
+		if ns != "" && ns != opts.Namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	opts.Namespaces = namespaces
+	return opts
+}
+
+// BootstrapServiceAccount creates the service account and grants the rules to it in the managed cluster accessed by the client,
+// then returns the secret holding the token of the service account. The created objects are removed if it fails.
+func BootstrapServiceAccount(ctx context.Context, c client.Client, opts ServiceAccountOptions) (*v1.Secret, error) {
+	opts = opts.complete()
+	secret, err := bootstrapServiceAccount(ctx, c, opts)
+	if err != nil {
+		CleanupServiceAccount(ctx, c, opts)
+		return nil, err
+	}
+	return secret, nil
+}
+
+func bootstrapServiceAccount(ctx context.Context, c client.Client, opts ServiceAccountOptions) (*v1.Secret, error) {
+	for _, ns := range opts.Namespaces {
+		if err := c.Get(ctx, client.ObjectKey{Name: ns}, &v1.Namespace{}); err != nil {
+			if !errors2.IsNotFound(err) {
+				return nil, errors.Wrapf(err, "failed to check namespace %s", ns)
+			}
+			if err = c.Create(ctx, &v1.Namespace{ObjectMeta: v12.ObjectMeta{Name: ns}}); err != nil {
+				return nil, errors.Wrapf(err, "failed to create namespace %s", ns)
+			}
+		}
+	}
+	sa := &v1.ServiceAccount{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, sa, func() error { return nil }); err != nil {
+		return nil, errors.Wrapf(err, "failed to create service account %s/%s", opts.Namespace, opts.Name)
+	}
+	subjects := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: opts.Name, Namespace: opts.Namespace}}
+	role := &rbacv1.ClusterRole{ObjectMeta: v12.ObjectMeta{Name: opts.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, role, func() error {
+		role.Rules = opts.Rules
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to create cluster role %s", opts.Name)
+	}
+	binding := &rbacv1.ClusterRoleBinding{ObjectMeta: v12.ObjectMeta{Name: opts.Name}}
+	if _, err := controllerutil.CreateOrUpdate(ctx, c, binding, func() error {
+		binding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: opts.Name}
+		binding.Subjects = subjects
+		return nil
+	}); err != nil {
+		return nil, errors.Wrapf(err, "failed to create cluster role binding %s", opts.Name)
+	}
+	for _, ns := range opts.Namespaces {
+		nsRole := &rbacv1.Role{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: ns}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, c, nsRole, func() error {
+			nsRole.Rules = opts.NamespaceRules
+			return nil
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to create role %s/%s", ns, opts.Name)
+		}
+		nsBinding := &rbacv1.RoleBinding{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: ns}}
+		if _, err := controllerutil.CreateOrUpdate(ctx, c, nsBinding, func() error {
+			nsBinding.RoleRef = rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: opts.Name}
+			nsBinding.Subjects = subjects
+			return nil
+		}); err != nil {
+			return nil, errors.Wrapf(err, "failed to create role binding %s/%s", ns, opts.Name)
+		}
+	}
+	return createServiceAccountToken(ctx, c, opts.Namespace, opts.Name)
+}
+
+// CleanupServiceAccount removes the service account, its token secrets and the roles granted to it from the managed cluster,
+// the namespaces are kept. The errors are ignored as it is used to clean up after failures.
+func CleanupServiceAccount(ctx context.Context, c client.Client, opts ServiceAccountOptions) {
+	opts = opts.complete()
+	tokens := &v1.SecretList{}
+	if err := c.List(ctx, tokens, client.InNamespace(opts.Namespace)); err == nil {
+		for i := range tokens.Items {
+			if token := &tokens.Items[i]; token.Type == v1.SecretTypeServiceAccountToken && token.Annotations[v1.ServiceAccountNameKey] == opts.Name {
+				_ = c.Delete(ctx, token)
+			}
+		}
+	}
+	objs := []client.Object{
+		&rbacv1.ClusterRoleBinding{ObjectMeta: v12.ObjectMeta{Name: opts.Name}},
+		&rbacv1.ClusterRole{ObjectMeta: v12.ObjectMeta{Name: opts.Name}},
+	}
+	for _, ns := range opts.Namespaces {
+		objs = append(objs,
+			&rbacv1.RoleBinding{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: ns}},
+			&rbacv1.Role{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: ns}})
+	}
+	objs = append(objs, &v1.ServiceAccount{ObjectMeta: v12.ObjectMeta{Name: opts.Name, Namespace: opts.Namespace}})
+	for _, obj := range objs {
+		if err := c.Delete(ctx, obj); err != nil && !errors2.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to clean up the service account", "kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+		}
+	}
+}
+
+// createServiceAccountToken creates a new token secret for the service account and waits for the token to be populated
+func createServiceAccountToken(ctx context.Context, c client.Client, namespace, name string) (*v1.Secret, error) {
+	secret := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:        fmt.Sprintf("%s-token-%s", name, utilrand.String(5)),
+			Namespace:   namespace,
+			Annotations: map[string]string{v1.ServiceAccountNameKey: name},
+		},
+		Type: v1.SecretTypeServiceAccountToken,
+	}
+	if err := c.Create(ctx, secret); err != nil {
+		return nil, errors.Wrapf(err, "failed to create token secret for service account %s/%s", namespace, name)
+	}
+	err := wait.PollImmediate(serviceAccountTokenPollInterval, serviceAccountTokenPollTimeout, func() (bool, error) {
+		if err := c.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+			return false, err
+		}
+		return len(secret.Data[v1.ServiceAccountTokenKey]) > 0, nil
+	})
+	if err != nil {
+		_ = c.Delete(ctx, secret)
+		return nil, errors.Wrapf(err, "failed to wait for the token of service account %s/%s", namespace, name)
+	}
+	return secret, nil
+}
+
+// JoinClusterByServiceAccount add child cluster by creating a dedicated service account in it with the rest config,
+// only the token of the service account is stored in the hub cluster. The server of the cluster is used as the endpoint
+// of the joined cluster, and the service account is removed if the cluster fails to join.
+func JoinClusterByServiceAccount(ctx context.Context, k8sClient client.Client, remoteConfig *rest.Config, cluster *api.Cluster, clusterName string, opts ServiceAccountOptions) error {
+	if err := ensureClusterNameAvailable(ctx, k8sClient, clusterName); err != nil {
+		return err
+	}
+	remoteClient, err := client.New(remoteConfig, client.Options{Scheme: clientgoscheme.Scheme})
+	if err != nil {
+		return errors.Wrapf(err, "failed to create client of cluster %s", clusterName)
+	}
+	return joinClusterByServiceAccount(ctx, k8sClient, remoteClient, clusterName, cluster, opts)
+}
+
+func joinClusterByServiceAccount(ctx context.Context, k8sClient client.Client, remoteClient client.Client, clusterName string, cluster *api.Cluster, opts ServiceAccountOptions) error {
+	opts = opts.complete()
+	tokenSecret, err := BootstrapServiceAccount(ctx, remoteClient, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to bootstrap service account in cluster %s", clusterName)
+	}
+	caData := cluster.CertificateAuthorityData
+	if len(caData) == 0 {
+		caData = tokenSecret.Data[v1.ServiceAccountRootCAKey]
+	}
+	if len(caData) == 0 {
+		CleanupServiceAccount(ctx, remoteClient, opts)
+		return errors.Wrapf(ErrClusterCANotFound, "cannot join cluster %s", clusterName)
+	}
+	secret := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      clusterName,
+			Namespace: ClusterGatewaySecretNamespace,
+			Labels: map[string]string{
+				v1alpha12.LabelKeyClusterCredentialType: string(v1alpha12.CredentialTypeServiceAccountToken),
+			},
+			Annotations: map[string]string{
+				oam.AnnotationClusterServiceAccount:      opts.Namespace + "/" + opts.Name,
+				oam.AnnotationClusterServiceAccountToken: tokenSecret.Name,
+			},
+		},
+		Type: v1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"endpoint": []byte(cluster.Server),
+			"ca.crt":   caData,
+			"token":    tokenSecret.Data[v1.ServiceAccountTokenKey],
+		},
+	}
+	if err := createClusterSecret(ctx, k8sClient, secret, types.DefaultKubeVelaNS); err != nil {
+		CleanupServiceAccount(ctx, remoteClient, opts)
+		return err
+	}
+	return nil
+}
+
+// RotateClusterCredential issues a new token for the service account of the cluster and revokes the old one,
+// the cluster must be joined by the service account created by KubeVela
+func RotateClusterCredential(ctx context.Context, k8sClient client.Client, clusterName string) error {
+	secret, err := getClusterSecret(ctx, k8sClient, clusterName)
+	if err != nil {
+		return err
+	}
+	parts := strings.SplitN(secret.Annotations[oam.AnnotationClusterServiceAccount], "/", 2)
+	if len(parts) != 2 || secret.Labels[v1alpha12.LabelKeyClusterCredentialType] != string(v1alpha12.CredentialTypeServiceAccountToken) {
+		return errors.Wrapf(ErrClusterNotJoinedByServiceAccount, "cannot rotate the credential of cluster %s", clusterName)
+	}
+	namespace, name := parts[0], parts[1]
+	remoteCtx := ContextWithClusterName(ctx, clusterName)
+	tokenSecret, err := createServiceAccountToken(remoteCtx, k8sClient, namespace, name)
+	if err != nil {
+		return errors.Wrapf(err, "failed to issue new token in cluster %s", clusterName)
+	}
+	oldTokenSecretName := secret.Annotations[oam.AnnotationClusterServiceAccountToken]
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data["token"] = tokenSecret.Data[v1.ServiceAccountTokenKey]
+	secret.Annotations[oam.AnnotationClusterServiceAccountToken] = tokenSecret.Name
+	if err := setClusterCredentialExpiration(secret); err != nil {
+		_ = k8sClient.Delete(remoteCtx, tokenSecret)
+		return err
+	}
+	if err := k8sClient.Update(ctx, secret); err != nil {
+		_ = k8sClient.Delete(remoteCtx, tokenSecret)
+		return errors.Wrapf(err, "failed to update the credential of cluster %s", clusterName)
+	}
+	if oldTokenSecretName == "" {
+		return nil
+	}
+	// the request is sent with the new token now, the old token is revoked by deleting its secret
+	oldTokenSecret := &v1.Secret{ObjectMeta: v12.ObjectMeta{Name: oldTokenSecretName, Namespace: namespace}}
+	if err := k8sClient.Delete(remoteCtx, oldTokenSecret); err != nil && !errors2.IsNotFound(err) {
+		return errors.Wrapf(err, "failed to revoke the old token of cluster %s", clusterName)
+	}
+	return nil
+}
+
+// NewTokenKubeConfig builds the kubeconfig accessing the cluster with the endpoint, the CA data and the bearer token,
+// the CA data is required unless the TLS verification is explicitly skipped by insecure
+func NewTokenKubeConfig(clusterName string, endpoint string, caData []byte, token string, insecure bool) ([]byte, error) {
+	if len(caData) == 0 && !insecure {
+		return nil, errors.Wrapf(ErrClusterCANotFound, "cannot access cluster %s", clusterName)
+	}
+	config := clientcmdapiv1.Config{
+		APIVersion: "v1",
+		Kind:       "Config",
+		Clusters: []clientcmdapiv1.NamedCluster{{
+			Name: clusterName,
+			Cluster: clientcmdapiv1.Cluster{
+				Server:                   endpoint,
+				CertificateAuthorityData: caData,
+				InsecureSkipTLSVerify:    insecure,
+			},
+		}},
+		AuthInfos: []clientcmdapiv1.NamedAuthInfo{{
+			Name:     clusterName,
+			AuthInfo: clientcmdapiv1.AuthInfo{Token: token},
+		}},
+		Contexts: []clientcmdapiv1.NamedContext{{
+			Name:    clusterName,
+			Context: clientcmdapiv1.Context{Cluster: clusterName, AuthInfo: clusterName},
+		}},
+		CurrentContext: clusterName,
+	}
+	return yaml.Marshal(config)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package multicluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	v12 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// populateServiceAccountTokens simulates the token controller of kubernetes
+func populateServiceAccountTokens(ctx context.Context, c client.Client, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		secrets := &v1.SecretList{}
+		if err := c.List(ctx, secrets); err != nil {
+			continue
+		}
+		for i := range secrets.Items {
+			secret := secrets.Items[i]
+			if secret.Type != v1.SecretTypeServiceAccountToken || len(secret.Data) > 0 {
+				continue
+			}
+			secret.Data = map[string][]byte{
+				v1.ServiceAccountTokenKey:  []byte("token-of-" + secret.Name),
+				v1.ServiceAccountRootCAKey: []byte("ca"),
+			}
+			_ = c.Update(ctx, &secret)
+		}
+	}
+}
+
+func TestJoinClusterByServiceAccount(t *testing.T) {
+	oldClusterGatewaySecretNamespace := ClusterGatewaySecretNamespace
+	ClusterGatewaySecretNamespace = "default"
+	oldPollInterval := serviceAccountTokenPollInterval
+	serviceAccountTokenPollInterval = 10 * time.Millisecond
+	defer func() {
+		ClusterGatewaySecretNamespace = oldClusterGatewaySecretNamespace
+		serviceAccountTokenPollInterval = oldPollInterval
+	}()
+	r := require.New(t)
+	ctx := context.Background()
+	hubClient := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	remoteClient := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	stop := make(chan struct{})
+	defer close(stop)
+	go populateServiceAccountTokens(ctx, remoteClient, stop)
+
+	cluster := &api.Cluster{Server: "https://1.2.3.4:6443"}
+	r.NoError(joinClusterByServiceAccount(ctx, hubClient, remoteClient, "cluster-sa", cluster, ServiceAccountOptions{Namespaces: []string{"apps"}}))
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	r.NoError(remoteClient.Get(ctx, client.ObjectKey{Name: DefaultClusterServiceAccountName}, binding))
+	r.Equal("vela-system", binding.Subjects[0].Namespace)
+	role := &rbacv1.ClusterRole{}
+	r.NoError(remoteClient.Get(ctx, client.ObjectKey{Name: DefaultClusterServiceAccountName}, role))
+	r.Equal(DefaultClusterServiceAccountRules, role.Rules)
+	for _, ns := range []string{"vela-system", "apps"} {
+		nsRole := &rbacv1.Role{}
+		r.NoError(remoteClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: DefaultClusterServiceAccountName}, nsRole))
+		r.Equal(DefaultNamespaceServiceAccountRules, nsRole.Rules)
+		nsBinding := &rbacv1.RoleBinding{}
+		r.NoError(remoteClient.Get(ctx, client.ObjectKey{Namespace: ns, Name: DefaultClusterServiceAccountName}, nsBinding))
+		r.Equal("Role", nsBinding.RoleRef.Kind)
+	}
+
+	secret := &v1.Secret{}
+	r.NoError(hubClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-sa"}, secret))
+	r.Equal(string(v1alpha1.CredentialTypeServiceAccountToken), secret.Labels[v1alpha1.LabelKeyClusterCredentialType])
+	r.Equal("vela-system/"+DefaultClusterServiceAccountName, secret.Annotations[oam.AnnotationClusterServiceAccount])
+	tokenSecretName := secret.Annotations[oam.AnnotationClusterServiceAccountToken]
+	r.Equal("token-of-"+tokenSecretName, string(secret.Data["token"]))
+	r.Equal("ca", string(secret.Data["ca.crt"]))
+	r.Equal("https://1.2.3.4:6443", string(secret.Data["endpoint"]))
+
+	// the service account is cleaned up if the cluster fails to join
+	otherRemoteClient := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	go populateServiceAccountTokens(ctx, otherRemoteClient, stop)
+	r.Error(joinClusterByServiceAccount(ctx, hubClient, otherRemoteClient, "cluster-sa", cluster, ServiceAccountOptions{}))
+	r.True(kerrors.IsNotFound(otherRemoteClient.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: DefaultClusterServiceAccountName}, &v1.ServiceAccount{})))
+	r.True(kerrors.IsNotFound(otherRemoteClient.Get(ctx, client.ObjectKey{Name: DefaultClusterServiceAccountName}, &rbacv1.ClusterRole{})))
+	r.True(kerrors.IsNotFound(otherRemoteClient.Get(ctx, client.ObjectKey{Name: DefaultClusterServiceAccountName}, &rbacv1.ClusterRoleBinding{})))
+	r.True(kerrors.IsNotFound(otherRemoteClient.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: DefaultClusterServiceAccountName}, &rbacv1.Role{})))
+	tokens := &v1.SecretList{}
+	r.NoError(otherRemoteClient.List(ctx, tokens))
+	r.Empty(tokens.Items)
+	r.NoError(hubClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-sa"}, &v1.Secret{}))
+}
+
+func TestRotateClusterCredential(t *testing.T) {
+	oldClusterGatewaySecretNamespace := ClusterGatewaySecretNamespace
+	ClusterGatewaySecretNamespace = "default"
+	oldPollInterval := serviceAccountTokenPollInterval
+	serviceAccountTokenPollInterval = 10 * time.Millisecond
+	defer func() {
+		ClusterGatewaySecretNamespace = oldClusterGatewaySecretNamespace
+		serviceAccountTokenPollInterval = oldPollInterval
+	}()
+	r := require.New(t)
+	ctx := context.Background()
+	c := fake.NewClientBuilder().WithScheme(common.Scheme).WithObjects(&v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      "cluster-sa",
+			Namespace: "default",
+			Labels:    map[string]string{v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeServiceAccountToken)},
+			Annotations: map[string]string{
+				oam.AnnotationClusterServiceAccount:      "vela-system/sa",
+				oam.AnnotationClusterServiceAccountToken: "sa-token-old",
+			},
+		},
+		Data: map[string][]byte{"token": []byte("old")},
+	}, &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{Name: "sa-token-old", Namespace: "vela-system"},
+		Type:       v1.SecretTypeServiceAccountToken,
+		Data:       map[string][]byte{v1.ServiceAccountTokenKey: []byte("old")},
+	}, &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Name:      "cluster-x509",
+			Namespace: "default",
+			Labels:    map[string]string{v1alpha1.LabelKeyClusterCredentialType: string(v1alpha1.CredentialTypeX509Certificate)},
+		},
+	}).Build()
+	stop := make(chan struct{})
+	defer close(stop)
+	go populateServiceAccountTokens(ctx, c, stop)
+
+	r.NoError(RotateClusterCredential(ctx, c, "cluster-sa"))
+	secret := &v1.Secret{}
+	r.NoError(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "cluster-sa"}, secret))
+	tokenSecretName := secret.Annotations[oam.AnnotationClusterServiceAccountToken]
+	r.NotEqual("sa-token-old", tokenSecretName)
+	r.Equal("token-of-"+tokenSecretName, string(secret.Data["token"]))
+	err := c.Get(ctx, client.ObjectKey{Namespace: "vela-system", Name: "sa-token-old"}, &v1.Secret{})
+	r.True(kerrors.IsNotFound(err))
+
+	r.ErrorIs(RotateClusterCredential(ctx, c, "cluster-x509"), ErrClusterNotJoinedByServiceAccount)
+	r.ErrorIs(RotateClusterCredential(ctx, c, ClusterLocalName), ErrReservedLocalClusterName)
+}
+
+func TestNewTokenKubeConfig(t *testing.T) {
+	r := require.New(t)
+	_, err := NewTokenKubeConfig("example", "https://1.2.3.4:6443", nil, "token", false)
+	r.ErrorIs(err, ErrClusterCANotFound)
+	data, err := NewTokenKubeConfig("example", "https://1.2.3.4:6443", nil, "token", true)
+	r.NoError(err)
+	config, err := clientcmd.Load(data)
+	r.NoError(err)
+	r.True(config.Clusters["example"].InsecureSkipTLSVerify)
+	data, err = NewTokenKubeConfig("example", "https://1.2.3.4:6443", []byte("ca"), "token", false)
+	r.NoError(err)
+	config, err = clientcmd.Load(data)
+	r.NoError(err)
+	r.False(config.Clusters["example"].InsecureSkipTLSVerify)
+	r.Equal("example", config.CurrentContext)
+	r.Equal("https://1.2.3.4:6443", config.Clusters["example"].Server)
+	r.Equal([]byte("ca"), config.Clusters["example"].CertificateAuthorityData)
+	r.Equal("token", config.AuthInfos["example"].Token)
+}
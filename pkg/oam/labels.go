@@ -39,6 +39,8 @@ const (
 	LabelAppCluster = "app.oam.dev/cluster"
 	// LabelAppUID records the uid of Application
 	LabelAppUID = "app.oam.dev/uid"
+	// LabelControllerShardID records the id of the controller shard that the Application is assigned to
+	LabelControllerShardID = "controller.core.oam.dev/shard-id"
 
 	// WorkloadTypeLabel indicates the type of the workloadDefinition
 	WorkloadTypeLabel = "workload.oam.dev/type"
@@ -77,6 +79,9 @@ const (
 
 	// LabelUsageNamespace mark the usage of the namespace.
 	LabelUsageNamespace = "usage.oam.dev"
+
+	// LabelConfigType records the type of the config stored in secret
+	LabelConfigType = "config.oam.dev/type"
 )
 
 const (
@@ -139,6 +144,21 @@ const (
 	// AnnotationAddonsName records the name of initializer stored in configMap
 	AnnotationAddonsName = "addons.oam.dev/name"
 
+	// AnnotationConfigClusters records the managed clusters the config is distributed to, split by comma
+	AnnotationConfigClusters = "config.oam.dev/clusters"
+
+	// AnnotationClusterHealthStatus records the health status of the managed cluster probed by the controller
+	AnnotationClusterHealthStatus = "cluster.oam.dev/health-status"
+
+	// AnnotationClusterServiceAccount records the service account in the managed cluster whose token is used to join the cluster, formatted as namespace/name
+	AnnotationClusterServiceAccount = "cluster.oam.dev/service-account"
+
+	// AnnotationClusterServiceAccountToken records the name of the secret holding the service account token in the managed cluster
+	AnnotationClusterServiceAccountToken = "cluster.oam.dev/service-account-token"
+
+	// AnnotationClusterCredentialExpiration records the expiration time of the credential of the managed cluster in RFC3339 format
+	AnnotationClusterCredentialExpiration = "cluster.oam.dev/credential-expiration"
+
 	// AnnotationLastAppliedConfiguration is kubectl annotations for 3-way merge
 	AnnotationLastAppliedConfiguration = "kubectl.kubernetes.io/last-applied-configuration"
 
@@ -54,6 +54,8 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 	cmd.Flags().StringVarP(&o.ApplicationFile, "file", "f", "./app.yaml", "application file name")
 	cmd.Flags().StringVarP(&o.DefinitionFile, "definition", "d", "", "specify a definition file or directory, it will only be used in dry-run rather than applied to K8s cluster")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "specify namespace of the definition file, by default is default namespace")
+	cmd.Flags().StringVarP(&o.Env, "env", "e", "", "specify the env in the env-binding policy of the application, by default all the envs are rendered")
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "", "specify the cluster to render the application for, by default all the clusters the envs are placed in are rendered")
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
@@ -60,6 +60,8 @@ func NewLiveDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 	cmd.Flags().StringVarP(&o.Revision, "revision", "r", "", "specify an application revision name, by default, it will compare with the latest revision")
 	cmd.Flags().IntVarP(&o.Context, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
 	cmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "specify namespace of the application to be compared, by default is default namespace")
+	cmd.Flags().StringVarP(&o.Env, "env", "e", "", "specify the env in the env-binding policy of the application, by default all the envs are rendered")
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "", "specify the cluster to render the application for, by default all the clusters the envs are placed in are rendered")
 	cmd.SetOut(ioStreams.Out)
 	return cmd
 }
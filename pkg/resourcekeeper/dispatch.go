@@ -18,11 +18,15 @@ package resourcekeeper
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+	"github.com/oam-dev/kubevela/pkg/monitor/tracing"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/resourcetracker"
@@ -49,6 +53,9 @@ func newDispatchConfig(options ...DispatchOption) *dispatchConfig {
 
 // Dispatch dispatch resources
 func (h *resourceKeeper) Dispatch(ctx context.Context, manifests []*unstructured.Unstructured, options ...DispatchOption) (err error) {
+	defer func(begin time.Time) {
+		metrics.DispatchTimeHistogram.WithLabelValues(metrics.Result(err)).Observe(time.Since(begin).Seconds())
+	}(time.Now())
 	if h.applyOncePolicy != nil && h.applyOncePolicy.Enable {
 		options = append(options, MetaOnlyOption{})
 	}
@@ -61,7 +68,16 @@ func (h *resourceKeeper) Dispatch(ctx context.Context, manifests []*unstructured
 				}
 			}
 			cfg := newDispatchConfig(_options...)
-			if err = h.dispatch(ctx, manifest, cfg); err != nil {
+			spanCtx, span := tracing.StartSpan(ctx, "dispatch",
+				attribute.String("cluster", oam.GetCluster(manifest)),
+				attribute.String("apiVersion", manifest.GetAPIVersion()),
+				attribute.String("kind", manifest.GetKind()),
+				attribute.String("namespace", manifest.GetNamespace()),
+				attribute.String("name", manifest.GetName()))
+			err = h.dispatch(spanCtx, manifest, cfg)
+			tracing.EndSpan(span, err)
+			metrics.DispatchResourceCounter.WithLabelValues(metrics.Result(err)).Inc()
+			if err != nil {
 				return err
 			}
 		}
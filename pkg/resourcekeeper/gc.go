@@ -19,6 +19,7 @@ package resourcekeeper
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	version "github.com/hashicorp/go-version"
@@ -31,6 +32,7 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/resourcetracker"
@@ -88,6 +90,10 @@ func (h *resourceKeeper) GarbageCollect(ctx context.Context, options ...GCOption
 		options = append(options, PassiveGCOption{})
 	}
 	cfg := newGCConfig(options...)
+	defer func(begin time.Time) {
+		metrics.GCTimeHistogram.WithLabelValues(metrics.Result(err)).Observe(time.Since(begin).Seconds())
+		metrics.GCCounter.WithLabelValues(metrics.Result(err)).Inc()
+	}(time.Now())
 	return h.garbageCollect(ctx, cfg)
 }
 
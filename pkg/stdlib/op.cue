@@ -22,6 +22,10 @@ import (
 
 #Delete: kube.#Delete
 
+#Patch: kube.#Patch
+
+#WaitFor: kube.#WaitFor
+
 #ApplyApplication: #Steps & {
 	load:       oam.#LoadComponetsInOrder @step(1)
 	components: #Steps & {
@@ -129,12 +133,38 @@ import (
 
 #ConvertString: convert.#String
 
+#Base64Encode: convert.#Base64Encode
+
+#Base64Decode: convert.#Base64Decode
+
+#JSONMarshal: convert.#JSONMarshal
+
+#JSONUnmarshal: convert.#JSONUnmarshal
+
+#YAMLMarshal: convert.#YAMLMarshal
+
+#YAMLUnmarshal: convert.#YAMLUnmarshal
+
+#SHA256: convert.#SHA256
+
+#HMAC: convert.#HMAC
+
+#RenderTemplate: convert.#Template
+
 #DateToTimestamp: time.#DateToTimestamp
 
 #TimestampToDate: time.#TimestampToDate
 
 #SendEmail: email.#Send
 
+#CreateConfig: config.#Create
+
+#ReadConfig: config.#Read
+
+#ListConfig: config.#List
+
+#DeleteConfig: config.#Delete
+
 #Load: oam.#LoadComponets
 
 #LoadInOrder: oam.#LoadComponetsInOrder
@@ -0,0 +1,50 @@
+#Create: {
+	#do:       "create"
+	#provider: "config"
+
+	name:      string
+	namespace: *"vela-system" | string
+	type:      string
+	config: {...}
+	// clusters specify the managed clusters to distribute the config to
+	clusters?: [...string]
+	...
+}
+
+#Read: {
+	#do:       "read"
+	#provider: "config"
+
+	name:      string
+	namespace: *"vela-system" | string
+	type?:     string
+	config?: {...}
+	err?: string
+	...
+}
+
+#List: {
+	#do:       "list"
+	#provider: "config"
+
+	namespace: *"vela-system" | string
+	type:      *"" | string
+	list?: [...{
+		name:      string
+		namespace: string
+		type:      string
+		config: {...}
+		clusters?: [...string]
+	}]
+	err?: string
+	...
+}
+
+#Delete: {
+	#do:       "delete"
+	#provider: "config"
+
+	name:      string
+	namespace: *"vela-system" | string
+	...
+}
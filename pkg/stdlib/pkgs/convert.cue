@@ -6,3 +6,89 @@
 	str?: string
 	...
 }
+
+#Base64Encode: {
+	#do:       "base64Encode"
+	#provider: "convert"
+
+	data:    string | bytes
+	result?: string
+	...
+}
+
+#Base64Decode: {
+	#do:       "base64Decode"
+	#provider: "convert"
+
+	data:    string
+	result?: string
+	...
+}
+
+#JSONMarshal: {
+	#do:       "jsonMarshal"
+	#provider: "convert"
+
+	value: _
+	str?:  string
+	...
+}
+
+#JSONUnmarshal: {
+	#do:       "jsonUnmarshal"
+	#provider: "convert"
+
+	str:    string
+	value?: _
+	...
+}
+
+#YAMLMarshal: {
+	#do:       "yamlMarshal"
+	#provider: "convert"
+
+	value: _
+	str?:  string
+	...
+}
+
+#YAMLUnmarshal: {
+	#do:       "yamlUnmarshal"
+	#provider: "convert"
+
+	str:    string
+	value?: _
+	...
+}
+
+#SHA256: {
+	#do:       "sha256"
+	#provider: "convert"
+
+	data:     string | bytes
+	encoding: *"hex" | "base64"
+	result?:  string
+	...
+}
+
+#HMAC: {
+	#do:       "hmac"
+	#provider: "convert"
+
+	data:      string | bytes
+	key:       string | bytes
+	algorithm: *"sha256" | "sha1" | "sha512"
+	encoding:  *"hex" | "base64"
+	result?:   string
+	...
+}
+
+#Template: {
+	#do:       "template"
+	#provider: "convert"
+
+	template: string
+	data?: {...}
+	result?: string
+	...
+}
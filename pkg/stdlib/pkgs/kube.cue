@@ -44,3 +44,50 @@
 	}
 	...
 }
+
+#Patch: {
+	#do:       "patch"
+	#provider: "kube"
+	cluster:   *"" | string
+	value: {
+		apiVersion: string
+		kind:       string
+		metadata: {
+			name:       string
+			namespace?: string
+		}
+		...
+	}
+	patch: {
+		type: *"merge" | "json" | "strategic"
+		data: _
+	}
+	err?: string
+	...
+}
+
+#WaitFor: {
+	#do:       "waitFor"
+	#provider: "kube"
+	cluster:   *"" | string
+	value: {
+		apiVersion: string
+		kind:       string
+		metadata: {
+			name:       string
+			namespace?: string
+		}
+		...
+	}
+	// jsonPath of the field to check, e.g. {.status.phase}
+	jsonPath?: string
+	// expected value of the field, wait until the field is not empty if not specified
+	expected?: string
+	condition?: {
+		type:   string
+		status: *"True" | "False" | "Unknown"
+	}
+	timeout:  *"5m" | string
+	matched?: bool
+	...
+}
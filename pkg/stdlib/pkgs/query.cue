@@ -19,6 +19,66 @@
 	...
 }
 
+#GetApplicationTree: {
+	#do:       "getApplicationTree"
+	#provider: "query"
+	app: {
+		name:      string
+		namespace: string
+		filter?: {
+			cluster?:          string
+			clusterNamespace?: string
+			components?: [...string]
+		}
+	}
+	list?: [...#ResourceTreeNode]
+	...
+}
+
+#ResourceTreeNode: {
+	cluster:     string
+	component?:  string
+	apiVersion:  string
+	kind:        string
+	namespace?:  string
+	name:        string
+	uid?:        string
+	healthStatus: {
+		status:   string
+		message?: string
+	}
+	error?: string
+	leafNodes?: [...#ResourceTreeNode]
+}
+
+#CollectServiceEndpoints: {
+	#do:       "collectServiceEndpoints"
+	#provider: "query"
+	app: {
+		name:      string
+		namespace: string
+		filter?: {
+			cluster?:          string
+			clusterNamespace?: string
+			components?: [...string]
+		}
+	}
+	list?: [...{
+		endpoint: {
+			protocol:     string
+			appProtocol?: string
+			host:         string
+			port:         int
+			path?:        string
+			inner?:       bool
+		}
+		ref: {...}
+		cluster:   string
+		component: string
+	}]
+	...
+}
+
 #CollectPods: {
 	#do:       "collectPods"
 	#provider: "query"
@@ -6,6 +6,10 @@
 
 #ListResourcesInApp: query.#ListResourcesInApp
 
+#GetApplicationTree: query.#GetApplicationTree
+
+#CollectServiceEndpoints: query.#CollectServiceEndpoints
+
 #CollectPods: query.#CollectPods
 
 #SearchEvents: query.#SearchEvents
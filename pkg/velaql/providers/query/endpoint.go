@@ -0,0 +1,367 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+const (
+	// ProtocolHTTP is the http protocol of the endpoint
+	ProtocolHTTP = "http"
+	// ProtocolHTTPS is the https protocol of the endpoint
+	ProtocolHTTPS = "https"
+)
+
+const (
+	istioNetworkingGroup = "networking.istio.io"
+	gatewayNetworkingAPI = "gateway.networking.k8s.io"
+	kubernetesNetworking = "networking.k8s.io"
+	extensionsNetworking = "extensions"
+	ingressKind          = "Ingress"
+	virtualServiceKind   = "VirtualService"
+	httpRouteKind        = "HTTPRoute"
+	serviceKind          = "Service"
+	defaultHTTPPort      = 80
+	defaultHTTPSPort     = 443
+)
+
+// ServiceEndpoint is the endpoint of the resource exposed by the application
+type ServiceEndpoint struct {
+	Endpoint  Endpoint               `json:"endpoint"`
+	Ref       corev1.ObjectReference `json:"ref"`
+	Cluster   string                 `json:"cluster"`
+	Component string                 `json:"component"`
+}
+
+// Endpoint is the normalized address of an endpoint
+type Endpoint struct {
+	// Protocol is the transport protocol of the endpoint, such as TCP or UDP
+	Protocol corev1.Protocol `json:"protocol"`
+	// AppProtocol is the application protocol of the endpoint, such as http or https
+	AppProtocol string `json:"appProtocol,omitempty"`
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Path        string `json:"path,omitempty"`
+	// Inner means the endpoint can only be accessed inside the cluster
+	Inner bool `json:"inner,omitempty"`
+}
+
+// String returns the url of the endpoint
+func (e Endpoint) String() string {
+	protocol := strings.ToLower(string(e.Protocol))
+	if e.AppProtocol != "" {
+		protocol = e.AppProtocol
+	}
+	if (protocol == ProtocolHTTP && e.Port == defaultHTTPPort) || (protocol == ProtocolHTTPS && e.Port == defaultHTTPSPort) {
+		return fmt.Sprintf("%s://%s%s", protocol, e.Host, e.Path)
+	}
+	return fmt.Sprintf("%s://%s:%d%s", protocol, e.Host, e.Port, e.Path)
+}
+
+// CollectServiceEndpoints collect the endpoints of the services, ingresses and routes created by application
+func (c *AppCollector) CollectServiceEndpoints() ([]ServiceEndpoint, error) {
+	resources, err := c.CollectResourceFromApp()
+	if err != nil {
+		return nil, err
+	}
+	var endpoints []ServiceEndpoint
+	for _, res := range resources {
+		ctx := multicluster.ContextWithClusterName(context.Background(), res.Cluster)
+		var resEndpoints []Endpoint
+		gvk := res.Object.GroupVersionKind()
+		switch {
+		case gvk.Group == "" && gvk.Kind == serviceKind:
+			resEndpoints, err = getServiceEndpoints(ctx, c.k8sClient, res.Object)
+		case (gvk.Group == kubernetesNetworking || gvk.Group == extensionsNetworking) && gvk.Kind == ingressKind:
+			resEndpoints = getIngressEndpoints(res.Object)
+		case gvk.Group == istioNetworkingGroup && gvk.Kind == virtualServiceKind:
+			resEndpoints = getVirtualServiceEndpoints(res.Object)
+		case gvk.Group == gatewayNetworkingAPI && gvk.Kind == httpRouteKind:
+			resEndpoints = getHTTPRouteEndpoints(res.Object)
+		default:
+			continue
+		}
+		if err != nil {
+			klog.ErrorS(err, "Failed to get the endpoints of the resource", "kind", gvk.Kind, "resource", klog.KObj(res.Object), "cluster", res.Cluster)
+			continue
+		}
+		ref := corev1.ObjectReference{
+			APIVersion:      res.Object.GetAPIVersion(),
+			Kind:            res.Object.GetKind(),
+			Namespace:       res.Object.GetNamespace(),
+			Name:            res.Object.GetName(),
+			UID:             res.Object.GetUID(),
+			ResourceVersion: res.Object.GetResourceVersion(),
+		}
+		for _, endpoint := range resEndpoints {
+			endpoints = append(endpoints, ServiceEndpoint{
+				Endpoint:  endpoint,
+				Ref:       ref,
+				Cluster:   res.Cluster,
+				Component: res.Component,
+			})
+		}
+	}
+	return endpoints, nil
+}
+
+// getServiceEndpoints resolves the load balancer addresses, node ports and cluster addresses of the service
+func getServiceEndpoints(ctx context.Context, cli client.Client, obj *unstructured.Unstructured) ([]Endpoint, error) {
+	svc := &corev1.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, svc); err != nil {
+		return nil, err
+	}
+	var endpoints []Endpoint
+	switch svc.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			host := ingress.IP
+			if host == "" {
+				host = ingress.Hostname
+			}
+			for _, port := range svc.Spec.Ports {
+				endpoints = append(endpoints, newServicePortEndpoint(port, host, int(port.Port), false))
+			}
+		}
+		// the node ports are the entries of the load balancer which is not assigned yet
+		if len(endpoints) != 0 {
+			break
+		}
+		fallthrough
+	case corev1.ServiceTypeNodePort:
+		// the node ports are skipped if the nodes are not accessible, the other endpoints of the service are kept
+		nodeHost, err := getNodeHost(ctx, cli)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get the node host of the service", "service", klog.KObj(svc))
+		}
+		if nodeHost != "" {
+			for _, port := range svc.Spec.Ports {
+				if port.NodePort != 0 {
+					endpoints = append(endpoints, newServicePortEndpoint(port, nodeHost, int(port.NodePort), false))
+				}
+			}
+		}
+	}
+	for _, port := range svc.Spec.Ports {
+		host := fmt.Sprintf("%s.%s", svc.Name, svc.Namespace)
+		endpoints = append(endpoints, newServicePortEndpoint(port, host, int(port.Port), true))
+	}
+	return endpoints, nil
+}
+
+func newServicePortEndpoint(port corev1.ServicePort, host string, portNumber int, inner bool) Endpoint {
+	endpoint := Endpoint{
+		Protocol: port.Protocol,
+		Host:     host,
+		Port:     portNumber,
+		Inner:    inner,
+	}
+	if endpoint.Protocol == "" {
+		endpoint.Protocol = corev1.ProtocolTCP
+	}
+	if port.AppProtocol != nil {
+		endpoint.AppProtocol = *port.AppProtocol
+	}
+	return endpoint
+}
+
+// getNodeHost returns the external address of one node in the cluster, the internal address is used if absent
+func getNodeHost(ctx context.Context, cli client.Client) (string, error) {
+	nodes := &corev1.NodeList{}
+	if err := cli.List(ctx, nodes); err != nil {
+		return "", err
+	}
+	var internalIP string
+	for _, node := range nodes.Items {
+		for _, address := range node.Status.Addresses {
+			switch address.Type {
+			case corev1.NodeExternalIP:
+				return address.Address, nil
+			case corev1.NodeInternalIP:
+				if internalIP == "" {
+					internalIP = address.Address
+				}
+			}
+		}
+	}
+	return internalIP, nil
+}
+
+// getIngressEndpoints resolves the hosts and paths of the ingress rules
+func getIngressEndpoints(obj *unstructured.Unstructured) []Endpoint {
+	tlsHosts := map[string]bool{}
+	tlsList, _, _ := unstructured.NestedSlice(obj.Object, "spec", "tls")
+	for _, tls := range tlsList {
+		hosts, _, _ := unstructured.NestedStringSlice(asMap(tls), "hosts")
+		for _, host := range hosts {
+			tlsHosts[host] = true
+		}
+	}
+	defaultHost := getLoadBalancerHost(obj)
+	var endpoints []Endpoint
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	for _, rule := range rules {
+		host, _, _ := unstructured.NestedString(asMap(rule), "host")
+		if host == "" {
+			host = defaultHost
+		}
+		if host == "" {
+			continue
+		}
+		paths, _, _ := unstructured.NestedSlice(asMap(rule), "http", "paths")
+		if len(paths) == 0 {
+			paths = []interface{}{map[string]interface{}{}}
+		}
+		for _, path := range paths {
+			p, _, _ := unstructured.NestedString(asMap(path), "path")
+			endpoints = append(endpoints, newHTTPEndpoint(host, p, tlsHosts[host]))
+		}
+	}
+	return endpoints
+}
+
+// routeMatch is a path of a route served on a port, the port is 0 if the route does not specify one
+type routeMatch struct {
+	path string
+	port int
+}
+
+// getVirtualServiceEndpoints resolves the hosts, uri prefixes and ports of the istio virtual service
+func getVirtualServiceEndpoints(obj *unstructured.Unstructured) []Endpoint {
+	hosts, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "hosts")
+	var matches []routeMatch
+	routes, _, _ := unstructured.NestedSlice(obj.Object, "spec", "http")
+	for _, route := range routes {
+		httpMatches, _, _ := unstructured.NestedSlice(asMap(route), "match")
+		for _, match := range httpMatches {
+			port, _, _ := unstructured.NestedInt64(asMap(match), "port")
+			path := ""
+			for _, matchType := range []string{"prefix", "exact"} {
+				if p, found, _ := unstructured.NestedString(asMap(match), "uri", matchType); found {
+					path = p
+				}
+			}
+			matches = append(matches, routeMatch{path: path, port: int(port)})
+		}
+	}
+	return newHostPathEndpoints(hosts, matches)
+}
+
+// getHTTPRouteEndpoints resolves the hostnames and paths of the gateway api http route, and the ports of the
+// gateway listeners it is attached to
+func getHTTPRouteEndpoints(obj *unstructured.Unstructured) []Endpoint {
+	hosts, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "hostnames")
+	var ports []int
+	parentRefs, _, _ := unstructured.NestedSlice(obj.Object, "spec", "parentRefs")
+	for _, ref := range parentRefs {
+		if port, found, _ := unstructured.NestedInt64(asMap(ref), "port"); found {
+			ports = append(ports, int(port))
+		}
+	}
+	if len(ports) == 0 {
+		ports = []int{0}
+	}
+	var paths []string
+	rules, _, _ := unstructured.NestedSlice(obj.Object, "spec", "rules")
+	for _, rule := range rules {
+		ruleMatches, _, _ := unstructured.NestedSlice(asMap(rule), "matches")
+		for _, match := range ruleMatches {
+			if p, found, _ := unstructured.NestedString(asMap(match), "path", "value"); found {
+				paths = append(paths, p)
+			}
+		}
+	}
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	var matches []routeMatch
+	for _, port := range ports {
+		for _, p := range paths {
+			matches = append(matches, routeMatch{path: p, port: port})
+		}
+	}
+	return newHostPathEndpoints(hosts, matches)
+}
+
+func newHostPathEndpoints(hosts []string, matches []routeMatch) []Endpoint {
+	if len(matches) == 0 {
+		matches = []routeMatch{{}}
+	}
+	var endpoints []Endpoint
+	for _, host := range hosts {
+		// wildcard hosts can not be accessed directly
+		if strings.Contains(host, "*") {
+			continue
+		}
+		for _, match := range matches {
+			endpoint := newHTTPEndpoint(host, match.path, match.port == defaultHTTPSPort)
+			if match.port != 0 {
+				endpoint.Port = match.port
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+func newHTTPEndpoint(host string, path string, tls bool) Endpoint {
+	endpoint := Endpoint{
+		Protocol:    corev1.ProtocolTCP,
+		AppProtocol: ProtocolHTTP,
+		Host:        host,
+		Port:        defaultHTTPPort,
+		Path:        path,
+	}
+	if endpoint.Path == "" {
+		endpoint.Path = "/"
+	}
+	if tls {
+		endpoint.AppProtocol = ProtocolHTTPS
+		endpoint.Port = defaultHTTPSPort
+	}
+	return endpoint
+}
+
+func getLoadBalancerHost(obj *unstructured.Unstructured) string {
+	ingressList, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	for _, ingress := range ingressList {
+		m := asMap(ingress)
+		if ip, _, _ := unstructured.NestedString(m, "ip"); ip != "" {
+			return ip
+		}
+		if hostname, _, _ := unstructured.NestedString(m, "hostname"); hostname != "" {
+			return hostname
+		}
+	}
+	return ""
+}
+
+func asMap(obj interface{}) map[string]interface{} {
+	m, _ := obj.(map[string]interface{})
+	return m
+}
@@ -0,0 +1,171 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeForbiddenClient fails the lists of the nodes
+type nodeForbiddenClient struct {
+	client.Client
+}
+
+func (c *nodeForbiddenClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*corev1.NodeList); ok {
+		return fmt.Errorf("nodes is forbidden")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+var _ = Describe("Test Service Endpoints", func() {
+	namespace := "test-service-endpoints"
+
+	It("Test collect service endpoints", func() {
+		createManagedResources(namespace, "app",
+			corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: "web"},
+			corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: "web-lb"},
+			corev1.ObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "Ingress", Name: "web"})
+
+		node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-endpoints-node"}}
+		Expect(k8sClient.Create(ctx, node)).Should(Succeed())
+		node.Status.Addresses = []corev1.NodeAddress{
+			{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+			{Type: corev1.NodeExternalIP, Address: "1.1.1.1"},
+		}
+		Expect(k8sClient.Status().Update(ctx, node)).Should(Succeed())
+
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeNodePort,
+				Ports: []corev1.ServicePort{{Port: 80, NodePort: 30080, Protocol: corev1.ProtocolTCP}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+		lb := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-lb", Namespace: namespace},
+			Spec: corev1.ServiceSpec{
+				Type:  corev1.ServiceTypeLoadBalancer,
+				Ports: []corev1.ServicePort{{Port: 8080, Protocol: corev1.ProtocolTCP}},
+			},
+		}
+		Expect(k8sClient.Create(ctx, lb)).Should(Succeed())
+		lb.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "2.2.2.2"}}
+		Expect(k8sClient.Status().Update(ctx, lb)).Should(Succeed())
+
+		ingress := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "networking.k8s.io/v1",
+			"kind":       "Ingress",
+			"metadata": map[string]interface{}{
+				"name":      "web",
+				"namespace": namespace,
+			},
+			"spec": map[string]interface{}{
+				"tls": []interface{}{map[string]interface{}{"hosts": []interface{}{"secure.example.com"}}},
+				"rules": []interface{}{
+					map[string]interface{}{
+						"host": "web.example.com",
+						"http": map[string]interface{}{"paths": []interface{}{map[string]interface{}{
+							"path":     "/api",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{"service": map[string]interface{}{
+								"name": "web", "port": map[string]interface{}{"number": int64(80)},
+							}},
+						}}},
+					},
+					map[string]interface{}{"host": "secure.example.com"},
+				},
+			},
+		}}
+		Expect(k8sClient.Create(ctx, ingress)).Should(Succeed())
+
+		endpoints, err := NewAppCollector(k8sClient, Option{Name: "app", Namespace: namespace}).CollectServiceEndpoints()
+		Expect(err).Should(BeNil())
+		var urls []string
+		for _, endpoint := range endpoints {
+			Expect(endpoint.Component).Should(Equal("web"))
+			urls = append(urls, endpoint.Endpoint.String())
+		}
+		Expect(urls).Should(ConsistOf(
+			"tcp://1.1.1.1:30080",
+			"tcp://web.test-service-endpoints:80",
+			"tcp://2.2.2.2:8080",
+			"tcp://web-lb.test-service-endpoints:8080",
+			"http://web.example.com/api",
+			"https://secure.example.com/",
+		))
+
+		By("the node ports are skipped when the nodes cannot be listed, the other endpoints are kept")
+		endpoints, err = NewAppCollector(&nodeForbiddenClient{Client: k8sClient}, Option{Name: "app", Namespace: namespace}).CollectServiceEndpoints()
+		Expect(err).Should(BeNil())
+		urls = nil
+		for _, endpoint := range endpoints {
+			urls = append(urls, endpoint.Endpoint.String())
+		}
+		Expect(urls).Should(ConsistOf(
+			"tcp://web.test-service-endpoints:80",
+			"tcp://2.2.2.2:8080",
+			"tcp://web-lb.test-service-endpoints:8080",
+			"http://web.example.com/api",
+			"https://secure.example.com/",
+		))
+	})
+
+	It("Test get route endpoints", func() {
+		vs := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"hosts": []interface{}{"web.example.com", "*.example.com"},
+				"http": []interface{}{
+					map[string]interface{}{
+						"match": []interface{}{map[string]interface{}{"uri": map[string]interface{}{"prefix": "/v1"}}},
+					},
+					map[string]interface{}{
+						"match": []interface{}{map[string]interface{}{"uri": map[string]interface{}{"prefix": "/v2"}, "port": int64(8080)}},
+					},
+				},
+			},
+		}}
+		var urls []string
+		for _, endpoint := range getVirtualServiceEndpoints(vs) {
+			urls = append(urls, endpoint.String())
+		}
+		Expect(urls).Should(Equal([]string{"http://web.example.com/v1", "http://web.example.com:8080/v2"}))
+
+		route := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"hostnames": []interface{}{"web.example.com"},
+			},
+		}}
+		endpoints := getHTTPRouteEndpoints(route)
+		Expect(len(endpoints)).Should(Equal(1))
+		Expect(endpoints[0].String()).Should(Equal("http://web.example.com/"))
+
+		route.Object["spec"].(map[string]interface{})["parentRefs"] = []interface{}{map[string]interface{}{"name": "gateway", "port": int64(443)}}
+		endpoints = getHTTPRouteEndpoints(route)
+		Expect(len(endpoints)).Should(Equal(1))
+		Expect(endpoints[0].String()).Should(Equal("https://web.example.com/"))
+	})
+})
@@ -84,6 +84,42 @@ func (h *provider) ListResourcesInApp(ctx wfContext.Context, v *value.Value, act
 	return v.FillObject(appResList, "list")
 }
 
+// GetApplicationTree gets the resource topology tree of the resources created by Application
+func (h *provider) GetApplicationTree(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	val, err := v.LookupValue("app")
+	if err != nil {
+		return err
+	}
+	opt := Option{}
+	if err = val.UnmarshalTo(&opt); err != nil {
+		return err
+	}
+	collector := NewAppCollector(h.cli, opt)
+	tree, err := collector.CollectResourceTree()
+	if err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	return v.FillObject(tree, "list")
+}
+
+// CollectServiceEndpoints collects the endpoints of the services, ingresses and routes created by Application
+func (h *provider) CollectServiceEndpoints(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	val, err := v.LookupValue("app")
+	if err != nil {
+		return err
+	}
+	opt := Option{}
+	if err = val.UnmarshalTo(&opt); err != nil {
+		return err
+	}
+	collector := NewAppCollector(h.cli, opt)
+	endpoints, err := collector.CollectServiceEndpoints()
+	if err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	return v.FillObject(endpoints, "list")
+}
+
 func (h *provider) CollectPods(ctx wfContext.Context, v *value.Value, act types.Action) error {
 	val, err := v.LookupValue("value")
 	if err != nil {
@@ -151,8 +187,10 @@ func Install(p providers.Providers, cli client.Client) {
 	}
 
 	p.Register(ProviderName, map[string]providers.Handler{
-		"listResourcesInApp": prd.ListResourcesInApp,
-		"collectPods":        prd.CollectPods,
-		"searchEvents":       prd.SearchEvents,
+		"listResourcesInApp":      prd.ListResourcesInApp,
+		"getApplicationTree":      prd.GetApplicationTree,
+		"collectPods":             prd.CollectPods,
+		"collectServiceEndpoints": prd.CollectServiceEndpoints,
+		"searchEvents":            prd.SearchEvents,
 	})
 }
@@ -0,0 +1,391 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+)
+
+// HealthStatusCode is the health status code of a resource in the tree
+type HealthStatusCode string
+
+const (
+	// HealthStatusHealthy means the resource is healthy
+	HealthStatusHealthy HealthStatusCode = "Healthy"
+	// HealthStatusUnHealthy means the resource is unhealthy
+	HealthStatusUnHealthy HealthStatusCode = "UnHealthy"
+	// HealthStatusProgressing means the resource is still being reconciled
+	HealthStatusProgressing HealthStatusCode = "Progressing"
+	// HealthStatusUnKnown means the health of the resource cannot be determined
+	HealthStatusUnKnown HealthStatusCode = "UnKnown"
+)
+
+// maxTreeDepth limits the depth of children expanding to avoid endless loops caused by cyclic owner references
+const maxTreeDepth = 5
+
+var terraformGroupVersion = schema.GroupVersion{Group: "terraform.core.oam.dev", Version: "v1beta1"}
+
+// HealthStatus is the health status of a resource in the tree
+type HealthStatus struct {
+	Status  HealthStatusCode `json:"status"`
+	Message string           `json:"message,omitempty"`
+}
+
+// ResourceTreeNode is a node of the resource topology tree of an application
+type ResourceTreeNode struct {
+	Cluster      string       `json:"cluster"`
+	Component    string       `json:"component,omitempty"`
+	APIVersion   string       `json:"apiVersion"`
+	Kind         string       `json:"kind"`
+	Namespace    string       `json:"namespace,omitempty"`
+	Name         string       `json:"name"`
+	UID          types.UID    `json:"uid,omitempty"`
+	HealthStatus HealthStatus `json:"healthStatus"`
+	// Error records the failure of collecting the children of the resource, the other nodes of the tree are kept
+	Error     string              `json:"error,omitempty"`
+	LeafNodes []*ResourceTreeNode `json:"leafNodes,omitempty"`
+}
+
+// childrenCollector collects the resources directly derived from the given object
+type childrenCollector func(ctx context.Context, b *resourceTreeBuilder, obj *unstructured.Unstructured) ([]unstructured.Unstructured, error)
+
+// resourceTreeBuilder builds the resource trees of an application, the resources of a kind in a namespace are listed
+// only once and shared by all the nodes looking for their owned resources of that kind
+type resourceTreeBuilder struct {
+	cli   client.Client
+	lists map[string]*listResult
+}
+
+type listResult struct {
+	items []unstructured.Unstructured
+	err   error
+}
+
+// healthChecker computes the health status of the given object
+type healthChecker func(obj *unstructured.Unstructured) HealthStatus
+
+var (
+	deploymentGVK    = appsv1.SchemeGroupVersion.WithKind(reflect.TypeOf(appsv1.Deployment{}).Name())
+	replicaSetGVK    = appsv1.SchemeGroupVersion.WithKind(reflect.TypeOf(appsv1.ReplicaSet{}).Name())
+	statefulSetGVK   = appsv1.SchemeGroupVersion.WithKind(reflect.TypeOf(appsv1.StatefulSet{}).Name())
+	daemonSetGVK     = appsv1.SchemeGroupVersion.WithKind(reflect.TypeOf(appsv1.DaemonSet{}).Name())
+	jobGVK           = batchv1.SchemeGroupVersion.WithKind(reflect.TypeOf(batchv1.Job{}).Name())
+	cronJobGVK       = batchv1.SchemeGroupVersion.WithKind(reflect.TypeOf(batchv1.CronJob{}).Name())
+	cronJobBetaGVK   = batchv1beta1.SchemeGroupVersion.WithKind(reflect.TypeOf(batchv1beta1.CronJob{}).Name())
+	cloneSetGVK      = kruise.SchemeGroupVersion.WithKind(reflect.TypeOf(kruise.CloneSet{}).Name())
+	podGVK           = corev1.SchemeGroupVersion.WithKind(reflect.TypeOf(corev1.Pod{}).Name())
+	serviceGVK       = corev1.SchemeGroupVersion.WithKind(reflect.TypeOf(corev1.Service{}).Name())
+	endpointsGVK     = corev1.SchemeGroupVersion.WithKind(reflect.TypeOf(corev1.Endpoints{}).Name())
+	secretGVK        = corev1.SchemeGroupVersion.WithKind(reflect.TypeOf(corev1.Secret{}).Name())
+	helmReleaseGVK   = fluxcdGroupVersion.WithKind(HelmReleaseKind)
+	configurationGVK = terraformGroupVersion.WithKind("Configuration")
+)
+
+var childrenCollectorMap = map[schema.GroupVersionKind]childrenCollector{
+	deploymentGVK:    ownedResourcesCollector(replicaSetGVK),
+	replicaSetGVK:    ownedResourcesCollector(podGVK),
+	statefulSetGVK:   ownedResourcesCollector(podGVK),
+	daemonSetGVK:     ownedResourcesCollector(podGVK),
+	jobGVK:           ownedResourcesCollector(podGVK),
+	cronJobGVK:       ownedResourcesCollector(jobGVK),
+	cronJobBetaGVK:   ownedResourcesCollector(jobGVK),
+	cloneSetGVK:      ownedResourcesCollector(podGVK),
+	serviceGVK:       serviceEndpointsCollector,
+	helmReleaseGVK:   helmReleaseChildrenCollector,
+	configurationGVK: configurationSecretCollector,
+}
+
+var healthCheckerMap = map[schema.GroupVersionKind]healthChecker{
+	deploymentGVK:    checkDeploymentStatus,
+	replicaSetGVK:    checkReplicaSetStatus,
+	statefulSetGVK:   checkStatefulSetStatus,
+	daemonSetGVK:     checkDaemonSetStatus,
+	jobGVK:           checkJobStatus,
+	cloneSetGVK:      checkCloneSetStatus,
+	podGVK:           checkPodStatus,
+	serviceGVK:       checkServiceStatus,
+	helmReleaseGVK:   checkReadyConditionStatus,
+	configurationGVK: checkConfigurationStatus,
+}
+
+// CollectResourceTree collect the resource topology tree of the application, the roots of the tree are the
+// resources managed by the application and the leaves are the resources derived from them
+func (c *AppCollector) CollectResourceTree() ([]*ResourceTreeNode, error) {
+	resources, err := c.CollectResourceFromApp()
+	if err != nil {
+		return nil, err
+	}
+	b := &resourceTreeBuilder{cli: c.k8sClient, lists: map[string]*listResult{}}
+	nodes := make([]*ResourceTreeNode, 0, len(resources))
+	for _, res := range resources {
+		ctx := multicluster.ContextWithClusterName(context.Background(), res.Cluster)
+		node := b.buildNode(ctx, res.Cluster, res.Object, 0)
+		node.Component = res.Component
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func (b *resourceTreeBuilder) buildNode(ctx context.Context, cluster string, obj *unstructured.Unstructured, depth int) *ResourceTreeNode {
+	node := &ResourceTreeNode{
+		Cluster:      cluster,
+		APIVersion:   obj.GetAPIVersion(),
+		Kind:         obj.GetKind(),
+		Namespace:    obj.GetNamespace(),
+		Name:         obj.GetName(),
+		UID:          obj.GetUID(),
+		HealthStatus: checkResourceStatus(obj),
+	}
+	if depth >= maxTreeDepth {
+		return node
+	}
+	collector, ok := childrenCollectorMap[obj.GroupVersionKind()]
+	if !ok {
+		return node
+	}
+	children, err := collector(ctx, b, obj)
+	if err != nil {
+		node.Error = err.Error()
+		return node
+	}
+	for i := range children {
+		node.LeafNodes = append(node.LeafNodes, b.buildNode(ctx, cluster, &children[i], depth+1))
+	}
+	return node
+}
+
+// list lists the resources of the kind in the namespace of the cluster in context, the result is cached for the
+// other nodes of the trees
+func (b *resourceTreeBuilder) list(ctx context.Context, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+	key := fmt.Sprintf("%s/%s/%s", multicluster.ClusterNameInContext(ctx), gvk.String(), namespace)
+	if result, ok := b.lists[key]; ok {
+		return result.items, result.err
+	}
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(gvk)
+	result := &listResult{}
+	if result.err = b.cli.List(ctx, list, client.InNamespace(namespace)); result.err == nil {
+		result.items = list.Items
+	}
+	b.lists[key] = result
+	return result.items, result.err
+}
+
+// ownedResourcesCollector returns a collector that lists resources of the given kind whose controller is the object
+func ownedResourcesCollector(childGVK schema.GroupVersionKind) childrenCollector {
+	return func(ctx context.Context, b *resourceTreeBuilder, obj *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+		items, err := b.list(ctx, childGVK, obj.GetNamespace())
+		if err != nil {
+			return nil, err
+		}
+		var children []unstructured.Unstructured
+		for _, item := range items {
+			for _, owner := range item.GetOwnerReferences() {
+				if owner.UID == obj.GetUID() {
+					item = *item.DeepCopy()
+					item.SetGroupVersionKind(childGVK)
+					children = append(children, item)
+					break
+				}
+			}
+		}
+		return children, nil
+	}
+}
+
+// serviceEndpointsCollector collects the endpoints of the service
+func serviceEndpointsCollector(ctx context.Context, b *resourceTreeBuilder, obj *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	return getResourceByName(ctx, b.cli, endpointsGVK, obj.GetNamespace(), obj.GetName())
+}
+
+// helmReleaseChildrenCollector collects the workloads rendered by the HelmRelease
+func helmReleaseChildrenCollector(ctx context.Context, b *resourceTreeBuilder, obj *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	return NewHelmReleaseCollector(b.cli, obj).CollectWorkloads(multicluster.ClusterNameInContext(ctx))
+}
+
+// configurationSecretCollector collects the connection secret written by the terraform Configuration
+func configurationSecretCollector(ctx context.Context, b *resourceTreeBuilder, obj *unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	ref, found, err := unstructured.NestedStringMap(obj.Object, "spec", "writeConnectionSecretToRef")
+	if err != nil || !found || ref["name"] == "" {
+		return nil, err
+	}
+	namespace := ref["namespace"]
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	return getResourceByName(ctx, b.cli, secretGVK, namespace, ref["name"])
+}
+
+func getResourceByName(ctx context.Context, cli client.Client, gvk schema.GroupVersionKind, namespace, name string) ([]unstructured.Unstructured, error) {
+	res := unstructured.Unstructured{}
+	res.SetGroupVersionKind(gvk)
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &res); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	res.SetGroupVersionKind(gvk)
+	return []unstructured.Unstructured{res}, nil
+}
+
+func checkResourceStatus(obj *unstructured.Unstructured) HealthStatus {
+	if obj.GetDeletionTimestamp() != nil {
+		return HealthStatus{Status: HealthStatusProgressing, Message: "resource is being deleted"}
+	}
+	if checker, ok := healthCheckerMap[obj.GroupVersionKind()]; ok {
+		return checker(obj)
+	}
+	if _, found, _ := unstructured.NestedSlice(obj.Object, "status", "conditions"); found {
+		return checkReadyConditionStatus(obj)
+	}
+	return HealthStatus{Status: HealthStatusHealthy}
+}
+
+func nestedInt64(obj *unstructured.Unstructured, fields ...string) int64 {
+	val, _, _ := unstructured.NestedInt64(obj.Object, fields...)
+	return val
+}
+
+func checkReplicas(obj *unstructured.Unstructured, desired int64, readyFields ...string) HealthStatus {
+	if generation, observed := obj.GetGeneration(), nestedInt64(obj, "status", "observedGeneration"); observed < generation {
+		return HealthStatus{Status: HealthStatusProgressing, Message: "waiting for the spec to be observed"}
+	}
+	ready := nestedInt64(obj, append([]string{"status"}, readyFields...)...)
+	if ready < desired {
+		return HealthStatus{Status: HealthStatusProgressing, Message: fmt.Sprintf("ready replicas %d/%d", ready, desired)}
+	}
+	return HealthStatus{Status: HealthStatusHealthy, Message: fmt.Sprintf("ready replicas %d/%d", ready, desired)}
+}
+
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+func checkDeploymentStatus(obj *unstructured.Unstructured) HealthStatus {
+	return checkReplicas(obj, specReplicas(obj), "availableReplicas")
+}
+
+func checkReplicaSetStatus(obj *unstructured.Unstructured) HealthStatus {
+	return checkReplicas(obj, specReplicas(obj), "readyReplicas")
+}
+
+func checkStatefulSetStatus(obj *unstructured.Unstructured) HealthStatus {
+	return checkReplicas(obj, specReplicas(obj), "readyReplicas")
+}
+
+func checkCloneSetStatus(obj *unstructured.Unstructured) HealthStatus {
+	return checkReplicas(obj, specReplicas(obj), "readyReplicas")
+}
+
+func checkDaemonSetStatus(obj *unstructured.Unstructured) HealthStatus {
+	return checkReplicas(obj, nestedInt64(obj, "status", "desiredNumberScheduled"), "numberReady")
+}
+
+func checkJobStatus(obj *unstructured.Unstructured) HealthStatus {
+	if failed := nestedInt64(obj, "status", "failed"); failed > 0 {
+		return HealthStatus{Status: HealthStatusUnHealthy, Message: fmt.Sprintf("%d pods failed", failed)}
+	}
+	if _, found, _ := unstructured.NestedString(obj.Object, "status", "completionTime"); found {
+		return HealthStatus{Status: HealthStatusHealthy, Message: "job completed"}
+	}
+	return HealthStatus{Status: HealthStatusProgressing, Message: "job is running"}
+}
+
+func checkPodStatus(obj *unstructured.Unstructured) HealthStatus {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch corev1.PodPhase(phase) {
+	case corev1.PodSucceeded:
+		return HealthStatus{Status: HealthStatusHealthy, Message: phase}
+	case corev1.PodFailed:
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "reason")
+		return HealthStatus{Status: HealthStatusUnHealthy, Message: reason}
+	case corev1.PodRunning:
+		status := checkReadyConditionStatus(obj)
+		if status.Status != HealthStatusHealthy {
+			status.Status = HealthStatusProgressing
+		}
+		return status
+	default:
+		return HealthStatus{Status: HealthStatusProgressing, Message: phase}
+	}
+}
+
+func checkServiceStatus(obj *unstructured.Unstructured) HealthStatus {
+	svcType, _, _ := unstructured.NestedString(obj.Object, "spec", "type")
+	if corev1.ServiceType(svcType) != corev1.ServiceTypeLoadBalancer {
+		return HealthStatus{Status: HealthStatusHealthy}
+	}
+	ingress, _, _ := unstructured.NestedSlice(obj.Object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return HealthStatus{Status: HealthStatusProgressing, Message: "waiting for load balancer to be assigned"}
+	}
+	return HealthStatus{Status: HealthStatusHealthy}
+}
+
+func checkConfigurationStatus(obj *unstructured.Unstructured) HealthStatus {
+	state, _, _ := unstructured.NestedString(obj.Object, "status", "apply", "state")
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "apply", "message")
+	switch state {
+	case "Available":
+		return HealthStatus{Status: HealthStatusHealthy, Message: message}
+	case "ApplyFailed", "ConfigurationSpecNotValid":
+		return HealthStatus{Status: HealthStatusUnHealthy, Message: message}
+	default:
+		return HealthStatus{Status: HealthStatusProgressing, Message: message}
+	}
+}
+
+// checkReadyConditionStatus computes the health status from the Ready condition of the object
+func checkReadyConditionStatus(obj *unstructured.Unstructured) HealthStatus {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		cond, ok := c.(map[string]interface{})
+		if !ok || cond["type"] != "Ready" {
+			continue
+		}
+		message, _ := cond["message"].(string)
+		switch cond["status"] {
+		case string(corev1.ConditionTrue):
+			return HealthStatus{Status: HealthStatusHealthy, Message: message}
+		case string(corev1.ConditionFalse):
+			return HealthStatus{Status: HealthStatusUnHealthy, Message: message}
+		default:
+			return HealthStatus{Status: HealthStatusProgressing, Message: message}
+		}
+	}
+	return HealthStatus{Status: HealthStatusUnKnown}
+}
@@ -0,0 +1,245 @@
+/*
+ Copyright 2021. The KubeVela Authors.
+
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package query
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/resourcetracker"
+)
+
+// listCountingClient counts the lists and fails the ones of the pods
+type listCountingClient struct {
+	client.Client
+	lists int
+}
+
+func (c *listCountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.lists++
+	if u, ok := list.(*unstructured.UnstructuredList); ok && u.GetKind() == "PodList" {
+		return fmt.Errorf("cannot list pods")
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+// createManagedResources creates the application and records the resources as its managed resources
+func createManagedResources(namespace, appName string, refs ...corev1.ObjectReference) {
+	Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).Should(Succeed())
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: appName, Namespace: namespace},
+		Spec:       v1beta1.ApplicationSpec{Components: []common.ApplicationComponent{}},
+	}
+	Expect(k8sClient.Create(ctx, app)).Should(Succeed())
+	rt, err := resourcetracker.CreateRootResourceTracker(ctx, k8sClient, app)
+	Expect(err).Should(BeNil())
+	for _, ref := range refs {
+		ref.Namespace = namespace
+		rt.Spec.ManagedResources = append(rt.Spec.ManagedResources, v1beta1.ManagedResource{
+			ClusterObjectReference: common.ClusterObjectReference{ObjectReference: ref},
+			OAMObjectReference:     common.OAMObjectReference{Component: "web"},
+		})
+	}
+	Expect(k8sClient.Update(ctx, rt)).Should(Succeed())
+}
+
+var _ = Describe("Test Resource Tree", func() {
+	namespace := "test-resource-tree"
+	labels := map[string]string{oam.LabelAppComponent: "web", "app": "web"}
+	podTemplate := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: labels},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: "busybox"}}},
+	}
+
+	It("Test collect resource tree", func() {
+		createManagedResources(namespace, "app",
+			corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"},
+			corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Name: "web"})
+
+		deploy := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace, Labels: labels},
+			Spec: appsv1.DeploymentSpec{
+				Replicas: pointer.Int32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: podTemplate,
+			},
+		}
+		Expect(k8sClient.Create(ctx, deploy)).Should(Succeed())
+		deploy.Status = appsv1.DeploymentStatus{AvailableReplicas: 1, ObservedGeneration: deploy.Generation}
+		Expect(k8sClient.Status().Update(ctx, deploy)).Should(Succeed())
+
+		rs := &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: namespace, Labels: labels,
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: deploy.UID}}},
+			Spec: appsv1.ReplicaSetSpec{
+				Replicas: pointer.Int32(1),
+				Selector: &metav1.LabelSelector{MatchLabels: labels},
+				Template: podTemplate,
+			},
+		}
+		Expect(k8sClient.Create(ctx, rs)).Should(Succeed())
+		rs.Status = appsv1.ReplicaSetStatus{Replicas: 1, ReadyReplicas: 1, ObservedGeneration: rs.Generation}
+		Expect(k8sClient.Status().Update(ctx, rs)).Should(Succeed())
+
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-1-abc", Namespace: namespace, Labels: labels,
+				OwnerReferences: []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web-1", UID: rs.UID}}},
+			Spec: podTemplate.Spec,
+		}
+		Expect(k8sClient.Create(ctx, pod)).Should(Succeed())
+
+		svc := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace, Labels: labels},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeClusterIP, Ports: []corev1.ServicePort{{Port: 80}}},
+		}
+		Expect(k8sClient.Create(ctx, svc)).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace}})).Should(Succeed())
+
+		nodes, err := NewAppCollector(k8sClient, Option{Name: "app", Namespace: namespace}).CollectResourceTree()
+		Expect(err).Should(BeNil())
+		Expect(len(nodes)).Should(Equal(2))
+		for _, node := range nodes {
+			Expect(node.Component).Should(Equal("web"))
+			Expect(node.HealthStatus.Status).Should(Equal(HealthStatusHealthy))
+			Expect(len(node.LeafNodes)).Should(Equal(1))
+			switch node.Kind {
+			case "Deployment":
+				rsNode := node.LeafNodes[0]
+				Expect(rsNode.Kind).Should(Equal("ReplicaSet"))
+				Expect(rsNode.UID).Should(Equal(rs.UID))
+				Expect(len(rsNode.LeafNodes)).Should(Equal(1))
+				Expect(rsNode.LeafNodes[0].Kind).Should(Equal("Pod"))
+				Expect(rsNode.LeafNodes[0].HealthStatus.Status).Should(Equal(HealthStatusProgressing))
+			case "Service":
+				Expect(node.LeafNodes[0].Kind).Should(Equal("Endpoints"))
+			default:
+				Fail("unexpected root node kind " + node.Kind)
+			}
+		}
+
+		By("the pods are listed once, and the failure is recorded in the nodes looking for pods")
+		cli := &listCountingClient{Client: k8sClient}
+		b := &resourceTreeBuilder{cli: cli, lists: map[string]*listResult{}}
+		var failed []*ResourceTreeNode
+		for _, name := range []string{"web-1", "web-2"} {
+			obj := &unstructured.Unstructured{}
+			obj.SetGroupVersionKind(replicaSetGVK)
+			obj.SetNamespace(namespace)
+			obj.SetName(name)
+			node := b.buildNode(ctx, "local", obj, 0)
+			Expect(node.Error).Should(Equal("cannot list pods"))
+			Expect(node.LeafNodes).Should(BeEmpty())
+			failed = append(failed, node)
+		}
+		Expect(cli.lists).Should(Equal(1))
+
+		By("the tree with the failed nodes is accepted by the stdlib definition")
+		v, err := value.NewValue(`
+import "vela/ql"
+
+ql.#GetApplicationTree & {
+	app: {
+		name:      "app"
+		namespace: "test-resource-tree"
+	}
+}`, nil, "")
+		Expect(err).Should(BeNil())
+		Expect(v.FillObject(append(nodes, failed...), "list")).Should(BeNil())
+		Expect(v.Error()).Should(BeNil())
+		list, err := v.LookupValue("list")
+		Expect(err).Should(BeNil())
+		var filled []*ResourceTreeNode
+		Expect(list.UnmarshalTo(&filled)).Should(BeNil())
+		Expect(len(filled)).Should(Equal(4))
+		Expect(filled[2].Error).Should(Equal("cannot list pods"))
+	})
+
+	It("Test check resource status", func() {
+		testCases := map[string]struct {
+			obj    map[string]interface{}
+			status HealthStatusCode
+		}{
+			"progressing deployment": {
+				obj: map[string]interface{}{
+					"apiVersion": "apps/v1",
+					"kind":       "Deployment",
+					"spec":       map[string]interface{}{"replicas": int64(2)},
+					"status":     map[string]interface{}{"availableReplicas": int64(1)},
+				},
+				status: HealthStatusProgressing,
+			},
+			"failed job": {
+				obj: map[string]interface{}{
+					"apiVersion": "batch/v1",
+					"kind":       "Job",
+					"status":     map[string]interface{}{"failed": int64(1)},
+				},
+				status: HealthStatusUnHealthy,
+			},
+			"pending load balancer": {
+				obj: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "Service",
+					"spec":       map[string]interface{}{"type": "LoadBalancer"},
+				},
+				status: HealthStatusProgressing,
+			},
+			"available configuration": {
+				obj: map[string]interface{}{
+					"apiVersion": "terraform.core.oam.dev/v1beta1",
+					"kind":       "Configuration",
+					"status":     map[string]interface{}{"apply": map[string]interface{}{"state": "Available"}},
+				},
+				status: HealthStatusHealthy,
+			},
+			"not ready helm release": {
+				obj: map[string]interface{}{
+					"apiVersion": "helm.toolkit.fluxcd.io/v2beta1",
+					"kind":       "HelmRelease",
+					"status": map[string]interface{}{"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "message": "install failed"},
+					}},
+				},
+				status: HealthStatusUnHealthy,
+			},
+			"unknown resource": {
+				obj: map[string]interface{}{
+					"apiVersion": "v1",
+					"kind":       "ConfigMap",
+				},
+				status: HealthStatusHealthy,
+			},
+		}
+		for name, tc := range testCases {
+			status := checkResourceStatus(&unstructured.Unstructured{Object: tc.obj})
+			Expect(status.Status).Should(Equal(tc.status), name)
+		}
+	})
+})
@@ -85,9 +85,10 @@ func ValidateCreate(client client.Client, rollout *v1alpha1.RolloutPlan, rootPat
 	}
 
 	if rollout.RolloutStrategy != v1alpha1.IncreaseFirstRolloutStrategyType &&
-		rollout.RolloutStrategy != v1alpha1.DecreaseFirstRolloutStrategyType {
+		rollout.RolloutStrategy != v1alpha1.DecreaseFirstRolloutStrategyType &&
+		rollout.RolloutStrategy != v1alpha1.BlueGreenRolloutStrategyType {
 		allErrs = append(allErrs, field.Invalid(rootPath.Child("rolloutStrategy"),
-			rollout.RolloutStrategy, "the rolloutStrategy can only be IncreaseFirst or DecreaseFirst"))
+			rollout.RolloutStrategy, "the rolloutStrategy can only be IncreaseFirst, DecreaseFirst or BlueGreen"))
 	}
 
 	// validate the webhooks
@@ -96,6 +97,12 @@ func ValidateCreate(client client.Client, rollout *v1alpha1.RolloutPlan, rootPat
 	// validate the rollout batches
 	allErrs = append(allErrs, validateRolloutBatches(rollout, rootPath)...)
 
+	// validate the traffic routing
+	allErrs = append(allErrs, validateTrafficRouting(rollout, rootPath)...)
+
+	// validate the blue-green strategy
+	allErrs = append(allErrs, validateBlueGreen(rollout, rootPath)...)
+
 	// TODO: The total number of num in the batches match the current target resource pod size
 	return allErrs
 }
@@ -153,6 +160,75 @@ func validateRolloutBatches(rollout *v1alpha1.RolloutPlan, rootPath *field.Path)
 	return allErrs
 }
 
+func validateTrafficRouting(rollout *v1alpha1.RolloutPlan, rootPath *field.Path) (allErrs field.ErrorList) {
+	if rollout.TrafficRouting != nil {
+		routingPath := rootPath.Child("trafficRouting")
+		routing := rollout.TrafficRouting
+		if routing.StableService == "" {
+			allErrs = append(allErrs, field.Required(routingPath.Child("stableService"), "the stable service is required"))
+		}
+		if routing.CanaryService == "" {
+			allErrs = append(allErrs, field.Required(routingPath.Child("canaryService"), "the canary service is required"))
+		}
+		providers := 0
+		if routing.Istio != nil {
+			providers++
+		}
+		if routing.SMI != nil {
+			providers++
+		}
+		if routing.Nginx != nil {
+			providers++
+		}
+		if providers != 1 {
+			allErrs = append(allErrs, field.Invalid(routingPath, providers,
+				"exactly one of istio, smi and nginx has to be specified"))
+		}
+	}
+
+	// the traffic weight of the batches is a percentage
+	batchesPath := rootPath.Child("rolloutBatches")
+	for i, rb := range rollout.RolloutBatches {
+		if rb.TrafficWeight != nil && (*rb.TrafficWeight < 0 || *rb.TrafficWeight > 100) {
+			allErrs = append(allErrs, field.Invalid(batchesPath.Index(i).Child("trafficWeight"),
+				*rb.TrafficWeight, "the traffic weight has to be between 0 and 100"))
+		}
+	}
+	return allErrs
+}
+
+func validateBlueGreen(rollout *v1alpha1.RolloutPlan, rootPath *field.Path) (allErrs field.ErrorList) {
+	blueGreenPath := rootPath.Child("blueGreen")
+	if rollout.RolloutStrategy != v1alpha1.BlueGreenRolloutStrategyType {
+		if rollout.BlueGreen != nil {
+			allErrs = append(allErrs, field.Invalid(blueGreenPath, rollout.RolloutStrategy,
+				"the blueGreen can only be set with the BlueGreen rolloutStrategy"))
+		}
+		return allErrs
+	}
+
+	// the traffic has to be switched by either the active service or the traffic routing
+	if rollout.TrafficRouting == nil && (rollout.BlueGreen == nil || rollout.BlueGreen.ActiveService == "") {
+		allErrs = append(allErrs, field.Required(blueGreenPath.Child("activeService"),
+			"the blue-green rollout needs either the active service or the traffic routing"))
+	}
+	if rollout.BlueGreen != nil && rollout.BlueGreen.ScaleDownDelaySeconds != nil &&
+		*rollout.BlueGreen.ScaleDownDelaySeconds < 0 {
+		allErrs = append(allErrs, field.Invalid(blueGreenPath.Child("scaleDownDelaySeconds"),
+			*rollout.BlueGreen.ScaleDownDelaySeconds, "the scale down delay can not be negative"))
+	}
+
+	// the blue-green rollout switches all the traffic at once
+	batchesPath := rootPath.Child("rolloutBatches")
+	for i, rb := range rollout.RolloutBatches {
+		if rb.TrafficWeight != nil {
+			allErrs = append(allErrs, field.Forbidden(batchesPath.Index(i).Child("trafficWeight"),
+				"the blue-green rollout does not shift the traffic in batches"))
+		}
+	}
+	return allErrs
+}
+
 // ValidateUpdate validate if one can change the rollout plan from the previous psec
 func ValidateUpdate(client client.Client, new *v1alpha1.RolloutPlan, prev *v1alpha1.RolloutPlan,
 	rootPath *field.Path) field.ErrorList {
@@ -155,3 +155,114 @@ func TestValidateIllegalReplicas(t *testing.T) {
 		t.Error("should invalidate negative replica value")
 	}
 }
+
+func TestValidateTrafficRouting(t *testing.T) {
+	validRouting := &v1alpha1.RolloutPlan{
+		TrafficRouting: &v1alpha1.TrafficRouting{
+			StableService: "stable",
+			CanaryService: "canary",
+			Istio:         &v1alpha1.IstioTrafficRouting{VirtualService: "vs"},
+		},
+		RolloutBatches: []v1alpha1.RolloutBatch{
+			{
+				Replicas:      intstr.FromInt(1),
+				TrafficWeight: pointer.Int32Ptr(20),
+			},
+		},
+	}
+	if errList := validateTrafficRouting(validRouting, field.NewPath("spec")); len(errList) != 0 {
+		t.Errorf("should validate the traffic routing, got %v", errList)
+	}
+
+	noProvider := &v1alpha1.RolloutPlan{
+		TrafficRouting: &v1alpha1.TrafficRouting{
+			StableService: "stable",
+			CanaryService: "canary",
+		},
+	}
+	if errList := validateTrafficRouting(noProvider, field.NewPath("spec")); len(errList) != 1 {
+		t.Error("should invalidate traffic routing without provider")
+	}
+
+	multipleProviders := &v1alpha1.RolloutPlan{
+		TrafficRouting: &v1alpha1.TrafficRouting{
+			Istio: &v1alpha1.IstioTrafficRouting{VirtualService: "vs"},
+			Nginx: &v1alpha1.NginxTrafficRouting{Ingress: "ingress"},
+		},
+	}
+	if errList := validateTrafficRouting(multipleProviders, field.NewPath("spec")); len(errList) != 3 {
+		t.Error("should invalidate traffic routing with multiple providers and without services")
+	}
+
+	illegalWeight := &v1alpha1.RolloutPlan{
+		RolloutBatches: []v1alpha1.RolloutBatch{
+			{
+				Replicas:      intstr.FromInt(1),
+				TrafficWeight: pointer.Int32Ptr(120),
+			},
+		},
+	}
+	if errList := validateTrafficRouting(illegalWeight, field.NewPath("spec")); len(errList) != 1 {
+		t.Error("should invalidate illegal traffic weight")
+	}
+}
+
+func TestValidateBlueGreen(t *testing.T) {
+	validBlueGreen := &v1alpha1.RolloutPlan{
+		RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+		BlueGreen: &v1alpha1.BlueGreenStrategy{
+			ActiveService:         "active",
+			PreviewService:        "preview",
+			ScaleDownDelaySeconds: pointer.Int32Ptr(60),
+		},
+		RolloutBatches: []v1alpha1.RolloutBatch{{Replicas: intstr.FromString("100%")}},
+	}
+	if errList := ValidateCreate(nil, validBlueGreen, field.NewPath("spec")); len(errList) != 0 {
+		t.Errorf("should validate the blue-green rollout, got %v", errList)
+	}
+
+	noActiveService := &v1alpha1.RolloutPlan{
+		RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+		BlueGreen:       &v1alpha1.BlueGreenStrategy{PreviewService: "preview"},
+	}
+	if errList := validateBlueGreen(noActiveService, field.NewPath("spec")); len(errList) != 1 {
+		t.Error("should invalidate the blue-green rollout without the active service")
+	}
+
+	withTrafficRouting := &v1alpha1.RolloutPlan{
+		RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+		TrafficRouting: &v1alpha1.TrafficRouting{
+			StableService: "stable",
+			CanaryService: "canary",
+			Istio:         &v1alpha1.IstioTrafficRouting{VirtualService: "vs"},
+		},
+	}
+	if errList := validateBlueGreen(withTrafficRouting, field.NewPath("spec")); len(errList) != 0 {
+		t.Errorf("should validate the blue-green rollout with the traffic routing, got %v", errList)
+	}
+
+	illegalBlueGreen := &v1alpha1.RolloutPlan{
+		RolloutStrategy: v1alpha1.BlueGreenRolloutStrategyType,
+		BlueGreen: &v1alpha1.BlueGreenStrategy{
+			ActiveService:         "active",
+			ScaleDownDelaySeconds: pointer.Int32Ptr(-1),
+		},
+		RolloutBatches: []v1alpha1.RolloutBatch{
+			{
+				Replicas:      intstr.FromInt(1),
+				TrafficWeight: pointer.Int32Ptr(20),
+			},
+		},
+	}
+	if errList := validateBlueGreen(illegalBlueGreen, field.NewPath("spec")); len(errList) != 2 {
+		t.Error("should invalidate negative scale down delay and batch traffic weight")
+	}
+
+	notBlueGreen := &v1alpha1.RolloutPlan{
+		RolloutStrategy: v1alpha1.IncreaseFirstRolloutStrategyType,
+		BlueGreen:       &v1alpha1.BlueGreenStrategy{ActiveService: "active"},
+	}
+	if errList := validateBlueGreen(notBlueGreen, field.NewPath("spec")); len(errList) != 1 {
+		t.Error("should invalidate the blueGreen with other strategies")
+	}
+}
@@ -85,6 +85,24 @@ var _ = Describe("Test Application Validator", func() {
 		Expect(resp.Allowed).Should(BeFalse())
 	})
 
+	It("Test Application Validator unknown definition revision [Error]", func() {
+		req := admission.Request{
+			AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: admissionv1.Create,
+				Resource:  metav1.GroupVersionResource{Group: "core.oam.dev", Version: "v1alpha2", Resource: "applications"},
+				Object: runtime.RawExtension{
+					Raw: []byte(`{"apiVersion":"core.oam.dev/v1beta1",
+"kind":"Application",
+"metadata":{"name":"application-sample"},
+"spec":{"components":[{"name":"myweb","properties":{"cmd":["sleep","1000"],"image":"busybox"},"type":"worker@v100"}]}}`),
+				},
+			},
+		}
+		resp := handler.Handle(ctx, req)
+		Expect(resp.Allowed).Should(BeFalse())
+		Expect(resp.Result.Message).Should(ContainSubstring("definition revision worker-v100 of worker@v100 not found"))
+	})
+
 	It("Test Application Validator Forbid rollout annotation", func() {
 		req := admission.Request{
 			AdmissionRequest: admissionv1.AdmissionRequest{
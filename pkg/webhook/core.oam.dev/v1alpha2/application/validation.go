@@ -19,12 +19,14 @@ package application
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/appfile"
 	"github.com/oam-dev/kubevela/pkg/oam"
@@ -35,6 +37,10 @@ import (
 // ValidateCreate validates the Application on creation
 func (h *ValidatingHandler) ValidateCreate(ctx context.Context, app *v1beta1.Application) field.ErrorList {
 	var componentErrs field.ErrorList
+	if revisionErrs := h.validateDefinitionRevisions(ctx, app); len(revisionErrs) != 0 {
+		// the pinned definition revisions are missing, no need to validate further
+		return revisionErrs
+	}
 	// try to generate an app file
 	appParser := appfile.NewApplicationParser(h.Client, h.dm, h.pd)
 
@@ -116,3 +122,49 @@ func (h *ValidatingHandler) validateExternalRevisionName(ctx context.Context, ap
 	}
 	return componentErrs
 }
+
+// validateDefinitionRevisions checks the definition revisions pinned by type `name@version` exist
+func (h *ValidatingHandler) validateDefinitionRevisions(ctx context.Context, app *v1beta1.Application) field.ErrorList {
+	var revisionErrs field.ErrorList
+	validate := func(path *field.Path, typ string, defType common.DefinitionType) {
+		if !strings.Contains(typ, "@") {
+			return
+		}
+		if err := h.validateDefinitionRevision(ctx, typ, defType); err != nil {
+			revisionErrs = append(revisionErrs, field.Invalid(path, typ, err.Error()))
+		}
+	}
+	for i, comp := range app.Spec.Components {
+		validate(field.NewPath("spec", "components").Index(i).Child("type"), comp.Type, common.ComponentType)
+		for j, trait := range comp.Traits {
+			validate(field.NewPath("spec", "components").Index(i).Child("traits").Index(j).Child("type"), trait.Type, common.TraitType)
+		}
+	}
+	for i, policy := range app.Spec.Policies {
+		validate(field.NewPath("spec", "policies").Index(i).Child("type"), policy.Type, common.PolicyType)
+	}
+	if app.Spec.Workflow != nil {
+		for i, step := range app.Spec.Workflow.Steps {
+			validate(field.NewPath("spec", "workflow", "steps").Index(i).Child("type"), step.Type, common.WorkflowStepType)
+		}
+	}
+	return revisionErrs
+}
+
+func (h *ValidatingHandler) validateDefinitionRevision(ctx context.Context, typ string, defType common.DefinitionType) error {
+	defRevName, err := util.ConvertDefinitionRevName(typ)
+	if err != nil {
+		return err
+	}
+	defRev := &v1beta1.DefinitionRevision{}
+	if err := util.GetDefinition(ctx, h.Client, defRev, defRevName); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("definition revision %s of %s not found", defRevName, typ)
+		}
+		return err
+	}
+	if defRev.Spec.DefinitionType != defType {
+		return fmt.Errorf("definition revision %s is a %s definition rather than %s", defRevName, defRev.Spec.DefinitionType, defType)
+	}
+	return nil
+}
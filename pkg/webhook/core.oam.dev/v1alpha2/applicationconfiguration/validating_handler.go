@@ -38,6 +38,7 @@ import (
 	controller "github.com/oam-dev/kubevela/pkg/controller/core.oam.dev"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	"github.com/oam-dev/kubevela/pkg/oam/util"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 const (
@@ -324,7 +325,7 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 // RegisterValidatingHandler will register application configuration validation to webhook
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1alpha2-applicationconfigurations", &webhook.Admission{Handler: &ValidatingHandler{
+	server.Register("/validating-core-oam-dev-v1alpha2-applicationconfigurations", &webhook.Admission{Handler: webhookutils.WithMetrics("validating-applicationconfigurations", &ValidatingHandler{
 		Mapper: args.DiscoveryMapper,
 		Validators: []AppConfigValidator{
 			AppConfigValidateFunc(ValidateRevisionNameFn),
@@ -333,5 +334,5 @@ func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 			AppConfigValidateFunc(ValidateTraitConflictFn),
 			// TODO(wonderflow): Add more validation logic here.
 		},
-	}})
+	})})
 }
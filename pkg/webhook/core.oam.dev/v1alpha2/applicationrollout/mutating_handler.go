@@ -32,6 +32,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/controller/common"
 	util "github.com/oam-dev/kubevela/pkg/utils"
 	"github.com/oam-dev/kubevela/pkg/webhook/common/rollout"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 // MutatingHandler handles AppRollout
@@ -97,5 +98,5 @@ func (h *MutatingHandler) InjectDecoder(d *admission.Decoder) error {
 func RegisterMutatingHandler(mgr manager.Manager) {
 	server := mgr.GetWebhookServer()
 	server.Register("/mutating-core-oam-dev-v1beta1-approllout",
-		&webhook.Admission{Handler: &MutatingHandler{}})
+		&webhook.Admission{Handler: webhookutils.WithMetrics("mutating-approllout", &MutatingHandler{})})
 }
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 // ValidatingHandler handles AppRollout
@@ -99,5 +100,5 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 func RegisterValidatingHandler(mgr manager.Manager) {
 	server := mgr.GetWebhookServer()
 	server.Register("/validating-core-oam-dev-v1beta1-approllout",
-		&webhook.Admission{Handler: &ValidatingHandler{}})
+		&webhook.Admission{Handler: webhookutils.WithMetrics("validating-approllout", &ValidatingHandler{})})
 }
@@ -31,6 +31,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
+	webhookutils "github.com/oam-dev/kubevela/pkg/webhook/utils"
 )
 
 // ValidatingHandler handles Component
@@ -120,5 +121,7 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 // RegisterValidatingHandler will regsiter component mutation handler to the webhook
 func RegisterValidatingHandler(mgr manager.Manager) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1alpha2-components", &webhook.Admission{Handler: &ValidatingHandler{}})
+	server.Register("/validating-core-oam-dev-v1alpha2-components", &webhook.Admission{
+		Handler: webhookutils.WithMetrics("validating-components", &ValidatingHandler{}),
+	})
 }
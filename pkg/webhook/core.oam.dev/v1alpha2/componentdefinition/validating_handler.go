@@ -101,9 +101,9 @@ func (h *ValidatingHandler) InjectDecoder(d *admission.Decoder) error {
 // RegisterValidatingHandler will register ComponentDefinition validation to webhook
 func RegisterValidatingHandler(mgr manager.Manager, args controller.Args) {
 	server := mgr.GetWebhookServer()
-	server.Register("/validating-core-oam-dev-v1beta1-componentdefinitions", &webhook.Admission{Handler: &ValidatingHandler{
+	server.Register("/validating-core-oam-dev-v1beta1-componentdefinitions", &webhook.Admission{Handler: webhookutils.WithMetrics("validating-componentdefinitions", &ValidatingHandler{
 		Mapper: args.DiscoveryMapper,
-	}})
+	})})
 }
 
 // ValidateWorkload validates whether the Workload field is valid
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+// metricsHandler records the admission latency of the handler it wraps
+type metricsHandler struct {
+	name    string
+	handler admission.Handler
+}
+
+// WithMetrics wraps the admission handler so that the latency of each admission request is recorded by the
+// webhook name, the operation and whether it is allowed
+func WithMetrics(name string, handler admission.Handler) admission.Handler {
+	return &metricsHandler{name: name, handler: handler}
+}
+
+// Handle handles the admission request with the wrapped handler
+func (h *metricsHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	begin := time.Now()
+	resp := h.handler.Handle(ctx, req)
+	metrics.WebhookAdmissionTimeHistogram.WithLabelValues(h.name, string(req.Operation), strconv.FormatBool(resp.Allowed)).
+		Observe(time.Since(begin).Seconds())
+	return resp
+}
+
+// InjectFunc injects the client and the scheme into the wrapped handler
+func (h *metricsHandler) InjectFunc(f inject.Func) error {
+	return f(h.handler)
+}
+
+// InjectDecoder injects the decoder into the wrapped handler
+func (h *metricsHandler) InjectDecoder(d *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(d, h.handler)
+	return err
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
+)
+
+type fakeHandler struct {
+	client  client.Client
+	decoder *admission.Decoder
+	allowed bool
+}
+
+func (h *fakeHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	return admission.ValidationResponse(h.allowed, "")
+}
+
+func (h *fakeHandler) InjectClient(c client.Client) error {
+	h.client = c
+	return nil
+}
+
+func (h *fakeHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+func TestWithMetrics(t *testing.T) {
+	handler := &fakeHandler{}
+	wrapped := WithMetrics("validating-test", handler)
+
+	cli := fake.NewClientBuilder().Build()
+	_, err := inject.InjectorInto(func(i interface{}) error {
+		_, err := inject.ClientInto(cli, i)
+		return err
+	}, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, cli, handler.client)
+
+	decoder, err := admission.NewDecoder(runtime.NewScheme())
+	require.NoError(t, err)
+	_, err = admission.InjectDecoderInto(decoder, wrapped)
+	require.NoError(t, err)
+	require.Equal(t, decoder, handler.decoder)
+
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{Operation: admissionv1.Create}}
+	before := testutil.CollectAndCount(metrics.WebhookAdmissionTimeHistogram)
+	require.False(t, wrapped.Handle(context.Background(), req).Allowed)
+	handler.allowed = true
+	require.True(t, wrapped.Handle(context.Background(), req).Allowed)
+	require.Equal(t, before+2, testutil.CollectAndCount(metrics.WebhookAdmissionTimeHistogram))
+}
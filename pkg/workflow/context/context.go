@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -55,6 +56,13 @@ type WorkflowContext struct {
 	components map[string]*ComponentManifest
 	vars       *value.Value
 	modified   bool
+
+	// componentsModified and varsModified mark whether the components and vars need to be encoded again on commit
+	componentsModified bool
+	varsModified       bool
+	// persisted is the copy of the store last read from or written to the cluster, the store is only written if it
+	// differs from the persisted one
+	persisted *corev1.ConfigMap
 }
 
 // GetComponent Get ComponentManifest from workflow context.
@@ -81,6 +89,7 @@ func (wf *WorkflowContext) PatchComponent(name string, patchValue *value.Value)
 		return err
 	}
 	wf.modified = true
+	wf.componentsModified = true
 	return nil
 }
 
@@ -102,6 +111,7 @@ func (wf *WorkflowContext) SetVar(v *value.Value, paths ...string) error {
 		return err
 	}
 	wf.modified = true
+	wf.varsModified = true
 	return nil
 }
 
@@ -161,7 +171,7 @@ func (wf *WorkflowContext) MakeParameter(parameter interface{}) (*value.Value, e
 	return wf.vars.MakeValue(s)
 }
 
-// Commit the workflow context and persist it's content.
+// Commit the workflow context and persist it's content. The store is only written if its content changes.
 func (wf *WorkflowContext) Commit() error {
 	if !wf.modified {
 		return nil
@@ -172,48 +182,73 @@ func (wf *WorkflowContext) Commit() error {
 	if err := wf.sync(); err != nil {
 		return errors.WithMessagef(err, "save context to configMap(%s/%s)", wf.store.Namespace, wf.store.Name)
 	}
+	wf.modified = false
 	return nil
 }
 
+// writeToStore encodes the components and vars into the store, only if they are modified since last commit.
 func (wf *WorkflowContext) writeToStore() error {
-	varStr, err := wf.vars.String()
-	if err != nil {
-		return err
+	if wf.store.Data == nil {
+		wf.store.Data = make(map[string]string)
 	}
-	jsonObject := map[string]string{}
-	for name, comp := range wf.components {
-		s, err := comp.string()
-		if err != nil {
-			return errors.WithMessagef(err, "encode component %s ", name)
+	if wf.componentsModified {
+		jsonObject := map[string]string{}
+		for name, comp := range wf.components {
+			s, err := comp.string()
+			if err != nil {
+				return errors.WithMessagef(err, "encode component %s ", name)
+			}
+			jsonObject[name] = s
+		}
+		if err := wf.saveData(ConfigMapKeyComponents, string(util.MustJSONMarshal(jsonObject))); err != nil {
+			return err
 		}
-		jsonObject[name] = s
+		wf.componentsModified = false
 	}
-
-	if wf.store.Data == nil {
-		wf.store.Data = make(map[string]string)
+	if wf.varsModified {
+		varStr, err := wf.vars.String()
+		if err != nil {
+			return err
+		}
+		if err := wf.saveData(ConfigMapKeyVars, varStr); err != nil {
+			return err
+		}
+		wf.varsModified = false
 	}
-	wf.store.Data[ConfigMapKeyComponents] = string(util.MustJSONMarshal(jsonObject))
-	wf.store.Data[ConfigMapKeyVars] = varStr
-	return nil
+	return wf.shardData(context.Background())
 }
 
 func (wf *WorkflowContext) sync() error {
 	ctx := context.Background()
+	if wf.persisted != nil && reflect.DeepEqual(wf.persisted.Data, wf.store.Data) &&
+		reflect.DeepEqual(wf.persisted.BinaryData, wf.store.BinaryData) &&
+		reflect.DeepEqual(wf.persisted.Annotations, wf.store.Annotations) {
+		return nil
+	}
 	if err := wf.cli.Update(ctx, wf.store); err != nil {
-		if kerrors.IsNotFound(err) {
-			return wf.cli.Create(ctx, wf.store)
+		if !kerrors.IsNotFound(err) {
+			return err
+		}
+		if err := wf.cli.Create(ctx, wf.store); err != nil {
+			return err
 		}
+	}
+	if err := wf.cleanupShards(ctx, wf.persisted, wf.store); err != nil {
 		return err
 	}
+	wf.persisted = wf.store.DeepCopy()
 	return nil
 }
 
 // LoadFromConfigMap recover workflow context from configMap.
 func (wf *WorkflowContext) LoadFromConfigMap(cm corev1.ConfigMap) error {
-	data := cm.Data
+	ctx := context.Background()
 	componentsJs := map[string]string{}
-
-	if err := json.Unmarshal([]byte(data[ConfigMapKeyComponents]), &componentsJs); err != nil {
+	componentsStr, err := wf.loadData(ctx, &cm, ConfigMapKeyComponents)
+	if err != nil {
+		return errors.WithMessage(err, "load components")
+	}
+	if err := json.Unmarshal([]byte(componentsStr), &componentsJs); err != nil {
 		return errors.WithMessage(err, "decode components")
 	}
 	wf.components = map[string]*ComponentManifest{}
@@ -224,8 +259,11 @@ func (wf *WorkflowContext) LoadFromConfigMap(cm corev1.ConfigMap) error {
 		}
 		wf.components[name] = cm
 	}
-	var err error
-	wf.vars, err = value.NewValue(data[ConfigMapKeyVars], nil, "")
+	varsStr, err := wf.loadData(ctx, &cm, ConfigMapKeyVars)
+	if err != nil {
+		return errors.WithMessage(err, "load vars")
+	}
+	wf.vars, err = value.NewValue(varsStr, nil, "")
 	if err != nil {
 		return errors.WithMessage(err, "decode vars")
 	}
@@ -237,6 +275,7 @@ func (wf *WorkflowContext) StoreRef() *corev1.ObjectReference {
 	return &corev1.ObjectReference{
 		APIVersion: wf.store.APIVersion,
 		Kind:       wf.store.Kind,
+		Namespace:  wf.store.Namespace,
 		Name:       wf.store.Name,
 		UID:        wf.store.UID,
 	}
@@ -331,23 +370,25 @@ func newContext(cli client.Client, ns, app string, appUID types.UID) (*WorkflowC
 			Controller: pointer.BoolPtr(true),
 		},
 	})
+	var persisted *corev1.ConfigMap
 	if err := cli.Get(ctx, client.ObjectKey{Name: store.Name, Namespace: store.Namespace}, &store); err != nil {
-		if kerrors.IsNotFound(err) {
-			if err := cli.Create(ctx, &store); err != nil {
-				return nil, err
-			}
-		} else {
+		if !kerrors.IsNotFound(err) {
 			return nil, err
 		}
+	} else {
+		persisted = store.DeepCopy()
 	}
 	store.Annotations = map[string]string{
 		AnnotationStartTimestamp: time.Now().String(),
 	}
 	wfCtx := &WorkflowContext{
-		cli:        cli,
-		store:      &store,
-		components: map[string]*ComponentManifest{},
-		modified:   true,
+		cli:                cli,
+		store:              &store,
+		components:         map[string]*ComponentManifest{},
+		modified:           true,
+		componentsModified: true,
+		varsModified:       true,
+		persisted:          persisted,
 	}
 	var err error
 	wfCtx.vars, err = value.NewValue("", nil, "")
@@ -365,8 +406,9 @@ func LoadContext(cli client.Client, ns, app string) (Context, error) {
 		return nil, err
 	}
 	ctx := &WorkflowContext{
-		cli:   cli,
-		store: &store,
+		cli:       cli,
+		store:     &store,
+		persisted: store.DeepCopy(),
 	}
 	if err := ctx.LoadFromConfigMap(store); err != nil {
 		return nil, err
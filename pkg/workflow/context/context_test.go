@@ -211,12 +211,14 @@ func TestRefObj(t *testing.T) {
 	wfCtx.store.APIVersion = "v1"
 	wfCtx.store.Kind = "ConfigMap"
 	wfCtx.store.Name = "app-v1"
+	wfCtx.store.Namespace = "default"
 
 	ref := wfCtx.StoreRef()
 	r := require.New(t)
 	r.Equal(*ref, corev1.ObjectReference{
 		APIVersion: "v1",
 		Kind:       "ConfigMap",
+		Namespace:  "default",
 		Name:       "app-v1",
 	})
 }
@@ -0,0 +1,241 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+const (
+	// compressThreshold is the size above which the data is compressed before being saved to the store.
+	compressThreshold = 64 * 1024
+	// maxInlineSize is the max size of all the data kept in the store itself, since the size of a ConfigMap is limited
+	// to 1MiB. The compressed components and vars are moved into the shard ConfigMaps, the larger one first, until the
+	// data left in the store fits in it. The mutable values are counted but always kept in the store, they are small
+	// and removed by the steps setting them, so they are not garbage collected by the context.
+	maxInlineSize = 512 * 1024
+	// maxShardSize is the max size of the compressed data kept in one shard ConfigMap.
+	maxShardSize = 512 * 1024
+
+	compressedKeySuffix = ".gz"
+	shardsKeySuffix     = ".shards"
+	shardDataKey        = "data"
+)
+
+// shardsRef refers to the shard ConfigMaps which the compressed data is split into. The shards are named by the hash
+// of the data, so the shards of the new data never overwrite the ones of the data in use.
+type shardsRef struct {
+	Hash  string `json:"hash"`
+	Count int    `json:"count"`
+}
+
+func (ref shardsRef) names(store string) []string {
+	var names []string
+	for i := 0; i < ref.Count; i++ {
+		names = append(names, fmt.Sprintf("%s-%s-%d", store, ref.Hash, i))
+	}
+	return names
+}
+
+// saveData saves the data of the key into the store, and removes the stale data of the key saved in other forms.
+// The data is compressed if it's large, and it's split into the shard ConfigMaps by shardData later if the store is
+// still too large.
+func (wf *WorkflowContext) saveData(key string, data string) error {
+	delete(wf.store.Data, key)
+	delete(wf.store.Data, key+shardsKeySuffix)
+	delete(wf.store.BinaryData, key+compressedKeySuffix)
+	if len(data) <= compressThreshold {
+		wf.store.Data[key] = data
+		return nil
+	}
+
+	compressed, err := compress(data)
+	if err != nil {
+		return errors.WithMessagef(err, "compress %s", key)
+	}
+	if wf.store.BinaryData == nil {
+		wf.store.BinaryData = make(map[string][]byte)
+	}
+	wf.store.BinaryData[key+compressedKeySuffix] = compressed
+	return nil
+}
+
+// shardData moves the compressed components and vars into the shard ConfigMaps, the larger one first, until the data
+// of all the keys left in the store is no larger than maxInlineSize.
+func (wf *WorkflowContext) shardData(ctx context.Context) error {
+	for inlineSize(wf.store) > maxInlineSize {
+		key := ""
+		for _, k := range []string{ConfigMapKeyComponents, ConfigMapKeyVars} {
+			if compressed, ok := wf.store.BinaryData[k+compressedKeySuffix]; ok &&
+				(key == "" || len(compressed) > len(wf.store.BinaryData[key+compressedKeySuffix])) {
+				key = k
+			}
+		}
+		if key == "" {
+			return errors.Errorf("the size of the data in configMap(%s/%s) exceeds %d", wf.store.Namespace, wf.store.Name, maxInlineSize)
+		}
+		if err := wf.saveShards(ctx, key, wf.store.BinaryData[key+compressedKeySuffix]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveShards splits the compressed data of the key into the shard ConfigMaps, and refers to them in the store.
+func (wf *WorkflowContext) saveShards(ctx context.Context, key string, compressed []byte) error {
+	ref := shardsRef{Hash: hashData(compressed), Count: (len(compressed) + maxShardSize - 1) / maxShardSize}
+	for i, name := range ref.names(wf.store.Name) {
+		end := (i + 1) * maxShardSize
+		if end > len(compressed) {
+			end = len(compressed)
+		}
+		shard := &corev1.ConfigMap{}
+		shard.Name = name
+		shard.Namespace = wf.store.Namespace
+		shard.OwnerReferences = wf.store.OwnerReferences
+		shard.BinaryData = map[string][]byte{shardDataKey: compressed[i*maxShardSize : end]}
+		if err := wf.cli.Create(ctx, shard); err != nil && !kerrors.IsAlreadyExists(err) {
+			return errors.WithMessagef(err, "create shard %s of %s", name, key)
+		}
+	}
+	delete(wf.store.BinaryData, key+compressedKeySuffix)
+	wf.store.Data[key+shardsKeySuffix] = string(util.MustJSONMarshal(ref))
+	return nil
+}
+
+// inlineSize returns the size of the data of all the keys kept in the store.
+func inlineSize(store *corev1.ConfigMap) int {
+	size := 0
+	for k, v := range store.Data {
+		size += len(k) + len(v)
+	}
+	for k, v := range store.BinaryData {
+		size += len(k) + len(v)
+	}
+	return size
+}
+
+// loadData loads the data of the key from the store, no matter which form the data is saved in.
+func (wf *WorkflowContext) loadData(ctx context.Context, store *corev1.ConfigMap, key string) (string, error) {
+	if data, ok := store.Data[key]; ok {
+		return data, nil
+	}
+	if compressed, ok := store.BinaryData[key+compressedKeySuffix]; ok {
+		return decompress(compressed)
+	}
+	refStr, ok := store.Data[key+shardsKeySuffix]
+	if !ok {
+		return "", nil
+	}
+	ref := shardsRef{}
+	if err := json.Unmarshal([]byte(refStr), &ref); err != nil {
+		return "", errors.WithMessagef(err, "decode the shards of %s", key)
+	}
+	var compressed []byte
+	for _, name := range ref.names(store.Name) {
+		shard := &corev1.ConfigMap{}
+		if err := wf.cli.Get(ctx, client.ObjectKey{Namespace: store.Namespace, Name: name}, shard); err != nil {
+			return "", errors.WithMessagef(err, "get shard %s of %s", name, key)
+		}
+		compressed = append(compressed, shard.BinaryData[shardDataKey]...)
+	}
+	if hashData(compressed) != ref.Hash {
+		return "", errors.Errorf("the shards of %s are corrupted", key)
+	}
+	return decompress(compressed)
+}
+
+// cleanupShards deletes the shard ConfigMaps which are referred by the old store but not by the new one.
+func (wf *WorkflowContext) cleanupShards(ctx context.Context, oldStore, newStore *corev1.ConfigMap) error {
+	if oldStore == nil {
+		return nil
+	}
+	inUse := map[string]bool{}
+	for _, name := range shardNames(newStore) {
+		inUse[name] = true
+	}
+	for _, name := range shardNames(oldStore) {
+		if inUse[name] {
+			continue
+		}
+		shard := &corev1.ConfigMap{}
+		shard.Name = name
+		shard.Namespace = oldStore.Namespace
+		if err := wf.cli.Delete(ctx, shard); err != nil && !kerrors.IsNotFound(err) {
+			return errors.WithMessagef(err, "delete shard %s", name)
+		}
+	}
+	return nil
+}
+
+func shardNames(store *corev1.ConfigMap) []string {
+	var names []string
+	for _, key := range []string{ConfigMapKeyComponents, ConfigMapKeyVars} {
+		ref := shardsRef{}
+		if err := json.Unmarshal([]byte(store.Data[key+shardsKeySuffix]), &ref); err != nil {
+			continue
+		}
+		names = append(names, ref.names(store.Name)...)
+	}
+	return names
+}
+
+func compress(data string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(compressed []byte) (string, error) {
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func hashData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
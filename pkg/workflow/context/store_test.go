@@ -0,0 +1,300 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package context
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	yamlUtil "sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+)
+
+// countingClient counts the writes to the ConfigMaps and the bytes written.
+type countingClient struct {
+	client.Client
+	writes int
+	bytes  int
+}
+
+func (c *countingClient) count(obj client.Object) {
+	if cm, ok := obj.(*corev1.ConfigMap); ok {
+		c.writes++
+		for k, v := range cm.Data {
+			c.bytes += len(k) + len(v)
+		}
+		for k, v := range cm.BinaryData {
+			c.bytes += len(k) + len(v)
+		}
+	}
+}
+
+func (c *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.count(obj)
+	return nil
+}
+
+func (c *countingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.Client.Update(ctx, obj, opts...); err != nil {
+		return err
+	}
+	c.count(obj)
+	return nil
+}
+
+func randomString(t require.TestingT, size int) string {
+	bs := make([]byte, size/2)
+	_, err := rand.Read(bs)
+	require.NoError(t, err)
+	return hex.EncodeToString(bs)
+}
+
+func setLargeVar(t require.TestingT, wfCtx Context, name string, size int) string {
+	data := randomString(t, size)
+	v, err := value.NewValue(fmt.Sprintf("%q", data), nil, "")
+	require.NoError(t, err)
+	require.NoError(t, wfCtx.SetVar(v, name))
+	return data
+}
+
+func getVar(t require.TestingT, wfCtx Context, name string) string {
+	v, err := wfCtx.GetVar(name)
+	require.NoError(t, err)
+	s, err := v.CueValue().String()
+	require.NoError(t, err)
+	return s
+}
+
+func listConfigMaps(t *testing.T, cli client.Client) []string {
+	cms := &corev1.ConfigMapList{}
+	require.NoError(t, cli.List(context.Background(), cms))
+	var names []string
+	for _, cm := range cms.Items {
+		names = append(names, cm.Name)
+	}
+	return names
+}
+
+func TestCommitOnlyOnChange(t *testing.T) {
+	r := require.New(t)
+	cli := &countingClient{Client: fake.NewClientBuilder().Build()}
+	wfCtx, err := NewContext(cli, "default", "app", "testuid")
+	r.NoError(err)
+	r.Equal(1, cli.writes)
+
+	r.NoError(wfCtx.Commit())
+	wfCtx.SetMutableValue("1", "test")
+	r.NoError(wfCtx.Commit())
+	r.Equal(2, cli.writes)
+	wfCtx.SetMutableValue("1", "test")
+	wfCtx.DeleteMutableValue("not-found")
+	r.NoError(wfCtx.Commit())
+	r.Equal(2, cli.writes)
+
+	wfCtx, err = LoadContext(cli, "default", "app")
+	r.NoError(err)
+	wfCtx.IncreaseMutableCountValue("test")
+	wfCtx.IncreaseMutableCountValue("test")
+	wfCtx.SetMutableValue("1", "test")
+	r.NoError(wfCtx.Commit())
+	r.Equal(2, cli.writes)
+	r.Equal("1", wfCtx.GetMutableValue("test"))
+}
+
+func TestCompressedStore(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().Build()
+	wfCtx, err := NewContext(cli, "default", "app", "testuid")
+	r.NoError(err)
+
+	// compressed into the store
+	compressed := setLargeVar(t, wfCtx, "compressed", 2*compressThreshold)
+	r.NoError(wfCtx.Commit())
+	store := wfCtx.GetStore()
+	r.NotContains(store.Data, ConfigMapKeyVars)
+	r.Contains(store.BinaryData, ConfigMapKeyVars+compressedKeySuffix)
+
+	loaded, err := LoadContext(cli, "default", "app")
+	r.NoError(err)
+	r.Equal(compressed, getVar(t, loaded, "compressed"))
+	r.Equal([]string{"workflow-app-context"}, listConfigMaps(t, cli))
+
+	// sharded into multiple config maps
+	sharded := setLargeVar(t, loaded, "sharded", 2*maxShardSize)
+	r.NoError(loaded.Commit())
+	store = loaded.GetStore()
+	r.NotContains(store.BinaryData, ConfigMapKeyVars+compressedKeySuffix)
+	r.Contains(store.Data, ConfigMapKeyVars+shardsKeySuffix)
+	shards := shardNames(store)
+	r.Equal(2, len(shards))
+	r.ElementsMatch(append([]string{"workflow-app-context"}, shards...), listConfigMaps(t, cli))
+
+	loaded, err = LoadContext(cli, "default", "app")
+	r.NoError(err)
+	r.Equal(compressed, getVar(t, loaded, "compressed"))
+	r.Equal(sharded, getVar(t, loaded, "sharded"))
+
+	// the stale shards are deleted once the vars change
+	setLargeVar(t, loaded, "another", 2*maxShardSize)
+	r.NoError(loaded.Commit())
+	newShards := shardNames(loaded.GetStore())
+	r.Equal(3, len(newShards))
+	r.ElementsMatch(append([]string{"workflow-app-context"}, newShards...), listConfigMaps(t, cli))
+	for _, name := range shards {
+		r.NotContains(newShards, name)
+	}
+
+	// the corrupted shards are detected
+	shard := &corev1.ConfigMap{}
+	r.NoError(cli.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: newShards[0]}, shard))
+	shard.BinaryData[shardDataKey] = []byte("corrupted")
+	r.NoError(cli.Update(context.Background(), shard))
+	_, err = LoadContext(cli, "default", "app")
+	r.Error(err)
+	r.Contains(err.Error(), "corrupted")
+
+	// the shards are deleted once the workflow restarts
+	_, err = NewContext(cli, "default", "app", "testuid")
+	r.NoError(err)
+	r.Equal([]string{"workflow-app-context"}, listConfigMaps(t, cli))
+}
+
+func TestStoreSizeBudget(t *testing.T) {
+	r := require.New(t)
+	cli := fake.NewClientBuilder().Build()
+	wfCtx, err := NewContext(cli, "default", "app", "testuid")
+	r.NoError(err)
+
+	// the compressed vars fit in a shard but not together with the mutable values
+	wfCtx.SetMutableValue(randomString(t, maxInlineSize/2), "large")
+	vars := setLargeVar(t, wfCtx, "vars", maxShardSize)
+	r.NoError(wfCtx.Commit())
+	store := wfCtx.GetStore()
+	r.NotContains(store.BinaryData, ConfigMapKeyVars+compressedKeySuffix)
+	r.Contains(store.Data, ConfigMapKeyVars+shardsKeySuffix)
+	r.LessOrEqual(inlineSize(store), maxInlineSize)
+
+	loaded, err := LoadContext(cli, "default", "app")
+	r.NoError(err)
+	r.Equal(vars, getVar(t, loaded, "vars"))
+
+	// the larger one of the components and vars is moved into the shards first
+	wf := &WorkflowContext{cli: cli, store: &corev1.ConfigMap{Data: map[string]string{}, BinaryData: map[string][]byte{
+		ConfigMapKeyComponents + compressedKeySuffix: []byte(randomString(t, maxInlineSize/2)),
+		ConfigMapKeyVars + compressedKeySuffix:       []byte(randomString(t, maxInlineSize/4*3)),
+	}}}
+	wf.store.Name = "budget"
+	wf.store.Namespace = "default"
+	r.NoError(wf.shardData(context.Background()))
+	r.Contains(wf.store.BinaryData, ConfigMapKeyComponents+compressedKeySuffix)
+	r.Contains(wf.store.Data, ConfigMapKeyVars+shardsKeySuffix)
+
+	// the mutable values are always kept in the store
+	wf.store.Data["large"] = randomString(t, maxInlineSize*2)
+	r.Error(wf.shardData(context.Background()))
+}
+
+// legacyCommit encodes all the components and vars into the store and writes it on every commit, like the workflow
+// context did before the components and vars are only encoded and written on change.
+func legacyCommit(wf *WorkflowContext) error {
+	varStr, err := wf.vars.String()
+	if err != nil {
+		return err
+	}
+	jsonObject := map[string]string{}
+	for name, comp := range wf.components {
+		s, err := comp.string()
+		if err != nil {
+			return err
+		}
+		jsonObject[name] = s
+	}
+	wf.store.Data[ConfigMapKeyComponents] = string(util.MustJSONMarshal(jsonObject))
+	wf.store.Data[ConfigMapKeyVars] = varStr
+	return wf.cli.Update(context.Background(), wf.store)
+}
+
+// BenchmarkCommit simulates the commits in the reconciles of an application with a large workflow context, the steps
+// in the reconciles only update the counters in the context.
+func BenchmarkCommit(b *testing.B) {
+	newBenchContext := func(b *testing.B) (*countingClient, *WorkflowContext) {
+		cli := &countingClient{Client: fake.NewClientBuilder().Build()}
+		wfCtx, err := newContext(cli, "default", "app", "testuid")
+		require.NoError(b, err)
+		var cm corev1.ConfigMap
+		testCaseJSON, err := yamlUtil.YAMLToJSON([]byte(testCaseYaml))
+		require.NoError(b, err)
+		require.NoError(b, json.Unmarshal(testCaseJSON, &cm))
+		base := &WorkflowContext{store: &cm}
+		require.NoError(b, base.LoadFromConfigMap(cm))
+		for i := 0; i < 50; i++ {
+			wfCtx.components[fmt.Sprintf("server-%d", i)] = base.components["server"]
+		}
+		setLargeVar(b, wfCtx, "large", 2*compressThreshold)
+		require.NoError(b, wfCtx.Commit())
+		cli.writes, cli.bytes = 0, 0
+		return cli, wfCtx
+	}
+	report := func(b *testing.B, cli *countingClient) {
+		b.ReportMetric(float64(cli.writes)/float64(b.N), "writes/op")
+		b.ReportMetric(float64(cli.bytes)/float64(b.N), "bytes-written/op")
+	}
+
+	b.Run("legacy", func(b *testing.B) {
+		cli, wfCtx := newBenchContext(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			wfCtx.IncreaseMutableCountValue("backoff", "step")
+			require.NoError(b, legacyCommit(wfCtx))
+		}
+		report(b, cli)
+	})
+	b.Run("counter-changed", func(b *testing.B) {
+		cli, wfCtx := newBenchContext(b)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			wfCtx.IncreaseMutableCountValue("backoff", "step")
+			require.NoError(b, wfCtx.Commit())
+		}
+		report(b, cli)
+	})
+	b.Run("unchanged", func(b *testing.B) {
+		cli, wfCtx := newBenchContext(b)
+		wfCtx.SetMutableValue("1", "backoff", "step")
+		require.NoError(b, wfCtx.Commit())
+		cli.writes, cli.bytes = 0, 0
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			wfCtx.SetMutableValue("1", "backoff", "step")
+			require.NoError(b, wfCtx.Commit())
+		}
+		report(b, cli)
+	})
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	wfTypes "github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+const (
+	// ProviderName is provider name for install.
+	ProviderName = "config"
+	// SecretKeyConfig is the key of the config content in the secret
+	SecretKeyConfig = "config"
+)
+
+type provider struct {
+	cli client.Client
+}
+
+// Config is the typed configuration entry stored in secret
+type Config struct {
+	Name      string                 `json:"name"`
+	Namespace string                 `json:"namespace"`
+	Type      string                 `json:"type"`
+	Config    map[string]interface{} `json:"config"`
+	Clusters  []string               `json:"clusters,omitempty"`
+}
+
+// Create creates or updates the config, and distributes it to the managed clusters.
+func (p *provider) Create(ctx wfContext.Context, v *value.Value, act wfTypes.Action) error {
+	cfg := Config{}
+	if err := v.UnmarshalTo(&cfg); err != nil {
+		return err
+	}
+	if cfg.Namespace == "" {
+		cfg.Namespace = types.DefaultKubeVelaNS
+	}
+	secret, err := newConfigSecret(cfg)
+	if err != nil {
+		return err
+	}
+	var staleClusters []string
+	existing := &corev1.Secret{}
+	if err := p.cli.Get(context.Background(), client.ObjectKeyFromObject(secret), existing); err == nil {
+		staleClusters = getConfigClusters(existing)
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	}
+	clusters := append([]string{multicluster.ClusterLocalName}, cfg.Clusters...)
+	for _, cluster := range clusters {
+		if err := applySecret(multicluster.ContextWithClusterName(context.Background(), cluster), p.cli, secret.DeepCopy()); err != nil {
+			return errors.WithMessagef(err, "create config %s in cluster %s", cfg.Name, cluster)
+		}
+	}
+	// remove the copies in the clusters which are dropped from the config
+	for _, cluster := range staleClusters {
+		if containsCluster(clusters, cluster) {
+			continue
+		}
+		if err := deleteSecret(multicluster.ContextWithClusterName(context.Background(), cluster), p.cli, cfg.Name, cfg.Namespace); err != nil {
+			return errors.WithMessagef(err, "delete config %s in cluster %s", cfg.Name, cluster)
+		}
+	}
+	return nil
+}
+
+// Read reads the config by name.
+func (p *provider) Read(ctx wfContext.Context, v *value.Value, act wfTypes.Action) error {
+	name, namespace, err := lookupNameAndNamespace(v)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{}
+	if err := p.cli.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	cfg, err := convertSecretToConfig(secret)
+	if err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	if err := v.FillObject(cfg.Type, "type"); err != nil {
+		return err
+	}
+	return fillJSON(v, cfg.Config, "config")
+}
+
+// List lists the configs, filtered by type if specified.
+func (p *provider) List(ctx wfContext.Context, v *value.Value, act wfTypes.Action) error {
+	namespace, err := v.GetString("namespace")
+	if err != nil {
+		return err
+	}
+	configType, err := v.GetString("type")
+	if err != nil {
+		return err
+	}
+	selector := client.HasLabels{oam.LabelConfigType}
+	listOpts := []client.ListOption{client.InNamespace(namespace), selector}
+	if configType != "" {
+		listOpts = []client.ListOption{client.InNamespace(namespace), client.MatchingLabels{oam.LabelConfigType: configType}}
+	}
+	secrets := &corev1.SecretList{}
+	if err := p.cli.List(context.Background(), secrets, listOpts...); err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	configs := make([]Config, 0, len(secrets.Items))
+	for i := range secrets.Items {
+		cfg, err := convertSecretToConfig(&secrets.Items[i])
+		if err != nil {
+			return v.FillObject(err.Error(), "err")
+		}
+		configs = append(configs, *cfg)
+	}
+	return fillJSON(v, configs, "list")
+}
+
+// Delete deletes the config and its copies in the managed clusters.
+func (p *provider) Delete(ctx wfContext.Context, v *value.Value, act wfTypes.Action) error {
+	name, namespace, err := lookupNameAndNamespace(v)
+	if err != nil {
+		return err
+	}
+	secret := &corev1.Secret{}
+	if err := p.cli.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, secret); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, ok := secret.Labels[oam.LabelConfigType]; !ok {
+		return errors.Errorf("secret %s/%s is not a config", namespace, name)
+	}
+	for _, cluster := range append(getConfigClusters(secret), multicluster.ClusterLocalName) {
+		if err := deleteSecret(multicluster.ContextWithClusterName(context.Background(), cluster), p.cli, name, namespace); err != nil {
+			return errors.WithMessagef(err, "delete config %s in cluster %s", name, cluster)
+		}
+	}
+	return nil
+}
+
+// fillJSON fill the object through its json encoding, so that the integers are not converted to floats
+func fillJSON(v *value.Value, obj interface{}, paths ...string) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	return v.FillRaw(string(data), paths...)
+}
+
+func lookupNameAndNamespace(v *value.Value) (string, string, error) {
+	name, err := v.GetString("name")
+	if err != nil {
+		return "", "", err
+	}
+	namespace, err := v.GetString("namespace")
+	if err != nil {
+		return "", "", err
+	}
+	return name, namespace, nil
+}
+
+func newConfigSecret(cfg Config) (*corev1.Secret, error) {
+	if cfg.Type == "" {
+		return nil, errors.Errorf("the type of config %s is required", cfg.Name)
+	}
+	data, err := json.Marshal(cfg.Config)
+	if err != nil {
+		return nil, err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cfg.Name,
+			Namespace: cfg.Namespace,
+			Labels:    map[string]string{oam.LabelConfigType: cfg.Type},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{SecretKeyConfig: data},
+	}
+	if len(cfg.Clusters) != 0 {
+		secret.Annotations = map[string]string{oam.AnnotationConfigClusters: strings.Join(cfg.Clusters, ",")}
+	}
+	return secret, nil
+}
+
+func convertSecretToConfig(secret *corev1.Secret) (*Config, error) {
+	configType, ok := secret.Labels[oam.LabelConfigType]
+	if !ok {
+		return nil, errors.Errorf("secret %s/%s is not a config", secret.Namespace, secret.Name)
+	}
+	cfg := &Config{
+		Name:      secret.Name,
+		Namespace: secret.Namespace,
+		Type:      configType,
+		Config:    map[string]interface{}{},
+		Clusters:  getConfigClusters(secret),
+	}
+	if data, ok := secret.Data[SecretKeyConfig]; ok {
+		if err := json.Unmarshal(data, &cfg.Config); err != nil {
+			return nil, errors.WithMessagef(err, "decode config %s/%s", secret.Namespace, secret.Name)
+		}
+	}
+	return cfg, nil
+}
+
+func getConfigClusters(secret *corev1.Secret) []string {
+	clusters := secret.Annotations[oam.AnnotationConfigClusters]
+	if clusters == "" {
+		return nil
+	}
+	return strings.Split(clusters, ",")
+}
+
+func containsCluster(clusters []string, cluster string) bool {
+	for _, c := range clusters {
+		if c == cluster {
+			return true
+		}
+	}
+	return false
+}
+
+// applySecret creates the config secret or updates the existing one, the secrets which are not configs are refused
+func applySecret(ctx context.Context, cli client.Client, secret *corev1.Secret) error {
+	existing := &corev1.Secret{}
+	err := cli.Get(ctx, client.ObjectKeyFromObject(secret), existing)
+	if kerrors.IsNotFound(err) {
+		return cli.Create(ctx, secret)
+	}
+	if err != nil {
+		return err
+	}
+	if _, ok := existing.Labels[oam.LabelConfigType]; !ok {
+		return errors.Errorf("secret %s/%s already exists and is not a config", existing.Namespace, existing.Name)
+	}
+	existing.Labels = secret.Labels
+	existing.Annotations = secret.Annotations
+	existing.Data = secret.Data
+	return cli.Update(ctx, existing)
+}
+
+// deleteSecret deletes the config secret if it exists, the secrets which are not configs are left untouched
+func deleteSecret(ctx context.Context, cli client.Client, name, namespace string) error {
+	existing := &corev1.Secret{}
+	if err := cli.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, existing); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if _, ok := existing.Labels[oam.LabelConfigType]; !ok {
+		return nil
+	}
+	return client.IgnoreNotFound(cli.Delete(ctx, existing))
+}
+
+// Install register handlers to provider discover.
+func Install(p providers.Providers, cli client.Client) {
+	prd := &provider{cli: cli}
+	p.Register(ProviderName, map[string]providers.Handler{
+		"create": prd.Create,
+		"read":   prd.Read,
+		"list":   prd.List,
+		"delete": prd.Delete,
+	})
+}
+
+// InstallReadOnly register the handlers which only read the configs to provider discover, it is used by the views.
+func InstallReadOnly(p providers.Providers, cli client.Client) {
+	prd := &provider{cli: cli}
+	p.Register(ProviderName, map[string]providers.Handler{
+		"read": prd.Read,
+		"list": prd.List,
+	})
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+func TestConfigLifecycle(t *testing.T) {
+	r := require.New(t)
+	cli := multicluster.NewFakeClient(fake.NewClientBuilder().Build())
+	remote := fake.NewClientBuilder().Build()
+	cli.AddCluster("cluster-a", remote)
+	p := &provider{cli: cli}
+
+	v, err := value.NewValue(`
+name:      "slack-token"
+namespace: "vela-system"
+type:      "slack"
+config: {url: "https://hooks.slack.com/xxx", retries: 3}
+clusters: ["cluster-a"]
+`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Create(nil, v, nil))
+
+	key := client.ObjectKey{Namespace: "vela-system", Name: "slack-token"}
+	secret := &corev1.Secret{}
+	r.NoError(cli.Get(context.Background(), key, secret))
+	r.Equal("slack", secret.Labels[oam.LabelConfigType])
+	r.Equal("cluster-a", secret.Annotations[oam.AnnotationConfigClusters])
+	r.NoError(remote.Get(context.Background(), key, &corev1.Secret{}))
+
+	v, err = value.NewValue(`name: "slack-token", namespace: "vela-system"`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Read(nil, v, nil))
+	url, err := v.GetString("config", "url")
+	r.NoError(err)
+	r.Equal("https://hooks.slack.com/xxx", url)
+	retries, err := v.GetInt64("config", "retries")
+	r.NoError(err)
+	r.Equal(int64(3), retries)
+
+	v, err = value.NewValue(`
+name:      "smtp"
+namespace: "vela-system"
+type:      "email"
+config: host: "smtp.example.com"
+`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Create(nil, v, nil))
+
+	v, err = value.NewValue(`namespace: "vela-system", type: "slack"`, nil, "")
+	r.NoError(err)
+	r.NoError(p.List(nil, v, nil))
+	configs := struct {
+		List []Config `json:"list"`
+	}{}
+	r.NoError(v.UnmarshalTo(&configs))
+	r.Equal(1, len(configs.List))
+	r.Equal("slack-token", configs.List[0].Name)
+	r.Equal([]string{"cluster-a"}, configs.List[0].Clusters)
+
+	v, err = value.NewValue(`namespace: "vela-system", type: ""`, nil, "")
+	r.NoError(err)
+	r.NoError(p.List(nil, v, nil))
+	r.NoError(v.UnmarshalTo(&configs))
+	r.Equal(2, len(configs.List))
+
+	v, err = value.NewValue(`name: "slack-token", namespace: "vela-system"`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Delete(nil, v, nil))
+	r.True(kerrors.IsNotFound(cli.Get(context.Background(), key, &corev1.Secret{})))
+	r.True(kerrors.IsNotFound(remote.Get(context.Background(), key, &corev1.Secret{})))
+
+	v, err = value.NewValue(`name: "slack-token", namespace: "vela-system"`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Read(nil, v, nil))
+	errMsg, err := v.GetString("err")
+	r.NoError(err)
+	r.NotEmpty(errMsg)
+}
+
+func TestUpdateConfig(t *testing.T) {
+	r := require.New(t)
+	key := client.ObjectKey{Namespace: "vela-system", Name: "slack-token"}
+	cli := multicluster.NewFakeClient(fake.NewClientBuilder().Build())
+	remote := fake.NewClientBuilder().WithObjects(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}).Build()
+	cli.AddCluster("cluster-a", remote)
+	cli.AddCluster("cluster-b", fake.NewClientBuilder().Build())
+	p := &provider{cli: cli}
+
+	v, err := value.NewValue(`
+name:      "slack-token"
+namespace: "vela-system"
+type:      "slack"
+config: url: "https://hooks.slack.com/xxx"
+clusters: ["cluster-a"]
+`, nil, "")
+	r.NoError(err)
+	r.Error(p.Create(nil, v, nil))
+	secret := &corev1.Secret{}
+	r.NoError(remote.Get(context.Background(), key, secret))
+	r.Empty(secret.Labels[oam.LabelConfigType])
+
+	r.NoError(remote.Delete(context.Background(), secret))
+	r.NoError(p.Create(nil, v, nil))
+	r.NoError(remote.Get(context.Background(), key, &corev1.Secret{}))
+
+	v, err = value.NewValue(`
+name:      "slack-token"
+namespace: "vela-system"
+type:      "slack"
+config: url: "https://hooks.slack.com/xxx"
+clusters: ["cluster-b"]
+`, nil, "")
+	r.NoError(err)
+	r.NoError(p.Create(nil, v, nil))
+	r.True(kerrors.IsNotFound(remote.Get(context.Background(), key, &corev1.Secret{})))
+	r.NoError(cli.Get(multicluster.ContextWithClusterName(context.Background(), "cluster-b"), key, &corev1.Secret{}))
+}
+
+func TestCreateConfigWithoutType(t *testing.T) {
+	p := &provider{cli: fake.NewClientBuilder().Build()}
+	v, err := value.NewValue(`name: "no-type", config: {}`, nil, "")
+	require.NoError(t, err)
+	require.Error(t, p.Create(nil, v, nil))
+}
+
+func TestInstall(t *testing.T) {
+	p := providers.NewProviders()
+	Install(p, nil)
+	for _, do := range []string{"create", "read", "list", "delete"} {
+		h, ok := p.GetHandler(ProviderName, do)
+		require.True(t, ok)
+		require.NotNil(t, h)
+	}
+
+	p = providers.NewProviders()
+	InstallReadOnly(p, nil)
+	for _, do := range []string{"read", "list"} {
+		_, ok := p.GetHandler(ProviderName, do)
+		require.True(t, ok)
+	}
+	for _, do := range []string{"create", "delete"} {
+		_, ok := p.GetHandler(ProviderName, do)
+		require.False(t, ok)
+	}
+}
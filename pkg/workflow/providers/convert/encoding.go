@@ -0,0 +1,120 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+// Base64Encode encode the data with standard base64 encoding
+func (h *provider) Base64Encode(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	data, err := lookupBytes(v, "data")
+	if err != nil {
+		return err
+	}
+	return v.FillObject(base64.StdEncoding.EncodeToString(data), "result")
+}
+
+// Base64Decode decode the standard base64 encoded data
+func (h *provider) Base64Decode(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	data, err := v.GetString("data")
+	if err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return err
+	}
+	return v.FillObject(string(decoded), "result")
+}
+
+// JSONMarshal marshal the value to json string
+func (h *provider) JSONMarshal(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	val, err := v.LookupValue("value")
+	if err != nil {
+		return err
+	}
+	b, err := val.CueValue().MarshalJSON()
+	if err != nil {
+		return err
+	}
+	return v.FillObject(string(b), "str")
+}
+
+// JSONUnmarshal unmarshal the json string to value
+func (h *provider) JSONUnmarshal(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	str, err := v.GetString("str")
+	if err != nil {
+		return err
+	}
+	return fillJSON(v, []byte(str), "value")
+}
+
+// YAMLMarshal marshal the value to yaml string
+func (h *provider) YAMLMarshal(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	val, err := v.LookupValue("value")
+	if err != nil {
+		return err
+	}
+	b, err := val.CueValue().MarshalJSON()
+	if err != nil {
+		return err
+	}
+	y, err := yaml.JSONToYAML(b)
+	if err != nil {
+		return err
+	}
+	return v.FillObject(string(y), "str")
+}
+
+// YAMLUnmarshal unmarshal the yaml string to value
+func (h *provider) YAMLUnmarshal(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	str, err := v.GetString("str")
+	if err != nil {
+		return err
+	}
+	b, err := yaml.YAMLToJSON([]byte(str))
+	if err != nil {
+		return err
+	}
+	return fillJSON(v, b, "value")
+}
+
+// fillJSON fill the json data as cue value, so that integers are kept instead of being converted to floats
+func fillJSON(v *value.Value, data []byte, paths ...string) error {
+	if !json.Valid(data) {
+		return errors.New("invalid json data")
+	}
+	return v.FillRaw(string(data), paths...)
+}
+
+// lookupBytes get the bytes of the string or bytes field
+func lookupBytes(v *value.Value, paths ...string) ([]byte, error) {
+	val, err := v.LookupValue(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return val.CueValue().Bytes()
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+func TestEncodingHandlers(t *testing.T) {
+	prd := &provider{}
+	testCases := map[string]struct {
+		from     string
+		handler  providers.Handler
+		path     string
+		expected string
+	}{
+		"base64 encode string": {
+			from:     `data: "hello"`,
+			handler:  prd.Base64Encode,
+			path:     "result",
+			expected: "aGVsbG8=",
+		},
+		"base64 encode bytes": {
+			from:     `data: 'hello'`,
+			handler:  prd.Base64Encode,
+			path:     "result",
+			expected: "aGVsbG8=",
+		},
+		"base64 decode": {
+			from:     `data: "aGVsbG8="`,
+			handler:  prd.Base64Decode,
+			path:     "result",
+			expected: "hello",
+		},
+		"json marshal": {
+			from:     `value: {name: "vela", replicas: 2}`,
+			handler:  prd.JSONMarshal,
+			path:     "str",
+			expected: `{"name":"vela","replicas":2}`,
+		},
+		"json unmarshal": {
+			from:    `str: "{\"name\":\"vela\",\"tags\":[\"a\"]}"`,
+			handler: prd.JSONUnmarshal,
+			path:    "value",
+			expected: `name: "vela"
+tags: ["a"]
+`,
+		},
+		"json unmarshal list": {
+			from:     `str: "[1, 2]"`,
+			handler:  prd.JSONUnmarshal,
+			path:     "value",
+			expected: "[1, 2]\n",
+		},
+		"yaml marshal": {
+			from:     `value: {name: "vela"}`,
+			handler:  prd.YAMLMarshal,
+			path:     "str",
+			expected: "name: vela\n",
+		},
+		"yaml unmarshal": {
+			from:    `str: "name: vela\nreplicas: 2\n"`,
+			handler: prd.YAMLUnmarshal,
+			path:    "value",
+			expected: `name:     "vela"
+replicas: 2
+`,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			v, err := value.NewValue(tc.from, nil, "")
+			r.NoError(err)
+			r.NoError(tc.handler(nil, v, nil))
+			if tc.path != "value" {
+				s, err := v.GetString(tc.path)
+				r.NoError(err)
+				r.Equal(tc.expected, s)
+				return
+			}
+			result, err := v.LookupValue(tc.path)
+			r.NoError(err)
+			s, err := result.String()
+			r.NoError(err)
+			r.Equal(tc.expected, s)
+		})
+	}
+}
+
+func TestBase64DecodeInvalidData(t *testing.T) {
+	v, err := value.NewValue(`data: "!!!"`, nil, "")
+	require.NoError(t, err)
+	prd := &provider{}
+	require.Error(t, prd.Base64Decode(nil, v, nil))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec used for the legacy webhook signature
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+
+	"github.com/pkg/errors"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+var hashAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// SHA256 compute the sha256 checksum of the data
+func (h *provider) SHA256(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	data, err := lookupBytes(v, "data")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	result, err := encodeDigest(v, sum[:])
+	if err != nil {
+		return err
+	}
+	return v.FillObject(result, "result")
+}
+
+// HMAC compute the keyed-hash message authentication code of the data
+func (h *provider) HMAC(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	data, err := lookupBytes(v, "data")
+	if err != nil {
+		return err
+	}
+	key, err := lookupBytes(v, "key")
+	if err != nil {
+		return err
+	}
+	algorithm := "sha256"
+	if _, err := v.LookupValue("algorithm"); err == nil {
+		if algorithm, err = v.GetString("algorithm"); err != nil {
+			return err
+		}
+	}
+	newHash, ok := hashAlgorithms[algorithm]
+	if !ok {
+		return errors.Errorf("unsupported hash algorithm %s", algorithm)
+	}
+	mac := hmac.New(newHash, key)
+	if _, err := mac.Write(data); err != nil {
+		return err
+	}
+	result, err := encodeDigest(v, mac.Sum(nil))
+	if err != nil {
+		return err
+	}
+	return v.FillObject(result, "result")
+}
+
+// encodeDigest encode the digest with the encoding specified in the value, hex by default
+func encodeDigest(v *value.Value, digest []byte) (string, error) {
+	encoding := "hex"
+	if _, err := v.LookupValue("encoding"); err == nil {
+		if encoding, err = v.GetString("encoding"); err != nil {
+			return "", err
+		}
+	}
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(digest), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(digest), nil
+	default:
+		return "", errors.Errorf("unsupported digest encoding %s", encoding)
+	}
+}
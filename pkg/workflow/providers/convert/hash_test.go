@@ -0,0 +1,79 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+)
+
+func TestHashHandlers(t *testing.T) {
+	prd := &provider{}
+	testCases := map[string]struct {
+		from        string
+		handler     providers.Handler
+		expected    string
+		expectedErr error
+	}{
+		"sha256 hex": {
+			from:     `data: "hello"`,
+			handler:  prd.SHA256,
+			expected: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+		},
+		"sha256 base64": {
+			from:     `data: "hello", encoding: "base64"`,
+			handler:  prd.SHA256,
+			expected: "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=",
+		},
+		"hmac default sha256": {
+			from:     `data: "hello", key: "secret"`,
+			handler:  prd.HMAC,
+			expected: "88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b",
+		},
+		"hmac sha1": {
+			from:     `data: "hello", key: "secret", algorithm: "sha1"`,
+			handler:  prd.HMAC,
+			expected: "5112055c05f944f85755efc5cd8970e194e9f45b",
+		},
+		"hmac unsupported algorithm": {
+			from:        `data: "hello", key: "secret", algorithm: "md5"`,
+			handler:     prd.HMAC,
+			expectedErr: errors.New("unsupported hash algorithm md5"),
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			v, err := value.NewValue(tc.from, nil, "")
+			r.NoError(err)
+			err = tc.handler(nil, v, nil)
+			if tc.expectedErr != nil {
+				r.Equal(tc.expectedErr.Error(), err.Error())
+				return
+			}
+			r.NoError(err)
+			result, err := v.GetString("result")
+			r.NoError(err)
+			r.Equal(tc.expected, result)
+		})
+	}
+}
@@ -48,6 +48,15 @@ func (h *provider) String(ctx wfContext.Context, v *value.Value, act types.Actio
 func Install(p providers.Providers) {
 	prd := &provider{}
 	p.Register(ProviderName, map[string]providers.Handler{
-		"string": prd.String,
+		"string":        prd.String,
+		"base64Encode":  prd.Base64Encode,
+		"base64Decode":  prd.Base64Decode,
+		"jsonMarshal":   prd.JSONMarshal,
+		"jsonUnmarshal": prd.JSONUnmarshal,
+		"yamlMarshal":   prd.YAMLMarshal,
+		"yamlUnmarshal": prd.YAMLUnmarshal,
+		"sha256":        prd.SHA256,
+		"hmac":          prd.HMAC,
+		"template":      prd.Template,
 	})
 }
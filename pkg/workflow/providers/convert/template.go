@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/types"
+)
+
+// templateFuncMap returns the sprig functions without the ones reading the environment of the controller, the same as
+// helm does
+func templateFuncMap() template.FuncMap {
+	funcs := sprig.TxtFuncMap()
+	delete(funcs, "env")
+	delete(funcs, "expandenv")
+	return funcs
+}
+
+// Template render the go template with the data, the sprig functions are available in the template
+func (h *provider) Template(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	tmpl, err := v.GetString("template")
+	if err != nil {
+		return err
+	}
+	var data interface{}
+	if val, err := v.LookupValue("data"); err == nil {
+		if err := val.UnmarshalTo(&data); err != nil {
+			return err
+		}
+	}
+	t, err := template.New("template").Funcs(templateFuncMap()).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return err
+	}
+	return v.FillObject(buf.String(), "result")
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/oam-dev/kubevela/pkg/cue/model/value"
+)
+
+func TestTemplate(t *testing.T) {
+	testCases := map[string]struct {
+		from      string
+		expected  string
+		expectErr bool
+	}{
+		"render with data": {
+			from: `template: "server={{ .host }}:{{ .port }} name={{ upper .name }}"
+data: {host: "localhost", port: 8080, name: "vela"}`,
+			expected: "server=localhost:8080 name=VELA",
+		},
+		"render without data": {
+			from:     `template: "static"`,
+			expected: "static",
+		},
+		"environment is not readable": {
+			from:      `template: "{{ env \"HOME\" }}{{ expandenv \"$HOME\" }}"`,
+			expectErr: true,
+		},
+		"missing key": {
+			from:      `template: "{{ .missing }}", data: {}`,
+			expectErr: true,
+		},
+	}
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			v, err := value.NewValue(tc.from, nil, "")
+			r.NoError(err)
+			prd := &provider{}
+			err = prd.Template(nil, v, nil)
+			if tc.expectErr {
+				r.Error(err)
+				return
+			}
+			r.NoError(err)
+			result, err := v.GetString("result")
+			r.NoError(err)
+			r.Equal(tc.expected, result)
+		})
+	}
+}
@@ -17,10 +17,18 @@ limitations under the License.
 package kube
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ktypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/jsonpath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
@@ -35,8 +43,16 @@ import (
 const (
 	// ProviderName is provider name for install.
 	ProviderName = "kube"
+
+	waitForStartTimePrefix = "wait_for_start_time"
 )
 
+var patchTypes = map[string]ktypes.PatchType{
+	"merge":     ktypes.MergePatchType,
+	"json":      ktypes.JSONPatchType,
+	"strategic": ktypes.StrategicMergePatchType,
+}
+
 // Dispatcher is a client for apply resources.
 type Dispatcher func(ctx context.Context, cluster string, owner common.ResourceCreatorRole, manifests ...*unstructured.Unstructured) error
 
@@ -180,6 +196,173 @@ func (h *provider) Delete(ctx wfContext.Context, v *value.Value, act types.Actio
 	return nil
 }
 
+// Patch patches the existing CR in cluster with merge, json or strategic merge patch.
+func (h *provider) Patch(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	obj, cluster, err := h.lookupObjectAndCluster(ctx, v)
+	if err != nil {
+		return err
+	}
+	patchType, err := v.GetString("patch", "type")
+	if err != nil {
+		return err
+	}
+	pt, ok := patchTypes[patchType]
+	if !ok {
+		return errors.Errorf("unsupported patch type %s", patchType)
+	}
+	data, err := v.LookupValue("patch", "data")
+	if err != nil {
+		return err
+	}
+	patch, err := data.CueValue().MarshalJSON()
+	if err != nil {
+		return err
+	}
+	patchCtx := multicluster.ContextWithClusterName(context.Background(), cluster)
+	if err := h.cli.Patch(patchCtx, obj, client.RawPatch(pt, patch)); err != nil {
+		return v.FillObject(err.Error(), "err")
+	}
+	return v.FillObject(obj.Object, "value")
+}
+
+// WaitFor waits until the CR in cluster matches the jsonpath or condition, or fails when timeout.
+func (h *provider) WaitFor(ctx wfContext.Context, v *value.Value, act types.Action) error {
+	obj, cluster, err := h.lookupObjectAndCluster(ctx, v)
+	if err != nil {
+		return err
+	}
+	timeoutStr, err := v.GetString("timeout")
+	if err != nil {
+		return err
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return err
+	}
+	target := fmt.Sprintf("%s %s/%s", obj.GetKind(), obj.GetNamespace(), obj.GetName())
+	if cluster != "" {
+		target = fmt.Sprintf("%s in cluster %s", target, cluster)
+	}
+	startTimeKey := []string{waitForStartTimePrefix, cluster, obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName()}
+
+	readCtx := multicluster.ContextWithClusterName(context.Background(), cluster)
+	matched, reason := false, ""
+	if err = h.cli.Get(readCtx, client.ObjectKeyFromObject(obj), obj); err == nil {
+		matched, reason, err = matchWaitCondition(v, obj)
+		if err != nil {
+			return err
+		}
+	} else if !kerrors.IsNotFound(err) {
+		return err
+	} else {
+		reason = "resource not found"
+	}
+	if matched {
+		ctx.DeleteMutableValue(startTimeKey...)
+		if err := v.FillObject(obj.Object, "value"); err != nil {
+			return err
+		}
+		return v.FillObject(true, "matched")
+	}
+
+	now := time.Now()
+	startTime := now
+	if s := ctx.GetMutableValue(startTimeKey...); s != "" {
+		if unix, err := strconv.ParseInt(s, 10, 64); err == nil {
+			startTime = time.Unix(unix, 0)
+		}
+	} else {
+		ctx.SetMutableValue(strconv.FormatInt(now.Unix(), 10), startTimeKey...)
+	}
+	if now.Sub(startTime) > timeout {
+		// clear the start time, so the retries of the step wait for another timeout
+		ctx.DeleteMutableValue(startTimeKey...)
+		return errors.Errorf("timeout waiting for %s after %s: %s", target, timeout, reason)
+	}
+	act.Wait(fmt.Sprintf("waiting for %s: %s", target, reason))
+	return v.FillObject(false, "matched")
+}
+
+// matchWaitCondition checks whether the object matches the jsonpath or the condition in the value.
+func matchWaitCondition(v *value.Value, obj *unstructured.Unstructured) (bool, string, error) {
+	if cond, err := v.LookupValue("condition"); err == nil {
+		expected := struct {
+			Type   string `json:"type"`
+			Status string `json:"status"`
+		}{}
+		if err := cond.UnmarshalTo(&expected); err != nil {
+			return false, "", err
+		}
+		conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+		for _, c := range conditions {
+			m, ok := c.(map[string]interface{})
+			if !ok || m["type"] != expected.Type {
+				continue
+			}
+			if m["status"] == expected.Status {
+				return true, "", nil
+			}
+			return false, fmt.Sprintf("condition %s is %v, expected %s", expected.Type, m["status"], expected.Status), nil
+		}
+		return false, fmt.Sprintf("condition %s not found", expected.Type), nil
+	}
+
+	path, err := v.GetString("jsonPath")
+	if err != nil {
+		return false, "", errors.New("either jsonPath or condition must be specified")
+	}
+	jp := jsonpath.New("waitFor").AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return false, "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := jp.Execute(buf, obj.Object); err != nil {
+		return false, "", err
+	}
+	actual := buf.String()
+	if _, err := v.LookupValue("expected"); err != nil {
+		if actual != "" {
+			return true, "", nil
+		}
+		return false, fmt.Sprintf("%s is empty", path), nil
+	}
+	expected, err := v.GetString("expected")
+	if err != nil {
+		return false, "", err
+	}
+	if actual == expected {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%s is %q, expected %q", path, actual, expected), nil
+}
+
+// lookupObjectAndCluster gets the object and the cluster from the value, the object is in the namespace of the
+// application if its namespace is not specified
+func (h *provider) lookupObjectAndCluster(ctx wfContext.Context, v *value.Value) (*unstructured.Unstructured, string, error) {
+	val, err := v.LookupValue("value")
+	if err != nil {
+		return nil, "", err
+	}
+	obj := new(unstructured.Unstructured)
+	if err := val.UnmarshalTo(obj); err != nil {
+		return nil, "", err
+	}
+	if obj.GetNamespace() == "" {
+		namespace := "default"
+		if ctx != nil {
+			if ref := ctx.StoreRef(); ref != nil && ref.Namespace != "" {
+				namespace = ref.Namespace
+			}
+		}
+		obj.SetNamespace(namespace)
+	}
+	cluster, err := v.GetString("cluster")
+	if err != nil {
+		return nil, "", err
+	}
+	return obj, cluster, nil
+}
+
 // Install register handlers to provider discover.
 func Install(p providers.Providers, cli client.Client, apply Dispatcher, deleter Deleter) {
 	prd := &provider{
@@ -188,9 +371,11 @@ func Install(p providers.Providers, cli client.Client, apply Dispatcher, deleter
 		cli:    cli,
 	}
 	p.Register(ProviderName, map[string]providers.Handler{
-		"apply":  prd.Apply,
-		"read":   prd.Read,
-		"list":   prd.List,
-		"delete": prd.Delete,
+		"apply":   prd.Apply,
+		"read":    prd.Read,
+		"list":    prd.List,
+		"delete":  prd.Delete,
+		"patch":   prd.Patch,
+		"waitFor": prd.WaitFor,
 	})
 }
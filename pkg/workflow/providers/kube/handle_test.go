@@ -46,6 +46,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	"github.com/oam-dev/kubevela/pkg/cue/packages"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/mock"
 )
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
@@ -337,6 +338,180 @@ cluster: ""
 		Expect(errors.IsNotFound(err)).Should(Equal(true))
 	})
 
+	It("patch", func() {
+		p := &provider{cli: k8sClient}
+		ctx, err := newWorkflowContextForTest()
+		Expect(err).ToNot(HaveOccurred())
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "patch-test", Namespace: "default"},
+			Data:       map[string]string{"key": "value"},
+		}
+		Expect(k8sClient.Create(context.Background(), cm)).Should(BeNil())
+
+		v, err := value.NewValue(`
+import "vela/op"
+
+op.#Patch & {
+	value: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "patch-test"
+	}
+	patch: data: {
+		metadata: annotations: "restartedAt": "now"
+		data: key: "patched"
+	}
+}`, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Patch(ctx, v, nil)).Should(BeNil())
+		Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cm), cm)).Should(BeNil())
+		Expect(cm.Annotations["restartedAt"]).Should(Equal("now"))
+		Expect(cm.Data["key"]).Should(Equal("patched"))
+
+		v, err = value.NewValue(`
+import "vela/op"
+
+op.#Patch & {
+	value: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "patch-test"
+	}
+	patch: {
+		type: "json"
+		data: [{op: "remove", path: "/data/key"}]
+	}
+}`, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Patch(ctx, v, nil)).Should(BeNil())
+		Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cm), cm)).Should(BeNil())
+		Expect(cm.Data).Should(BeEmpty())
+
+		v, err = value.NewValue(`
+value: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: "patch-test"
+}
+cluster: ""
+patch: {
+	type: "unknown"
+	data: {}
+}`, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Patch(ctx, v, nil)).ShouldNot(BeNil())
+
+		// the object is looked up in the namespace of the application
+		Expect(k8sClient.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "patch-ns"}})).Should(BeNil())
+		nsCM := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "patch-test", Namespace: "patch-ns"},
+			Data:       map[string]string{"key": "value"},
+		}
+		Expect(k8sClient.Create(context.Background(), nsCM)).Should(BeNil())
+		nsCtx, err := wfContext.NewContext(k8sClient, "patch-ns", "patch-app", "patch-app-uid")
+		Expect(err).ToNot(HaveOccurred())
+		v, err = value.NewValue(`
+import "vela/op"
+
+op.#Patch & {
+	value: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "patch-test"
+	}
+	patch: data: data: key: "patched"
+}`, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(p.Patch(nsCtx, v, nil)).Should(BeNil())
+		Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(nsCM), nsCM)).Should(BeNil())
+		Expect(nsCM.Data["key"]).Should(Equal("patched"))
+	})
+
+	It("wait for", func() {
+		p := &provider{cli: k8sClient}
+		ctx, err := wfContext.NewContext(k8sClient, "default", "wait-for-app", "wait-for-app-uid")
+		Expect(err).ToNot(HaveOccurred())
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "wait-for-test", Namespace: "default"},
+			Data:       map[string]string{"phase": "pending"},
+		}
+		Expect(k8sClient.Create(context.Background(), cm)).Should(BeNil())
+		waitForCue := `
+import "vela/op"
+
+op.#WaitFor & {
+	value: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "wait-for-test"
+	}
+	jsonPath: "{.data.phase}"
+	expected: "ready"
+	timeout:  "1m"
+}
+`
+		v, err := value.NewValue(waitForCue, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		act := &mock.Action{}
+		Expect(p.WaitFor(ctx, v, act)).Should(BeNil())
+		Expect(act.Phase).Should(Equal("Wait"))
+		matched, err := v.GetBool("matched")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matched).Should(BeFalse())
+
+		cm.Data["phase"] = "ready"
+		Expect(k8sClient.Update(context.Background(), cm)).Should(BeNil())
+		v, err = value.NewValue(waitForCue, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		act = &mock.Action{}
+		Expect(p.WaitFor(ctx, v, act)).Should(BeNil())
+		Expect(act.Phase).Should(Equal(""))
+		matched, err = v.GetBool("matched")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matched).Should(BeTrue())
+
+		v, err = value.NewValue(`
+import "vela/op"
+
+op.#WaitFor & {
+	value: {
+		apiVersion: "v1"
+		kind:       "ConfigMap"
+		metadata: name: "wait-for-not-exist"
+	}
+	jsonPath: "{.data.phase}"
+	timeout:  "0s"
+}
+`, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		act = &mock.Action{}
+		Expect(p.WaitFor(ctx, v, act)).Should(BeNil())
+		Expect(act.Phase).Should(Equal("Wait"))
+		time.Sleep(time.Second * 2)
+		Expect(p.WaitFor(ctx, v, act)).ShouldNot(BeNil())
+		// the retry of the step starts a new wait
+		act = &mock.Action{}
+		Expect(p.WaitFor(ctx, v, act)).Should(BeNil())
+		Expect(act.Phase).Should(Equal("Wait"))
+
+		// the object is looked up in the namespace of the application
+		Expect(k8sClient.Create(context.Background(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "wait-for-ns"}})).Should(BeNil())
+		nsCM := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "wait-for-test", Namespace: "wait-for-ns"},
+			Data:       map[string]string{"phase": "ready"},
+		}
+		Expect(k8sClient.Create(context.Background(), nsCM)).Should(BeNil())
+		nsCtx, err := wfContext.NewContext(k8sClient, "wait-for-ns", "wait-for-app", "wait-for-app-uid")
+		Expect(err).ToNot(HaveOccurred())
+		v, err = value.NewValue(waitForCue, nil, "")
+		Expect(err).ToNot(HaveOccurred())
+		act = &mock.Action{}
+		Expect(p.WaitFor(nsCtx, v, act)).Should(BeNil())
+		matched, err = v.GetBool("matched")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(matched).Should(BeTrue())
+	})
+
 	It("test error case", func() {
 		p := &provider{
 			apply: func(ctx context.Context, _ string, _ common.ResourceCreatorRole, manifests ...*unstructured.Unstructured) error {
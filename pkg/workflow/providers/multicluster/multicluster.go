@@ -24,7 +24,6 @@ import (
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
-	"github.com/oam-dev/kubevela/pkg/clustermanager"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/policy/envbinding"
@@ -82,36 +81,11 @@ func (p *provider) MakePlacementDecisions(ctx wfContext.Context, v *value.Value,
 		return errors.Wrapf(err, "failed to parse placement while making placement decision")
 	}
 
-	var namespace, clusterName string
-	// check if namespace selector is valid
-	if placement.NamespaceSelector != nil {
-		if len(placement.NamespaceSelector.Labels) != 0 {
-			return errors.Errorf("invalid env %s: namespace selector in cluster-gateway does not support label selector for now", env)
-		}
-		namespace = placement.NamespaceSelector.Name
-	}
-	// check if cluster selector is valid
-	if placement.ClusterSelector != nil {
-		if len(placement.ClusterSelector.Labels) != 0 {
-			return errors.Errorf("invalid env %s: cluster selector does not support label selector for now", env)
-		}
-		clusterName = placement.ClusterSelector.Name
-	}
-	// set fallback cluster
-	if clusterName == "" {
-		clusterName = multicluster.ClusterLocalName
-	}
-	// check if target cluster exists
-	if clusterName != multicluster.ClusterLocalName {
-		if err = clustermanager.EnsureClusterExists(p, clusterName); err != nil {
-			return errors.Wrapf(err, "failed to get cluster %s for env %s", clusterName, env)
-		}
+	decisions, err := multicluster.MakePlacementDecisions(context.Background(), p, env, placement)
+	if err != nil {
+		return err
 	}
 	// write result back
-	decisions := []v1alpha1.PlacementDecision{{
-		Cluster:   clusterName,
-		Namespace: namespace,
-	}}
 	if err = envbinding.WritePlacementDecisions(p.app, policy, env, decisions); err != nil {
 		return err
 	}
@@ -29,6 +29,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	common2 "github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/condition"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
@@ -177,7 +178,7 @@ func TestMakePlacementDecisions(t *testing.T) {
 				},
 			},
 		},
-		ExpectError: "cluster selector does not support label selector for now",
+		ExpectError: "no healthy cluster matches the cluster selector",
 	}, {
 		InputVal: map[string]interface{}{
 			"policyName": "example-policy",
@@ -321,6 +322,96 @@ func TestMakePlacementDecisions(t *testing.T) {
 	}
 }
 
+func TestMakePlacementDecisionsByLabels(t *testing.T) {
+	multicluster.ClusterGatewaySecretNamespace = types.DefaultKubeVelaNS
+	r := require.New(t)
+	cli := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	for name, region := range map[string]string{"cluster-a": "east", "cluster-b": "east", "cluster-c": "west"} {
+		r.NoError(cli.Create(context.Background(), &v1.Secret{
+			ObjectMeta: v12.ObjectMeta{
+				Namespace: multicluster.ClusterGatewaySecretNamespace,
+				Name:      name,
+				Labels: map[string]string{
+					v1alpha12.LabelKeyClusterCredentialType: string(v1alpha12.CredentialTypeX509Certificate),
+					"region":                                region,
+				},
+			},
+		}))
+	}
+	unhealthy := &v1.Secret{
+		ObjectMeta: v12.ObjectMeta{
+			Namespace: multicluster.ClusterGatewaySecretNamespace,
+			Name:      "cluster-d",
+			Labels: map[string]string{
+				v1alpha12.LabelKeyClusterCredentialType: string(v1alpha12.CredentialTypeX509Certificate),
+				"region":                                "east",
+			},
+		},
+	}
+	r.NoError(multicluster.SetClusterHealthStatus(unhealthy, &multicluster.ClusterHealthStatus{
+		ConditionedStatus: condition.ConditionedStatus{Conditions: []condition.Condition{{
+			Type:   multicluster.ClusterConditionReachable,
+			Status: v1.ConditionFalse,
+		}}},
+	}))
+	r.NoError(cli.Create(context.Background(), unhealthy))
+	testCases := map[string]struct {
+		Selector       map[string]interface{}
+		ExpectClusters []string
+		ExpectError    string
+	}{
+		"select-by-labels": {
+			Selector:       map[string]interface{}{"labels": map[string]string{"region": "east"}},
+			ExpectClusters: []string{"cluster-a", "cluster-b"},
+		},
+		"select-by-labels-and-name": {
+			Selector:       map[string]interface{}{"name": "cluster-b", "labels": map[string]string{"region": "east"}},
+			ExpectClusters: []string{"cluster-b"},
+		},
+		"unhealthy-cluster": {
+			Selector:    map[string]interface{}{"name": "cluster-d"},
+			ExpectError: "cluster cluster-d for env example-env is unhealthy",
+		},
+		"no-match": {
+			Selector:    map[string]interface{}{"name": "cluster-c", "labels": map[string]string{"region": "east"}},
+			ExpectError: "no healthy cluster matches the cluster selector",
+		},
+	}
+	for name, testCase := range testCases {
+		t.Run(name, func(t *testing.T) {
+			r := require.New(t)
+			p := &provider{Client: cli, app: &v1beta1.Application{}}
+			v, err := value.NewValue("", nil, "")
+			r.NoError(err)
+			r.NoError(v.FillObject(map[string]interface{}{
+				"policyName": "example-policy",
+				"envName":    "example-env",
+				"placement": map[string]interface{}{
+					"clusterSelector":   testCase.Selector,
+					"namespaceSelector": map[string]interface{}{"name": "example-namespace"},
+				},
+			}, "inputs"))
+			err = p.MakePlacementDecisions(nil, v, &mock.Action{})
+			if testCase.ExpectError != "" {
+				r.Error(err)
+				r.Contains(err.Error(), testCase.ExpectError)
+				return
+			}
+			r.NoError(err)
+			outputs, err := v.LookupValue("outputs")
+			r.NoError(err)
+			md := map[string][]v1alpha1.PlacementDecision{}
+			r.NoError(outputs.UnmarshalTo(&md))
+			var clusters []string
+			for _, decision := range md["decisions"] {
+				r.Equal("example-namespace", decision.Namespace)
+				clusters = append(clusters, decision.Cluster)
+			}
+			r.Equal(testCase.ExpectClusters, clusters)
+		})
+	}
+}
+
 func TestPatchApplication(t *testing.T) {
 	baseApp := &v1beta1.Application{Spec: v1beta1.ApplicationSpec{
 		Components: []common2.ApplicationComponent{{
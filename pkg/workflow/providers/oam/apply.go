@@ -17,6 +17,7 @@ limitations under the License.
 package oam
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 
@@ -39,11 +40,11 @@ const (
 	ProviderName = "oam"
 )
 
-// ComponentApply apply oam component.
-type ComponentApply func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error)
+// ComponentApply apply oam component, the context carries the span of the step applying it.
+type ComponentApply func(ctx context.Context, comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, bool, error)
 
-// ComponentRender render oam component.
-type ComponentRender func(comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, error)
+// ComponentRender render oam component, the context carries the span of the step rendering it.
+type ComponentRender func(ctx context.Context, comp common.ApplicationComponent, patcher *value.Value, clusterName string, overrideNamespace string, env string) (*unstructured.Unstructured, []*unstructured.Unstructured, error)
 
 type provider struct {
 	render ComponentRender
@@ -57,7 +58,7 @@ func (p *provider) RenderComponent(ctx wfContext.Context, v *value.Value, act wf
 	if err != nil {
 		return err
 	}
-	workload, traits, err := p.render(*comp, patcher, clusterName, overrideNamespace, env)
+	workload, traits, err := p.render(stepContext(act), *comp, patcher, clusterName, overrideNamespace, env)
 	if err != nil {
 		return err
 	}
@@ -86,7 +87,7 @@ func (p *provider) ApplyComponent(ctx wfContext.Context, v *value.Value, act wfT
 	if err != nil {
 		return err
 	}
-	workload, traits, healthy, err := p.apply(*comp, patcher, clusterName, overrideNamespace, env)
+	workload, traits, healthy, err := p.apply(stepContext(act), *comp, patcher, clusterName, overrideNamespace, env)
 	if err != nil {
 		return err
 	}
@@ -113,6 +114,14 @@ func (p *provider) ApplyComponent(ctx wfContext.Context, v *value.Value, act wfT
 	return nil
 }
 
+// stepContext returns the context of the step running the action, so that the component is traced within the step.
+func stepContext(act wfTypes.Action) context.Context {
+	if traced, ok := act.(wfTypes.TracedAction); ok {
+		return traced.GetContext()
+	}
+	return context.Background()
+}
+
 func lookUpValues(v *value.Value) (*common.ApplicationComponent, *value.Value, string, string, string, error) {
 	compSettings, err := v.LookupValue("value")
 	if err != nil {
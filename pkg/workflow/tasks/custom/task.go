@@ -275,6 +275,14 @@ func (exec *executor) Wait(message string) {
 	exec.wfStatus.Message = message
 }
 
+// GetContext returns the context carrying the span of the step.
+func (exec *executor) GetContext() context.Context {
+	if exec.tracer == nil {
+		return context.Background()
+	}
+	return exec.tracer.GetContext()
+}
+
 func (exec *executor) err(ctx wfContext.Context, err error, reason string) {
 	exec.wait = true
 	exec.wfStatus.Phase = common.WorkflowStepPhaseFailed
@@ -29,6 +29,7 @@ import (
 	"github.com/oam-dev/kubevela/pkg/velaql/providers/query"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers"
+	"github.com/oam-dev/kubevela/pkg/workflow/providers/config"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/convert"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/email"
 	"github.com/oam-dev/kubevela/pkg/workflow/providers/http"
@@ -78,6 +79,7 @@ func NewTaskDiscover(providerHandlers providers.Providers, pd *packages.PackageD
 	workspace.Install(providerHandlers)
 	convert.Install(providerHandlers)
 	email.Install(providerHandlers)
+	config.Install(providerHandlers, cli)
 	templateLoader := template.NewWorkflowStepTemplateLoader(cli, dm)
 	return &taskDiscover{
 		builtins: map[string]types.TaskGenerator{
@@ -124,6 +126,7 @@ func NewViewTaskDiscover(pd *packages.PackageDiscover, cli client.Client, apply
 	http.Install(handlerProviders, cli, viewNs)
 	convert.Install(handlerProviders)
 	email.Install(handlerProviders)
+	config.InstallReadOnly(handlerProviders, cli)
 
 	templateLoader := template.NewViewTemplateLoader(cli, viewNs)
 	return &taskDiscover{
@@ -78,6 +78,12 @@ type Action interface {
 	Wait(message string)
 }
 
+// TracedAction is the Action of a step that is traced, the span of the step is carried by the context.
+type TracedAction interface {
+	Action
+	GetContext() context.Context
+}
+
 const (
 	// ContextKeyMetadata is key that refer to application metadata.
 	ContextKeyMetadata = "metadata__"
@@ -163,14 +163,17 @@ func (w *workflow) ExecuteSteps(ctx monitorContext.Context, appRev *oamcore.Appl
 	e.checkWorkflowStatusMessage(wfStatus)
 	if wfStatus.Terminated {
 		w.CleanupCountersInContext(ctx)
+		metrics.WorkflowRunCounter.WithLabelValues(string(common.WorkflowStateTerminated)).Inc()
 		return common.WorkflowStateTerminated, nil
 	}
 	if wfStatus.Suspend {
 		w.CleanupCountersInContext(ctx)
+		metrics.WorkflowRunCounter.WithLabelValues(string(common.WorkflowStateSuspended)).Inc()
 		return common.WorkflowStateSuspended, nil
 	}
 	if w.allDone(taskRunners) {
 		wfStatus.Message = string(common.WorkflowStateSucceeded)
+		metrics.WorkflowRunCounter.WithLabelValues(string(common.WorkflowStateSucceeded)).Inc()
 		return common.WorkflowStateSucceeded, nil
 	}
 	wfStatus.Message = string(common.WorkflowStateExecuting)
@@ -194,11 +197,11 @@ func (w *workflow) CleanupCountersInContext(ctx monitorContext.Context) {
 			strings.HasPrefix(k, wfTypes.ContextPrefixBackoffTimes) ||
 			strings.HasPrefix(k, wfTypes.ContextKeyLastExecuteTime) ||
 			strings.HasPrefix(k, wfTypes.ContextKeyNextExecuteTime) {
-			delete(ctxCM.Data, k)
+			w.wfCtx.DeleteMutableValue(k)
 		}
 	}
 
-	if err := w.cli.Update(ctx, ctxCM); err != nil {
+	if err := w.wfCtx.Commit(); err != nil {
 		ctx.Error(err, "failed to update workflow context", "application", w.app.Name, "config map", ctxCM.Name)
 	}
 
@@ -439,9 +442,11 @@ func (e *engine) steps(taskRunners []wfTypes.TaskRunner) error {
 	for _, runner := range taskRunners {
 		status, operation, err := runner.Run(wfCtx, &wfTypes.TaskRunOptions{
 			GetTracer: func(id string, stepStatus oamcore.WorkflowStep) monitorContext.Context {
-				return e.monitorCtx.Fork(id, monitorContext.DurationMetric(func(v float64) {
+				stepCtx := e.monitorCtx.Fork(id, monitorContext.DurationMetric(func(v float64) {
 					metrics.StepDurationSummary.WithLabelValues(e.app.Namespace+"/"+e.app.Name, e.status.AppRevision, stepStatus.Name, stepStatus.Type).Observe(v)
 				}))
+				monitorContext.SetSpanName(stepCtx, "step "+stepStatus.Name)
+				return stepCtx
 			},
 		})
 		if err != nil {
@@ -505,8 +510,10 @@ func (e *engine) updateStepStatus(status common.WorkflowStepStatus) {
 
 	e.wfCtx.SetMutableValue(strconv.FormatInt(now.Unix(), 10), wfTypes.ContextKeyLastExecuteTime)
 	status.LastExecuteTime = now
+	var lastPhase common.WorkflowStepPhase
 	for i := range e.status.Steps {
 		if e.status.Steps[i].Name == status.Name {
+			lastPhase = e.status.Steps[i].Phase
 			status.FirstExecuteTime = e.status.Steps[i].FirstExecuteTime
 			e.status.Steps[i] = status
 			conditionUpdated = true
@@ -517,6 +524,19 @@ func (e *engine) updateStepStatus(status common.WorkflowStepStatus) {
 		status.FirstExecuteTime = now
 		e.status.Steps = append(e.status.Steps, status)
 	}
+	// the step is counted once it ends up with a phase, the repeated updates of the same phase are not counted
+	if isStepPhaseTerminal(status.Phase) && status.Phase != lastPhase {
+		metrics.StepPhaseCounter.WithLabelValues(status.Type, string(status.Phase)).Inc()
+	}
+}
+
+func isStepPhaseTerminal(phase common.WorkflowStepPhase) bool {
+	switch phase {
+	case common.WorkflowStepPhaseSucceeded, common.WorkflowStepPhaseFailed, common.WorkflowStepPhaseStopped:
+		return true
+	default:
+		return false
+	}
 }
 
 func (e *engine) checkFailedAfterRetries() {
@@ -26,6 +26,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -35,6 +36,7 @@ import (
 	oamcore "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/pkg/cue/model/value"
 	monitorContext "github.com/oam-dev/kubevela/pkg/monitor/context"
+	"github.com/oam-dev/kubevela/pkg/monitor/metrics"
 	wfContext "github.com/oam-dev/kubevela/pkg/workflow/context"
 	wfTypes "github.com/oam-dev/kubevela/pkg/workflow/types"
 )
@@ -551,6 +553,24 @@ var _ = Describe("Test Workflow", func() {
 		Expect(err).ToNot(HaveOccurred())
 		Expect(saved).Should(BeEquivalentTo(true))
 	})
+
+	It("count the steps once they end up with a phase", func() {
+		wfCtx, err := wfContext.NewContext(k8sClient, "default", "app-step-phase", "app-step-phase-uid")
+		Expect(err).ToNot(HaveOccurred())
+		e := &engine{status: &common.WorkflowStatus{}, wfCtx: wfCtx}
+		succeeded := metrics.StepPhaseCounter.WithLabelValues("count-phase", string(common.WorkflowStepPhaseSucceeded))
+		before := testutil.ToFloat64(succeeded)
+		for _, phase := range []common.WorkflowStepPhase{
+			common.WorkflowStepPhaseRunning,
+			common.WorkflowStepPhaseRunning,
+			common.WorkflowStepPhaseSucceeded,
+			common.WorkflowStepPhaseSucceeded,
+		} {
+			e.updateStepStatus(common.WorkflowStepStatus{Name: "s1", Type: "count-phase", Phase: phase})
+		}
+		Expect(testutil.ToFloat64(succeeded) - before).Should(BeEquivalentTo(1))
+		Expect(testutil.ToFloat64(metrics.StepPhaseCounter.WithLabelValues("count-phase", string(common.WorkflowStepPhaseRunning)))).Should(BeEquivalentTo(0))
+	})
 })
 
 func makeTestCase(steps []oamcore.WorkflowStep) (*oamcore.Application, []wfTypes.TaskRunner) {
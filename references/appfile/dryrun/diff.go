@@ -37,7 +37,7 @@ import (
 // NewLiveDiffOption creates a live-diff option
 func NewLiveDiffOption(c client.Client, dm discoverymapper.DiscoveryMapper, pd *packages.PackageDiscover, as []oam.Object) *LiveDiffOption {
 	parser := appfile.NewApplicationParser(c, dm, pd)
-	return &LiveDiffOption{DryRun: NewDryRunOption(c, dm, pd, as), Parser: parser}
+	return &LiveDiffOption{DryRun: NewDryRunOption(c, dm, pd, as), Parser: parser, Client: c}
 }
 
 // ManifestKind enums the kind of OAM objects
@@ -88,6 +88,8 @@ type manifest struct {
 type LiveDiffOption struct {
 	DryRun
 	Parser *appfile.Parser
+	// Client is used to get the live objects in the clusters
+	Client client.Client
 }
 
 // Diff does three phases, dry-run on input app, preparing manifest for diff, and
@@ -35,6 +35,7 @@ import (
 // DryRun executes dry-run on an application
 type DryRun interface {
 	ExecuteDryRun(ctx context.Context, app *v1beta1.Application) ([]*types.ComponentManifest, error)
+	ExecuteMultiClusterDryRun(ctx context.Context, app *v1beta1.Application, envName, clusterName string) ([]*PlacementManifests, error)
 }
 
 // NewDryRunOption creates a dry-run option
@@ -0,0 +1,750 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/appfile"
+	velacue "github.com/oam-dev/kubevela/pkg/cue"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/policy/envbinding"
+)
+
+const (
+	// ExportFormatHelm exports the application as a helm chart
+	ExportFormatHelm = "helm"
+	// ExportFormatKustomize exports the application as a kustomize base and the overlays of the envs
+	ExportFormatKustomize = "kustomize"
+)
+
+// ExportedFile is a file of the exported helm chart or kustomize directories, the path is relative to the output
+// directory
+type ExportedFile struct {
+	Path    string
+	Content []byte
+}
+
+// envResources contains the resources rendered for an env in the env-binding policy
+type envResources struct {
+	Name      string
+	Namespace string
+	// Components are the resources of the components indexed by the component name, the first one is the workload
+	Components map[string][]*unstructured.Unstructured
+}
+
+// exposedValue is a parameter of a component exposed in the values of the helm chart
+type exposedValue struct {
+	Component string
+	Parameter types.Parameter
+	Value     interface{}
+	// Paths are the fields of the component resources set by the parameter, indexed by the resource
+	Paths map[int][]fieldPath
+}
+
+// fieldPath is the path of a field in an unstructured object, the elements are the keys of maps or indexes of lists
+type fieldPath []interface{}
+
+type chartMetadata struct {
+	APIVersion  string `json:"apiVersion"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Version     string `json:"version"`
+	AppVersion  string `json:"appVersion,omitempty"`
+}
+
+type kustomization struct {
+	APIVersion string           `json:"apiVersion"`
+	Kind       string           `json:"kind"`
+	Namespace  string           `json:"namespace,omitempty"`
+	Resources  []string         `json:"resources"`
+	Patches    []kustomizePatch `json:"patches,omitempty"`
+}
+
+// kustomizePatch is a patch file of the kustomization, the file is either a JSON 6902 patch of the target, or a patch
+// deleting a resource of the base without a target
+type kustomizePatch struct {
+	Path   string       `json:"path"`
+	Target *patchTarget `json:"target,omitempty"`
+}
+
+type patchTarget struct {
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	Name    string `json:"name"`
+}
+
+// resourcePatch contains the JSON 6902 operations turning a resource of the base into the one rendered for an env
+type resourcePatch struct {
+	Resource   *unstructured.Unstructured
+	Operations []patchOperation
+}
+
+// patchOperation is an operation of a JSON 6902 patch
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// ExportHelmChart renders the components and traits of the application into a helm chart. The string, int and bool
+// parameters of the components which are rendered into the resources as they are, are exposed in values.yaml by the
+// component name with the descriptions in the CUE schema of the component definitions. If the application has an
+// env-binding policy, the values of each env are exported into values-<env>.yaml, the envs which patch the resources
+// beyond the exposed parameters can not be expressed by the chart and should be exported with kustomize.
+func (d *Option) ExportHelmChart(ctx context.Context, app *v1beta1.Application) ([]*ExportedFile, error) {
+	base := withoutEnvBinding(app)
+	comps, err := d.ExecuteDryRun(ctx, base.DeepCopy())
+	if err != nil {
+		return nil, err
+	}
+	baseResources := exportedComponents(comps)
+	values, err := d.exposeValues(ctx, base, baseResources)
+	if err != nil {
+		return nil, err
+	}
+	envs, err := d.renderEnvs(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := chartMetadata{
+		APIVersion:  "v2",
+		Name:        app.Name,
+		Description: fmt.Sprintf("A Helm chart exported from the KubeVela application %s", app.Name),
+		Type:        "application",
+		Version:     "0.1.0",
+		AppVersion:  app.GetAnnotations()[oam.AnnotationPublishVersion],
+	}
+	chart, err := yaml.Marshal(metadata)
+	if err != nil {
+		return nil, err
+	}
+	files := []*ExportedFile{{Path: "Chart.yaml", Content: chart}}
+
+	valuesFile, err := writeValues(fmt.Sprintf("# Default values of the components of the application %s.\n", app.Name), values, nil)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, &ExportedFile{Path: "values.yaml", Content: valuesFile})
+	for _, env := range envs {
+		envValues, err := envValuesOf(env, baseResources, values)
+		if err != nil {
+			return nil, err
+		}
+		header := fmt.Sprintf("# Values of the env %s, install with `helm install %s <chart> -f values-%s.yaml", env.Name, app.Name, env.Name)
+		if env.Namespace != "" {
+			header += " -n " + env.Namespace
+		}
+		content, err := writeValues(header+"`.\n", values, envValues)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &ExportedFile{Path: fmt.Sprintf("values-%s.yaml", env.Name), Content: content})
+	}
+
+	tokens := map[string]string{}
+	for _, comp := range comps {
+		resources := make([]*unstructured.Unstructured, len(baseResources[comp.Name]))
+		for i, res := range baseResources[comp.Name] {
+			resources[i] = res.DeepCopy()
+		}
+		for _, v := range values {
+			if v.Component != comp.Name {
+				continue
+			}
+			token := fmt.Sprintf("__VELA_VALUE_%d__", len(tokens))
+			tokens[token] = fmt.Sprintf("{{ index .Values %q %q }}", v.Component, v.Parameter.Name)
+			if v.Parameter.Type == cue.StringKind {
+				tokens[token] = fmt.Sprintf("{{ index .Values %q %q | quote }}", v.Component, v.Parameter.Name)
+			}
+			for i, paths := range v.Paths {
+				for _, path := range paths {
+					setField(resources[i].Object, path, token)
+				}
+			}
+		}
+		data, err := marshalResources(resources)
+		if err != nil {
+			return nil, err
+		}
+		// escape the delimiters of go templates in the resources, since they are not templates of the chart
+		content := strings.ReplaceAll(string(data), "{{", `{{"{{"}}`)
+		for token, expr := range tokens {
+			content = strings.ReplaceAll(content, token, expr)
+		}
+		files = append(files, &ExportedFile{Path: filepath.Join("templates", comp.Name+".yaml"), Content: []byte(content)})
+	}
+	return files, nil
+}
+
+// ExportKustomize renders the components and traits of the application into a kustomize base. If the application has
+// an env-binding policy, an overlay is exported for each env, which moves the resources into the namespace of the env
+// placement and patches the resources of the base into the ones rendered for the env. The patches are JSON 6902 patches
+// with the semantics of JSON merge patches, the lists are replaced as a whole rather than merged by their keys.
+func (d *Option) ExportKustomize(ctx context.Context, app *v1beta1.Application) ([]*ExportedFile, error) {
+	comps, err := d.ExecuteDryRun(ctx, withoutEnvBinding(app))
+	if err != nil {
+		return nil, err
+	}
+	baseResources := exportedComponents(comps)
+	envs, err := d.renderEnvs(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ExportedFile
+	base := kustomization{APIVersion: "kustomize.config.k8s.io/v1beta1", Kind: "Kustomization"}
+	baseIndex := map[string]*unstructured.Unstructured{}
+	for _, comp := range comps {
+		data, err := marshalResources(baseResources[comp.Name])
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &ExportedFile{Path: filepath.Join("base", comp.Name+".yaml"), Content: data})
+		base.Resources = append(base.Resources, comp.Name+".yaml")
+		for _, res := range baseResources[comp.Name] {
+			baseIndex[resourceKey(res)] = res
+		}
+	}
+	content, err := yaml.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	files = append(files, &ExportedFile{Path: filepath.Join("base", "kustomization.yaml"), Content: content})
+
+	for _, env := range envs {
+		dir := filepath.Join("overlays", env.Name)
+		overlay := kustomization{
+			APIVersion: "kustomize.config.k8s.io/v1beta1",
+			Kind:       "Kustomization",
+			Namespace:  env.Namespace,
+			Resources:  []string{"../../base"},
+		}
+		var patches []resourcePatch
+		rendered := map[string]bool{}
+		for _, comp := range comps {
+			// the components are kept in the same order as the base, the ones added by the env come after them
+			if _, ok := env.Components[comp.Name]; !ok {
+				continue
+			}
+			added, compPatches, err := overlayResources(env.Components[comp.Name], baseIndex, rendered)
+			if err != nil {
+				return nil, errors.WithMessagef(err, "cannot make overlay for component %s of env %s", comp.Name, env.Name)
+			}
+			patches = append(patches, compPatches...)
+			if len(added) != 0 {
+				data, err := marshalResources(added)
+				if err != nil {
+					return nil, err
+				}
+				files = append(files, &ExportedFile{Path: filepath.Join(dir, comp.Name+".yaml"), Content: data})
+				overlay.Resources = append(overlay.Resources, comp.Name+".yaml")
+			}
+		}
+		for _, name := range sortedComponentNames(env.Components) {
+			if _, ok := baseResources[name]; ok {
+				continue
+			}
+			data, err := marshalResources(env.Components[name])
+			if err != nil {
+				return nil, err
+			}
+			for _, res := range env.Components[name] {
+				rendered[resourceKey(res)] = true
+			}
+			files = append(files, &ExportedFile{Path: filepath.Join(dir, name+".yaml"), Content: data})
+			overlay.Resources = append(overlay.Resources, name+".yaml")
+		}
+		for _, patch := range patches {
+			data, err := yaml.Marshal(patch.Operations)
+			if err != nil {
+				return nil, err
+			}
+			res := patch.Resource
+			path := patchPath(res)
+			files = append(files, &ExportedFile{Path: filepath.Join(dir, path), Content: data})
+			gv := res.GroupVersionKind()
+			overlay.Patches = append(overlay.Patches, kustomizePatch{
+				Path:   path,
+				Target: &patchTarget{Group: gv.Group, Version: gv.Version, Kind: gv.Kind, Name: res.GetName()},
+			})
+		}
+		// the resources of the base which are not rendered for the env are deleted by the overlay
+		for _, comp := range comps {
+			for _, res := range baseResources[comp.Name] {
+				if rendered[resourceKey(res)] {
+					continue
+				}
+				patch := &unstructured.Unstructured{Object: map[string]interface{}{"$patch": "delete"}}
+				patch.SetAPIVersion(res.GetAPIVersion())
+				patch.SetKind(res.GetKind())
+				patch.SetName(res.GetName())
+				data, err := yaml.Marshal(patch.Object)
+				if err != nil {
+					return nil, err
+				}
+				path := patchPath(res)
+				files = append(files, &ExportedFile{Path: filepath.Join(dir, path), Content: data})
+				overlay.Patches = append(overlay.Patches, kustomizePatch{Path: path})
+			}
+		}
+		content, err := yaml.Marshal(overlay)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &ExportedFile{Path: filepath.Join(dir, "kustomization.yaml"), Content: content})
+	}
+	return files, nil
+}
+
+// overlayResources splits the resources rendered for an env into the ones not in the base and the patches to the base
+func overlayResources(resources []*unstructured.Unstructured, baseIndex map[string]*unstructured.Unstructured, rendered map[string]bool) ([]*unstructured.Unstructured, []resourcePatch, error) {
+	var added []*unstructured.Unstructured
+	var patches []resourcePatch
+	for _, res := range resources {
+		key := resourceKey(res)
+		rendered[key] = true
+		baseRes, ok := baseIndex[key]
+		if !ok {
+			added = append(added, res)
+			continue
+		}
+		if ops := createPatch(baseRes.Object, res.Object, ""); len(ops) != 0 {
+			patches = append(patches, resourcePatch{Resource: res, Operations: ops})
+		}
+	}
+	return added, patches, nil
+}
+
+// createPatch creates the JSON 6902 operations turning the base object into the target one. The maps are patched by
+// their fields, the other values including the lists are replaced as a whole, the same as JSON merge patches.
+func createPatch(base, target map[string]interface{}, path string) []patchOperation {
+	var keys []string
+	for k := range base {
+		keys = append(keys, k)
+	}
+	for k := range target {
+		if _, ok := base[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	var ops []patchOperation
+	for _, k := range keys {
+		p := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+		baseValue, inBase := base[k]
+		targetValue, inTarget := target[k]
+		switch {
+		case !inTarget || targetValue == nil:
+			if inBase {
+				ops = append(ops, patchOperation{Op: "remove", Path: p})
+			}
+		case !inBase:
+			ops = append(ops, patchOperation{Op: "add", Path: p, Value: targetValue})
+		default:
+			baseMap, baseIsMap := baseValue.(map[string]interface{})
+			targetMap, targetIsMap := targetValue.(map[string]interface{})
+			if baseIsMap && targetIsMap {
+				ops = append(ops, createPatch(baseMap, targetMap, p)...)
+			} else if !reflect.DeepEqual(baseValue, targetValue) {
+				ops = append(ops, patchOperation{Op: "replace", Path: p, Value: targetValue})
+			}
+		}
+	}
+	return ops
+}
+
+// patchPath is the path of the patch file of the resource in the overlay
+func patchPath(res *unstructured.Unstructured) string {
+	return filepath.Join("patches", fmt.Sprintf("%s-%s.yaml", strings.ToLower(res.GetKind()), res.GetName()))
+}
+
+// renderEnvs renders the application patched by each env in its env-binding policy
+func (d *Option) renderEnvs(ctx context.Context, app *v1beta1.Application) ([]*envResources, error) {
+	policy, err := envbinding.GetEnvBindingPolicy(app, "")
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot parse env-binding policy")
+	}
+	if policy == nil {
+		return nil, nil
+	}
+	var envs []*envResources
+	for _, env := range policy.Envs {
+		patchedApp, err := envbinding.PatchApplication(app, env.Patch.DeepCopy(), env.Selector)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot patch application for env %s", env.Name)
+		}
+		comps, err := d.ExecuteDryRun(ctx, withoutEnvBinding(patchedApp))
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot dry-run for env %s", env.Name)
+		}
+		result := &envResources{Name: env.Name, Components: exportedComponents(comps)}
+		if env.Placement.NamespaceSelector != nil {
+			result.Namespace = env.Placement.NamespaceSelector.Name
+		}
+		envs = append(envs, result)
+	}
+	return envs, nil
+}
+
+// exposeValues finds the string, int and bool parameters of the components which are rendered into the resources as
+// they are. The application is rendered twice with each parameter set to two different markers, the fields equal to
+// the markers in both renderings are set by the parameter. The parameters failing to render with the markers, like
+// the ones with enums or ranges, are not exposed.
+func (d *Option) exposeValues(ctx context.Context, app *v1beta1.Application, resources map[string][]*unstructured.Unstructured) ([]*exposedValue, error) {
+	parser := appfile.NewDryRunApplicationParser(d.Client, d.DiscoveryMapper, d.PackageDiscover, d.Auxiliaries)
+	if app.Namespace != "" {
+		ctx = oamutil.SetNamespaceInCtx(ctx, app.Namespace)
+	}
+	appFile, err := parser.GenerateAppFile(ctx, app.DeepCopy())
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot generate appFile from application")
+	}
+	var values []*exposedValue
+	for _, wl := range appFile.Workloads {
+		if wl.FullTemplate == nil || wl.FullTemplate.TemplateStr == "" {
+			continue
+		}
+		// the templates importing the kube packages can not be parsed without the package discover, they are
+		// exported as they are rendered
+		params, err := velacue.GetParameters(wl.FullTemplate.TemplateStr)
+		if err != nil {
+			continue
+		}
+		for _, param := range params {
+			if param.Ignore {
+				continue
+			}
+			markers := valueMarkers(wl.Name, param)
+			if markers == nil {
+				continue
+			}
+			var rendered [2][]*unstructured.Unstructured
+			for i, marker := range markers {
+				markedApp, err := setComponentProperty(app, wl.Name, param.Name, marker)
+				if err != nil {
+					return nil, err
+				}
+				if comps, err := d.ExecuteDryRun(ctx, markedApp); err == nil {
+					rendered[i] = exportedComponents(comps)[wl.Name]
+				}
+			}
+			if len(rendered[0]) != len(resources[wl.Name]) || len(rendered[1]) != len(resources[wl.Name]) {
+				continue
+			}
+			v := &exposedValue{Component: wl.Name, Parameter: param, Value: param.Default, Paths: map[int][]fieldPath{}}
+			if val, ok := wl.Params[param.Name]; ok {
+				v.Value = val
+			}
+			for i, res := range rendered[0] {
+				var paths []fieldPath
+				for _, path := range findFields(res.Object, markers[0], nil) {
+					if val, ok := getField(rendered[1][i].Object, path); ok && equalValue(val, markers[1]) {
+						paths = append(paths, path)
+					}
+				}
+				if len(paths) == 0 {
+					continue
+				}
+				v.Paths[i] = paths
+				// the value in the resources is used, so that it has the same type as the values of the envs
+				if val, ok := getField(resources[wl.Name][i].Object, paths[0]); ok {
+					v.Value = val
+				}
+			}
+			if len(v.Paths) != 0 {
+				values = append(values, v)
+			}
+		}
+	}
+	return values, nil
+}
+
+// valueMarkers returns the two markers of the parameter, nil if the parameter can not be exposed
+func valueMarkers(component string, param types.Parameter) []interface{} {
+	switch param.Type {
+	case cue.StringKind:
+		return []interface{}{fmt.Sprintf("vela-export-%s-%s-0", component, param.Name), fmt.Sprintf("vela-export-%s-%s-1", component, param.Name)}
+	case cue.IntKind:
+		return []interface{}{30001, 30002}
+	case cue.BoolKind:
+		return []interface{}{true, false}
+	default:
+		return nil
+	}
+}
+
+// envValuesOf gets the values of the env from the resources rendered for the env, and checks that the env only
+// differs from the base by the exposed values
+func envValuesOf(env *envResources, baseResources map[string][]*unstructured.Unstructured, values []*exposedValue) (map[*exposedValue]interface{}, error) {
+	envValues := map[*exposedValue]interface{}{}
+	for _, v := range values {
+		resources := env.Components[v.Component]
+		for i, paths := range v.Paths {
+			if i >= len(resources) {
+				continue
+			}
+			if val, ok := getField(resources[i].Object, paths[0]); ok {
+				envValues[v] = val
+				break
+			}
+		}
+	}
+	errResources := errors.Errorf("env %s patches the resources beyond the parameters exposed in the values, please export the application with kustomize", env.Name)
+	if len(env.Components) != len(baseResources) {
+		return nil, errResources
+	}
+	for name, resources := range baseResources {
+		expected := make([]*unstructured.Unstructured, len(resources))
+		for i, res := range resources {
+			expected[i] = res.DeepCopy()
+		}
+		for _, v := range values {
+			if v.Component != name {
+				continue
+			}
+			for i, paths := range v.Paths {
+				for _, path := range paths {
+					setField(expected[i].Object, path, envValues[v])
+				}
+			}
+		}
+		actual := env.Components[name]
+		if len(actual) != len(expected) {
+			return nil, errResources
+		}
+		for i := range expected {
+			if !reflect.DeepEqual(expected[i].Object, actual[i].Object) {
+				return nil, errResources
+			}
+		}
+	}
+	return envValues, nil
+}
+
+// writeValues writes the exposed values grouped by the components with the usages of the parameters, if the
+// overrides is not nil, only the values overridden are written
+func writeValues(header string, values []*exposedValue, overrides map[*exposedValue]interface{}) ([]byte, error) {
+	buff := bytes.NewBufferString(header)
+	component := ""
+	for _, v := range values {
+		val := v.Value
+		if overrides != nil {
+			override, ok := overrides[v]
+			if !ok || reflect.DeepEqual(override, v.Value) {
+				continue
+			}
+			val = override
+		}
+		if v.Component != component {
+			component = v.Component
+			buff.WriteString(component + ":\n")
+		}
+		if v.Parameter.Usage != "" {
+			buff.WriteString(fmt.Sprintf("  # %s\n", v.Parameter.Usage))
+		}
+		data, err := yaml.Marshal(map[string]interface{}{v.Parameter.Name: val})
+		if err != nil {
+			return nil, err
+		}
+		buff.WriteString("  " + string(data))
+	}
+	return buff.Bytes(), nil
+}
+
+// setComponentProperty returns a copy of the application with the property of the component set to the value
+func setComponentProperty(app *v1beta1.Application, compName, name string, value interface{}) (*v1beta1.Application, error) {
+	app = app.DeepCopy()
+	for i, comp := range app.Spec.Components {
+		if comp.Name != compName {
+			continue
+		}
+		properties := map[string]interface{}{}
+		if comp.Properties != nil && len(comp.Properties.Raw) != 0 {
+			if err := json.Unmarshal(comp.Properties.Raw, &properties); err != nil {
+				return nil, errors.Wrapf(err, "invalid properties of component %s", compName)
+			}
+		}
+		properties[name] = value
+		data, err := json.Marshal(properties)
+		if err != nil {
+			return nil, err
+		}
+		app.Spec.Components[i].Properties = &runtime.RawExtension{Raw: data}
+	}
+	return app, nil
+}
+
+// withoutEnvBinding returns a copy of the application without the env-binding policies, so only the components are
+// rendered
+func withoutEnvBinding(app *v1beta1.Application) *v1beta1.Application {
+	app = app.DeepCopy()
+	app.Spec.Policies = removeEnvBindingPolicies(app.Spec.Policies)
+	return app
+}
+
+// exportedComponents strips the namespace and the metadata of the revisions and envs set by KubeVela from the rendered
+// resources, so the resources can be installed into any namespace of the clusters without KubeVela
+func exportedComponents(comps []*types.ComponentManifest) map[string][]*unstructured.Unstructured {
+	results := map[string][]*unstructured.Unstructured{}
+	for _, comp := range comps {
+		var resources []*unstructured.Unstructured
+		for _, res := range append([]*unstructured.Unstructured{comp.StandardWorkload}, comp.Traits...) {
+			if res == nil {
+				continue
+			}
+			res = res.DeepCopy()
+			removeRevisionRelatedLabelAndAnnotation(res)
+			labels := res.GetLabels()
+			delete(labels, oam.LabelAppRevision)
+			delete(labels, oam.LabelAppEnv)
+			if len(labels) == 0 {
+				labels = nil
+			}
+			res.SetLabels(labels)
+			if len(res.GetAnnotations()) == 0 {
+				res.SetAnnotations(nil)
+			}
+			res.SetNamespace("")
+			resources = append(resources, res)
+		}
+		results[comp.Name] = resources
+	}
+	return results
+}
+
+func marshalResources(resources []*unstructured.Unstructured) ([]byte, error) {
+	var buff bytes.Buffer
+	for i, res := range resources {
+		if i > 0 {
+			buff.WriteString("---\n")
+		}
+		data, err := yaml.Marshal(res.Object)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot marshal %s %s", res.GetKind(), res.GetName())
+		}
+		buff.Write(data)
+	}
+	return buff.Bytes(), nil
+}
+
+func resourceKey(res *unstructured.Unstructured) string {
+	return fmt.Sprintf("%s/%s/%s", res.GetAPIVersion(), res.GetKind(), res.GetName())
+}
+
+func sortedComponentNames(components map[string][]*unstructured.Unstructured) []string {
+	var names []string
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// findFields finds the paths of the fields equal to the target
+func findFields(obj interface{}, target interface{}, path fieldPath) []fieldPath {
+	var paths []fieldPath
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range o {
+			paths = append(paths, findFields(v, target, append(path[:len(path):len(path)], k))...)
+		}
+	case []interface{}:
+		for i, v := range o {
+			paths = append(paths, findFields(v, target, append(path[:len(path):len(path)], i))...)
+		}
+	default:
+		if equalValue(o, target) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// equalValue compares the values by their JSON encodings, so the numbers of different types are equal
+func equalValue(a, b interface{}) bool {
+	da, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	db, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(da, db)
+}
+
+func getField(obj interface{}, path fieldPath) (interface{}, bool) {
+	for _, elem := range path {
+		switch e := elem.(type) {
+		case string:
+			m, ok := obj.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if obj, ok = m[e]; !ok {
+				return nil, false
+			}
+		case int:
+			l, ok := obj.([]interface{})
+			if !ok || e >= len(l) {
+				return nil, false
+			}
+			obj = l[e]
+		}
+	}
+	return obj, true
+}
+
+func setField(obj interface{}, path fieldPath, value interface{}) {
+	if len(path) == 0 {
+		return
+	}
+	parent, ok := getField(obj, path[:len(path)-1])
+	if !ok {
+		return
+	}
+	switch e := path[len(path)-1].(type) {
+	case string:
+		if m, ok := parent.(map[string]interface{}); ok {
+			m[e] = value
+		}
+	case int:
+		if l, ok := parent.([]interface{}); ok && e < len(l) {
+			l[e] = value
+		}
+	}
+}
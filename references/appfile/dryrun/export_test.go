@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// the component definition with the string, int and bool parameters exposed in the values of the helm chart
+const exportWorkerDef = `apiVersion: core.oam.dev/v1beta1
+kind: ComponentDefinition
+metadata:
+  name: export-worker
+spec:
+  workload:
+    definition:
+      apiVersion: apps/v1
+      kind: Deployment
+  schematic:
+    cue:
+      template: |
+        output: {
+        	apiVersion: "apps/v1"
+        	kind:       "Deployment"
+        	spec: {
+        		replicas: parameter.replicas
+        		selector: matchLabels: "app.oam.dev/component": context.name
+        		template: {
+        			metadata: labels: "app.oam.dev/component": context.name
+        			spec: {
+        				enableServiceLinks: parameter.serviceLinks
+        				containers: [{
+        					name:  context.name
+        					image: parameter.image
+        				}]
+        			}
+        		}
+        	}
+        }
+        parameter: {
+        	// +usage=Which image would you like to use for your service
+        	image: string
+        	// +usage=Number of the replicas
+        	replicas: *1 | int
+        	// +usage=Whether to inject the environment variables of the services
+        	serviceLinks: *true | bool
+        }
+`
+
+const exportApp = `apiVersion: core.oam.dev/v1beta1
+kind: Application
+metadata:
+  name: app-export
+  namespace: default
+spec:
+  components:
+    - name: myweb
+      type: export-worker
+      properties:
+        image: "busybox"
+      traits:
+        - type: simple-service
+          properties:
+            port: 80
+  policies:
+    - name: example-multi-env-policy
+      type: env-binding
+      properties:
+        envs:
+          - name: staging
+            placement:
+              clusterSelector:
+                name: cluster-staging
+          - name: prod
+            placement:
+              clusterSelector:
+                name: cluster-prod
+              namespaceSelector:
+                name: prod
+            patch:
+              components:
+                - name: myweb
+                  type: export-worker
+                  properties:
+                    image: "busybox:prod"
+                    replicas: 3
+`
+
+func newExportOption(t *testing.T) *Option {
+	var objs []oam.Object
+	for _, def := range []string{exportWorkerDef, simpleServiceDef} {
+		obj := &unstructured.Unstructured{}
+		require.NoError(t, yaml.Unmarshal([]byte(def), &obj.Object))
+		objs = append(objs, obj)
+	}
+	cli := fake.NewClientBuilder().WithScheme(common2.Scheme).Build()
+	return NewDryRunOption(cli, nil, &packages.PackageDiscover{}, objs)
+}
+
+func readExportApp(t *testing.T) *v1beta1.Application {
+	app := &v1beta1.Application{}
+	require.NoError(t, yaml.Unmarshal([]byte(exportApp), app))
+	return app
+}
+
+func exportedFiles(files []*ExportedFile) map[string]string {
+	contents := map[string]string{}
+	for _, file := range files {
+		contents[file.Path] = string(file.Content)
+	}
+	return contents
+}
+
+// patchTraitInProd makes the prod env change the port of the service trait besides the image, the port is not a
+// parameter of the component
+func patchTraitInProd(app *v1beta1.Application) {
+	patch := &app.Spec.Policies[0]
+	patch.Properties = &runtime.RawExtension{Raw: []byte(`{"envs":[{"name":"staging","placement":{"clusterSelector":{"name":"cluster-staging"}}},` +
+		`{"name":"prod","placement":{"clusterSelector":{"name":"cluster-prod"},"namespaceSelector":{"name":"prod"}},` +
+		`"patch":{"components":[{"name":"myweb","type":"export-worker","properties":{"image":"busybox:prod","replicas":3},"traits":[{"type":"simple-service","properties":{"port":8080}}]}]}}]}`)}
+}
+
+func TestExportHelmChart(t *testing.T) {
+	r := require.New(t)
+	opt := newExportOption(t)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), "default")
+
+	files, err := opt.ExportHelmChart(ctx, readExportApp(t))
+	r.NoError(err)
+	contents := exportedFiles(files)
+	r.Equal(5, len(contents))
+	r.Contains(contents["Chart.yaml"], "name: app-export")
+	r.Contains(contents["values.yaml"], "myweb:\n  # Number of the replicas\n  replicas: 1\n")
+	r.Contains(contents["values.yaml"], "  # Whether to inject the environment variables of the services\n  serviceLinks: true\n")
+	r.Contains(contents["values.yaml"], "  # Which image would you like to use for your service\n  image: busybox\n")
+	r.NotContains(contents["values-staging.yaml"], "myweb:")
+	r.Contains(contents["values-prod.yaml"], "-n prod")
+	r.Contains(contents["values-prod.yaml"], "replicas: 3\n")
+	r.Contains(contents["values-prod.yaml"], "image: busybox:prod\n")
+	r.NotContains(contents["values-prod.yaml"], "serviceLinks")
+	template := contents["templates/myweb.yaml"]
+	r.Contains(template, `- image: {{ index .Values "myweb" "image" | quote }}`)
+	r.Contains(template, `replicas: {{ index .Values "myweb" "replicas" }}`)
+	r.Contains(template, `enableServiceLinks: {{ index .Values "myweb" "serviceLinks" }}`)
+	r.Contains(template, "kind: Service")
+	r.NotContains(template, "\n  namespace:")
+	r.NotContains(template, "app.oam.dev/appRevision")
+
+	// the port of the trait can not be expressed by the values of the chart
+	app := readExportApp(t)
+	patchTraitInProd(app)
+	_, err = opt.ExportHelmChart(ctx, app)
+	r.Error(err)
+
+	// the go templates in the resources are escaped
+	app = readExportApp(t)
+	app.Spec.Policies = nil
+	app.Spec.Components[0].Traits = []common.ApplicationTrait{}
+	app.Spec.Components[0].Properties = &runtime.RawExtension{Raw: []byte(`{"image":"{{ busybox }}"}`)}
+	files, err = opt.ExportHelmChart(ctx, app)
+	r.NoError(err)
+	contents = exportedFiles(files)
+	r.Equal(3, len(contents))
+	r.Contains(contents["values.yaml"], "image: '{{ busybox }}'")
+	r.Contains(contents["templates/myweb.yaml"], `- image: {{ index .Values "myweb" "image" | quote }}`)
+}
+
+func TestExportKustomize(t *testing.T) {
+	r := require.New(t)
+	opt := newExportOption(t)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), "default")
+
+	app := readExportApp(t)
+	patchTraitInProd(app)
+	files, err := opt.ExportKustomize(ctx, app)
+	r.NoError(err)
+	contents := exportedFiles(files)
+	r.Equal(7, len(contents))
+	r.Contains(contents["base/kustomization.yaml"], "resources:\n- myweb.yaml\n")
+	r.Contains(contents["base/myweb.yaml"], "image: busybox\n")
+	r.Contains(contents["base/myweb.yaml"], "port: 80\n")
+	r.NotContains(contents["base/myweb.yaml"], "\n  namespace:")
+	r.NotContains(contents["base/myweb.yaml"], "envbinding.oam.dev/env")
+
+	r.NotContains(contents["overlays/staging/kustomization.yaml"], "namespace:")
+	r.NotContains(contents["overlays/staging/kustomization.yaml"], "patches")
+	overlay := contents["overlays/prod/kustomization.yaml"]
+	r.Contains(overlay, "namespace: prod\n")
+	r.NotContains(overlay, "patchesStrategicMerge")
+	r.Contains(overlay, "- path: patches/deployment-myweb.yaml\n  target:\n    group: apps\n    kind: Deployment\n    name: myweb\n    version: v1\n")
+	r.Contains(overlay, "- ../../base\n- myweb.yaml\n")
+
+	// the JSON 6902 patch turns the deployment of the base into the one of the env, the containers are replaced
+	patchData, err := yaml.YAMLToJSON([]byte(contents["overlays/prod/patches/deployment-myweb.yaml"]))
+	r.NoError(err)
+	patch, err := jsonpatch.DecodePatch(patchData)
+	r.NoError(err)
+	baseDeploy, err := yaml.YAMLToJSON([]byte(strings.Split(contents["base/myweb.yaml"], "---\n")[0]))
+	r.NoError(err)
+	patched, err := patch.Apply(baseDeploy)
+	r.NoError(err)
+	deploy := &unstructured.Unstructured{}
+	r.NoError(deploy.UnmarshalJSON(patched))
+	replicas, _, _ := unstructured.NestedInt64(deploy.Object, "spec", "replicas")
+	r.Equal(int64(3), replicas)
+	containers, _, _ := unstructured.NestedSlice(deploy.Object, "spec", "template", "spec", "containers")
+	r.Equal([]interface{}{map[string]interface{}{"name": "myweb", "image": "busybox:prod"}}, containers)
+	r.Equal("myweb", deploy.GetLabels()[oam.LabelAppComponent])
+	r.NotContains(contents["overlays/prod/patches/deployment-myweb.yaml"], "port")
+
+	// the name of the trait changes with its properties, so the service of the base is deleted and added again
+	var deletion string
+	for path, content := range contents {
+		if strings.HasPrefix(path, "overlays/prod/patches/service-") {
+			deletion = content
+			r.Contains(overlay, "- path: "+strings.TrimPrefix(path, "overlays/prod/")+"\n")
+		}
+	}
+	r.Contains(deletion, "$patch: delete\napiVersion: v1\nkind: Service")
+	r.Contains(contents["overlays/prod/myweb.yaml"], "port: 8080")
+}
+
+func TestCreatePatch(t *testing.T) {
+	base := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.oam.dev/name": "app", "stale": "true"}},
+		"spec":     map[string]interface{}{"ports": []interface{}{int64(80)}, "type": "ClusterIP"},
+	}
+	target := map[string]interface{}{
+		"metadata": map[string]interface{}{"labels": map[string]interface{}{"app.oam.dev/name": "app-v2", "env": "prod"}},
+		"spec":     map[string]interface{}{"ports": []interface{}{int64(80), int64(443)}, "type": "ClusterIP", "clusterIP": nil},
+	}
+	require.Equal(t, []patchOperation{
+		{Op: "replace", Path: "/metadata/labels/app.oam.dev~1name", Value: "app-v2"},
+		{Op: "add", Path: "/metadata/labels/env", Value: "prod"},
+		{Op: "remove", Path: "/metadata/labels/stale"},
+		{Op: "replace", Path: "/spec/ports", Value: []interface{}{int64(80), int64(443)}},
+	}, createPatch(base, target, ""))
+	require.Empty(t, createPatch(base, base, ""))
+}
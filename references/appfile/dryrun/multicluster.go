@@ -0,0 +1,284 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/policy/envbinding"
+)
+
+// PlacementManifests contains the resources rendered for a placement decision of an env
+type PlacementManifests struct {
+	// Env is the name of the env in the env-binding policy, empty if the application has no env-binding policy
+	Env        string
+	Decision   v1alpha1.PlacementDecision
+	Components []*types.ComponentManifest
+}
+
+// ExecuteMultiClusterDryRun renders the application for every placement decision of the envs in its env-binding
+// policy, the components are patched by the env and the resources are moved to the namespace of the decision, just
+// like the deploy2env workflow step does. The envName and clusterName filter the envs and clusters to render, if
+// the application has no env-binding policy, the application is rendered for the specified cluster or the local one.
+func (d *Option) ExecuteMultiClusterDryRun(ctx context.Context, app *v1beta1.Application, envName, clusterName string) ([]*PlacementManifests, error) {
+	policy, err := envbinding.GetEnvBindingPolicy(app, "")
+	if err != nil {
+		return nil, errors.WithMessage(err, "cannot parse env-binding policy")
+	}
+	if policy == nil {
+		if envName != "" {
+			return nil, errors.Errorf("env %s is specified but application %s has no env-binding policy", envName, app.Name)
+		}
+		comps, err := d.ExecuteDryRun(ctx, app)
+		if err != nil {
+			return nil, err
+		}
+		if clusterName == "" {
+			clusterName = multicluster.ClusterLocalName
+		}
+		return []*PlacementManifests{{Decision: v1alpha1.PlacementDecision{Cluster: clusterName}, Components: comps}}, nil
+	}
+
+	var results []*PlacementManifests
+	found := false
+	for _, env := range policy.Envs {
+		if envName != "" && env.Name != envName {
+			continue
+		}
+		found = true
+		decisions, err := multicluster.MakePlacementDecisions(ctx, d.Client, env.Name, env.Placement.DeepCopy())
+		if err != nil {
+			return nil, err
+		}
+		patchedApp, err := envbinding.PatchApplication(app, env.Patch.DeepCopy(), env.Selector)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "cannot patch application for env %s", env.Name)
+		}
+		// the env-binding policy has been resolved into the placement decisions, drop it from the patched application
+		// so only the components are rendered, as the deploy2env workflow step does
+		patchedApp.Spec.Policies = removeEnvBindingPolicies(patchedApp.Spec.Policies)
+		for _, decision := range decisions {
+			if clusterName != "" && decision.Cluster != clusterName {
+				continue
+			}
+			comps, err := d.ExecuteDryRun(ctx, patchedApp.DeepCopy())
+			if err != nil {
+				return nil, errors.WithMessagef(err, "cannot dry-run for env %s in cluster %s", env.Name, decision.Cluster)
+			}
+			for _, comp := range comps {
+				placeResources(comp, decision.Namespace, env.Name)
+			}
+			results = append(results, &PlacementManifests{Env: env.Name, Decision: decision, Components: comps})
+		}
+	}
+	if envName != "" && !found {
+		return nil, errors.Errorf("env %s not found in the env-binding policy of application %s", envName, app.Name)
+	}
+	if len(results) == 0 && clusterName != "" {
+		return nil, errors.Errorf("application %s is not placed in cluster %s", app.Name, clusterName)
+	}
+	return results, nil
+}
+
+func removeEnvBindingPolicies(policies []v1beta1.AppPolicy) []v1beta1.AppPolicy {
+	var remained []v1beta1.AppPolicy
+	for _, policy := range policies {
+		if policy.Type != v1alpha1.EnvBindingPolicyType {
+			remained = append(remained, policy)
+		}
+	}
+	return remained
+}
+
+// placeResources moves the resources of the component to the namespace of the placement decision and labels them with
+// the env, the same as the resources dispatched by the deploy2env workflow step
+func placeResources(comp *types.ComponentManifest, namespace, env string) {
+	resources := append([]*unstructured.Unstructured{comp.StandardWorkload}, comp.Traits...)
+	for _, res := range resources {
+		if res == nil {
+			continue
+		}
+		if namespace != "" {
+			res.SetNamespace(namespace)
+		}
+		labels := res.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[oam.LabelAppEnv] = env
+		res.SetLabels(labels)
+	}
+}
+
+// PlacementDiff records the differences between the resources rendered for a placement decision and the live ones
+type PlacementDiff struct {
+	Env      string
+	Decision v1alpha1.PlacementDecision
+	// Components are the diff entries of the components, the subs of a component are its workload and traits
+	Components []*DiffEntry
+}
+
+// DiffWithLiveObjects renders the application for the placement decisions and compares the resources with the live
+// objects in the clusters through cluster-gateway. Only the fields rendered by KubeVela are compared, the fields
+// defaulted or populated by the clusters are ignored.
+func (l *LiveDiffOption) DiffWithLiveObjects(ctx context.Context, app *v1beta1.Application, envName, clusterName string) ([]*PlacementDiff, error) {
+	placements, err := l.ExecuteMultiClusterDryRun(ctx, app, envName, clusterName)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "cannot dry-run for app %q", app.Name)
+	}
+	var results []*PlacementDiff
+	for _, placement := range placements {
+		clusterCtx := multicluster.ContextWithClusterName(ctx, placement.Decision.Cluster)
+		result := &PlacementDiff{Env: placement.Env, Decision: placement.Decision}
+		for _, comp := range placement.Components {
+			compEntry := &DiffEntry{Name: comp.Name, Kind: AppConfigCompKind}
+			resources := []*unstructured.Unstructured{comp.StandardWorkload}
+			kinds := []ManifestKind{RawCompKind}
+			for _, t := range comp.Traits {
+				resources = append(resources, t)
+				kinds = append(kinds, TraitKind)
+			}
+			for i, res := range resources {
+				if res == nil {
+					continue
+				}
+				entry, err := l.diffLiveObject(clusterCtx, res, kinds[i])
+				if err != nil {
+					return nil, errors.WithMessagef(err, "cannot compare component %s with the live objects in cluster %s", comp.Name, placement.Decision.Cluster)
+				}
+				if entry.DiffType == AddDiff || entry.DiffType == ModifyDiff {
+					compEntry.DiffType = ModifyDiff
+				}
+				compEntry.Subs = append(compEntry.Subs, entry)
+			}
+			result.Components = append(result.Components, compEntry)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (l *LiveDiffOption) diffLiveObject(ctx context.Context, rendered *unstructured.Unstructured, kind ManifestKind) (*DiffEntry, error) {
+	name := rendered.GetName()
+	if kind == TraitKind {
+		name = fmt.Sprintf("%s/%s", rendered.GetLabels()[oam.TraitTypeLabel], rendered.GetLabels()[oam.TraitResource])
+	}
+	entry := &DiffEntry{Name: name, Kind: kind}
+	rendered = rendered.DeepCopy()
+	removeRevisionRelatedLabelAndAnnotation(rendered)
+	newData, err := yaml.Marshal(rendered.Object)
+	if err != nil {
+		return nil, err
+	}
+	newManifest := &manifest{Data: string(newData)}
+
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(rendered.GroupVersionKind())
+	if err := l.Client.Get(ctx, client.ObjectKeyFromObject(rendered), live); err != nil {
+		if !kerrors.IsNotFound(err) {
+			return nil, err
+		}
+		entry.DiffType = AddDiff
+		entry.Diffs = diffManifest(&manifest{}, newManifest)
+		return entry, nil
+	}
+	removeRevisionRelatedLabelAndAnnotation(live)
+	liveData, err := yaml.Marshal(pruneLiveObject(live.Object, rendered.Object))
+	if err != nil {
+		return nil, err
+	}
+	entry.Diffs = diffManifest(&manifest{Data: string(liveData)}, newManifest)
+	if hasChanges(entry.Diffs) {
+		entry.DiffType = ModifyDiff
+	}
+	return entry, nil
+}
+
+// pruneLiveObject keeps only the fields of the live object which are also in the rendered object, so the fields
+// defaulted or populated by the cluster, like status and the metadata set by the apiserver, are not compared
+func pruneLiveObject(live, rendered interface{}) interface{} {
+	switch r := rendered.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return live
+		}
+		pruned := map[string]interface{}{}
+		for k, v := range r {
+			if lv, ok := l[k]; ok {
+				pruned[k] = pruneLiveObject(lv, v)
+			}
+		}
+		return pruned
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			return live
+		}
+		pruned := make([]interface{}, 0, len(l))
+		for i, lv := range l {
+			if i < len(r) {
+				lv = pruneLiveObject(lv, r[i])
+			}
+			pruned = append(pruned, lv)
+		}
+		return pruned
+	default:
+		return live
+	}
+}
+
+// PrintPlacementDiffReport formats and prints the differences between the resources rendered for a placement decision
+// and the live objects into target io.Writer
+func (r *ReportDiffOption) PrintPlacementDiffReport(diff *PlacementDiff) {
+	_, _ = yellow.Fprintf(r.To, "---\n# %s\n---\n", placementTitle(diff.Env, diff.Decision))
+	for _, comp := range diff.Components {
+		_, _ = yellow.Fprintf(r.To, "---\n## Component (%s) %s\n---\n", comp.Name, r.DiffMsgs[comp.DiffType])
+		for _, sub := range comp.Subs {
+			if sub.Kind == TraitKind {
+				_, _ = yellow.Fprintf(r.To, "---\n### Component (%s) / Trait (%s) %s\n---\n", comp.Name, sub.Name, r.DiffMsgs[sub.DiffType])
+			} else {
+				_, _ = yellow.Fprintf(r.To, "---\n### Component (%s) / Workload (%s) %s\n---\n", comp.Name, sub.Name, r.DiffMsgs[sub.DiffType])
+			}
+			printDiffs(sub.Diffs, r.Context, r.To)
+		}
+	}
+}
+
+// placementTitle describes the env and the placement decision
+func placementTitle(env string, decision v1alpha1.PlacementDecision) string {
+	title := fmt.Sprintf("Cluster (%s)", decision.Cluster)
+	if env != "" {
+		title = fmt.Sprintf("Env (%s) / %s", env, title)
+	}
+	if decision.Namespace != "" {
+		title = fmt.Sprintf("%s / Namespace (%s)", title, decision.Namespace)
+	}
+	return title
+}
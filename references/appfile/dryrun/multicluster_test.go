@@ -0,0 +1,244 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
+
+	clusterv1alpha1 "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+)
+
+// the definitions without the imports of kube packages, which need the package discover of a real cluster
+const (
+	simpleWorkerDef = `apiVersion: core.oam.dev/v1beta1
+kind: ComponentDefinition
+metadata:
+  name: simple-worker
+spec:
+  workload:
+    definition:
+      apiVersion: apps/v1
+      kind: Deployment
+  schematic:
+    cue:
+      template: |
+        output: {
+        	apiVersion: "apps/v1"
+        	kind:       "Deployment"
+        	spec: {
+        		selector: matchLabels: "app.oam.dev/component": context.name
+        		template: {
+        			metadata: labels: "app.oam.dev/component": context.name
+        			spec: containers: [{
+        				name:  context.name
+        				image: parameter.image
+        			}]
+        		}
+        	}
+        }
+        parameter: image: string
+`
+	simpleServiceDef = `apiVersion: core.oam.dev/v1beta1
+kind: TraitDefinition
+metadata:
+  name: simple-service
+spec:
+  schematic:
+    cue:
+      template: |
+        outputs: service: {
+        	apiVersion: "v1"
+        	kind:       "Service"
+        	spec: {
+        		selector: "app.oam.dev/component": context.name
+        		ports: [{port: parameter.port}]
+        	}
+        }
+        parameter: port: int
+`
+)
+
+const envBindingApp = `apiVersion: core.oam.dev/v1beta1
+kind: Application
+metadata:
+  name: app-envbinding
+  namespace: default
+spec:
+  components:
+    - name: myweb
+      type: simple-worker
+      properties:
+        image: "busybox"
+      traits:
+        - type: simple-service
+          properties:
+            port: 80
+  policies:
+    - name: example-multi-env-policy
+      type: env-binding
+      properties:
+        envs:
+          - name: staging
+            placement:
+              clusterSelector:
+                name: cluster-staging
+          - name: prod
+            placement:
+              clusterSelector:
+                name: cluster-prod
+              namespaceSelector:
+                name: prod
+            patch:
+              components:
+                - name: myweb
+                  type: simple-worker
+                  properties:
+                    image: "busybox:prod"
+`
+
+func newMultiClusterDryRunOption(t *testing.T) (*LiveDiffOption, *multicluster.FakeClient) {
+	r := require.New(t)
+	var objs []oam.Object
+	for _, def := range []string{simpleWorkerDef, simpleServiceDef} {
+		obj := &unstructured.Unstructured{}
+		r.NoError(yaml.Unmarshal([]byte(def), &obj.Object))
+		objs = append(objs, obj)
+	}
+	hub := fake.NewClientBuilder().WithScheme(common.Scheme).Build()
+	for _, cluster := range []string{"cluster-staging", "cluster-prod"} {
+		r.NoError(hub.Create(context.Background(), &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      cluster,
+			Namespace: multicluster.ClusterGatewaySecretNamespace,
+			Labels:    map[string]string{clusterv1alpha1.LabelKeyClusterCredentialType: string(clusterv1alpha1.CredentialTypeX509Certificate)},
+		}}))
+	}
+	cli := multicluster.NewFakeClient(hub)
+	cli.AddCluster("cluster-staging", fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	cli.AddCluster("cluster-prod", fake.NewClientBuilder().WithScheme(common.Scheme).Build())
+	return &LiveDiffOption{DryRun: NewDryRunOption(cli, nil, &packages.PackageDiscover{}, objs), Client: cli}, cli
+}
+
+func readEnvBindingApp(t *testing.T) *v1beta1.Application {
+	app := &v1beta1.Application{}
+	require.NoError(t, yaml.Unmarshal([]byte(envBindingApp), app))
+	return app
+}
+
+func TestExecuteMultiClusterDryRun(t *testing.T) {
+	r := require.New(t)
+	opt, _ := newMultiClusterDryRunOption(t)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), "default")
+
+	placements, err := opt.ExecuteMultiClusterDryRun(ctx, readEnvBindingApp(t), "", "")
+	r.NoError(err)
+	r.Equal(2, len(placements))
+	r.Equal("staging", placements[0].Env)
+	r.Equal("cluster-staging", placements[0].Decision.Cluster)
+	staging := placements[0].Components[0].StandardWorkload
+	r.Equal("default", staging.GetNamespace())
+	r.Equal("staging", staging.GetLabels()[oam.LabelAppEnv])
+
+	r.Equal("prod", placements[1].Env)
+	prod := placements[1].Components[0]
+	r.Equal("prod", prod.StandardWorkload.GetNamespace())
+	for _, trait := range prod.Traits {
+		r.Equal("prod", trait.GetNamespace())
+	}
+	data, err := yaml.Marshal(prod.StandardWorkload)
+	r.NoError(err)
+	r.Contains(string(data), "busybox:prod")
+
+	placements, err = opt.ExecuteMultiClusterDryRun(ctx, readEnvBindingApp(t), "prod", "")
+	r.NoError(err)
+	r.Equal(1, len(placements))
+	r.Equal("cluster-prod", placements[0].Decision.Cluster)
+
+	_, err = opt.ExecuteMultiClusterDryRun(ctx, readEnvBindingApp(t), "test", "")
+	r.Error(err)
+	_, err = opt.ExecuteMultiClusterDryRun(ctx, readEnvBindingApp(t), "staging", "cluster-prod")
+	r.Error(err)
+
+	app := readEnvBindingApp(t)
+	app.Spec.Policies = nil
+	placements, err = opt.ExecuteMultiClusterDryRun(ctx, app, "", "cluster-prod")
+	r.NoError(err)
+	r.Equal(1, len(placements))
+	r.Equal("cluster-prod", placements[0].Decision.Cluster)
+	r.Equal("default", placements[0].Components[0].StandardWorkload.GetNamespace())
+	_, err = opt.ExecuteMultiClusterDryRun(ctx, app, "prod", "")
+	r.Error(err)
+}
+
+func TestDiffWithLiveObjects(t *testing.T) {
+	r := require.New(t)
+	opt, cli := newMultiClusterDryRunOption(t)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), "default")
+
+	// the workload is only deployed to the staging cluster, with the fields populated by the cluster
+	placements, err := opt.ExecuteMultiClusterDryRun(ctx, readEnvBindingApp(t), "staging", "")
+	r.NoError(err)
+	live := placements[0].Components[0].StandardWorkload.DeepCopy()
+	live.SetUID("uid")
+	r.NoError(unstructured.SetNestedField(live.Object, int64(1), "status", "replicas"))
+	stagingCtx := multicluster.ContextWithClusterName(ctx, "cluster-staging")
+	r.NoError(cli.Create(stagingCtx, live))
+
+	diffs, err := opt.DiffWithLiveObjects(ctx, readEnvBindingApp(t), "", "")
+	r.NoError(err)
+	r.Equal(2, len(diffs))
+	r.Equal("cluster-staging", diffs[0].Decision.Cluster)
+	r.Equal(NoDiff, diffs[0].Components[0].Subs[0].DiffType)
+	r.Equal(AddDiff, diffs[0].Components[0].Subs[1].DiffType)
+	r.Equal(AddDiff, diffs[1].Components[0].Subs[0].DiffType)
+
+	// the live workload is changed in the staging cluster
+	r.NoError(unstructured.SetNestedField(live.Object, "changed", "metadata", "annotations", "test"))
+	r.NoError(unstructured.SetNestedField(live.Object, int64(3), "spec", "replicas"))
+	r.NoError(unstructured.SetNestedField(live.Object, "nginx", "spec", "selector", "matchLabels", "app.oam.dev/component"))
+	r.NoError(cli.Update(stagingCtx, live))
+	diffs, err = opt.DiffWithLiveObjects(ctx, readEnvBindingApp(t), "staging", "")
+	r.NoError(err)
+	r.Equal(1, len(diffs))
+	workload := diffs[0].Components[0].Subs[0]
+	r.Equal(ModifyDiff, workload.DiffType)
+	r.Equal(ModifyDiff, diffs[0].Components[0].DiffType)
+
+	buff := bytes.Buffer{}
+	NewReportDiffOption(-1, &buff).PrintPlacementDiffReport(diffs[0])
+	r.Contains(buff.String(), "Env (staging) / Cluster (cluster-staging)")
+	r.Contains(buff.String(), "Component (myweb) has been modified(*)")
+	r.NotContains(buff.String(), "uid")
+	r.NotContains(buff.String(), "changed")
+	r.NotContains(buff.String(), "replicas")
+	r.Regexp(`-\s+app.oam.dev/component: nginx`, buff.String())
+}
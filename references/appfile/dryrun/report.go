@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"strings"
 
 	"github.com/aryann/difflib"
 	"github.com/fatih/color"
+
+	"github.com/oam-dev/kubevela/pkg/apprevision"
 )
 
 var (
@@ -75,6 +78,35 @@ func (r *ReportDiffOption) PrintDiffReport(app *DiffEntry) {
 	}
 }
 
+// PrintRevisionDiffReport formats and prints the changes of an application between two revisions into target io.Writer
+func (r *ReportDiffOption) PrintRevisionDiffReport(base, target string, entries []apprevision.DiffEntry) {
+	if len(entries) == 0 {
+		_, _ = yellow.Fprintf(r.To, "---\n# Revision (%s) and (%s) have no difference\n---\n", base, target)
+		return
+	}
+	_, _ = yellow.Fprintf(r.To, "---\n# Revision (%s) -> (%s)\n---\n", base, target)
+	const sep = "\n"
+	for _, entry := range entries {
+		msg := r.DiffMsgs[DiffType(entry.DiffType)]
+		switch entry.Kind {
+		case apprevision.TraitKind:
+			_, _ = yellow.Fprintf(r.To, "---\n### Component (%s) / Trait (%s) %s\n---\n", entry.Component, entry.Name, msg)
+		case apprevision.ComponentKind:
+			_, _ = yellow.Fprintf(r.To, "---\n## Component (%s) %s\n---\n", entry.Name, msg)
+		default:
+			_, _ = yellow.Fprintf(r.To, "---\n## %s (%s) %s\n---\n", entry.Kind, entry.Name, msg)
+		}
+		var baseLines, targetLines []string
+		if entry.Base != "" {
+			baseLines = strings.Split(strings.TrimSuffix(entry.Base, sep), sep)
+		}
+		if entry.Target != "" {
+			targetLines = strings.Split(strings.TrimSuffix(entry.Target, sep), sep)
+		}
+		printDiffs(difflib.Diff(baseLines, targetLines), r.Context, r.To)
+	}
+}
+
 func printDiffs(diffs []difflib.DiffRecord, context int, to io.Writer) {
 	if context > 0 {
 		ctx := calculateContext(diffs)
@@ -0,0 +1,210 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+
+	"github.com/oam-dev/kubevela/apis/types"
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+const (
+	// FlagAPIServerEndpoint specifies the endpoint of the KubeVela apiserver
+	FlagAPIServerEndpoint = "endpoint"
+	// DefaultAPIServerEndpoint is the default endpoint of the KubeVela apiserver
+	DefaultAPIServerEndpoint = "http://127.0.0.1:8000"
+)
+
+// NewAppCommandGroup create a group of commands to manage the applications of the apiserver
+func NewAppCommandGroup(ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "app",
+		Short: "Manage the applications of the apiserver",
+		Long:  "Export the applications managed by the apiserver as declarative files or import them back.",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.PersistentFlags().String(FlagAPIServerEndpoint, DefaultAPIServerEndpoint, "specify the endpoint of the apiserver")
+	cmd.AddCommand(
+		NewAppExportCommand(ioStreams),
+		NewAppImportCommand(ioStreams),
+	)
+	return cmd
+}
+
+// NewAppExportCommand create app export command
+func NewAppExportCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
+	var dir string
+	cmd := &cobra.Command{
+		Use:     "export APP_NAME",
+		Short:   "Export the application as declarative files",
+		Long:    "Export the application, its env bindings, workflows and delivery targets as yaml files into the directory.",
+		Example: "vela app export my-app --dir ./my-app",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := cmd.Flags().GetString(FlagAPIServerEndpoint)
+			if err != nil {
+				return err
+			}
+			if dir == "" {
+				dir = args[0]
+			}
+			resp := &apisv1.ExportApplicationResponse{}
+			if err := requestAPIServer(cmd.Context(), http.MethodGet, endpoint, fmt.Sprintf("/applications/%s/export", args[0]), nil, resp); err != nil {
+				return err
+			}
+			if err := writeApplicationFiles(dir, resp.Files); err != nil {
+				return err
+			}
+			ioStreams.Infof("Application %s is exported to %s\n", args[0], dir)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dir, "dir", "d", "", "specify the directory to export the files to, defaults to the application name")
+	return cmd
+}
+
+// NewAppImportCommand create app import command
+func NewAppImportCommand(ioStreams cmdutil.IOStreams) *cobra.Command {
+	var dir, project string
+	cmd := &cobra.Command{
+		Use:     "import",
+		Short:   "Import the application from declarative files",
+		Long:    "Create or update the application, its env bindings, workflows and delivery targets from the yaml files in the directory.",
+		Example: "vela app import --dir ./my-app",
+		Args:    cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			endpoint, err := cmd.Flags().GetString(FlagAPIServerEndpoint)
+			if err != nil {
+				return err
+			}
+			files, err := readApplicationFiles(dir)
+			if err != nil {
+				return err
+			}
+			resp := &apisv1.ApplicationBase{}
+			req := apisv1.ImportApplicationRequest{Files: files, Project: project}
+			if err := requestAPIServer(cmd.Context(), http.MethodPost, endpoint, "/applications/import", req, resp); err != nil {
+				return err
+			}
+			ioStreams.Infof("Application %s is imported\n", resp.Name)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&dir, "dir", "d", ".", "specify the directory of the files to import")
+	cmd.Flags().StringVarP(&project, "project", "p", "", "specify the project of the application, overrides the project in the metadata")
+	return cmd
+}
+
+// requestAPIServer sends the request to the apiserver and decodes the response into the result
+func requestAPIServer(ctx context.Context, method, endpoint, path string, body interface{}, result interface{}) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	url := strings.TrimSuffix(endpoint, "/") + "/api/v1" + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to request the apiserver %s", endpoint)
+	}
+	//nolint:errcheck
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		code := &bcode.Bcode{}
+		if err := json.Unmarshal(data, code); err == nil && code.Message != "" {
+			return errors.Errorf("request %s failed: %s", path, code.Message)
+		}
+		return errors.Errorf("request %s failed with status %d: %s", path, resp.StatusCode, string(data))
+	}
+	return json.Unmarshal(data, result)
+}
+
+func writeApplicationFiles(dir string, files map[string]string) error {
+	for name, content := range files {
+		cleaned := filepath.Clean(filepath.FromSlash(name))
+		if filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("the file %s is out of the directory %s", name, dir)
+		}
+		path := filepath.Join(dir, cleaned)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readApplicationFiles(dir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (filepath.Ext(path) != ".yaml" && filepath.Ext(path) != ".yml") {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(name)] = string(content)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apisv1 "github.com/oam-dev/kubevela/pkg/apiserver/rest/apis/v1"
+	"github.com/oam-dev/kubevela/pkg/apiserver/rest/utils/bcode"
+)
+
+func TestApplicationFiles(t *testing.T) {
+	r := require.New(t)
+	dir, err := os.MkdirTemp("", "vela-app")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"metadata.yaml":    "name: app\n",
+		"application.yaml": "kind: Application\n",
+		"envs/dev.yaml":    "kind: Application\n",
+	}
+	r.NoError(writeApplicationFiles(dir, files))
+	r.NoError(os.WriteFile(dir+"/README.md", []byte("ignored"), 0600))
+	read, err := readApplicationFiles(dir)
+	r.NoError(err)
+	r.Equal(files, read)
+
+	for _, name := range []string{"../escaped.yaml", "envs/../../escaped.yaml", "/tmp/escaped.yaml"} {
+		r.Error(writeApplicationFiles(dir, map[string]string{name: "kind: Application\n"}), name)
+	}
+	_, err = os.Stat(filepath.Join(filepath.Dir(dir), "escaped.yaml"))
+	r.True(os.IsNotExist(err))
+}
+
+func TestRequestAPIServer(t *testing.T) {
+	r := require.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/v1/applications/import":
+			importReq := apisv1.ImportApplicationRequest{}
+			r.NoError(json.NewDecoder(req.Body).Decode(&importReq))
+			r.Equal("p", importReq.Project)
+			r.NoError(json.NewEncoder(w).Encode(apisv1.ApplicationBase{Name: "app"}))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			r.NoError(json.NewEncoder(w).Encode(bcode.ErrApplicationNotExist))
+		}
+	}))
+	defer server.Close()
+
+	base := &apisv1.ApplicationBase{}
+	r.NoError(requestAPIServer(context.Background(), http.MethodPost, server.URL, "/applications/import", apisv1.ImportApplicationRequest{Project: "p"}, base))
+	r.Equal("app", base.Name)
+
+	err := requestAPIServer(context.Background(), http.MethodGet, server.URL, "/applications/unknown/export", nil, &apisv1.ExportApplicationResponse{})
+	r.Error(err)
+	r.Contains(err.Error(), bcode.ErrApplicationNotExist.Message)
+}
@@ -87,6 +87,8 @@ func NewCommand() *cobra.Command {
 		NewPortForwardCommand(commandArgs, ioStream),
 		NewLogsCommand(commandArgs, ioStream),
 		NewEnvCommand(commandArgs, ioStream),
+		NewAppCommandGroup(ioStream),
+		NewRevisionCommand(commandArgs, ioStream),
 
 		// Workflows
 		NewWorkflowCommand(commandArgs, ioStream),
@@ -19,19 +19,19 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
-	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	ocmclusterv1 "open-cluster-management.io/api/cluster/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	clusterv1alpha1 "github.com/oam-dev/cluster-gateway/pkg/apis/cluster/v1alpha1"
 	"github.com/oam-dev/cluster-gateway/pkg/generated/clientset/versioned"
@@ -52,12 +52,18 @@ const (
 	FlagClusterName = "name"
 	// FlagClusterManagementEngine specifies the cluster management type, eg: ocm
 	FlagClusterManagementEngine = "engine"
+	// FlagClusterSelector specifies the label selector to filter clusters
+	FlagClusterSelector = "selector"
 	// FlagKubeConfigPath specifies the kubeconfig path
 	FlagKubeConfigPath = "kubeconfig-path"
 	// FlagInClusterBootstrap prescribes the cluster registration to use the internal
 	// IP from the kube-public/cluster-info configmap, otherwise the endpoint in the
 	// hub kubeconfig will be used for registration.
 	FlagInClusterBootstrap = "in-cluster-boostrap"
+	// FlagServiceAccount specifies to join the cluster with the token of a dedicated service account created in it
+	FlagServiceAccount = "service-account"
+	// FlagServiceAccountNamespaces specifies the namespaces where the service account can manage secrets, service accounts and pods
+	FlagServiceAccountNamespaces = "service-account-namespaces"
 
 	// ClusterGateWayClusterManagement cluster-gateway cluster management solution
 	ClusterGateWayClusterManagement = "cluster-gateway"
@@ -105,6 +111,9 @@ func ClusterCommandGroup(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comm
 		NewClusterRenameCommand(&c),
 		NewClusterDetachCommand(&c),
 		NewClusterProbeCommand(&c),
+		NewClusterLabelsCommandGroup(&c),
+		NewClusterRotateCredentialCommand(&c),
+		NewClusterUpdateCredentialCommand(&c),
 	)
 	return cmd
 }
@@ -116,15 +125,27 @@ func NewClusterListCommand(c *common.Args) *cobra.Command {
 		Aliases: []string{"ls"},
 		Short:   "list managed clusters",
 		Long:    "list child clusters managed by KubeVela",
+		Example: "vela cluster list --selector region=east",
 		Args:    cobra.ExactValidArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			table := newUITable().AddRow("CLUSTER", "TYPE", "ENDPOINT")
+			selectorStr, err := cmd.Flags().GetString(FlagClusterSelector)
+			if err != nil {
+				return err
+			}
+			selector, err := labels.Parse(selectorStr)
+			if err != nil {
+				return errors.Wrapf(err, "invalid selector %s", selectorStr)
+			}
+			table := newUITable().AddRow("CLUSTER", "TYPE", "ENDPOINT", "LABELS")
 			clusters, err := clustermanager.GetRegisteredClusters(c.Client)
 			if err != nil {
 				return errors.Wrap(err, "fail to get registered cluster")
 			}
 			for _, cluster := range clusters {
-				table.AddRow(cluster.Name, cluster.Type, cluster.EndPoint)
+				if !selector.Matches(labels.Set(cluster.Labels)) {
+					continue
+				}
+				table.AddRow(cluster.Name, cluster.Type, cluster.EndPoint, labels.Set(cluster.Labels).String())
 			}
 			if len(table.Rows) == 1 {
 				cmd.Println("No managed cluster found.")
@@ -134,21 +155,64 @@ func NewClusterListCommand(c *common.Args) *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().StringP(FlagClusterSelector, "l", "", "Filter the clusters by the label selector, e.g. region=east,env!=test")
 	return cmd
 }
 
-func ensureVelaSystemNamespaceInstalled(c client.Client, clusterName string, createNamespace string) error {
-	ctx := context.Background()
-	remoteCtx := multicluster.ContextWithClusterName(ctx, clusterName)
-	if err := c.Get(remoteCtx, k8stypes.NamespacedName{Name: createNamespace}, &corev1.Namespace{}); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return errors.Wrapf(err, "failed to check vela-system ")
-		}
-		if err = c.Create(remoteCtx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: createNamespace}}); err != nil {
-			return errors.Wrapf(err, "failed to create vela-system namespace")
-		}
+// NewClusterLabelsCommandGroup create a group of commands to manage the labels of the cluster
+func NewClusterLabelsCommandGroup(c *common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "labels",
+		Short: "manage the labels of managed cluster",
+		Long:  "Manage the labels of managed cluster, the labels can be used to select clusters in the env binding",
 	}
-	return nil
+	cmd.AddCommand(
+		NewClusterAddLabelsCommand(c),
+		NewClusterDelLabelsCommand(c),
+	)
+	return cmd
+}
+
+// NewClusterAddLabelsCommand create command to add labels to the cluster
+func NewClusterAddLabelsCommand(c *common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "add CLUSTER_NAME LABELS",
+		Short:   "add labels to managed cluster",
+		Example: "vela cluster labels add my-cluster region=east,env=prod",
+		Args:    cobra.ExactValidArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterLabels, err := labels.ConvertSelectorToLabelsMap(args[1])
+			if err != nil {
+				return errors.Wrapf(err, "invalid labels %s", args[1])
+			}
+			if err := multicluster.AddClusterLabels(context.Background(), c.Client, args[0], clusterLabels); err != nil {
+				return errors.Wrapf(err, "failed to add labels to cluster %s", args[0])
+			}
+			cmd.Printf("Successfully update labels for cluster %s: %s.\n", args[0], clusterLabels.String())
+			return nil
+		},
+	}
+	return cmd
+}
+
+// NewClusterDelLabelsCommand create command to delete labels of the cluster
+func NewClusterDelLabelsCommand(c *common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "del CLUSTER_NAME LABEL_KEYS",
+		Aliases: []string{"delete", "remove"},
+		Short:   "delete labels of managed cluster",
+		Example: "vela cluster labels del my-cluster region,env",
+		Args:    cobra.ExactValidArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys := strings.Split(args[1], ",")
+			if err := multicluster.DeleteClusterLabels(context.Background(), c.Client, args[0], keys...); err != nil {
+				return errors.Wrapf(err, "failed to delete labels of cluster %s", args[0])
+			}
+			cmd.Printf("Successfully delete labels %s of cluster %s.\n", args[1], args[0])
+			return nil
+		},
+	}
+	return cmd
 }
 
 // NewClusterJoinCommand create command to help user join cluster to multicluster management
@@ -158,7 +222,9 @@ func NewClusterJoinCommand(c *common.Args, ioStreams cmdutil.IOStreams) *cobra.C
 		Short: "join managed cluster",
 		Long:  "join managed cluster by kubeconfig",
 		Example: "# Join cluster declared in my-child-cluster.kubeconfig\n" +
-			"> vela cluster join my-child-cluster.kubeconfig --name example-cluster",
+			"> vela cluster join my-child-cluster.kubeconfig --name example-cluster\n" +
+			"# Join cluster with the token of a service account created in it, the credential in kubeconfig is not stored\n" +
+			"> vela cluster join my-child-cluster.kubeconfig --name example-cluster --service-account",
 		Args: cobra.ExactValidArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			config, err := clientcmd.LoadFromFile(args[0])
@@ -218,7 +284,27 @@ func NewClusterJoinCommand(c *common.Args, ioStreams cmdutil.IOStreams) *cobra.C
 				} else {
 					ioStreams.Infof("failed to parse server endpoint: %v", err)
 				}
-				if err = registerClusterManagedByVela(c.Client, cluster, authInfo, clusterName, createNamespace); err != nil {
+				serviceAccount, err := cmd.Flags().GetBool(FlagServiceAccount)
+				if err != nil {
+					return errors.Wrapf(err, "failed to get service account flag")
+				}
+				if serviceAccount {
+					// the credential in kubeconfig only bootstraps the service account, the normalized cluster server is stored
+					remoteConfig, err := clientcmd.BuildConfigFromFlags("", args[0])
+					if err != nil {
+						return errors.Wrapf(err, "failed to build rest config from kubeconfig")
+					}
+					namespaces, err := cmd.Flags().GetStringSlice(FlagServiceAccountNamespaces)
+					if err != nil {
+						return errors.Wrapf(err, "failed to get service account namespaces flag")
+					}
+					opts := multicluster.ServiceAccountOptions{Namespace: createNamespace, Namespaces: namespaces}
+					if err = multicluster.JoinClusterByServiceAccount(context.Background(), c.Client, remoteConfig, cluster, clusterName, opts); err != nil {
+						return err
+					}
+					break
+				}
+				if err = multicluster.JoinClusterByCredential(context.Background(), c.Client, cluster, authInfo, clusterName, createNamespace); err != nil {
 					return err
 				}
 			case OCMClusterManagement:
@@ -238,50 +324,16 @@ func NewClusterJoinCommand(c *common.Args, ioStreams cmdutil.IOStreams) *cobra.C
 	cmd.Flags().StringP(FlagClusterName, "n", "", "Specify the cluster name. If empty, it will use the cluster name in config file. Default to be empty.")
 	cmd.Flags().StringP(FlagClusterManagementEngine, "t", "", "Specify the cluster management engine. If empty, it will use cluster-gateway cluster management solution. Default to be empty.")
 	cmd.Flags().StringP(CreateNamespace, "", "", "Specifies the namespace need to create in managedCluster")
+	cmd.Flags().BoolP(FlagServiceAccount, "", false, "If true, a service account with limited permissions is created in the managed cluster by the credential in kubeconfig, "+
+		"only the token of the service account is stored. Only works with the cluster-gateway engine.")
+	cmd.Flags().StringSliceP(FlagServiceAccountNamespaces, "", nil, "Specifies the namespaces where the service account can manage secrets, service accounts and pods "+
+		"besides the namespace it is created in. Only works with --service-account.")
 	cmd.Flags().BoolP(FlagInClusterBootstrap, "", true, "If true, the registering managed cluster "+
 		`will use the internal endpoint prescribed in the hub cluster's configmap "kube-public/cluster-info to register "`+
 		"itself to the hub cluster. Otherwise use the original endpoint from the hub kubeconfig.")
 	return cmd
 }
 
-func registerClusterManagedByVela(k8sClient client.Client, cluster *clientcmdapi.Cluster, authInfo *clientcmdapi.AuthInfo, clusterName string, createNamespace string) error {
-	if err := clustermanager.EnsureClusterNotExists(k8sClient, clusterName); err != nil {
-		return errors.Wrapf(err, "cannot use cluster name %s", clusterName)
-	}
-	var credentialType clusterv1alpha1.CredentialType
-	data := map[string][]byte{
-		"endpoint": []byte(cluster.Server),
-		"ca.crt":   cluster.CertificateAuthorityData,
-	}
-	if len(authInfo.Token) > 0 {
-		credentialType = clusterv1alpha1.CredentialTypeServiceAccountToken
-		data["token"] = []byte(authInfo.Token)
-	} else {
-		credentialType = clusterv1alpha1.CredentialTypeX509Certificate
-		data["tls.crt"] = authInfo.ClientCertificateData
-		data["tls.key"] = authInfo.ClientKeyData
-	}
-	secret := &corev1.Secret{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      clusterName,
-			Namespace: multicluster.ClusterGatewaySecretNamespace,
-			Labels: map[string]string{
-				clusterv1alpha1.LabelKeyClusterCredentialType: string(credentialType),
-			},
-		},
-		Type: corev1.SecretTypeOpaque,
-		Data: data,
-	}
-	if err := k8sClient.Create(context.Background(), secret); err != nil {
-		return errors.Wrapf(err, "failed to add cluster to kubernetes")
-	}
-	if err := ensureVelaSystemNamespaceInstalled(k8sClient, clusterName, createNamespace); err != nil {
-		_ = k8sClient.Delete(context.Background(), secret)
-		return errors.Wrapf(err, "failed to ensure vela-system namespace installed in cluster %s", clusterName)
-	}
-	return nil
-}
-
 func registerClusterManagedByOCM(ioStreams cmdutil.IOStreams, hubConfig *rest.Config, spokeConfig *clientcmdapi.Config, clusterName string, inClusterBootstrap bool) error {
 	ctx := context.Background()
 	hubCluster, err := hub.NewHubCluster(hubConfig)
@@ -418,6 +470,44 @@ func NewClusterRenameCommand(c *common.Args) *cobra.Command {
 	return cmd
 }
 
+// NewClusterRotateCredentialCommand create command to rotate the service account token of the cluster
+func NewClusterRotateCredentialCommand(c *common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rotate-credential CLUSTER_NAME",
+		Short:   "rotate the credential of managed cluster",
+		Long:    "Issue a new token for the service account of the managed cluster and revoke the old one, only works for the cluster joined with --service-account",
+		Example: "vela cluster rotate-credential my-cluster",
+		Args:    cobra.ExactValidArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := multicluster.RotateClusterCredential(context.Background(), c.Client, args[0]); err != nil {
+				return errors.Wrapf(err, "failed to rotate the credential of cluster %s", args[0])
+			}
+			cmd.Printf("Successfully rotate the credential of cluster %s.\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
+// NewClusterUpdateCredentialCommand create command to replace the credential of the cluster in place
+func NewClusterUpdateCredentialCommand(c *common.Args) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "update-credential CLUSTER_NAME KUBECONFIG",
+		Short:   "update the credential of managed cluster",
+		Long:    "Replace the credential of managed cluster with the one in kubeconfig, the cluster is not detached so the applications are not affected",
+		Example: "vela cluster update-credential my-cluster my-cluster.kubeconfig",
+		Args:    cobra.ExactValidArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := multicluster.UpdateClusterCredential(context.Background(), c.Client, args[1], args[0]); err != nil {
+				return errors.Wrapf(err, "failed to update the credential of cluster %s", args[0])
+			}
+			cmd.Printf("Successfully update the credential of cluster %s.\n", args[0])
+			return nil
+		},
+	}
+	return cmd
+}
+
 // NewClusterDetachCommand create command to help user detach existing cluster
 func NewClusterDetachCommand(c *common.Args) *cobra.Command {
 	cmd := &cobra.Command{
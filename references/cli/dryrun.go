@@ -29,7 +29,9 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/yaml"
 
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha1"
 	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
 	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
@@ -43,6 +45,9 @@ type DryRunCmdOptions struct {
 	cmdutil.IOStreams
 	ApplicationFile string
 	DefinitionFile  string
+	// Env and Cluster select the env in the env-binding policy and the cluster to render the application for
+	Env     string
+	Cluster string
 }
 
 // NewSystemDryRunCommand is deprecated
@@ -66,13 +71,14 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 		Use:                   "dry-run",
 		DisableFlagsInUseLine: true,
 		Short:                 "Dry Run an application, and output the K8s resources as result to stdout",
-		Long:                  "Dry Run an application, and output the K8s resources as result to stdout, only CUE template supported for now",
-		Example:               "vela dry-run",
+		Long: "Dry Run an application, and output the K8s resources as result to stdout, only CUE template supported for now. " +
+			"If the application has an env-binding policy, the components are patched by each env and rendered for each cluster the env is placed in.",
+		Example: "vela dry-run --env prod --cluster cluster-hangzhou",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			namespace, err := getDryRunNamespace(cmd, c)
 			if err != nil {
 				return err
 			}
@@ -87,6 +93,7 @@ func NewDryRunCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command
 
 	cmd.Flags().StringVarP(&o.ApplicationFile, "file", "f", "./app.yaml", "application file name")
 	cmd.Flags().StringVarP(&o.DefinitionFile, "definition", "d", "", "specify a definition file or directory, it will only be used in dry-run rather than applied to K8s cluster")
+	addPlacementArgs(cmd, o)
 	addNamespaceArg(cmd)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
@@ -124,34 +131,86 @@ func DryRunApplication(cmdOption *DryRunCmdOptions, c common.Args, namespace str
 
 	dryRunOpt := dryrun.NewDryRunOption(newClient, dm, pd, objs)
 	ctx := oamutil.SetNamespaceInCtx(context.Background(), namespace)
+	if cmdOption.Env != "" || cmdOption.Cluster != "" || hasEnvBindingPolicy(app) {
+		placements, err := dryRunOpt.ExecuteMultiClusterDryRun(ctx, app, cmdOption.Env, cmdOption.Cluster)
+		if err != nil {
+			return buff, errors.WithMessage(err, "generate OAM objects")
+		}
+		for _, placement := range placements {
+			title := fmt.Sprintf("Application(%s) -- Env(%s) -- Cluster(%s)", app.Name, placement.Env, placement.Decision.Cluster)
+			if placement.Env == "" {
+				title = fmt.Sprintf("Application(%s) -- Cluster(%s)", app.Name, placement.Decision.Cluster)
+			}
+			if err := writeComponentManifests(&buff, title, placement.Components); err != nil {
+				return buff, err
+			}
+		}
+		return buff, nil
+	}
 	comps, err := dryRunOpt.ExecuteDryRun(ctx, app)
 	if err != nil {
 		return buff, errors.WithMessage(err, "generate OAM objects")
 	}
-
-	var components = make(map[string]*unstructured.Unstructured)
-	for _, comp := range comps {
-		components[comp.Name] = comp.StandardWorkload
+	if err := writeComponentManifests(&buff, fmt.Sprintf("Application(%s)", app.Name), comps); err != nil {
+		return buff, err
 	}
+	return buff, nil
+}
+
+// writeComponentManifests writes the workloads and traits of the components in YAML
+func writeComponentManifests(buff *bytes.Buffer, title string, comps []*types.ComponentManifest) error {
 	for _, c := range comps {
-		buff.Write([]byte(fmt.Sprintf("---\n# Application(%s) -- Component(%s) \n---\n\n", app.Name, c.Name)))
-		result, err := yaml.Marshal(components[c.Name])
+		buff.Write([]byte(fmt.Sprintf("---\n# %s -- Component(%s) \n---\n\n", title, c.Name)))
+		result, err := yaml.Marshal(c.StandardWorkload)
 		if err != nil {
-			return buff, errors.WithMessage(err, "marshal result for component "+c.Name+" object in yaml format")
+			return errors.WithMessage(err, "marshal result for component "+c.Name+" object in yaml format")
 		}
 		buff.Write(result)
 		buff.Write([]byte("\n---\n"))
 		for _, t := range c.Traits {
 			result, err := yaml.Marshal(t)
 			if err != nil {
-				return buff, errors.WithMessage(err, "marshal result for component "+c.Name+" object in yaml format")
+				return errors.WithMessage(err, "marshal result for component "+c.Name+" object in yaml format")
 			}
 			buff.Write(result)
 			buff.Write([]byte("\n---\n"))
 		}
 		buff.Write([]byte("\n"))
 	}
-	return buff, nil
+	return nil
+}
+
+// addPlacementArgs adds the flags to select the env and the cluster to render the application for. The env flag
+// overrides the global env flag, so the namespace is got by getDryRunNamespace.
+func addPlacementArgs(cmd *cobra.Command, o *DryRunCmdOptions) {
+	cmd.Flags().StringVarP(&o.Env, "env", "e", "", "specify the env in the env-binding policy of the application, by default all the envs are rendered")
+	cmd.Flags().StringVarP(&o.Cluster, "cluster", "", "", "specify the cluster to render the application for, by default all the clusters the envs are placed in are rendered")
+}
+
+// getDryRunNamespace gets the namespace from the namespace flag or the current vela env, since the env flag selects
+// the env in the env-binding policy rather than the vela env
+func getDryRunNamespace(cmd *cobra.Command, c common.Args) (string, error) {
+	namespace, err := cmd.Flags().GetString(Namespace)
+	if err != nil {
+		return "", err
+	}
+	if namespace != "" {
+		return namespace, nil
+	}
+	velaEnv, err := GetFlagEnvOrCurrent(nil, c)
+	if err != nil {
+		return "", err
+	}
+	return velaEnv.Namespace, nil
+}
+
+func hasEnvBindingPolicy(app *corev1beta1.Application) bool {
+	for _, policy := range app.Spec.Policies {
+		if policy.Type == v1alpha1.EnvBindingPolicyType {
+			return true
+		}
+	}
+	return false
 }
 
 // ReadObjectsFromFile will read objects from file or dir in the format of yaml
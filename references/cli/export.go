@@ -17,22 +17,37 @@ limitations under the License.
 package cli
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 
+	corev1beta1 "github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
 	"github.com/oam-dev/kubevela/references/common"
 )
 
 // NewExportCommand will create command for exporting deploy manifests from an AppFile
 func NewExportCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command {
 	appFilePath := new(string)
+	format := new(string)
+	output := new(string)
 	cmd := &cobra.Command{
 		Use:                   "export",
 		DisableFlagsInUseLine: true,
 		Short:                 "Export deploy manifests from appfile",
-		Long:                  "Export deploy manifests from appfile",
+		Long: "Export deploy manifests from appfile. With the format flag, the components and traits of the appfile or application file " +
+			"are rendered into a helm chart or a kustomize base, the envs of the env-binding policy are exported as the values files " +
+			"of the chart or the overlays of the kustomize base.",
+		Example: "vela export -f app.yaml --format helm -o ./chart",
 		Annotations: map[string]string{
 			types.TagCommandType: types.TypeStart,
 		},
@@ -44,17 +59,105 @@ func NewExportCommand(c common2.Args, ioStream cmdutil.IOStreams) *cobra.Command
 			o := &common.AppfileOptions{
 				IO: ioStream,
 			}
-			_, data, err := o.Export(*appFilePath, namespace, true, c)
+			if *format == "" {
+				_, data, err := o.Export(*appFilePath, namespace, true, c)
+				if err != nil {
+					return err
+				}
+				_, err = ioStream.Out.Write(data)
+				return err
+			}
+			if *format != dryrun.ExportFormatHelm && *format != dryrun.ExportFormatKustomize {
+				return fmt.Errorf("unsupported export format %s, only %s and %s are supported", *format, dryrun.ExportFormatHelm, dryrun.ExportFormatKustomize)
+			}
+			app, err := loadExportApplication(o, *appFilePath, namespace, c)
+			if err != nil {
+				return err
+			}
+			files, err := exportApplication(c, app, *format)
 			if err != nil {
 				return err
 			}
-			_, err = ioStream.Out.Write(data)
-			return err
+			dir := *output
+			if dir == "" {
+				dir = app.Name
+			}
+			for _, file := range files {
+				path := filepath.Join(dir, file.Path)
+				if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+					return err
+				}
+				//nolint:gosec
+				if err := os.WriteFile(path, file.Content, 0644); err != nil {
+					return err
+				}
+			}
+			ioStream.Infof("Application %s is exported as %s into %s\n", app.Name, *format, dir)
+			return nil
 		},
 	}
 	cmd.SetOut(ioStream.Out)
 
 	addNamespaceArg(cmd)
 	cmd.Flags().StringVarP(appFilePath, "file", "f", "", "specify file path for appfile")
+	cmd.Flags().StringVarP(format, "format", "", "", "export the application as a helm chart or a kustomize base, support: [helm, kustomize]")
+	cmd.Flags().StringVarP(output, "output", "o", "", "specify the directory to export the helm chart or kustomize base into, by default the application name")
 	return cmd
 }
+
+// loadExportApplication loads the application from the application file, or builds it from the appfile
+func loadExportApplication(o *common.AppfileOptions, filePath, namespace string, c common2.Args) (*corev1beta1.Application, error) {
+	var app *corev1beta1.Application
+	if isApplicationFile(filePath) {
+		var err error
+		if app, err = readApplicationFromFile(filePath); err != nil {
+			return nil, err
+		}
+	} else {
+		result, _, err := o.Export(filePath, namespace, true, c)
+		if err != nil {
+			return nil, err
+		}
+		app = result.GetApplication()
+	}
+	if app.Namespace == "" {
+		app.Namespace = namespace
+	}
+	return app, nil
+}
+
+func isApplicationFile(filePath string) bool {
+	if filePath == "" {
+		return false
+	}
+	data, err := os.ReadFile(filepath.Clean(filePath))
+	if err != nil {
+		return false
+	}
+	typeMeta := metav1.TypeMeta{}
+	if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+		return false
+	}
+	return typeMeta.Kind == "Application"
+}
+
+func exportApplication(c common2.Args, app *corev1beta1.Application, format string) ([]*dryrun.ExportedFile, error) {
+	newClient, err := c.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	pd, err := c.GetPackageDiscover()
+	if err != nil {
+		return nil, err
+	}
+	dm, err := c.GetDiscoveryMapper()
+	if err != nil {
+		return nil, err
+	}
+	dryRunOpt := dryrun.NewDryRunOption(newClient, dm, pd, nil)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), app.Namespace)
+	if format == dryrun.ExportFormatHelm {
+		return dryRunOpt.ExportHelmChart(ctx, app)
+	}
+	return dryRunOpt.ExportKustomize(ctx, app)
+}
@@ -23,11 +23,15 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/cue/packages"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/oam"
 	"github.com/oam-dev/kubevela/pkg/oam/discoverymapper"
+	oamutil "github.com/oam-dev/kubevela/pkg/oam/util"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
 	"github.com/oam-dev/kubevela/references/appfile/dryrun"
@@ -67,13 +71,14 @@ func NewLiveDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 		Use:                   "live-diff",
 		DisableFlagsInUseLine: true,
 		Short:                 "Dry-run an application, and do diff on a specific app revison",
-		Long:                  "Dry-run an application, and do diff on a specific app revison. The provided capability definitions will be used during Dry-run. If any capabilities used in the app are not found in the provided ones, it will try to find from cluster.",
-		Example:               "vela live-diff -f app-v2.yaml -r app-v1 --context 10",
+		Long: "Dry-run an application, and do diff on a specific app revison. The provided capability definitions will be used during Dry-run. If any capabilities used in the app are not found in the provided ones, it will try to find from cluster. " +
+			"If the env or cluster is specified, the resources rendered for each cluster are compared with the live objects in the cluster instead.",
+		Example: "vela live-diff -f app-v2.yaml -r app-v1 --context 10\nvela live-diff -f app-v2.yaml --env prod --cluster cluster-hangzhou",
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			namespace, err := getDryRunNamespace(cmd, c)
 			if err != nil {
 				return err
 			}
@@ -90,6 +95,7 @@ func NewLiveDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comma
 	cmd.Flags().StringVarP(&o.DefinitionFile, "definition", "d", "", "specify a file or directory containing capability definitions, they will only be used in dry-run rather than applied to K8s cluster")
 	cmd.Flags().StringVarP(&o.Revision, "Revision", "r", "", "specify an application Revision name, by default, it will compare with the latest Revision")
 	cmd.Flags().IntVarP(&o.Context, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
+	addPlacementArgs(cmd, &o.DryRunCmdOptions)
 	addNamespaceArg(cmd)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
@@ -128,6 +134,13 @@ func LiveDiffApplication(cmdOption *LiveDiffCmdOptions, c common.Args, namespace
 		app.SetNamespace(namespace)
 	}
 
+	if cmdOption.Env != "" || cmdOption.Cluster != "" {
+		if cmdOption.Revision != "" {
+			return buff, fmt.Errorf("the revision cannot be specified with the env or cluster")
+		}
+		return liveDiffPlacements(cmdOption, c, app, dm, pd, objs)
+	}
+
 	appRevision := &v1beta1.ApplicationRevision{}
 	if cmdOption.Revision != "" {
 		// get the Revision if user specifies
@@ -166,3 +179,27 @@ func LiveDiffApplication(cmdOption *LiveDiffCmdOptions, c common.Args, namespace
 
 	return buff, nil
 }
+
+// liveDiffPlacements compares the resources rendered for each cluster with the live objects in the cluster
+func liveDiffPlacements(cmdOption *LiveDiffCmdOptions, c common.Args, app *v1beta1.Application, dm discoverymapper.DiscoveryMapper, pd *packages.PackageDiscover, objs []oam.Object) (bytes.Buffer, error) {
+	var buff = bytes.Buffer{}
+	// the live objects in the managed clusters are got through cluster-gateway, the config is copied so that the
+	// shared one is not wrapped
+	config := rest.CopyConfig(c.Config)
+	config.Wrap(multicluster.NewSecretModeMultiClusterRoundTripper)
+	newClient, err := client.New(config, client.Options{Scheme: c.Schema})
+	if err != nil {
+		return buff, err
+	}
+	liveDiffOption := dryrun.NewLiveDiffOption(newClient, dm, pd, objs)
+	ctx := oamutil.SetNamespaceInCtx(context.Background(), app.Namespace)
+	diffs, err := liveDiffOption.DiffWithLiveObjects(ctx, app, cmdOption.Env, cmdOption.Cluster)
+	if err != nil {
+		return buff, errors.WithMessage(err, "cannot calculate diff")
+	}
+	reportDiffOpt := dryrun.NewReportDiffOption(cmdOption.Context, &buff)
+	for _, diff := range diffs {
+		reportDiffOpt.PrintPlacementDiffReport(diff)
+	}
+	return buff, nil
+}
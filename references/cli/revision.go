@@ -0,0 +1,275 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/apprevision"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	"github.com/oam-dev/kubevela/pkg/oam/util"
+	"github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/references/appfile"
+	"github.com/oam-dev/kubevela/references/appfile/dryrun"
+)
+
+// NewRevisionCommand create `revision` command
+func NewRevisionCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revision",
+		Short: "Manage the revisions of an application",
+		Long:  "List, inspect and compare the revisions of an application, and rollback the application to a revision.",
+		Annotations: map[string]string{
+			types.TagCommandType: types.TypeApp,
+		},
+	}
+	cmd.AddCommand(
+		NewRevisionListCommand(c, ioStreams),
+		NewRevisionGetCommand(c, ioStreams),
+		NewRevisionDiffCommand(c, ioStreams),
+		NewRevisionRollbackCommand(c, ioStreams),
+	)
+	return cmd
+}
+
+// NewRevisionListCommand create revision list command
+func NewRevisionListCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List the revisions of an application",
+		Long:    "List the revisions of an application in cluster",
+		Example: "vela revision list <application-name>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return fmt.Errorf("must specify application name")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			revisions, err := listRevisions(kubecli, app)
+			if err != nil {
+				return err
+			}
+			table := newUITable()
+			table.AddRow("NAME", "PUBLISH-VERSION", "CURRENT", "CREATED-TIME")
+			for _, rev := range revisions {
+				current := ""
+				if app.Status.LatestRevision != nil && app.Status.LatestRevision.Name == rev.Name {
+					current = "*"
+				}
+				table.AddRow(rev.Name, rev.GetAnnotations()[oam.AnnotationPublishVersion], current, rev.CreationTimestamp.String())
+			}
+			ioStreams.Info(table.String())
+			return nil
+		},
+	}
+	addNamespaceArg(cmd)
+	return cmd
+}
+
+// NewRevisionGetCommand create revision get command
+func NewRevisionGetCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "get",
+		Short:   "Get the application recorded in a revision",
+		Long:    "Get the application recorded in a revision of an application in cluster",
+		Example: "vela revision get <application-name> <revision>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("must specify application name and revision")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			rev, err := getRevision(kubecli, namespace, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			app := apprevision.ApplicationFromRevision(rev)
+			app.Status = rev.Spec.Application.Status
+			out, err := yaml.Marshal(app)
+			if err != nil {
+				return err
+			}
+			ioStreams.Info(string(out))
+			return nil
+		},
+	}
+	addNamespaceArg(cmd)
+	return cmd
+}
+
+// NewRevisionDiffCommand create revision diff command
+func NewRevisionDiffCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	var diffContext int
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Show the differences between two revisions of an application",
+		Long: "Show the differences of the components, traits, policies and workflow steps between two revisions of an application. " +
+			"If the second revision is not specified, compare with the current revision of the application.",
+		Example: "vela revision diff <application-name> <revision> [revision]",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("must specify application name and revision")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			target := ""
+			if len(args) > 2 {
+				target = args[2]
+			} else {
+				app, err := appfile.LoadApplication(namespace, args[0], c)
+				if err != nil {
+					return err
+				}
+				if app.Status.LatestRevision == nil || app.Status.LatestRevision.Name == "" {
+					return fmt.Errorf("the latest revision is not set: %s", app.Name)
+				}
+				target = app.Status.LatestRevision.Name
+			}
+			baseRev, err := getRevision(kubecli, namespace, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			targetRev, err := getRevision(kubecli, namespace, args[0], target)
+			if err != nil {
+				return err
+			}
+			entries, err := apprevision.Diff(apprevision.ApplicationFromRevision(baseRev), apprevision.ApplicationFromRevision(targetRev))
+			if err != nil {
+				return err
+			}
+			reportDiffOpt := dryrun.NewReportDiffOption(diffContext, cmd.OutOrStdout())
+			reportDiffOpt.PrintRevisionDiffReport(baseRev.Name, targetRev.Name, entries)
+			return nil
+		},
+	}
+	cmd.Flags().IntVarP(&diffContext, "context", "c", -1, "output number lines of context around changes, by default show all unchanged lines")
+	addNamespaceArg(cmd)
+	cmd.SetOut(ioStreams.Out)
+	return cmd
+}
+
+// NewRevisionRollbackCommand create revision rollback command
+func NewRevisionRollbackCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rollback",
+		Short: "Rollback an application to a revision",
+		Long: "Rollback an application to a revision by publishing the components, policies and workflow recorded in the revision again. " +
+			"The components keep the revisions pinned in the revision, and a new revision of the application will be created.",
+		Example: "vela revision rollback <application-name> <revision>",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return fmt.Errorf("must specify application name and revision")
+			}
+			namespace, err := GetFlagNamespaceOrEnv(cmd, c)
+			if err != nil {
+				return err
+			}
+			app, err := appfile.LoadApplication(namespace, args[0], c)
+			if err != nil {
+				return err
+			}
+			if app.Status.Workflow != nil && !app.Status.Workflow.Terminated && !app.Status.Workflow.Suspend && !app.Status.Workflow.Finished {
+				return fmt.Errorf("can not rollback an application with a running workflow")
+			}
+			kubecli, err := c.GetClient()
+			if err != nil {
+				return err
+			}
+			rev, err := getRevision(kubecli, namespace, app.Name, args[1])
+			if err != nil {
+				return err
+			}
+			if app.Status.LatestRevision != nil && app.Status.LatestRevision.Name == rev.Name {
+				return fmt.Errorf("the application is already at the revision %s", rev.Name)
+			}
+			apprevision.Rollback(app, rev)
+			if err := kubecli.Update(context.TODO(), app); err != nil {
+				return err
+			}
+			ioStreams.Infof("Successfully rollback application %s to the revision %s\n", app.Name, rev.Name)
+			return nil
+		},
+	}
+	addNamespaceArg(cmd)
+	return cmd
+}
+
+// listRevisions lists the revisions of the application sorted by the revision number
+func listRevisions(kubecli client.Client, app *v1beta1.Application) ([]v1beta1.ApplicationRevision, error) {
+	revisions := &v1beta1.ApplicationRevisionList{}
+	if err := kubecli.List(context.TODO(), revisions, client.InNamespace(app.Namespace), client.MatchingLabels{oam.LabelAppName: app.Name}); err != nil {
+		return nil, err
+	}
+	items := revisions.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		vi, _ := util.ExtractRevisionNum(items[i].Name, "-")
+		vj, _ := util.ExtractRevisionNum(items[j].Name, "-")
+		return vi < vj
+	})
+	return items, nil
+}
+
+// getRevision gets the revision of the application, the revision can be specified by the full name like `app-v1`
+// or just the version like `v1`
+func getRevision(kubecli client.Client, namespace, appName, revision string) (*v1beta1.ApplicationRevision, error) {
+	name := revision
+	if !strings.HasPrefix(revision, appName+"-") {
+		name = appName + "-" + revision
+	}
+	rev := &v1beta1.ApplicationRevision{}
+	if err := kubecli.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, rev); err != nil {
+		return nil, errors.Wrapf(err, "cannot get application revision %q", name)
+	}
+	if rev.GetLabels()[oam.LabelAppName] != appName {
+		return nil, fmt.Errorf("the revision %s does not belong to the application %s", name, appName)
+	}
+	return rev, nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KubeVela Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/common"
+	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
+	"github.com/oam-dev/kubevela/pkg/oam"
+	common2 "github.com/oam-dev/kubevela/pkg/utils/common"
+	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+)
+
+func createRevisions(t *testing.T, c common2.Args) *v1beta1.Application {
+	ctx := context.TODO()
+	r := require.New(t)
+	app := &v1beta1.Application{
+		ObjectMeta: metav1.ObjectMeta{Name: "revision-app", Namespace: "default"},
+		Spec:       workflowSpec,
+	}
+	for i, image := range []string{"busybox:1", "busybox:2"} {
+		spec := workflowSpec.DeepCopy()
+		spec.Components[0].Properties = &runtime.RawExtension{Raw: []byte(fmt.Sprintf(`{"image":"%s"}`, image))}
+		spec.Components[0].ExternalRevision = fmt.Sprintf("test-component-v%d", i+1)
+		// the workflow is not a part of the revision but recorded in its last applied configuration
+		applied, err := json.Marshal(&v1beta1.Application{Spec: *spec})
+		r.NoError(err)
+		spec.Workflow = nil
+		r.NoError(c.Client.Create(ctx, &v1beta1.ApplicationRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("revision-app-v%d", i+1),
+				Namespace:   "default",
+				Labels:      map[string]string{oam.LabelAppName: "revision-app"},
+				Annotations: map[string]string{oam.AnnotationLastAppliedConfig: string(applied)},
+			},
+			Spec: v1beta1.ApplicationRevisionSpec{Application: v1beta1.Application{Spec: *spec}},
+		}))
+		app.Spec = *spec.DeepCopy()
+		app.Spec.Workflow = workflowSpec.Workflow
+	}
+	app.Status.LatestRevision = &common.Revision{Name: "revision-app-v2", Revision: 2}
+	r.NoError(c.Client.Create(ctx, app))
+	return app
+}
+
+func TestRevisionDiff(t *testing.T) {
+	c := initArgs()
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	r := require.New(t)
+	createRevisions(t, c)
+
+	cmd := NewRevisionDiffCommand(c, ioStream)
+	initCommand(cmd)
+	out := bytes.Buffer{}
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"revision-app", "v1"})
+	r.NoError(cmd.Execute())
+	r.Contains(out.String(), "Revision (revision-app-v1) -> (revision-app-v2)")
+	r.Contains(out.String(), "Component (test-component) has been modified(*)")
+	r.Contains(out.String(), "-   image: busybox:1")
+	r.Contains(out.String(), "+   image: busybox:2")
+	r.Contains(out.String(), "+ externalRevision: test-component-v2")
+
+	cmd = NewRevisionDiffCommand(c, ioStream)
+	initCommand(cmd)
+	out.Reset()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"revision-app", "revision-app-v2", "v2"})
+	r.NoError(cmd.Execute())
+	r.Contains(out.String(), "have no difference")
+
+	cmd = NewRevisionDiffCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"revision-app", "v3"})
+	r.Error(cmd.Execute())
+}
+
+func TestRevisionRollback(t *testing.T) {
+	c := initArgs()
+	ioStream := cmdutil.IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+	r := require.New(t)
+	createRevisions(t, c)
+
+	cmd := NewRevisionRollbackCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"revision-app", "v2"})
+	r.Equal(fmt.Errorf("the application is already at the revision revision-app-v2"), cmd.Execute())
+
+	cmd = NewRevisionRollbackCommand(c, ioStream)
+	initCommand(cmd)
+	cmd.SetArgs([]string{"revision-app", "v1"})
+	r.NoError(cmd.Execute())
+	app := &v1beta1.Application{}
+	r.NoError(c.Client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "revision-app"}, app))
+	r.Equal(`{"image":"busybox:1"}`, string(app.Spec.Components[0].Properties.Raw))
+	r.Equal("test-component-v1", app.Spec.Components[0].ExternalRevision)
+	r.NotNil(app.Spec.Workflow)
+}
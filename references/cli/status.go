@@ -18,7 +18,9 @@ package cli
 
 import (
 	"context"
+	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -31,8 +33,10 @@ import (
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1alpha2"
 	"github.com/oam-dev/kubevela/apis/core.oam.dev/v1beta1"
 	"github.com/oam-dev/kubevela/apis/types"
+	"github.com/oam-dev/kubevela/pkg/multicluster"
 	"github.com/oam-dev/kubevela/pkg/utils/common"
 	cmdutil "github.com/oam-dev/kubevela/pkg/utils/util"
+	"github.com/oam-dev/kubevela/pkg/velaql/providers/query"
 	"github.com/oam-dev/kubevela/references/appfile"
 )
 
@@ -84,10 +88,17 @@ const (
 func NewAppStatusCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Command {
 	ctx := context.Background()
 	cmd := &cobra.Command{
-		Use:     "status APP_NAME",
-		Short:   "Show status of an application",
-		Long:    "Show status of an application, including workloads and traits of each service.",
-		Example: `vela status APP_NAME`,
+		Use:   "status APP_NAME",
+		Short: "Show status of an application",
+		Long:  "Show status of an application, including workloads and traits of each service.",
+		Example: `  # Show the status of an application
+  vela status APP_NAME
+
+  # Show the resource topology tree of an application
+  vela status APP_NAME --tree
+
+  # Show the endpoints exposed by an application
+  vela status APP_NAME --endpoint`,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			return c.SetConfig()
 		},
@@ -102,6 +113,25 @@ func NewAppStatusCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comm
 				os.Exit(1)
 			}
 			appName := args[0]
+			printTree, err := cmd.Flags().GetBool("tree")
+			if err != nil {
+				return err
+			}
+			printEndpoint, err := cmd.Flags().GetBool("endpoint")
+			if err != nil {
+				return err
+			}
+			if printTree || printEndpoint {
+				c.Config.Wrap(multicluster.NewSecretModeMultiClusterRoundTripper)
+				newClient, err := c.GetClient()
+				if err != nil {
+					return err
+				}
+				if printEndpoint {
+					return printAppEndpoints(newClient, ioStreams, appName, namespace)
+				}
+				return printAppResourceTree(newClient, ioStreams, appName, namespace)
+			}
 			newClient, err := c.GetClient()
 			if err != nil {
 				return err
@@ -113,6 +143,8 @@ func NewAppStatusCommand(c common.Args, ioStreams cmdutil.IOStreams) *cobra.Comm
 		},
 	}
 	cmd.Flags().StringP("svc", "s", "", "service name")
+	cmd.Flags().BoolP("tree", "t", false, "display the resource topology tree of the application, including resources derived from the managed resources")
+	cmd.Flags().Bool("endpoint", false, "display the endpoints exposed by the services, ingresses and routes of the application")
 	addNamespaceArg(cmd)
 	cmd.SetOut(ioStreams.Out)
 	return cmd
@@ -135,6 +167,80 @@ func printAppStatus(_ context.Context, c client.Client, ioStreams cmdutil.IOStre
 	return loopCheckStatus(c, ioStreams, appName, namespace)
 }
 
+func printAppResourceTree(c client.Client, ioStreams cmdutil.IOStreams, appName string, namespace string) error {
+	collector := query.NewAppCollector(c, query.Option{Name: appName, Namespace: namespace})
+	nodes, err := collector.CollectResourceTree()
+	if err != nil {
+		return err
+	}
+	clusterNodes := map[string][]*query.ResourceTreeNode{}
+	var clusters []string
+	for _, node := range nodes {
+		cluster := node.Cluster
+		if cluster == "" {
+			cluster = multicluster.ClusterLocalName
+		}
+		if _, ok := clusterNodes[cluster]; !ok {
+			clusters = append(clusters, cluster)
+		}
+		clusterNodes[cluster] = append(clusterNodes[cluster], node)
+	}
+	sort.Strings(clusters)
+	for _, cluster := range clusters {
+		ioStreams.Infof("%s\n", white.Sprintf("Cluster: %s", cluster))
+		printResourceTreeNodes(ioStreams, clusterNodes[cluster], "")
+		ioStreams.Info("")
+	}
+	return nil
+}
+
+func printAppEndpoints(c client.Client, ioStreams cmdutil.IOStreams, appName string, namespace string) error {
+	collector := query.NewAppCollector(c, query.Option{Name: appName, Namespace: namespace})
+	endpoints, err := collector.CollectServiceEndpoints()
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		ioStreams.Info("no endpoint found for the application")
+		return nil
+	}
+	table := newUITable()
+	table.AddRow("CLUSTER", "COMPONENT", "REF(KIND/NAMESPACE/NAME)", "ENDPOINT", "INNER")
+	for _, endpoint := range endpoints {
+		cluster := endpoint.Cluster
+		if cluster == "" {
+			cluster = multicluster.ClusterLocalName
+		}
+		ref := fmt.Sprintf("%s/%s/%s", endpoint.Ref.Kind, endpoint.Ref.Namespace, endpoint.Ref.Name)
+		table.AddRow(cluster, endpoint.Component, ref, endpoint.Endpoint.String(), endpoint.Endpoint.Inner)
+	}
+	ioStreams.Info(table.String())
+	return nil
+}
+
+func printResourceTreeNodes(ioStreams cmdutil.IOStreams, nodes []*query.ResourceTreeNode, prefix string) {
+	for i, node := range nodes {
+		branch, indent := "├── ", "│   "
+		if i == len(nodes)-1 {
+			branch, indent = "└── ", "    "
+		}
+		name := node.Name
+		if node.Namespace != "" {
+			name = node.Namespace + "/" + name
+		}
+		healthColor := getHealthStatusColor(node.HealthStatus.Status == query.HealthStatusHealthy)
+		status := healthColor.Sprint(node.HealthStatus.Status)
+		if node.HealthStatus.Message != "" {
+			status += " " + healthColor.Sprint(node.HealthStatus.Message)
+		}
+		if node.Error != "" {
+			status += " " + getHealthStatusColor(false).Sprintf("(failed to collect children: %s)", node.Error)
+		}
+		ioStreams.Infof("%s%s%s %s %s\n", prefix, branch, node.Kind, name, status)
+		printResourceTreeNodes(ioStreams, node.LeafNodes, prefix+indent)
+	}
+}
+
 func loadRemoteApplication(c client.Client, ns string, name string) (*v1beta1.Application, error) {
 	app := new(v1beta1.Application)
 	err := c.Get(context.Background(), client.ObjectKey{
@@ -72,6 +72,11 @@ type BuildResult struct {
 	scopes      []oam.Object
 }
 
+// GetApplication returns the Application built from the Appfile
+func (r *BuildResult) GetApplication() *corev1beta1.Application {
+	return r.application
+}
+
 // Option is option work with dashboard api server
 type Option struct {
 	// Optional filter, if specified, only components in such app will be listed
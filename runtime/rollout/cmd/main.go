@@ -33,6 +33,7 @@ import (
 	"github.com/oam-dev/kubevela/version"
 
 	kruise "github.com/openkruise/kruise-api/apps/v1alpha1"
+	kruisev1beta1 "github.com/openkruise/kruise-api/apps/v1beta1"
 )
 
 var (
@@ -49,8 +50,9 @@ func init() {
 	_ = core_oam_dev.AddToScheme(scheme)
 	// need request controllerRevision and deployment
 	_ = clientgoscheme.AddToScheme(scheme)
-	// need request cloneset
+	// need request cloneset, advanced daemonset and advanced statefulset
 	_ = kruise.AddToScheme(scheme)
+	_ = kruisev1beta1.AddToScheme(scheme)
 }
 
 func main() {